@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/restclient/pkg/bench"
+	"github.com/ideaspaper/restclient/pkg/client"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/executor"
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/session"
+)
+
+const defaultBenchRequests = 100
+
+var (
+	benchConcurrency int
+	benchRequests    int
+	benchDuration    time.Duration
+)
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench [file.http|file.rest] [flags]",
+	Short: "Load test a single request from a .http or .rest file",
+	Long: `Send a request repeatedly from a pool of concurrent workers and report
+throughput, status codes, errors and latency percentiles for each phase.
+
+Variables are resolved again for every request, so dynamic variables such as
+{{$uuid}} and {{$randomInt}} differ between requests. Session cookies and
+variables are read but never written, and requests are not saved to history.
+Pre-request and post-response scripts are not run.
+
+If neither --requests nor --duration is given, 100 requests are sent. When
+both are given, the run stops at whichever limit is reached first. Press
+Ctrl+C to stop early and print the results so far.
+
+Examples:
+  # Send 1000 requests from 20 workers
+  restclient bench api.http --name getUsers --concurrency 20 --requests 1000
+
+  # Send as many requests as possible for 30 seconds
+  restclient bench api.http --name getUsers --duration 30s
+
+  # Select a request by index
+  restclient bench api.http --index 2 --concurrency 5 --requests 200`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBench,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().StringVarP(&requestName, "name", "n", "", "request name (from @name metadata)")
+	benchCmd.Flags().IntVarP(&requestIndex, "index", "i", 0, "request index (1-based)")
+	benchCmd.Flags().IntVar(&benchConcurrency, "concurrency", 10, "number of concurrent workers")
+	benchCmd.Flags().IntVar(&benchRequests, "requests", 0, "total number of requests to send")
+	benchCmd.Flags().DurationVar(&benchDuration, "duration", 0, "send requests for this long (e.g. 30s, 1m)")
+	benchCmd.Flags().BoolVar(&skipValidate, "skip-validate", false, "skip request validation")
+	benchCmd.Flags().StringVar(&sessionName, "session", "", "use named session instead of directory-based session")
+	benchCmd.Flags().BoolVar(&noSession, "no-session", false, "don't load session state (cookies, variables and request results)")
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	setup, err := loadRequestForSend(cmd, args, cmd.OutOrStdout())
+	if err != nil {
+		if errors.Is(err, errors.ErrCanceled) {
+			return nil
+		}
+		return err
+	}
+	template := setup.request
+
+	// Prompts are answered once and reused for every request
+	if err := processSessionInputs(template, setup.filePath); err != nil {
+		if errors.Is(err, errors.ErrCanceled) {
+			return nil
+		}
+		return err
+	}
+
+	if err := applyPromptVariables(template, setup.varProcessor); err != nil {
+		return err
+	}
+
+	// Named requests the template refers to are sent once, up front
+	dependencies := newDependencies(cmd.OutOrStdout(), setup.filePath, setup.requests, setup.imported, setup.sessionCfg, setup.varProcessor, setup.envStore)
+	if err := dependencies.Resolve(context.Background(), template); err != nil {
+		return err
+	}
+
+	if template.Metadata.PreScript != "" || template.Metadata.PostScript != "" {
+		fmt.Fprintln(os.Stderr, "Warning: scripts are not run during bench")
+	}
+
+	opts := bench.Options{
+		Concurrency: benchConcurrency,
+		Requests:    benchRequests,
+		Duration:    benchDuration,
+	}
+	if opts.Requests == 0 && opts.Duration == 0 {
+		opts.Requests = defaultBenchRequests
+	}
+
+	clientCfg := executor.ClientConfigFor(setup.sessionCfg, template)
+	clientCfg.MaxIdleConnsPerHost = benchConcurrency
+
+	httpClient, err := client.NewHttpClient(clientCfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create HTTP client")
+	}
+
+	sessionMgr := loadBenchSession(setup.filePath, setup.sessionCfg, template)
+
+	cookiesLoaded := false
+	newRequest := func() (*models.HttpRequest, error) {
+		request := template.Clone()
+
+		// Dynamic variables must be resolved again for every request
+		setup.varProcessor.ClearCache()
+		if err := processRequestVariables(request, setup.varProcessor); err != nil {
+			return nil, err
+		}
+		if err := validateRequest(request); err != nil {
+			return nil, err
+		}
+
+		// The URL is only known once variables are resolved
+		if sessionMgr != nil && !cookiesLoaded {
+			httpClient.SetCookies(request.URL, sessionMgr.GetCookiesForURL(request.URL))
+			cookiesLoaded = true
+		}
+		return request, nil
+	}
+
+	fmt.Printf("%s %s\n", printMethod(template.Method), template.URL)
+	fmt.Println(printDimText(describeBenchRun(opts)))
+	fmt.Println()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	report, err := bench.Run(ctx, opts, httpClient, newRequest)
+	if report != nil && report.Requests > 0 {
+		printBenchReport(report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return errors.Wrap(errors.ErrCanceled, "bench cancelled")
+	}
+	return nil
+}
+
+// loadBenchSession loads the session read-only, or returns nil if sessions
+// are disabled or cannot be loaded
+func loadBenchSession(filePath string, sessionCfg *session.SessionConfig, request *models.HttpRequest) *session.SessionManager {
+	if noSession || !sessionCfg.RememberCookies() || request.Metadata.NoCookieJar {
+		return nil
+	}
+
+	sessionMgr, err := session.NewSessionManager("", filePath, sessionName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to initialize session: %v\n", err)
+		return nil
+	}
+	if err := sessionMgr.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load session: %v\n", err)
+		return nil
+	}
+	return sessionMgr
+}
+
+// describeBenchRun describes the limits of a run, e.g. "100 requests, 10 workers"
+func describeBenchRun(opts bench.Options) string {
+	var limit string
+	switch {
+	case opts.Requests > 0 && opts.Duration > 0:
+		limit = fmt.Sprintf("%d requests or %s", opts.Requests, opts.Duration)
+	case opts.Requests > 0:
+		limit = fmt.Sprintf("%d requests", opts.Requests)
+	default:
+		limit = opts.Duration.String()
+	}
+	return fmt.Sprintf("%s, %d workers", limit, opts.Concurrency)
+}
+
+// printBenchReport prints throughput, status codes, errors and a latency
+// table with one row per timing phase
+func printBenchReport(report *bench.Report) {
+	printHeader("Summary")
+	fmt.Printf("  Requests:    %d\n", report.Requests)
+	fmt.Printf("  Errors:      %d\n", report.Errors)
+	fmt.Printf("  Duration:    %s\n", formatRunDuration(report.Elapsed))
+	fmt.Printf("  Throughput:  %.2f req/s\n", report.Throughput())
+	fmt.Println()
+
+	if len(report.StatusCodes) > 0 {
+		printHeader("Status codes")
+		codes := make([]int, 0, len(report.StatusCodes))
+		for code := range report.StatusCodes {
+			codes = append(codes, code)
+		}
+		slices.Sort(codes)
+		for _, code := range codes {
+			status := fmt.Sprintf("%d", code)
+			if useColors() {
+				if code >= 400 {
+					status = errorColor.Sprint(status)
+				} else {
+					status = successColor.Sprint(status)
+				}
+			}
+			fmt.Printf("  %s  %d\n", status, report.StatusCodes[code])
+		}
+		fmt.Println()
+	}
+
+	if len(report.ErrorCounts) > 0 {
+		printHeader("Errors")
+		messages := make([]string, 0, len(report.ErrorCounts))
+		for msg := range report.ErrorCounts {
+			messages = append(messages, msg)
+		}
+		slices.Sort(messages)
+		for _, msg := range messages {
+			fmt.Printf("  %5d  %s\n", report.ErrorCounts[msg], msg)
+		}
+		fmt.Println()
+	}
+
+	if len(report.Timings) == 0 {
+		return
+	}
+
+	printHeader("Latency")
+	fmt.Printf("  %-20s %10s %10s %10s %10s\n", "", "p50", "p90", "p99", "max")
+	for _, phase := range bench.Phases {
+		// Phases that never occurred (e.g. TLS over plain HTTP) are noise
+		if report.Max(phase) == 0 {
+			continue
+		}
+		fmt.Printf("  %-20s %10s %10s %10s %10s\n", phase.Name,
+			formatBenchLatency(report.Percentile(50, phase)),
+			formatBenchLatency(report.Percentile(90, phase)),
+			formatBenchLatency(report.Percentile(99, phase)),
+			formatBenchLatency(report.Max(phase)))
+	}
+	fmt.Println()
+}
+
+// formatBenchLatency formats a latency with sub-millisecond precision
+func formatBenchLatency(d time.Duration) string {
+	return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+}
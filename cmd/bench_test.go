@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/bench"
+	"github.com/ideaspaper/restclient/pkg/session"
+)
+
+// executeBenchCommand runs the bench command and returns its output
+func executeBenchCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	defer func() {
+		requestName = ""
+		benchRequests = 0
+		benchDuration = 0
+		benchConcurrency = 10
+	}()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	rootCmd.SetArgs(append([]string{"bench", "--no-session"}, args...))
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String(), err
+}
+
+func TestBenchCommand_ResolvesVariablesPerRequest(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	var mu sync.Mutex
+	ids := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ids[r.Header.Get("X-Request-Id")] = true
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	httpFile := filepath.Join(t.TempDir(), "bench.http")
+	content := `# @name other
+GET ` + server.URL + `/other
+
+###
+
+# @name ping
+GET ` + server.URL + `/ping
+X-Request-Id: {{$uuid}}
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeBenchCommand(t, httpFile, "--name", "ping", "--concurrency", "4", "--requests", "20")
+	if err != nil {
+		t.Fatalf("bench error = %v\noutput:\n%s", err, output)
+	}
+
+	if len(ids) != 20 {
+		t.Errorf("got %d distinct request ids, want 20", len(ids))
+	}
+	for _, want := range []string{"Requests:    20", "Errors:      0", "200  20", "p50", "Total"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "TLS Handshake") {
+		t.Errorf("phases that never occurred should be omitted:\n%s", output)
+	}
+}
+
+func TestBenchCommand_UndefinedVariable(t *testing.T) {
+	httpFile := filepath.Join(t.TempDir(), "bench.http")
+	if err := os.WriteFile(httpFile, []byte("GET http://localhost/{{missing}}\n"), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	if _, err := executeBenchCommand(t, httpFile, "--requests", "5"); err == nil {
+		t.Error("expected an error for an unresolved variable")
+	}
+}
+
+func TestBenchCommand_FileVariablesOverrideSession(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+	t.Setenv("HOME", t.TempDir())
+
+	var mu sync.Mutex
+	paths := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths[r.URL.Path] = true
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	httpFile := filepath.Join(t.TempDir(), "bench.http")
+	content := "@resource = file\n\nGET " + server.URL + "/{{resource}}/{{id}}\n"
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	sessionMgr, err := session.NewSessionManager("", httpFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionMgr.SetVariable("resource", "session")
+	sessionMgr.SetVariable("id", "42")
+	if err := sessionMgr.SaveVariables(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Other tests run bench with --no-session
+	noSession = false
+	defer func() { benchRequests = 0 }()
+	oldStdout := os.Stdout
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	rootCmd.SetArgs([]string{"bench", httpFile, "--requests", "2"})
+	err = rootCmd.Execute()
+	os.Stdout = oldStdout
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+
+	if len(paths) != 1 || !paths["/file/42"] {
+		t.Errorf("paths = %v, want /file/42", paths)
+	}
+}
+
+func TestDescribeBenchRun(t *testing.T) {
+	tests := []struct {
+		opts bench.Options
+		want string
+	}{
+		{bench.Options{Concurrency: 10, Requests: 100}, "100 requests, 10 workers"},
+		{bench.Options{Concurrency: 2, Duration: 30 * time.Second}, "30s, 2 workers"},
+		{bench.Options{Concurrency: 1, Requests: 5, Duration: time.Minute}, "5 requests or 1m0s, 1 workers"},
+	}
+
+	for _, tt := range tests {
+		if got := describeBenchRun(tt.opts); got != tt.want {
+			t.Errorf("describeBenchRun(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
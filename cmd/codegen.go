@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/restclient/pkg/codegen"
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+var codegenLang string
+
+// codegenCmd represents the codegen command
+var codegenCmd = &cobra.Command{
+	Use:   "codegen [file.http|file.rest] --lang <language> [flags]",
+	Short: "Generate code sending a request",
+	Long: `Generate code sending a request from a .http or .rest file, for bug reports
+and SDK examples. Languages: ` + strings.Join(codegen.Languages(), ", ") + `.
+
+The request is resolved as by send: prompts are asked, the pre-request
+script is run and variables are substituted, and the session's default
+headers and cookies are added. Authorization is processed too, so Basic
+credentials are encoded and AWS requests signed. Digest authentication is
+only supported by python, httpie and powershell. Named requests the request
+refers to are not sent, so their results come from the session.
+
+Examples:
+  # Generate a Python script
+  restclient codegen api.http --name getUsers --lang python
+
+  # Generate a Go program from the last used file
+  restclient codegen --lang go`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCodegen,
+}
+
+func init() {
+	rootCmd.AddCommand(codegenCmd)
+
+	codegenCmd.Flags().StringVarP(&codegenLang, "lang", "l", "", "language: "+strings.Join(codegen.Languages(), ", "))
+	codegenCmd.Flags().StringVarP(&requestName, "name", "n", "", "request name (from @name metadata)")
+	codegenCmd.Flags().IntVarP(&requestIndex, "index", "i", 0, "request index (1-based)")
+	codegenCmd.Flags().BoolVar(&skipValidate, "skip-validate", false, "skip request validation")
+	codegenCmd.Flags().StringVar(&sessionName, "session", "", "use named session instead of directory-based session")
+	codegenCmd.Flags().BoolVar(&noSession, "no-session", false, "don't load session state (cookies, variables and request results)")
+	_ = codegenCmd.MarkFlagRequired("lang")
+	_ = codegenCmd.RegisterFlagCompletionFunc("lang", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return codegen.Languages(), cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+func runCodegen(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(codegen.Languages(), codegenLang) {
+		return errors.NewValidationErrorWithValue("lang", codegenLang, "unsupported, expected one of "+strings.Join(codegen.Languages(), ", "))
+	}
+
+	// Stdout holds the generated code
+	setup, err := loadRequestForSend(cmd, args, cmd.ErrOrStderr())
+	if err != nil {
+		if errors.Is(err, errors.ErrCanceled) {
+			return nil
+		}
+		return err
+	}
+
+	flow, err := prepareRequest(cmd.ErrOrStderr(), setup.request, setup.filePath, setup.sessionCfg, setup.varProcessor, setup.envStore)
+	if err != nil {
+		if errors.Is(err, errors.ErrCanceled) {
+			return nil
+		}
+		return err
+	}
+	if flow.Skip {
+		return errors.NewValidationError("request", "skipped by pre-request script")
+	}
+
+	code, err := codegen.Generate(withSessionState(setup.filePath, setup.request, setup.sessionCfg), codegenLang)
+	if err != nil {
+		return err
+	}
+	fmt.Print(code)
+	return nil
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCodegenCommand(t *testing.T) {
+	defer func() {
+		codegenLang = ""
+		requestName = ""
+		noSession = false
+	}()
+
+	path := writeLintFile(t, `@host = https://api.example.com
+
+# @name login
+POST {{host}}/login
+Content-Type: application/x-www-form-urlencoded
+
+user=john&password=secret
+`)
+
+	output, err := executeLintCommand(t, "codegen", path, "--name", "login", "--lang", "python", "--no-session")
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	for _, want := range []string{`url = "https://api.example.com/login"`, `"password": "secret",`, "requests.post(url, headers=headers, data=data)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q\nGot: %s", want, output)
+		}
+	}
+
+	if _, err := executeLintCommand(t, "codegen", path, "--lang", "cobol", "--no-session"); err == nil {
+		t.Error("an unknown language should fail")
+	}
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/fatih/color"
 )
@@ -118,18 +120,28 @@ func printMarker(marked bool) string {
 
 // printTestPass prints a passing test result
 func printTestPass(name string) {
+	fprintTestPass(os.Stdout, name)
+}
+
+// fprintTestPass prints a passing test result to w
+func fprintTestPass(w io.Writer, name string) {
 	if useColors() {
-		fmt.Printf("  %s %s\n", successColor.Sprint("✓"), name)
+		fmt.Fprintf(w, "  %s %s\n", successColor.Sprint("✓"), name)
 	} else {
-		fmt.Printf("  [PASS] %s\n", name)
+		fmt.Fprintf(w, "  [PASS] %s\n", name)
 	}
 }
 
 // printTestFail prints a failing test result
 func printTestFail(name, errMsg string) {
+	fprintTestFail(os.Stdout, name, errMsg)
+}
+
+// fprintTestFail prints a failing test result to w
+func fprintTestFail(w io.Writer, name, errMsg string) {
 	if useColors() {
-		fmt.Printf("  %s %s: %s\n", errorColor.Sprint("✗"), name, errMsg)
+		fmt.Fprintf(w, "  %s %s: %s\n", errorColor.Sprint("✗"), name, errMsg)
 	} else {
-		fmt.Printf("  [FAIL] %s: %s\n", name, errMsg)
+		fmt.Fprintf(w, "  [FAIL] %s: %s\n", name, errMsg)
 	}
 }
@@ -4,11 +4,11 @@ import (
 	"fmt"
 	"os"
 	"sort"
-	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ideaspaper/restclient/internal/filesystem"
+	"github.com/ideaspaper/restclient/internal/stringutil"
 	"github.com/ideaspaper/restclient/pkg/errors"
 	"github.com/ideaspaper/restclient/pkg/lastfile"
 	"github.com/ideaspaper/restclient/pkg/session"
@@ -422,19 +422,8 @@ func maskValue(value string) string {
 
 // maskValueByName masks the value if the variable name suggests it's sensitive
 func maskValueByName(name, value string) string {
-	lowerName := strings.ToLower(name)
-	if strings.Contains(lowerName, "password") ||
-		strings.Contains(lowerName, "secret") ||
-		strings.Contains(lowerName, "token") ||
-		strings.Contains(lowerName, "key") ||
-		strings.Contains(lowerName, "api_key") ||
-		strings.Contains(lowerName, "apikey") ||
-		strings.Contains(lowerName, "credential") ||
-		strings.Contains(lowerName, "auth") {
-		if len(value) > 4 {
-			return value[:4] + strings.Repeat("*", len(value)-4)
-		}
-		return strings.Repeat("*", len(value))
+	if stringutil.IsSensitiveName(name) {
+		return stringutil.Mask(value)
 	}
 
 	return maskValue(value)
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/formatter"
+)
+
+var fmtCheck bool
+
+// fmtCmd represents the fmt command
+var fmtCmd = &cobra.Command{
+	Use:   "fmt <file.http|file.rest>... [flags]",
+	Short: "Format .http files",
+	Long: `Format .http and .rest files in place.
+
+Formatting uppercases methods, writes headers as "Name: value" with
+canonical casing, aligns the "=" of consecutive @variable declarations,
+pretty-prints JSON bodies, writes ### separators with one blank line around
+them and collapses runs of blank lines. The requests a file holds are not
+changed.
+
+With --check, files are not written; the files that need formatting are
+listed and the command exits with a non-zero status if there are any,
+which suits CI and pre-commit hooks.
+
+Examples:
+  # Format files in place
+  restclient fmt api.http auth.http
+
+  # Fail if any file is not formatted
+  restclient fmt --check *.http`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runFmt,
+}
+
+func init() {
+	rootCmd.AddCommand(fmtCmd)
+
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "list files that are not formatted and exit non-zero instead of writing them")
+}
+
+func runFmt(cmd *cobra.Command, args []string) error {
+	unformatted := 0
+	for _, path := range args {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read file")
+		}
+
+		formatted := formatter.Format(string(content))
+		if formatted == string(content) {
+			continue
+		}
+		unformatted++
+
+		if fmtCheck {
+			fmt.Println(path)
+			continue
+		}
+		if err := os.WriteFile(path, []byte(formatted), 0644); err != nil {
+			return errors.Wrapf(err, "failed to write %s", path)
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Formatted %s\n", path)
+		}
+	}
+
+	if fmtCheck && unformatted > 0 {
+		return errors.NewValidationError("", fmt.Sprintf("%d of %d files are not formatted", unformatted, len(args)))
+	}
+	return nil
+}
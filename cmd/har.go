@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/har"
+	"github.com/ideaspaper/restclient/pkg/history"
+	"github.com/ideaspaper/restclient/pkg/session"
+)
+
+var (
+	harImportOutput string
+	harImportAll    bool
+	harExportOutput string
+	harExportLimit  int
+)
+
+var harCmd = &cobra.Command{
+	Use:   "har",
+	Short: "Import and export HTTP Archive (HAR) files",
+	Long: `Work with HTTP Archive (HAR) 1.2 files, as saved by browser devtools.
+
+Examples:
+  # Convert a browser capture to .http requests
+  restclient har import capture.har
+
+  # Export the request history
+  restclient har export -o history.har
+
+  # Record the requests of a run
+  restclient run api.http --reporter har --report-file run.har`,
+}
+
+var harImportCmd = &cobra.Command{
+	Use:   "import <capture.har>",
+	Short: "Convert a HAR capture to .http requests",
+	Long: `Convert the entries of a HAR file, such as one saved from the Network tab of
+browser devtools, to requests in a .http file.
+
+Static assets (images, fonts, stylesheets, scripts and media) and CORS
+preflight requests are skipped unless --all is given. Headers the client
+sets itself, such as Host and Content-Length, and HTTP/2 pseudo-headers are
+dropped, as is Accept-Encoding since browsers accept encodings the client
+cannot decode. Form bodies captured only as params are rebuilt, with file
+fields read from ./<filename>.
+
+Cookies sent and set in the capture are stored in the session of the
+.http file rather than written as headers, so the requests run with the
+browser's login.
+
+Examples:
+  # Write capture.http in the current directory
+  restclient har import capture.har
+
+  # Write to a specific file and keep static assets
+  restclient har import capture.har -o api/browser.http --all
+
+  # Store the cookies in a named session
+  restclient har import capture.har --session staging`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHARImport,
+}
+
+var harExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export request history as a HAR file",
+	Long: `Export the request history as a HAR 1.2 file, oldest request first, to view
+in browser devtools or a HAR viewer.
+
+Each entry has the request's headers, cookies and body, and the response's
+status, headers and cookies with its timing phases (DNS lookup, connection,
+TLS handshake, waiting and receiving). History keeps no response bodies,
+so only their size is exported. Requests sent before this version of
+restclient have no response details.
+
+To record the full exchanges of a run, bodies included, use
+restclient run --reporter har.
+
+Examples:
+  # Print the history as HAR
+  restclient har export
+
+  # Export the 10 most recent requests to a file
+  restclient har export --limit 10 -o recent.har`,
+	Args: cobra.NoArgs,
+	RunE: runHARExport,
+}
+
+func init() {
+	rootCmd.AddCommand(harCmd)
+	harCmd.AddCommand(harImportCmd)
+	harCmd.AddCommand(harExportCmd)
+
+	harImportCmd.Flags().StringVarP(&harImportOutput, "output", "o", "", "Output .http file (default: <capture>.http)")
+	harImportCmd.Flags().BoolVar(&harImportAll, "all", false, "Include static assets and CORS preflight requests")
+	harImportCmd.Flags().StringVar(&sessionName, "session", "", "store cookies in a named session instead of the directory-based session")
+	harImportCmd.Flags().BoolVar(&noSession, "no-session", false, "don't store the captured cookies")
+
+	harExportCmd.Flags().StringVarP(&harExportOutput, "output", "o", "", "Output file (default: stdout)")
+	harExportCmd.Flags().IntVarP(&harExportLimit, "limit", "n", 0, "Export only the most recent requests (0 = all)")
+}
+
+func runHARImport(cmd *cobra.Command, args []string) error {
+	harPath := args[0]
+
+	if _, err := os.Stat(harPath); os.IsNotExist(err) {
+		return errors.NewValidationErrorWithValue("HAR file", harPath, "file not found")
+	}
+
+	result, err := har.Import(harPath, har.ImportOptions{OutputFile: harImportOutput, IncludeStatic: harImportAll})
+	if err != nil {
+		return errors.Wrap(err, "import failed")
+	}
+
+	cookies := 0
+	if !noSession {
+		if cookies, err = storeHARCookies(result); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Successfully imported HAR file\n")
+	fmt.Printf("  Requests: %d\n", result.RequestsCount)
+	fmt.Printf("  Skipped:  %d\n", result.SkippedCount)
+	fmt.Printf("  Cookies:  %d\n", cookies)
+	fmt.Printf("\nFile created:\n  - %s\n", result.OutputFile)
+	return nil
+}
+
+// storeHARCookies stores the captured cookies in the session of the
+// imported .http file, returning how many were stored
+func storeHARCookies(result *har.ImportResult) (int, error) {
+	if len(result.Cookies) == 0 {
+		return 0, nil
+	}
+
+	sessionMgr, err := session.NewSessionManager("", result.OutputFile, sessionName)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create session manager")
+	}
+	if err := sessionMgr.LoadCookies(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, errors.Wrap(err, "failed to load cookies")
+	}
+
+	origins := make([]string, 0, len(result.Cookies))
+	for origin := range result.Cookies {
+		origins = append(origins, origin)
+	}
+	slices.Sort(origins)
+
+	count := 0
+	for _, origin := range origins {
+		sessionMgr.SetCookiesFromResponse(origin, result.Cookies[origin])
+		count += len(result.Cookies[origin])
+	}
+	if err := sessionMgr.SaveCookies(); err != nil {
+		return 0, errors.Wrap(err, "failed to save cookies")
+	}
+	return count, nil
+}
+
+func runHARExport(cmd *cobra.Command, args []string) error {
+	histMgr, err := history.NewHistoryManager("")
+	if err != nil {
+		return errors.Wrap(err, "failed to load history")
+	}
+
+	items := histMgr.GetRecent(harExportLimit)
+	archive := har.FromHistory(items)
+
+	if harExportOutput == "" {
+		return har.Write(cmd.OutOrStdout(), archive)
+	}
+
+	file, err := os.Create(harExportOutput)
+	if err != nil {
+		return errors.Wrap(err, "failed to create HAR file")
+	}
+	defer file.Close()
+
+	if err := har.Write(file, archive); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d requests to %s\n", len(items), harExportOutput)
+	return nil
+}
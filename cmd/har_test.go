@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/har"
+	"github.com/ideaspaper/restclient/pkg/history"
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/session"
+)
+
+func TestHARImportCommand(t *testing.T) {
+	defer func() {
+		harImportOutput = ""
+		harImportAll = false
+	}()
+
+	capture := filepath.Join(t.TempDir(), "capture.har")
+	content := `{"log": {"version": "1.2", "creator": {"name": "Firefox", "version": "120"}, "entries": [
+  {
+    "startedDateTime": "2024-01-02T03:04:05Z",
+    "time": 20,
+    "request": {
+      "method": "GET",
+      "url": "https://app.example.com/api/me",
+      "httpVersion": "HTTP/1.1",
+      "headers": [{"name": "Host", "value": "app.example.com"}, {"name": "Accept", "value": "application/json"}, {"name": "Cookie", "value": "sid=abc"}],
+      "cookies": [{"name": "sid", "value": "abc"}],
+      "queryString": [],
+      "headersSize": -1,
+      "bodySize": 0
+    },
+    "response": {"status": 200, "statusText": "OK", "httpVersion": "HTTP/1.1", "headers": [], "cookies": [], "content": {"size": 2, "mimeType": "application/json"}, "redirectURL": "", "headersSize": -1, "bodySize": 2},
+    "cache": {},
+    "timings": {"send": 0, "wait": 18, "receive": 2}
+  }
+]}}`
+	if err := os.WriteFile(capture, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	httpFile := filepath.Join(t.TempDir(), "browser.http")
+	output, err := executeLintCommand(t, "har", "import", capture, "-o", httpFile)
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if !strings.Contains(output, "Requests: 1") || !strings.Contains(output, "Cookies:  1") {
+		t.Errorf("output = %q", output)
+	}
+
+	got, err := os.ReadFile(httpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "GET https://app.example.com/api/me\nAccept: application/json\n"; string(got) != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+
+	sessionMgr, err := session.NewSessionManager("", httpFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sessionMgr.LoadCookies(); err != nil {
+		t.Fatal(err)
+	}
+	cookies := sessionMgr.GetCookiesForURL("https://app.example.com/api/me")
+	if len(cookies) != 1 || cookies[0].Name != "sid" || cookies[0].Value != "abc" {
+		t.Errorf("session cookies = %+v", cookies)
+	}
+
+	if _, err := executeLintCommand(t, "har", "import", filepath.Join(t.TempDir(), "missing.har")); err == nil {
+		t.Error("a missing HAR file should fail")
+	}
+}
+
+func TestHARExportCommand(t *testing.T) {
+	defer func() {
+		harExportOutput = ""
+		harExportLimit = 0
+	}()
+	t.Setenv("HOME", t.TempDir())
+
+	histMgr, err := history.NewHistoryManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/first", "/second"} {
+		request := &models.HttpRequest{Method: "GET", URL: "https://api.example.com" + path}
+		response := &models.HttpResponse{StatusCode: 200, StatusMessage: "200 OK", Timing: models.ResponseTiming{Total: 10 * time.Millisecond}}
+		if err := histMgr.AddWithResponse(request, nil, response); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "history.har")
+	rootCmd.SetArgs([]string{"har", "export", "--limit", "1", "-o", file})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+
+	archive, err := har.Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.Log.Entries) != 1 || archive.Log.Entries[0].Request.URL != "https://api.example.com/second" || archive.Log.Entries[0].Timings.Wait != 10 {
+		t.Errorf("entries = %+v", archive.Log.Entries)
+	}
+}
@@ -280,7 +280,7 @@ func runHistoryReplay(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("%s %s\n\n", printMethod(request.Method), request.URL)
 
-	return sendRequest("", request, sessionCfg, varProcessor, envStore)
+	return sendRequest(cmd.OutOrStdout(), "", request, sessionCfg, varProcessor, envStore)
 }
 
 // selectHistoryItem shows an interactive selector for history items
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/restclient/pkg/curl"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/parser"
+)
+
+var (
+	curlImportOutput string
+	curlImportName   string
+)
+
+// importCmd groups the commands converting other formats to .http requests
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Convert other request formats to .http requests",
+}
+
+var importCurlCmd = &cobra.Command{
+	Use:   "curl ['curl command' | -]",
+	Short: "Convert a curl command to an .http request",
+	Long: `Convert a curl command, as copied from browser devtools or a runbook, to an
+.http request. The command is read from the argument, or from stdin when
+there is none or it is -. Line continuations and shell quoting, including
+the $'...' strings Chrome writes, are understood.
+
+Headers (-H, -A, -e, -b, --compressed), bodies (-d, --data-raw,
+--data-binary, --data-urlencode, --json, -T), multipart forms (-F), methods
+(-X, -G, -I), credentials (-u, --digest, --oauth2-bearer) and timeouts
+(--max-time, --connect-timeout) are converted. Options without an .http
+equivalent, such as -k or --proxy, are reported on stderr.
+
+The request is printed, or appended to a file with -o.
+
+Examples:
+  # Convert a command
+  restclient import curl "curl -X POST https://api.example.com/users -d name=John"
+
+  # Paste a command and append it to a file as a named request
+  pbpaste | restclient import curl -o api.http --name createUser`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runImportCurl,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importCurlCmd)
+
+	importCurlCmd.Flags().StringVarP(&curlImportOutput, "output", "o", "", "append the request to a .http file")
+	importCurlCmd.Flags().StringVar(&curlImportName, "name", "", "name the request with # @name")
+}
+
+func runImportCurl(cmd *cobra.Command, args []string) error {
+	var command string
+	if len(args) == 0 || args[0] == "-" {
+		input, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return errors.Wrap(err, "failed to read stdin")
+		}
+		command = string(input)
+	} else {
+		command = args[0]
+	}
+
+	request, err := curl.Parse(strings.TrimSpace(command))
+	if err != nil {
+		return err
+	}
+	request.Name = curlImportName
+	for _, warning := range request.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	if curlImportOutput == "" {
+		fmt.Print(request.HTTP())
+		return nil
+	}
+	return appendRequest(curlImportOutput, request.HTTP())
+}
+
+// appendRequest appends a request block to a .http file, separating it
+// from the requests already there
+func appendRequest(path, block string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to read file")
+	}
+
+	var content strings.Builder
+	content.Write(existing)
+	if strings.TrimSpace(string(existing)) != "" {
+		if !strings.HasSuffix(string(existing), "\n") {
+			content.WriteString("\n")
+		}
+		content.WriteString("\n" + parser.RequestDelimiter + "\n\n")
+	}
+	content.WriteString(block)
+
+	if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	fmt.Printf("Added request to %s\n", path)
+	return nil
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/restclient/internal/filesystem"
+	"github.com/ideaspaper/restclient/pkg/bruno"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/insomnia"
+	"github.com/ideaspaper/restclient/pkg/postman"
+	"github.com/ideaspaper/restclient/pkg/session"
+)
+
+var (
+	collectionImportOutput         string
+	collectionImportSingleFile     bool
+	collectionImportNoScripts      bool
+	collectionImportNoEnvironments bool
+)
+
+var importInsomniaCmd = &cobra.Command{
+	Use:   "insomnia <export.json>",
+	Short: "Convert an Insomnia export to .http files",
+	Long: `Convert an Insomnia export, saved with Export Data as Insomnia v4 (JSON), to
+.http files, laid out as postman import does: a directory per workspace
+with a subdirectory and .http file per folder.
+
+Folders, headers, query parameters, bodies (JSON, text, form, multipart,
+GraphQL and file), auth (basic, digest, bearer, API key and AWS IAM,
+inherited from folders) and pre-request and after-response scripts are
+converted. Nunjucks variables become {{name}}, with nested environment
+values named with dots.
+
+The base environment is stored as $shared and its sub environments under
+their names, in the session of each directory files are written to.
+
+Examples:
+  # Import into the current directory
+  restclient import insomnia Insomnia_export.json
+
+  # Import into a single file, leaving environments out
+  restclient import insomnia Insomnia_export.json --single-file -o api.http --no-environments`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportInsomnia,
+}
+
+var importBrunoCmd = &cobra.Command{
+	Use:   "bruno <collection-dir>",
+	Short: "Convert a Bruno collection to .http files",
+	Long: `Convert a Bruno collection, given its directory or bruno.json, to .http
+files, laid out as postman import does: a directory for the collection with
+a subdirectory and .http file per folder.
+
+Folders (ordered by seq), headers and auth inherited from collection.bru
+and folder.bru, path parameters, bodies (JSON, text, XML, SPARQL, form,
+multipart, GraphQL and file), auth (basic, digest, bearer, API key and AWS
+Sig v4), request vars, assertions, scripts and tests are converted. The
+bru and res script objects become client and response.
+
+Environments in environments/*.bru are stored under their names in the
+session of each directory files are written to. Secret variables are
+skipped, as Bruno keeps their values outside the collection.
+
+Examples:
+  # Import into the current directory
+  restclient import bruno ./my-collection
+
+  # Import into a named session's environments
+  restclient import bruno ./my-collection -o api --session shop`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportBruno,
+}
+
+func init() {
+	importCmd.AddCommand(importInsomniaCmd)
+	importCmd.AddCommand(importBrunoCmd)
+
+	for _, cmd := range []*cobra.Command{importInsomniaCmd, importBrunoCmd} {
+		cmd.Flags().StringVarP(&collectionImportOutput, "output", "o", "", "Output path (directory for multi-file, file path for --single-file)")
+		cmd.Flags().BoolVar(&collectionImportSingleFile, "single-file", false, "Create a single .http file instead of multiple files")
+		cmd.Flags().BoolVar(&collectionImportNoScripts, "no-scripts", false, "Don't include pre-request and test scripts")
+		cmd.Flags().BoolVar(&collectionImportNoEnvironments, "no-environments", false, "Don't store environments in the session")
+		cmd.Flags().StringVar(&sessionName, "session", "", "store environments in a named session instead of the directory-based session")
+	}
+}
+
+func runImportInsomnia(cmd *cobra.Command, args []string) error {
+	if _, err := os.Stat(args[0]); os.IsNotExist(err) {
+		return errors.NewValidationErrorWithValue("export file", args[0], "file not found")
+	}
+
+	result, err := insomnia.Import(args[0], collectionImportOptions())
+	if err != nil {
+		return errors.Wrap(err, "import failed")
+	}
+	return printCollectionImport("Insomnia export", &result.ImportResult, result.Environments)
+}
+
+func runImportBruno(cmd *cobra.Command, args []string) error {
+	if _, err := os.Stat(args[0]); os.IsNotExist(err) {
+		return errors.NewValidationErrorWithValue("collection", args[0], "not found")
+	}
+
+	result, err := bruno.Import(args[0], collectionImportOptions())
+	if err != nil {
+		return errors.Wrap(err, "import failed")
+	}
+	return printCollectionImport("Bruno collection", &result.ImportResult, result.Environments)
+}
+
+// collectionImportOptions returns the Postman import options for the flags
+func collectionImportOptions() postman.ImportOptions {
+	opts := postman.DefaultImportOptions()
+	opts.SingleFile = collectionImportSingleFile
+	opts.IncludeScripts = !collectionImportNoScripts
+	if collectionImportSingleFile {
+		opts.OutputFile = collectionImportOutput
+	} else if collectionImportOutput != "" {
+		opts.OutputDir = collectionImportOutput
+	}
+	return opts
+}
+
+// printCollectionImport stores the environments of an import and prints
+// its summary
+func printCollectionImport(kind string, result *postman.ImportResult, environments map[string]map[string]string) error {
+	stored := 0
+	if !collectionImportNoEnvironments && len(environments) > 0 {
+		var err error
+		if stored, err = storeImportedEnvironments(result.FilesCreated, environments); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Successfully imported %s\n", kind)
+	fmt.Printf("  Requests:     %d\n", result.RequestsCount)
+	fmt.Printf("  Folders:      %d\n", result.FoldersCount)
+	fmt.Printf("  Environments: %d\n", stored)
+	fmt.Printf("\nFiles created:\n")
+	for _, file := range result.FilesCreated {
+		fmt.Printf("  - %s\n", file)
+	}
+
+	if len(result.Errors) > 0 {
+		fmt.Printf("\nWarnings:\n")
+		for _, err := range result.Errors {
+			fmt.Printf("  - %s\n", err)
+		}
+	}
+	return nil
+}
+
+// storeImportedEnvironments merges environments into the session of each
+// directory files were written to, or into the named session, returning
+// how many environments were stored
+func storeImportedEnvironments(files []string, environments map[string]map[string]string) (int, error) {
+	dirs := make(map[string]string)
+	for _, file := range files {
+		dirs[filepath.Dir(file)] = file
+	}
+	if sessionName != "" {
+		dirs = map[string]string{"": ""}
+	}
+
+	names := make([]string, 0, len(environments))
+	for name := range environments {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, file := range dirs {
+		sessionMgr, err := session.NewSessionManager("", file, sessionName)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to create session manager")
+		}
+		sessionPath := sessionMgr.GetSessionPath()
+
+		envStore, err := session.LoadOrCreateEnvironmentStore(filesystem.Default, sessionPath)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to load session environments")
+		}
+		for _, name := range names {
+			if !envStore.HasEnvironment(name) {
+				if err := envStore.AddEnvironment(name, nil); err != nil {
+					return 0, err
+				}
+			}
+			for key, value := range environments[name] {
+				if err := envStore.SetVariable(name, key, value); err != nil {
+					return 0, err
+				}
+			}
+		}
+		if err := session.SaveEnvironmentStore(filesystem.Default, sessionPath, envStore); err != nil {
+			return 0, errors.Wrap(err, "failed to save session environments")
+		}
+	}
+	return len(names), nil
+}
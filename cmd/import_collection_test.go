@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/restclient/internal/filesystem"
+	"github.com/ideaspaper/restclient/pkg/session"
+)
+
+func resetCollectionImportFlags() {
+	collectionImportOutput = ""
+	collectionImportSingleFile = false
+	collectionImportNoScripts = false
+	collectionImportNoEnvironments = false
+	sessionName = ""
+}
+
+// loadImportedEnvironments loads the environments of the session of a
+// .http file, or of a named session
+func loadImportedEnvironments(t *testing.T, httpFile, name string) *session.EnvironmentStore {
+	t.Helper()
+	sessionMgr, err := session.NewSessionManager("", httpFile, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := session.LoadOrCreateEnvironmentStore(filesystem.Default, sessionMgr.GetSessionPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestImportInsomniaCommand(t *testing.T) {
+	defer resetCollectionImportFlags()
+
+	export := filepath.Join(t.TempDir(), "export.json")
+	content := `{"_type": "export", "__export_format": 4, "resources": [
+  {"_id": "wrk_1", "_type": "workspace", "name": "Shop"},
+  {"_id": "env_1", "_type": "environment", "parentId": "wrk_1", "name": "Base", "data": {"baseUrl": "https://api.example.com"}},
+  {"_id": "env_2", "_type": "environment", "parentId": "env_1", "name": "staging", "data": {"baseUrl": "https://staging.example.com"}},
+  {"_id": "req_1", "_type": "request", "parentId": "wrk_1", "name": "Health", "method": "GET", "url": "{{ _.baseUrl }}/health"}
+]}`
+	if err := os.WriteFile(export, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	output, err := executeLintCommand(t, "import", "insomnia", export, "-o", outputDir)
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if !strings.Contains(output, "Requests:     1") || !strings.Contains(output, "Environments: 2") {
+		t.Errorf("output = %q", output)
+	}
+
+	httpFile := filepath.Join(outputDir, "Shop", "Shop.http")
+	got, err := os.ReadFile(httpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "GET {{baseUrl}}/health") {
+		t.Errorf("file = %q", got)
+	}
+
+	store := loadImportedEnvironments(t, httpFile, "")
+	if vars := store.GetEnvironment("staging"); vars["baseUrl"] != "https://staging.example.com" {
+		t.Errorf("staging = %v", vars)
+	}
+	if vars := store.GetEnvironment("development"); vars["baseUrl"] != "https://api.example.com" {
+		t.Errorf("the base environment should be shared, development = %v", vars)
+	}
+
+	if _, err := executeLintCommand(t, "import", "insomnia", filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("a missing export should fail")
+	}
+}
+
+func TestImportBrunoCommand(t *testing.T) {
+	defer resetCollectionImportFlags()
+
+	collection := t.TempDir()
+	files := map[string]string{
+		"bruno.json":             `{"version": "1", "name": "Shop", "type": "collection"}`,
+		"Health.bru":             "meta {\n  name: Health\n  seq: 1\n}\n\nget {\n  url: {{baseUrl}}/health\n  body: none\n  auth: none\n}\n",
+		"environments/local.bru": "vars {\n  baseUrl: http://localhost:3000\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(collection, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	httpFile := filepath.Join(t.TempDir(), "shop.http")
+	output, err := executeLintCommand(t, "import", "bruno", collection, "--single-file", "-o", httpFile, "--session", "shop")
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if !strings.Contains(output, "Environments: 1") {
+		t.Errorf("output = %q", output)
+	}
+
+	got, err := os.ReadFile(httpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "# @name Health\nGET {{baseUrl}}/health\n") {
+		t.Errorf("file = %q", got)
+	}
+
+	store := loadImportedEnvironments(t, "", "shop")
+	if vars := store.GetEnvironment("local"); vars["baseUrl"] != "http://localhost:3000" {
+		t.Errorf("local = %v", vars)
+	}
+}
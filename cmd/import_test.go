@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportCurlCommand(t *testing.T) {
+	defer func() {
+		curlImportOutput = ""
+		curlImportName = ""
+	}()
+
+	output, err := executeLintCommand(t, "import", "curl", "curl -u admin:pw https://api.example.com/users")
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if want := "GET https://api.example.com/users\nAuthorization: Basic admin:pw\n"; output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "api.http")
+	if err := os.WriteFile(path, []byte("GET https://example.com"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executeLintCommand(t, "import", "curl", "curl -d a=1 https://example.com", "-o", path, "--name", "create"); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "GET https://example.com\n\n###\n\n# @name create\nPOST https://example.com\nContent-Type: application/x-www-form-urlencoded\n\na=1\n"
+	if string(content) != want {
+		t.Errorf("file = %q, want %q", content, want)
+	}
+
+	if _, err := executeLintCommand(t, "import", "curl", "wget https://example.com"); err == nil {
+		t.Error("a command other than curl should fail")
+	}
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/restclient/internal/filesystem"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/lint"
+	"github.com/ideaspaper/restclient/pkg/session"
+)
+
+var (
+	lintFix       bool
+	lintCheck     bool
+	lintRules     []string
+	lintListRules bool
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint <file.http|file.rest>... [flags]",
+	Short: "Check .http files for mistakes",
+	Long: `Check .http and .rest files for likely mistakes: duplicate @name values,
+undefined variables, GET requests with a body, JSON bodies that don't parse,
+bodies without a Content-Type, unused file variables and hard-coded
+credentials. Run with --list-rules to see every rule.
+
+Variables are looked up in the file, the files it imports, prompts,
+client.global.set calls of the scripts of all linted files, and the
+environments (all of them) and variables of the session. Files importing
+one another should be linted together so that variables used by an
+importing file are not reported as unused.
+
+Each rule reports issues as errors or warnings; --rule changes the severity
+of a rule or turns it off. --fix fixes what it can (adding a JSON
+Content-Type and removing unused variables) and writes the files.
+
+The command exits with a non-zero status if errors remain, or with --check
+if any issue is found, which suits CI and pre-commit hooks.
+
+Examples:
+  # Lint files
+  restclient lint api.http auth.http
+
+  # Fail on warnings too, e.g. in a pre-commit hook
+  restclient lint --check *.http
+
+  # Fix what can be fixed
+  restclient lint --fix api.http
+
+  # Allow GET bodies and treat unused variables as errors
+  restclient lint api.http --rule get-with-body=off --rule unused-variable=error`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if lintListRules {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	RunE: runLint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "fix fixable issues and write the files")
+	lintCmd.Flags().BoolVar(&lintCheck, "check", false, "exit non-zero on warnings as well as errors")
+	lintCmd.Flags().StringArrayVar(&lintRules, "rule", nil, "set the severity of a rule: name=off|warning|error (repeatable)")
+	lintCmd.Flags().BoolVar(&lintListRules, "list-rules", false, "list the rules and their default severity")
+	lintCmd.Flags().StringVar(&sessionName, "session", "", "use named session instead of directory-based session")
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	if lintListRules {
+		printLintRules()
+		return nil
+	}
+
+	options := lint.Options{Rules: make(map[string]lint.Severity), Fix: lintFix}
+	for _, rule := range lintRules {
+		name, value, ok := strings.Cut(rule, "=")
+		if !ok {
+			return errors.NewValidationErrorWithValue("rule", rule, "expected name=off|warning|error")
+		}
+		severity, err := lint.ParseSeverity(value)
+		if err != nil {
+			return err
+		}
+		options.Rules[strings.TrimSpace(name)] = severity
+	}
+
+	files := make([]lint.File, 0, len(args))
+	for _, path := range args {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read file")
+		}
+		files = append(files, lint.File{Path: path, Content: string(content), Variables: lintVariables(path)})
+	}
+
+	results, err := lint.Lint(files, options)
+	if err != nil {
+		return err
+	}
+
+	errorCount, warningCount, fixedCount := 0, 0, 0
+	for _, result := range results {
+		if result.FixedCount > 0 {
+			if err := os.WriteFile(result.Path, []byte(result.Fixed), 0644); err != nil {
+				return errors.Wrapf(err, "failed to write %s", result.Path)
+			}
+			fixedCount += result.FixedCount
+		}
+		for _, issue := range result.Issues {
+			printLintIssue(result.Path, issue)
+			if issue.Severity == lint.SeverityError {
+				errorCount++
+			} else {
+				warningCount++
+			}
+		}
+	}
+
+	if fixedCount > 0 {
+		fmt.Fprintf(os.Stderr, "Fixed %d issues\n", fixedCount)
+	}
+	if errorCount > 0 || (lintCheck && warningCount > 0) {
+		return errors.NewValidationError("", fmt.Sprintf("%d errors, %d warnings", errorCount, warningCount))
+	}
+	return nil
+}
+
+// lintVariables returns the names of the environment and session
+// variables available to a file, from every environment
+func lintVariables(path string) []string {
+	sessionMgr, err := session.NewSessionManager("", path, sessionName)
+	if err != nil {
+		return nil
+	}
+
+	// Linting only reads the session; a missing store has no variables
+	var names []string
+	if envStore, err := session.LoadEnvironmentStore(filesystem.Default, sessionMgr.GetSessionPath()); err == nil {
+		for _, vars := range envStore.EnvironmentVariables {
+			for name := range vars {
+				names = append(names, name)
+			}
+		}
+	}
+	// A missing variables file is not an error
+	_ = sessionMgr.LoadVariables()
+	for name := range sessionMgr.GetAllVariables() {
+		names = append(names, name)
+	}
+	return names
+}
+
+// printLintIssue prints an issue as path:line:column: severity: message (rule)
+func printLintIssue(path string, issue lint.Issue) {
+	severity := issue.Severity.String()
+	if useColors() {
+		if issue.Severity == lint.SeverityError {
+			severity = errorColor.Sprint(severity)
+		} else {
+			severity = warnColor.Sprint(severity)
+		}
+	}
+	fmt.Printf("%s:%d:%d: %s: %s (%s)\n", path, issue.Span.Start.Line+1, issue.Span.Start.Column+1, severity, issue.Message, issue.Rule)
+}
+
+// printLintRules prints the rules with their default severity
+func printLintRules() {
+	for _, rule := range lint.Rules() {
+		fixable := ""
+		if rule.Fixable {
+			fixable = " (fixable)"
+		}
+		fmt.Printf("%-22s %-8s %s%s\n", rule.Name, rule.Severity, rule.Description, fixable)
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/restclient/pkg/session"
+)
+
+// executeLintCommand runs a command such as lint, fmt or import and returns
+// its output
+func executeLintCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	defer func() {
+		lintFix = false
+		lintCheck = false
+		lintRules = nil
+		fmtCheck = false
+	}()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	rootCmd.SetArgs(args)
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String(), err
+}
+
+func writeLintFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "api.http")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+	return path
+}
+
+func TestLintCommand_ExitStatus(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	path := writeLintFile(t, "GET https://example.com\n\nhello\n")
+
+	output, err := executeLintCommand(t, "lint", path)
+	if err != nil {
+		t.Errorf("warnings alone should not fail: %v", err)
+	}
+	if !strings.Contains(output, path+":3:1: warning: GET request has a body") {
+		t.Errorf("output = %q", output)
+	}
+
+	if _, err := executeLintCommand(t, "lint", "--check", path); err == nil {
+		t.Error("--check should fail on warnings")
+	}
+	if _, err := executeLintCommand(t, "lint", "--rule", "get-with-body=error", path); err == nil {
+		t.Error("errors should fail")
+	}
+	if _, err := executeLintCommand(t, "lint", "--check", "--rule", "get-with-body=off", "--rule", "missing-content-type=off", path); err != nil {
+		t.Errorf("rules turned off should not fail: %v", err)
+	}
+}
+
+func TestLintCommand_Fix(t *testing.T) {
+	path := writeLintFile(t, "@unused = 1\nPOST https://example.com\n\n{}\n")
+
+	if _, err := executeLintCommand(t, "lint", "--fix", "--check", path); err != nil {
+		t.Fatalf("lint --fix error = %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if want := "POST https://example.com\nContent-Type: application/json\n\n{}\n"; string(content) != want {
+		t.Errorf("fixed file = %q, want %q", content, want)
+	}
+}
+
+func TestLintCommand_DoesNotCreateSession(t *testing.T) {
+	path := writeLintFile(t, "@id = 1\nGET https://example.com/{{id}}\n")
+
+	if _, err := executeLintCommand(t, "lint", path); err != nil {
+		t.Fatalf("lint error = %v", err)
+	}
+
+	sessionMgr, err := session.NewSessionManager("", path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(sessionMgr.GetSessionPath()); !os.IsNotExist(err) {
+		t.Errorf("lint should not create the session directory, stat error = %v", err)
+	}
+}
+
+func TestFmtCommand(t *testing.T) {
+	path := writeLintFile(t, "get https://example.com\naccept:*/*\n")
+
+	output, err := executeLintCommand(t, "fmt", "--check", path)
+	if err == nil || strings.TrimSpace(output) != path {
+		t.Errorf("fmt --check = %q, %v; want the file listed and an error", output, err)
+	}
+
+	if _, err := executeLintCommand(t, "fmt", path); err != nil {
+		t.Fatalf("fmt error = %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if want := "GET https://example.com\nAccept: */*\n"; string(content) != want {
+		t.Errorf("formatted file = %q, want %q", content, want)
+	}
+
+	if _, err := executeLintCommand(t, "fmt", "--check", path); err != nil {
+		t.Errorf("formatted file should pass --check: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/restclient/pkg/lsp"
+)
+
+// lspCmd represents the lsp command
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a language server for .http and .rest files",
+	Long: `Run a Language Server Protocol server on stdin and stdout, for editors
+such as Neovim, Helix and Zed.
+
+The server reports parse warnings and validation errors, completes
+{{variables}} from file, environment, session and system scopes, jumps to
+the declaration of variables and named requests, shows variable values on
+hover (masking secrets) and offers a "Send request" code lens.
+
+Environments, session variables and stored request results come from the
+session of each file, as with send.
+
+Examples:
+  # Start the server (editors run this for you)
+  restclient lsp
+
+  # Resolve variables against the staging environment
+  restclient lsp -e staging`,
+	Args: cobra.NoArgs,
+	RunE: runLSP,
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+
+	lspCmd.Flags().StringVar(&sessionName, "session", "", "use named session instead of directory-based session")
+}
+
+func runLSP(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := lsp.NewServer(lsp.Options{
+		SessionName: sessionName,
+		Environment: environment,
+		Version:     version,
+	})
+	return server.Run(ctx, os.Stdin, os.Stdout)
+}
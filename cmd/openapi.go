@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/openapi"
+)
+
+var openapiImportOutput string
+
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Import OpenAPI and Swagger specifications",
+	Long: `Work with OpenAPI 3 and Swagger 2 specifications.
+
+Examples:
+  # Import a specification to .http files
+  restclient openapi import openapi.yaml
+
+  # Import to a specific directory
+  restclient openapi import swagger.json -o ./requests`,
+}
+
+var openapiImportCmd = &cobra.Command{
+	Use:   "import <spec.yaml|spec.json>",
+	Short: "Import an OpenAPI or Swagger specification to .http files",
+	Long: `Import an OpenAPI 3 or Swagger 2 specification, in YAML or JSON, and convert
+its operations to .http requests.
+
+A folder named after the API is created with one file per tag, and
+operations without tags in default.http. Each file sets @baseUrl from the
+first server. Each request is:
+  - Titled with the operation's summary and named with # @name from its
+    operationId, with its description as # @note
+  - Given {{:param}} prompts for path, query, header and cookie parameters
+  - Given an example body from the spec's examples or built from the
+    schema, as JSON, a URL-encoded form or a multipart form
+  - Authenticated per its security scheme with {{token}}, {{username}} and
+    {{password}}, or an API key variable, to set in an environment
+
+Examples:
+  # Import to current directory (creates API-Title/ folder)
+  restclient openapi import openapi.yaml
+
+  # Import to specific directory
+  restclient openapi import openapi.yaml -o ./api-requests`,
+	Args: cobra.ExactArgs(1),
+	RunE: runOpenAPIImport,
+}
+
+func init() {
+	rootCmd.AddCommand(openapiCmd)
+	openapiCmd.AddCommand(openapiImportCmd)
+
+	openapiImportCmd.Flags().StringVarP(&openapiImportOutput, "output", "o", "", "Output directory")
+}
+
+func runOpenAPIImport(cmd *cobra.Command, args []string) error {
+	specPath := args[0]
+
+	if _, err := os.Stat(specPath); os.IsNotExist(err) {
+		return errors.NewValidationErrorWithValue("specification file", specPath, "file not found")
+	}
+
+	outputDir := "."
+	if openapiImportOutput != "" {
+		outputDir = openapiImportOutput
+	}
+
+	result, err := openapi.Import(specPath, openapi.ImportOptions{OutputDir: outputDir})
+	if err != nil {
+		return errors.Wrap(err, "import failed")
+	}
+
+	fmt.Printf("Successfully imported OpenAPI specification\n")
+	fmt.Printf("  Operations: %d\n", result.OperationsCount)
+	fmt.Printf("  Tags:       %d\n", result.TagsCount)
+	fmt.Printf("\nFiles created:\n")
+	for _, file := range result.FilesCreated {
+		fmt.Printf("  - %s\n", file)
+	}
+	return nil
+}
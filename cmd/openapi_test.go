@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIImportCommand(t *testing.T) {
+	defer func() { openapiImportOutput = "" }()
+
+	spec := filepath.Join(t.TempDir(), "openapi.yaml")
+	content := `openapi: 3.1.0
+info: {title: Users}
+servers: [{url: https://api.example.com}]
+paths:
+  /users/{id}:
+    get:
+      operationId: getUser
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+`
+	if err := os.WriteFile(spec, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	output, err := executeLintCommand(t, "openapi", "import", spec, "-o", dir)
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if !strings.Contains(output, "Operations: 1") {
+		t.Errorf("output = %q", output)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "Users", "default.http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "@baseUrl = https://api.example.com\n\n# GET /users/{id}\n# @name getUser\nGET {{baseUrl}}/users/{{:id}}\n"
+	if string(got) != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+
+	if _, err := executeLintCommand(t, "openapi", "import", filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("a missing specification should fail")
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	progressBarWidth    = 30
+	progressRedrawEvery = 100 * time.Millisecond
+)
+
+// progressBar draws download progress on a single terminal line
+type progressBar struct {
+	out      io.Writer
+	start    time.Time
+	lastDraw time.Time
+	received int64
+	total    int64
+}
+
+// newProgressBar creates a progress bar whose clock starts now
+func newProgressBar(out io.Writer) *progressBar {
+	return &progressBar{out: out, start: time.Now(), total: -1}
+}
+
+// update records the bytes received so far and redraws at most every
+// progressRedrawEvery. total is -1 if the size is unknown.
+func (p *progressBar) update(received, total int64) {
+	p.received, p.total = received, total
+
+	now := time.Now()
+	if now.Sub(p.lastDraw) < progressRedrawEvery {
+		return
+	}
+	p.lastDraw = now
+	fmt.Fprintf(p.out, "\r%s\033[K", p.render(now))
+}
+
+// finish draws the final state and ends the line, if anything was drawn
+func (p *progressBar) finish() {
+	if p.lastDraw.IsZero() {
+		return
+	}
+	fmt.Fprintf(p.out, "\r%s\033[K\n", p.render(time.Now()))
+}
+
+// render formats the progress, e.g. "[=====>    ]  50%  5.0 MiB / 10.0 MiB  2.5 MiB/s"
+func (p *progressBar) render(now time.Time) string {
+	rate := ""
+	if elapsed := now.Sub(p.start).Seconds(); elapsed > 0 {
+		rate = formatByteSize(int64(float64(p.received)/elapsed)) + "/s"
+	}
+
+	if p.total <= 0 {
+		return fmt.Sprintf("%s  %s", formatByteSize(p.received), rate)
+	}
+
+	fraction := min(float64(p.received)/float64(p.total), 1)
+	filled := int(fraction * progressBarWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+	return fmt.Sprintf("[%s] %3.0f%%  %s / %s  %s", bar, fraction*100,
+		formatByteSize(p.received), formatByteSize(p.total), rate)
+}
+
+// formatByteSize formats a size in bytes with a binary unit, e.g. "1.5 MiB"
+func formatByteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressBarRender(t *testing.T) {
+	start := time.Now()
+
+	tests := []struct {
+		name     string
+		received int64
+		total    int64
+		want     string
+	}{
+		{"known size", 5 << 20, 10 << 20, "[===============>              ]  50%  5.0 MiB / 10.0 MiB  2.5 MiB/s"},
+		{"complete", 10 << 20, 10 << 20, "[==============================] 100%  10.0 MiB / 10.0 MiB  5.0 MiB/s"},
+		{"unknown size", 3 << 10, -1, "3.0 KiB  1.5 KiB/s"},
+	}
+
+	for _, tt := range tests {
+		p := &progressBar{start: start, received: tt.received, total: tt.total}
+		if got := p.render(start.Add(2 * time.Second)); got != tt.want {
+			t.Errorf("%s: render() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProgressBarFinish(t *testing.T) {
+	var buf bytes.Buffer
+	p := newProgressBar(&buf)
+
+	p.finish()
+	if buf.Len() != 0 {
+		t.Errorf("finish() before any update should draw nothing, got %q", buf.String())
+	}
+
+	p.update(512, 1024)
+	p.update(1024, 1024) // Within the redraw interval, so not drawn
+	p.finish()
+
+	lines := strings.Split(buf.String(), "\r")
+	if len(lines) != 3 || !strings.Contains(lines[2], "100%") || !strings.HasSuffix(lines[2], "\n") {
+		t.Errorf("unexpected progress output %q", buf.String())
+	}
+}
+
+func TestFormatByteSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536 << 10, "1.5 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatByteSize(tt.n); got != tt.want {
+			t.Errorf("formatByteSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
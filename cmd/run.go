@@ -0,0 +1,633 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/executor"
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/reporter"
+	"github.com/ideaspaper/restclient/pkg/scripting"
+	"github.com/ideaspaper/restclient/pkg/session"
+	"github.com/ideaspaper/restclient/pkg/variables"
+)
+
+var (
+	runFailFast    bool
+	runReporter    string
+	runReportFile  string
+	runMaxRequests int
+)
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run <file.http|file.rest>... [flags]",
+	Short: "Run every request in one or more .http files as a test suite",
+	Long: `Run every request in one or more .http or .rest files, in order.
+
+All requests share the same variable state, so request variables such as
+{{login.response.body.$.token}} resolve against earlier requests in the run,
+including requests from previous files. File variables (@name = value)
+only apply to the file that declares them.
+
+Test results from post-response scripts are collected and a summary is
+printed at the end.
+
+Scripts can change the order of the run with client.execution:
+setNextRequest("name") jumps to a named request (which allows loops such as
+polling until a job completes), stop() ends the run, and skipRequest() in a
+pre-request script skips the current request.
+
+The command exits with a non-zero status if any request fails to send or
+any test fails, which makes it suitable for CI.
+
+Examples:
+  # Run all requests in a file
+  restclient run api.http
+
+  # Run several files in order (e.g. auth first, then the API)
+  restclient run auth.http users.http orders.http
+
+  # Stop at the first failing request
+  restclient run api.http --fail-fast
+
+  # Run against a specific environment without touching the session
+  restclient run api.http -e staging --no-session --no-history
+
+  # Write a JUnit XML report for CI
+  restclient run api.http --reporter junit --report-file results.xml
+
+  # Print a JSON report to stdout (progress goes to stderr)
+  restclient run api.http --reporter json
+
+  # Record the requests and responses as a HAR archive
+  restclient run api.http --reporter har --report-file run.har`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runRun,
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().BoolVar(&runFailFast, "fail-fast", false, "stop at the first failing request")
+	runCmd.Flags().StringVar(&runReporter, "reporter", "", "write a report in the given format ("+strings.Join(reporter.Formats(), ", ")+")")
+	runCmd.Flags().StringVar(&runReportFile, "report-file", "", "write the report to a file instead of stdout")
+	runCmd.Flags().IntVar(&runMaxRequests, "max-requests", 1000, "stop after this many requests, sent or skipped, to guard against setNextRequest loops (0 = no limit)")
+	runCmd.Flags().BoolVar(&noHistory, "no-history", false, "don't save requests to history")
+	runCmd.Flags().BoolVar(&skipValidate, "skip-validate", false, "skip request validation")
+	runCmd.Flags().StringVar(&sessionName, "session", "", "use named session instead of directory-based session")
+	runCmd.Flags().BoolVar(&noSession, "no-session", false, "don't load or save session state (cookies, variables and request results)")
+	runCmd.Flags().BoolVar(&strictMode, "strict", false, "error on duplicate @name values instead of warning")
+}
+
+// runFile holds a parsed .http file and the session state used to run it
+type runFile struct {
+	path       string
+	fileVars   map[string]string
+	sessionCfg *session.SessionConfig
+	envStore   *session.EnvironmentStore
+	requests   []*models.HttpRequest
+	imported   []*models.HttpRequest // Requests of the files imported with @import
+}
+
+// runItem is a single request scheduled for execution
+type runItem struct {
+	file    *runFile
+	index   int // 0-based index within the file
+	request *models.HttpRequest
+}
+
+// runResult holds the outcome of a single request in a run
+type runResult struct {
+	File      string
+	Index     int
+	Request   *models.HttpRequest
+	Response  *models.HttpResponse
+	Tests     []scripting.TestResult
+	Logs      []string
+	Err       error
+	StartedAt time.Time
+	Duration  time.Duration
+	Skipped   bool                // Skipped by the pre-request script
+	Execution scripting.Execution // Flow-control decisions made by the scripts
+}
+
+// failedTests returns the number of failed script tests
+func (r *runResult) failedTests() int {
+	failed := 0
+	for _, test := range r.Tests {
+		if !test.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// passed reports whether the request was sent and all of its tests passed
+func (r *runResult) passed() bool {
+	return r.Err == nil && r.failedTests() == 0
+}
+
+// name returns the request name, falling back to its position in the file
+func (r *runResult) name() string {
+	if r.Request.Metadata.Name != "" {
+		return r.Request.Metadata.Name
+	}
+	if r.Request.Name != "" {
+		return r.Request.Name
+	}
+	return fmt.Sprintf("%s #%d", filepath.Base(r.File), r.Index+1)
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	var report reporter.Reporter
+	if runReporter != "" {
+		var err error
+		if report, err = reporter.New(runReporter); err != nil {
+			return err
+		}
+	}
+
+	// Keep stdout machine-readable when the report is written there
+	progress := cmd.OutOrStdout()
+	if report != nil && runReportFile == "" {
+		progress = cmd.ErrOrStderr()
+	}
+
+	files, err := loadRunFiles(args)
+	if err != nil {
+		return err
+	}
+
+	var items []runItem
+	for _, file := range files {
+		for i, request := range file.requests {
+			items = append(items, runItem{file: file, index: i, request: request})
+		}
+	}
+
+	// Create context with cancellation support for interrupt signals
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		if verbose {
+			fmt.Fprintln(os.Stderr, "\nInterrupt received, stopping run...")
+		}
+		cancel()
+	}()
+	defer signal.Stop(sigChan)
+
+	varProcessor := variables.NewVariableProcessor()
+	varProcessor.SetPromptHandler(promptHandler)
+
+	start := time.Now()
+	var results []*runResult
+	var currentFile *runFile
+	var limitErr error
+	executed := make([]bool, len(items))
+	// Skipped requests count towards --max-requests too, as a script can
+	// skip a request and jump back to it
+	steps := 0
+
+	for pos := 0; pos < len(items); {
+		if ctx.Err() != nil {
+			break
+		}
+		if runMaxRequests > 0 && steps >= runMaxRequests {
+			limitErr = errors.NewValidationErrorWithValue("max-requests", fmt.Sprintf("%d", runMaxRequests), "limit reached; check for setNextRequest loops")
+			break
+		}
+
+		item := items[pos]
+		if item.file != currentFile {
+			enterRunFile(varProcessor, currentFile, item.file)
+			currentFile = item.file
+		}
+
+		result := executeRunItem(ctx, progress, item, varProcessor)
+		steps++
+		if ctx.Err() != nil {
+			// An interrupted request is not reported as a failure
+			break
+		}
+
+		next, err := nextRunPosition(items, pos, result.Execution)
+		if err != nil && result.Err == nil {
+			result.Err = err
+		}
+
+		if result.Skipped {
+			printRunSkipped(progress, item)
+		} else {
+			executed[pos] = true
+			results = append(results, result)
+			printRunResult(progress, len(results), result)
+		}
+
+		if runFailFast && !result.passed() {
+			break
+		}
+		pos = next
+	}
+
+	notRun := 0
+	for _, ran := range executed {
+		if !ran {
+			notRun++
+		}
+	}
+
+	elapsed := time.Since(start)
+	printRunSummary(progress, results, notRun, elapsed)
+
+	if report != nil {
+		if err := writeRunReport(report, cmd.OutOrStdout(), progress, buildRunReport(args, results, start, elapsed)); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return errors.Wrap(errors.ErrCanceled, "run cancelled")
+	}
+	if limitErr != nil {
+		return limitErr
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.passed() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.NewScriptError("test", fmt.Sprintf("%d of %d requests failed", failed, len(results)))
+	}
+
+	return nil
+}
+
+// loadRunFiles reads and parses every file up front so that parse errors
+// are reported before any request is sent
+func loadRunFiles(paths []string) ([]*runFile, error) {
+	files := make([]*runFile, 0, len(paths))
+	for _, path := range paths {
+		_, sessionCfg, envStore, err := loadSendConfig(path)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := readRequestFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		imports, err := loadImports(path, content, sessionCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		requests, parseWarnings, err := parseRequestsFromContent(content, sessionCfg, path)
+		if err != nil {
+			return nil, errors.Wrap(err, path)
+		}
+		printParseWarnings(parseWarnings)
+
+		// Variables of the file override those of its imports
+		fileVars := imports.variables
+		maps.Copy(fileVars, parseFileVariables(content))
+
+		files = append(files, &runFile{
+			path:       path,
+			fileVars:   fileVars,
+			sessionCfg: sessionCfg,
+			envStore:   envStore,
+			requests:   requests,
+			imported:   imports.requests,
+		})
+	}
+	return files, nil
+}
+
+// enterRunFile switches the shared variable processor from the previous
+// file, if any, to a file's variables, environment and directory. Request
+// results and script globals from earlier files are kept.
+func enterRunFile(varProcessor *variables.VariableProcessor, previous, file *runFile) {
+	varProcessor.SetEnvironment(file.sessionCfg.CurrentEnvironment())
+	if file.envStore != nil {
+		varProcessor.SetEnvironmentVariables(file.envStore.EnvironmentVariables)
+	}
+	if previous != nil {
+		varProcessor.UnsetFileVariables(previous.fileVars)
+	}
+	varProcessor.SetFileVariables(file.fileVars)
+	varProcessor.SetCurrentDir(filepath.Dir(file.path))
+}
+
+// executeRunItem prepares and sends a single request, collecting its test results
+func executeRunItem(ctx context.Context, progress io.Writer, item runItem, varProcessor *variables.VariableProcessor) *runResult {
+	// A request may run more than once when scripts loop, so never modify the parsed one
+	request := item.request.Clone()
+	result := &runResult{File: item.file.path, Index: item.index, Request: request}
+
+	result.StartedAt = time.Now()
+	defer func() {
+		result.Duration = time.Since(result.StartedAt)
+	}()
+
+	// Dynamic variables and request results must be re-resolved for every request
+	varProcessor.ClearCache()
+
+	printRequestWarnings(request)
+
+	// Named requests this one refers to are sent first, unless cached
+	dependencies := newDependencies(progress, item.file.path, item.file.requests, item.file.imported, item.file.sessionCfg, varProcessor, item.file.envStore)
+	if err := dependencies.Resolve(ctx, request); err != nil {
+		result.Err = err
+		return result
+	}
+
+	flow, err := prepareRequest(progress, request, item.file.path, item.file.sessionCfg, varProcessor, item.file.envStore)
+	result.Execution = flow
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if flow.Skip {
+		result.Skipped = true
+		result.Execution.Skip = false
+		return result
+	}
+
+	if verbose {
+		printRequestInfo(progress, request)
+	}
+
+	opts := executor.Options{
+		HTTPFilePath:     item.file.path,
+		SessionName:      sessionName,
+		NoSession:        noSession,
+		NoHistory:        noHistory,
+		Verbose:          verbose,
+		EnvironmentStore: item.file.envStore,
+		LogFunc: func(format string, args ...any) {
+			fmt.Fprintf(os.Stderr, format+"\n", args...)
+		},
+	}
+
+	execResult, err := executor.New(item.file.sessionCfg, varProcessor, opts).ExecuteWithContext(ctx, request)
+	if execResult != nil {
+		result.Response = execResult.Response
+		result.Tests = execResult.TestResults
+		result.Logs = execResult.Logs
+		if execResult.Execution.IsSet() {
+			result.Execution = execResult.Execution
+		}
+	}
+	if err != nil {
+		// Failed tests are reported through Tests; keep Err for everything else
+		if !errors.Is(err, errors.ErrScript) || result.failedTests() == 0 {
+			result.Err = err
+		}
+	}
+
+	return result
+}
+
+// nextRunPosition returns the position of the request to run after the one
+// at pos, applying the scripts' flow-control decisions. A position past the
+// end of items ends the run.
+func nextRunPosition(items []runItem, pos int, flow scripting.Execution) (int, error) {
+	if flow.Stop {
+		return len(items), nil
+	}
+
+	next := pos + 1
+	if flow.NextRequest != "" {
+		target := findRunItem(items, items[pos].file, flow.NextRequest)
+		if target < 0 {
+			return len(items), errors.NewValidationErrorWithValue("setNextRequest", flow.NextRequest, "request not found")
+		}
+		next = target
+	}
+
+	// In a post-response script, skipRequest skips the request that would run next
+	if flow.Skip && next < len(items) {
+		next++
+	}
+	return next, nil
+}
+
+// findRunItem returns the position of the named request, preferring one in
+// the given file, or -1 if there is none
+func findRunItem(items []runItem, file *runFile, name string) int {
+	found := -1
+	for i, item := range items {
+		if item.request.Metadata.Name != name && item.request.Name != name {
+			continue
+		}
+		if item.file == file {
+			return i
+		}
+		if found < 0 {
+			found = i
+		}
+	}
+	return found
+}
+
+// printRunSkipped prints a request skipped by its pre-request script
+func printRunSkipped(w io.Writer, item runItem) {
+	fmt.Fprintf(w, "%s %s %s  %s\n\n", printDimText("[-]"), printMethod(item.request.Method), item.request.URL, printDimText("skipped"))
+}
+
+// printRunResult prints the outcome of a single request in a run
+func printRunResult(w io.Writer, position int, result *runResult) {
+	request := result.Request
+	line := fmt.Sprintf("%s %s %s", printListIndex(position), printMethod(request.Method), request.URL)
+	if request.Metadata.Name != "" {
+		line += "  " + printDimText(request.Metadata.Name)
+	}
+	fmt.Fprintln(w, line)
+
+	if result.Response != nil {
+		fmt.Fprintf(w, "    %s  %s\n", formatRunStatus(result.Response), printDimText(formatRunDuration(result.Duration)))
+	}
+
+	for _, log := range result.Logs {
+		fmt.Fprintf(w, "    [script] %s\n", log)
+	}
+
+	for _, test := range result.Tests {
+		if test.Passed {
+			fprintTestPass(w, test.Name)
+		} else {
+			fprintTestFail(w, test.Name, test.Error)
+		}
+	}
+
+	if result.Err != nil {
+		msg := fmt.Sprintf("Error: %v", result.Err)
+		if useColors() {
+			msg = errorColor.Sprint(msg)
+		}
+		fmt.Fprintf(w, "    %s\n", msg)
+	}
+
+	fmt.Fprintln(w)
+}
+
+// formatRunStatus returns the colored status code and text of a response.
+// StatusMessage already holds the code for HTTP responses, so the text
+// comes from the code.
+func formatRunStatus(resp *models.HttpResponse) string {
+	status := fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	if !useColors() {
+		return status
+	}
+	if resp.StatusCode >= 400 {
+		return errorColor.Sprint(status)
+	}
+	return successColor.Sprint(status)
+}
+
+// printRunSummary prints pass/fail totals for requests and tests
+func printRunSummary(w io.Writer, results []*runResult, skipped int, elapsed time.Duration) {
+	var requestsPassed, requestsFailed, testsPassed, testsFailed int
+	for _, result := range results {
+		if result.passed() {
+			requestsPassed++
+		} else {
+			requestsFailed++
+		}
+		failed := result.failedTests()
+		testsFailed += failed
+		testsPassed += len(result.Tests) - failed
+	}
+
+	if useColors() {
+		fmt.Fprintln(w, headerColor.Sprint("Summary:"))
+	} else {
+		fmt.Fprintln(w, "Summary:")
+	}
+	fmt.Fprintf(w, "  Requests: %s\n", formatRunCounts(requestsPassed, requestsFailed, skipped))
+	fmt.Fprintf(w, "  Tests:    %s\n", formatRunCounts(testsPassed, testsFailed, 0))
+	fmt.Fprintf(w, "  Time:     %s\n", formatRunDuration(elapsed))
+
+	if requestsFailed > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Failed requests:")
+		for _, result := range results {
+			if !result.passed() {
+				fmt.Fprintf(w, "  - %s (%s %s)\n", result.name(), result.Request.Method, result.Request.URL)
+			}
+		}
+	}
+}
+
+// formatRunCounts formats passed/failed/skipped counts for the summary
+func formatRunCounts(passed, failed, skipped int) string {
+	passedText := fmt.Sprintf("%d passed", passed)
+	failedText := fmt.Sprintf("%d failed", failed)
+	if useColors() {
+		passedText = successColor.Sprint(passedText)
+		if failed > 0 {
+			failedText = errorColor.Sprint(failedText)
+		}
+	}
+
+	text := fmt.Sprintf("%s, %s", passedText, failedText)
+	if skipped > 0 {
+		text += fmt.Sprintf(", %d skipped", skipped)
+	}
+	return fmt.Sprintf("%s, %d total", text, passed+failed+skipped)
+}
+
+// formatRunDuration formats a duration for run output
+func formatRunDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+// buildRunReport converts run results into a report, one suite per request
+func buildRunReport(paths []string, results []*runResult, start time.Time, elapsed time.Duration) *reporter.Run {
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+
+	run := &reporter.Run{
+		Name:      strings.Join(names, " "),
+		Timestamp: start,
+		Duration:  elapsed,
+	}
+
+	for _, result := range results {
+		suite := reporter.Suite{
+			Name:      result.name(),
+			File:      result.File,
+			Method:    result.Request.Method,
+			URL:       result.Request.URL,
+			Timestamp: result.StartedAt,
+			Duration:  result.Duration,
+			Logs:      result.Logs,
+			Request:   result.Request,
+			Response:  result.Response,
+		}
+		if result.Response != nil {
+			suite.StatusCode = result.Response.StatusCode
+		}
+		if result.Err != nil {
+			suite.Error = result.Err.Error()
+		}
+		for _, test := range result.Tests {
+			suite.Cases = append(suite.Cases, reporter.Case{
+				Name:     test.Name,
+				Passed:   test.Passed,
+				Failure:  test.Error,
+				Duration: test.Duration,
+				Logs:     test.Logs,
+			})
+		}
+		run.Suites = append(run.Suites, suite)
+	}
+
+	return run
+}
+
+// writeRunReport writes the report to --report-file, or to out if none is
+// set, printing where the file was saved to progress
+func writeRunReport(report reporter.Reporter, out, progress io.Writer, run *reporter.Run) error {
+	if runReportFile == "" {
+		return report.Report(out, run)
+	}
+
+	file, err := os.Create(runReportFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to create report file")
+	}
+	defer file.Close()
+
+	if err := report.Report(file, run); err != nil {
+		return err
+	}
+	fmt.Fprintf(progress, "Report saved to %s\n", runReportFile)
+	return nil
+}
@@ -0,0 +1,537 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// executeRunCommand runs the run command with the given files and returns its output
+func executeRunCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	rootCmd.SetArgs(append([]string{"run", "--no-history", "--no-session"}, args...))
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String(), err
+}
+
+func newRunTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"token": "abc123"}`))
+		case "/me":
+			if r.Header.Get("Authorization") != "Bearer abc123" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name": "Alice"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunCommand_ChainsRequestsAcrossFiles(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	server := newRunTestServer(t)
+	tempDir := t.TempDir()
+
+	authFile := filepath.Join(tempDir, "auth.http")
+	authContent := `@baseUrl = ` + server.URL + `
+
+# @name login
+POST {{baseUrl}}/login
+
+> {%
+client.test("login succeeds", function() {
+    client.assert(response.status === 200, "expected 200");
+});
+%}
+`
+	usersFile := filepath.Join(tempDir, "users.http")
+	usersContent := `@baseUrl = ` + server.URL + `
+
+# @name me
+GET {{baseUrl}}/me
+Authorization: Bearer {{login.response.body.$.token}}
+
+> {%
+client.test("returns the current user", function() {
+    client.assert(response.body.name === "Alice", "expected Alice");
+});
+%}
+`
+	if err := os.WriteFile(authFile, []byte(authContent), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+	if err := os.WriteFile(usersFile, []byte(usersContent), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeRunCommand(t, authFile, usersFile)
+	if err != nil {
+		t.Fatalf("command failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, want := range []string{
+		"[PASS] login succeeds",
+		"[PASS] returns the current user",
+		"Requests: 2 passed, 0 failed, 2 total",
+		"Tests:    2 passed, 0 failed, 2 total",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q\nGot: %s", want, output)
+		}
+	}
+}
+
+func TestRunCommand_FileVariablesStayInTheirFile(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	server := newRunTestServer(t)
+	tempDir := t.TempDir()
+
+	firstFile := filepath.Join(tempDir, "first.http")
+	secondFile := filepath.Join(tempDir, "second.http")
+	files := map[string]string{
+		firstFile:  "@baseUrl = " + server.URL + "\n\nPOST {{baseUrl}}/login\n",
+		secondFile: "GET {{baseUrl}}/me\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write http file: %v", err)
+		}
+	}
+
+	output, err := executeRunCommand(t, firstFile, secondFile)
+	if err == nil {
+		t.Fatalf("the second file should not see the variables of the first\nOutput: %s", output)
+	}
+	if !strings.Contains(output, "Requests: 1 passed, 1 failed, 2 total") {
+		t.Errorf("output should report the second request as failed\nGot: %s", output)
+	}
+}
+
+func TestRunCommand_FailsOnFailedTest(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	server := newRunTestServer(t)
+	httpFile := filepath.Join(t.TempDir(), "api.http")
+	content := `@baseUrl = ` + server.URL + `
+
+# @name missing
+GET {{baseUrl}}/missing
+
+> {%
+client.test("status is 200", function() {
+    client.assert(response.status === 200, "expected 200");
+});
+%}
+
+###
+
+# @name login
+POST {{baseUrl}}/login
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeRunCommand(t, httpFile)
+	if err == nil {
+		t.Fatalf("expected error for failed test\nOutput: %s", output)
+	}
+	if !errors.Is(err, errors.ErrScript) {
+		t.Errorf("error = %v, want ErrScript", err)
+	}
+
+	for _, want := range []string{
+		"[FAIL] status is 200",
+		"Requests: 1 passed, 1 failed, 2 total",
+		"- missing (GET " + server.URL + "/missing)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q\nGot: %s", want, output)
+		}
+	}
+}
+
+func TestRunCommand_FailFastSkipsRemaining(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+	defer func() { runFailFast = false }()
+
+	server := newRunTestServer(t)
+	httpFile := filepath.Join(t.TempDir(), "api.http")
+	content := `@baseUrl = ` + server.URL + `
+
+GET {{baseUrl}}/me
+Authorization: Bearer {{undefinedToken}}
+
+###
+
+POST {{baseUrl}}/login
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeRunCommand(t, "--fail-fast", httpFile)
+	if err == nil {
+		t.Fatalf("expected error for unresolved variable\nOutput: %s", output)
+	}
+
+	if !strings.Contains(output, "Requests: 0 passed, 1 failed, 1 skipped, 2 total") {
+		t.Errorf("output should report skipped request\nGot: %s", output)
+	}
+}
+
+func TestRunCommand_WritesJUnitReport(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+	defer func() { runReporter, runReportFile = "", "" }()
+
+	server := newRunTestServer(t)
+	tempDir := t.TempDir()
+	httpFile := filepath.Join(tempDir, "api.http")
+	reportFile := filepath.Join(tempDir, "report.xml")
+	content := `# @name login
+POST ` + server.URL + `/login
+
+> {%
+client.test("has token", function() {
+    client.log("token: " + response.body.token);
+    client.assert(response.body.token === "abc123", "expected token");
+});
+%}
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeRunCommand(t, "--reporter", "junit", "--report-file", reportFile, httpFile)
+	if err != nil {
+		t.Fatalf("command failed: %v\nOutput: %s", err, output)
+	}
+
+	report, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+
+	for _, want := range []string{
+		`<testsuite name="login" tests="1" failures="0" errors="0"`,
+		`<property name="status" value="200"></property>`,
+		`<testcase name="has token" classname="login"`,
+		`<system-out>token: abc123</system-out>`,
+	} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("report should contain %q\nGot:\n%s", want, report)
+		}
+	}
+}
+
+func TestRunCommand_JSONReportToStdout(t *testing.T) {
+	defer func() { runReporter = "" }()
+
+	server := newRunTestServer(t)
+	httpFile := filepath.Join(t.TempDir(), "api.http")
+	content := `# @name me
+< {%
+client.log("fetching profile");
+%}
+GET ` + server.URL + `/me
+Authorization: Bearer {{login.response.body.$.token}}
+
+###
+
+# @name login
+POST ` + server.URL + `/login
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	for _, format := range []string{"json", "tap"} {
+		output, err := executeRunCommand(t, "--reporter", format, httpFile)
+		if err != nil {
+			t.Fatalf("%s: command failed: %v\nOutput: %s", format, err, output)
+		}
+
+		// Progress, pre-script logs and dependencies go to stderr, so stdout
+		// holds only the report
+		if format == "json" {
+			var report map[string]any
+			if err := json.Unmarshal([]byte(output), &report); err != nil {
+				t.Errorf("stdout should be a JSON report: %v\nGot:\n%s", err, output)
+			}
+		} else if !strings.HasPrefix(output, "TAP version") {
+			t.Errorf("stdout should be a TAP stream\nGot:\n%s", output)
+		}
+	}
+}
+
+func TestRunCommand_InvalidReporter(t *testing.T) {
+	defer func() { runReporter = "" }()
+
+	_, err := executeRunCommand(t, "--reporter", "html", "missing.http")
+	if !errors.Is(err, errors.ErrInvalidInput) {
+		t.Errorf("error = %v, want ErrInvalidInput", err)
+	}
+}
+
+// newPollTestServer returns a server whose /job endpoint reports "pending"
+// until it has been polled three times
+func newPollTestServer(t *testing.T, hits map[string]int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[r.Method+" "+r.URL.Path]++
+		if r.URL.Path == "/job" {
+			status := "pending"
+			if hits["GET /job"] >= 3 {
+				status = "done"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status": "` + status + `"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunCommand_SetNextRequestLoops(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	hits := make(map[string]int)
+	server := newPollTestServer(t, hits)
+	httpFile := filepath.Join(t.TempDir(), "api.http")
+	content := `@baseUrl = ` + server.URL + `
+
+# @name start
+POST {{baseUrl}}/start
+
+###
+
+# @name poll
+GET {{baseUrl}}/job
+
+> {%
+if (response.body.status !== "done") {
+    client.execution.setNextRequest("poll");
+}
+%}
+
+###
+
+# @name cleanup
+DELETE {{baseUrl}}/job
+
+> {%
+client.execution.stop();
+%}
+
+###
+
+# @name never
+GET {{baseUrl}}/never
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeRunCommand(t, httpFile)
+	if err != nil {
+		t.Fatalf("command failed: %v\nOutput: %s", err, output)
+	}
+
+	if hits["GET /job"] != 3 || hits["DELETE /job"] != 1 || hits["GET /never"] != 0 {
+		t.Errorf("unexpected hits: %v", hits)
+	}
+	if !strings.Contains(output, "Requests: 5 passed, 0 failed, 1 skipped, 6 total") {
+		t.Errorf("output should count the loop and the request after stop()\nGot: %s", output)
+	}
+}
+
+func TestRunCommand_SkipRequest(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	hits := make(map[string]int)
+	server := newPollTestServer(t, hits)
+	httpFile := filepath.Join(t.TempDir(), "api.http")
+	content := `< {%
+client.execution.skipRequest();
+%}
+POST ` + server.URL + `/skipped
+
+###
+
+GET ` + server.URL + `/job
+
+> {%
+client.execution.skipRequest();
+%}
+
+###
+
+GET ` + server.URL + `/skipped-by-previous
+
+###
+
+DELETE ` + server.URL + `/job
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeRunCommand(t, httpFile)
+	if err != nil {
+		t.Fatalf("command failed: %v\nOutput: %s", err, output)
+	}
+
+	if hits["POST /skipped"] != 0 || hits["GET /skipped-by-previous"] != 0 {
+		t.Errorf("skipped requests should not be sent: %v", hits)
+	}
+	if hits["GET /job"] != 1 || hits["DELETE /job"] != 1 {
+		t.Errorf("unexpected hits: %v", hits)
+	}
+	if !strings.Contains(output, "Requests: 2 passed, 0 failed, 2 skipped, 4 total") {
+		t.Errorf("output should report skipped requests\nGot: %s", output)
+	}
+}
+
+func TestRunCommand_MaxRequestsCountsSkipped(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+	defer func() { runMaxRequests = 1000 }()
+
+	hits := make(map[string]int)
+	server := newPollTestServer(t, hits)
+	httpFile := filepath.Join(t.TempDir(), "api.http")
+	content := `# @name again
+< {%
+client.execution.skipRequest();
+client.execution.setNextRequest("again");
+%}
+GET ` + server.URL + `/again
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeRunCommand(t, "--max-requests", "5", httpFile)
+	if !errors.Is(err, errors.ErrInvalidInput) {
+		t.Fatalf("expected max-requests error, got %v\nOutput: %s", err, output)
+	}
+	if hits["GET /again"] != 0 {
+		t.Errorf("skipped requests should not be sent, got %d", hits["GET /again"])
+	}
+	if got := strings.Count(output, "skipped\n"); got != 5 {
+		t.Errorf("expected 5 skipped requests, got %d\nOutput: %s", got, output)
+	}
+}
+
+func TestRunCommand_SetNextRequestUnknown(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	hits := make(map[string]int)
+	server := newPollTestServer(t, hits)
+	httpFile := filepath.Join(t.TempDir(), "api.http")
+	content := `GET ` + server.URL + `/job
+
+> {%
+client.execution.setNextRequest("missing");
+%}
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeRunCommand(t, httpFile)
+	if err == nil {
+		t.Fatalf("expected error for unknown request\nOutput: %s", output)
+	}
+	if !strings.Contains(output, "missing") || !strings.Contains(output, "request not found") {
+		t.Errorf("output should name the missing request\nGot: %s", output)
+	}
+}
+
+func TestRunCommand_MaxRequests(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+	defer func() { runMaxRequests = 1000 }()
+
+	hits := make(map[string]int)
+	server := newPollTestServer(t, hits)
+	httpFile := filepath.Join(t.TempDir(), "api.http")
+	content := `# @name forever
+GET ` + server.URL + `/forever
+
+> {%
+client.execution.setNextRequest("forever");
+%}
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeRunCommand(t, "--max-requests", "5", httpFile)
+	if !errors.Is(err, errors.ErrInvalidInput) {
+		t.Fatalf("expected max-requests error, got %v\nOutput: %s", err, output)
+	}
+	if hits["GET /forever"] != 5 {
+		t.Errorf("expected 5 requests, got %d", hits["GET /forever"])
+	}
+}
+
+func TestRunCommand_ReportsUnresolvedURL(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	httpFile := filepath.Join(t.TempDir(), "api.http")
+	if err := os.WriteFile(httpFile, []byte("GET https://example.com/{{%}}\n"), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeRunCommand(t, httpFile)
+	if err == nil {
+		t.Fatalf("expected the request to fail\nOutput: %s", output)
+	}
+	for _, want := range []string{"[1] GET https://example.com/{{%}}", "(GET https://example.com/{{%}})"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q\nGot: %s", want, output)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -22,6 +24,7 @@ import (
 	"github.com/ideaspaper/restclient/pkg/lastfile"
 	"github.com/ideaspaper/restclient/pkg/models"
 	"github.com/ideaspaper/restclient/pkg/parser"
+	"github.com/ideaspaper/restclient/pkg/scripting"
 	"github.com/ideaspaper/restclient/pkg/session"
 	"github.com/ideaspaper/restclient/pkg/tui"
 	"github.com/ideaspaper/restclient/pkg/userinput"
@@ -64,17 +67,20 @@ func (r RequestItem) String() string {
 }
 
 var (
-	requestName  string
-	requestIndex int
-	showHeaders  bool
-	showBody     bool
-	outputFile   string
-	noHistory    bool
-	dryRun       bool
-	skipValidate bool
-	sessionName  string
-	noSession    bool
-	strictMode   bool
+	requestName   string
+	requestIndex  int
+	showHeaders   bool
+	showBody      bool
+	outputFile    string
+	streamBody    bool
+	maxBodyMemory int
+	noHistory     bool
+	dryRun        bool
+	dryRunAs      string
+	skipValidate  bool
+	sessionName   string
+	noSession     bool
+	strictMode    bool
 )
 
 // sendCmd represents the send command
@@ -109,7 +115,16 @@ Examples:
   restclient send api.http --body
 
   # Save response to file
-  restclient send api.http --output response.json`,
+  restclient send api.http --output response.json
+
+  # Download a large file, streaming it to disk with a progress bar
+  restclient send api.http --name export --output export.csv
+
+  # Stream the body to stdout as it arrives
+  restclient send api.http --name export --stream | gzip > export.csv.gz
+
+  # Print the resolved request as a curl command
+  restclient send api.http --name getUsers --dry-run --as curl`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runSend,
 }
@@ -121,83 +136,139 @@ func init() {
 	sendCmd.Flags().IntVarP(&requestIndex, "index", "i", 0, "request index (1-based)")
 	sendCmd.Flags().BoolVar(&showHeaders, "headers", false, "only show response headers")
 	sendCmd.Flags().BoolVar(&showBody, "body", false, "only show response body")
-	sendCmd.Flags().StringVarP(&outputFile, "output", "o", "", "save response body to file")
+	sendCmd.Flags().StringVarP(&outputFile, "output", "o", "", "save response body to file as it arrives")
+	sendCmd.Flags().BoolVar(&streamBody, "stream", false, "write response body to stdout as it arrives, without formatting")
+	sendCmd.Flags().IntVar(&maxBodyMemory, "max-body-memory", 10, "MiB of a streamed response body kept in memory for scripts")
 	sendCmd.Flags().BoolVar(&noHistory, "no-history", false, "don't save request to history")
 	sendCmd.Flags().BoolVar(&dryRun, "dry-run", false, "preview request without sending")
+	sendCmd.Flags().StringVar(&dryRunAs, "as", "", "with --dry-run, print the request as a command instead (curl)")
 	sendCmd.Flags().BoolVar(&skipValidate, "skip-validate", false, "skip request validation")
 	sendCmd.Flags().StringVar(&sessionName, "session", "", "use named session instead of directory-based session")
-	sendCmd.Flags().BoolVar(&noSession, "no-session", false, "don't load or save session state (cookies and variables)")
+	sendCmd.Flags().BoolVar(&noSession, "no-session", false, "don't load or save session state (cookies, variables and request results)")
 	sendCmd.Flags().BoolVar(&strictMode, "strict", false, "error on duplicate @name values instead of warning")
 }
 
 func runSend(cmd *cobra.Command, args []string) error {
-	filePath, err := resolveRequestFilePath(cmd, args)
-	if err != nil {
-		return err
+	if dryRunAs != "" {
+		if !dryRun {
+			return errors.NewValidationError("as", "requires --dry-run")
+		}
+		if dryRunAs != "curl" {
+			return errors.NewValidationErrorWithValue("as", dryRunAs, "unsupported format, expected curl")
+		}
 	}
 
-	cfg, sessionCfg, envStore, err := loadSendConfig(filePath)
-	if err != nil {
-		return err
+	// The body owns stdout when it is streamed; everything else goes to stderr
+	out := cmd.OutOrStdout()
+	if streamsToStdout() {
+		out = cmd.ErrOrStderr()
 	}
 
-	content, err := readRequestFile(filePath)
+	setup, err := loadRequestForSend(cmd, args, out)
 	if err != nil {
+		if errors.Is(err, errors.ErrCanceled) {
+			return nil
+		}
 		return err
 	}
+	request := setup.request
 
-	varProcessor := buildVariableProcessor(sessionCfg, filePath, content, envStore)
-
-	requests, parseWarnings, err := parseRequestsFromContent(content, sessionCfg, filePath)
-	if err != nil {
-		return err
+	// Named requests this one refers to are sent first, unless cached
+	if !dryRun {
+		dependencies := newDependencies(out, setup.filePath, setup.requests, setup.imported, setup.sessionCfg, setup.varProcessor, setup.envStore)
+		if err := dependencies.Resolve(context.Background(), request); err != nil {
+			return err
+		}
 	}
 
-	printParseWarnings(parseWarnings)
-
-	request, err := selectRequestForSend(cmd, requests)
+	flow, err := prepareRequest(out, request, setup.filePath, setup.sessionCfg, setup.varProcessor, setup.envStore)
 	if err != nil {
 		if errors.Is(err, errors.ErrCanceled) {
 			return nil
 		}
 		return err
 	}
+	if flow.Skip {
+		fmt.Fprintln(out, "Request skipped by pre-request script")
+		return nil
+	}
 
-	printRequestWarnings(request)
+	if dryRunAs == "curl" {
+		return printDryRunCurl(setup.filePath, request, setup.sessionCfg)
+	}
+	if dryRun {
+		return printDryRun(setup.filePath, request, setup.cfg, setup.sessionCfg)
+	}
 
-	if err := processSessionInputs(request, filePath); err != nil {
-		if errors.Is(err, errors.ErrCanceled) {
-			return nil
-		}
-		return err
+	fmt.Fprintf(out, "%s %s\n\n", printMethod(request.Method), request.URL)
+
+	return sendRequest(out, setup.filePath, request, setup.sessionCfg, setup.varProcessor, setup.envStore)
+}
+
+// sendSetup is a request selected from a .http file, with the
+// configuration, session and variables it is resolved with
+type sendSetup struct {
+	filePath     string
+	cfg          *config.Config
+	sessionCfg   *session.SessionConfig
+	envStore     *session.EnvironmentStore
+	varProcessor *variables.VariableProcessor
+	requests     []*models.HttpRequest
+	imported     []*models.HttpRequest
+	request      *models.HttpRequest
+}
+
+// loadRequestForSend loads the file and session of send, bench and codegen
+// and selects the request, printing parse and request warnings and the
+// selected request to out. It returns errors.ErrCanceled when the selection
+// is canceled.
+func loadRequestForSend(cmd *cobra.Command, args []string, out io.Writer) (*sendSetup, error) {
+	filePath, err := resolveRequestFilePath(cmd, args)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := applyPromptVariables(request, varProcessor); err != nil {
-		return err
+	cfg, sessionCfg, envStore, err := loadSendConfig(filePath)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := runPreRequestScript(request, sessionCfg, varProcessor, envStore); err != nil {
-		if errors.Is(err, errors.ErrCanceled) {
-			return nil
-		}
-		return err
+	content, err := readRequestFile(filePath)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := processRequestVariables(request, varProcessor); err != nil {
-		return err
+	imports, err := loadImports(filePath, content, sessionCfg)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := validateRequest(request); err != nil {
-		return err
+	varProcessor := buildVariableProcessor(sessionCfg, filePath, content, envStore, imports)
+
+	requests, parseWarnings, err := parseRequestsFromContent(content, sessionCfg, filePath)
+	if err != nil {
+		return nil, err
 	}
 
-	if dryRun {
-		return printDryRun(filePath, request, cfg, sessionCfg)
+	printParseWarnings(parseWarnings)
+
+	request, err := selectRequestForSend(cmd, out, requests, imports.requests)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Printf("%s %s\n\n", printMethod(request.Method), request.URL)
+	printRequestWarnings(request)
 
-	return sendRequest(filePath, request, sessionCfg, varProcessor, envStore)
+	return &sendSetup{
+		filePath:     filePath,
+		cfg:          cfg,
+		sessionCfg:   sessionCfg,
+		envStore:     envStore,
+		varProcessor: varProcessor,
+		requests:     requests,
+		imported:     imports.requests,
+		request:      request,
+	}, nil
 }
 
 func resolveRequestFilePath(cmd *cobra.Command, args []string) (string, error) {
@@ -274,20 +345,8 @@ func readRequestFile(filePath string) ([]byte, error) {
 	return content, nil
 }
 
-func buildVariableProcessor(sessionCfg *session.SessionConfig, filePath string, content []byte, envStore *session.EnvironmentStore) *variables.VariableProcessor {
-	fileVarsResult := variables.ParseFileVariablesWithDuplicates(string(content))
-
-	if len(fileVarsResult.Duplicates) > 0 {
-		for _, dup := range fileVarsResult.Duplicates {
-			msg := fmt.Sprintf("duplicate file variable '@%s': value '%s' overwritten with '%s'",
-				dup.Name, dup.OldValue, dup.NewValue)
-			if useColors() {
-				warnColor.Fprintf(os.Stderr, "Warning: %s\n", msg)
-			} else {
-				fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
-			}
-		}
-	}
+func buildVariableProcessor(sessionCfg *session.SessionConfig, filePath string, content []byte, envStore *session.EnvironmentStore, imports *importedFiles) *variables.VariableProcessor {
+	fileVars := parseFileVariables(content)
 
 	varProcessor := variables.NewVariableProcessor()
 	varProcessor.SetEnvironment(sessionCfg.CurrentEnvironment())
@@ -297,15 +356,110 @@ func buildVariableProcessor(sessionCfg *session.SessionConfig, filePath string,
 		varProcessor.SetEnvironmentVariables(envStore.EnvironmentVariables)
 	}
 
-	varProcessor.SetFileVariables(fileVarsResult.Variables)
+	// Session variables are overridden by those of the imports, which are
+	// overridden by those of the file
+	if !noSession && sessionCfg.RememberCookies() {
+		loadSessionVariables(varProcessor, filePath)
+		loadSessionResults(varProcessor, filePath)
+	}
+	varProcessor.SetFileVariables(imports.variables)
+	varProcessor.SetFileVariables(fileVars)
 	varProcessor.SetCurrentDir(filepath.Dir(filePath))
 	varProcessor.SetPromptHandler(promptHandler)
 
 	return varProcessor
 }
 
+// loadSessionVariables makes the variables stored in the session by
+// client.global.set() in earlier invocations available to requests
+func loadSessionVariables(varProcessor *variables.VariableProcessor, filePath string) {
+	sessionMgr, err := session.NewSessionManager("", filePath, sessionName)
+	if err != nil {
+		return
+	}
+	if err := sessionMgr.LoadVariables(); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Warning: failed to load session variables: %v\n", err)
+		}
+		return
+	}
+
+	vars := make(map[string]string)
+	for name, value := range sessionMgr.GetAllVariables() {
+		vars[name] = fmt.Sprintf("%v", value)
+	}
+	varProcessor.SetFileVariables(vars)
+}
+
+// loadSessionResults makes the named request results stored in the
+// session by earlier invocations available to request variables
+func loadSessionResults(varProcessor *variables.VariableProcessor, filePath string) {
+	sessionMgr, err := session.NewSessionManager("", filePath, sessionName)
+	if err != nil {
+		return
+	}
+	if err := sessionMgr.LoadResults(); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Warning: failed to load session results: %v\n", err)
+		}
+		return
+	}
+
+	for key, result := range sessionMgr.GetAllResults() {
+		varProcessor.SetRequestResult(key, variables.RequestResult{
+			StatusCode: result.StatusCode,
+			Headers:    result.Headers,
+			Body:       result.Body,
+			ExpiresAt:  result.ExpiresAt,
+		})
+	}
+}
+
+// parseFileVariables extracts file variables, warning about duplicates
+func parseFileVariables(content []byte) map[string]string {
+	fileVarsResult := variables.ParseFileVariablesWithDuplicates(string(content))
+
+	for _, dup := range fileVarsResult.Duplicates {
+		msg := fmt.Sprintf("duplicate file variable '@%s': value '%s' overwritten with '%s'",
+			dup.Name, dup.OldValue, dup.NewValue)
+		if useColors() {
+			warnColor.Fprintf(os.Stderr, "Warning: %s\n", msg)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+		}
+	}
+
+	return fileVarsResult.Variables
+}
+
+// importedFiles holds what the files imported with @import share: their
+// file variables, merged in import order, and their requests
+type importedFiles struct {
+	variables map[string]string
+	requests  []*models.HttpRequest
+}
+
+// loadImports resolves the @import directives of a file
+func loadImports(filePath string, content []byte, sessionCfg *session.SessionConfig) (*importedFiles, error) {
+	files, err := parser.ResolveImports(filePath, string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	imports := &importedFiles{variables: make(map[string]string)}
+	for _, file := range files {
+		maps.Copy(imports.variables, variables.ParseFileVariables(file.Content))
+
+		httpParser := parser.NewHttpRequestParser(file.Content, sessionCfg.DefaultHeaders(), filepath.Dir(file.Path))
+		httpParser.SetSourceFile(file.Path)
+		imports.requests = append(imports.requests, httpParser.ParseAllWithWarnings().Requests...)
+	}
+	return imports, nil
+}
+
 func parseRequestsFromContent(content []byte, sessionCfg *session.SessionConfig, filePath string) ([]*models.HttpRequest, []parser.ParseWarning, error) {
 	httpParser := parser.NewHttpRequestParser(string(content), sessionCfg.DefaultHeaders(), filepath.Dir(filePath))
+	httpParser.SetSourceFile(filePath)
 	parseResult := httpParser.ParseAllWithWarnings()
 	requests := parseResult.Requests
 
@@ -358,16 +512,18 @@ func printParseWarnings(warnings []parser.ParseWarning) {
 
 	for _, w := range warnings {
 		if useColors() {
-			warnColor.Fprintf(os.Stderr, "Warning: block %d: %s\n", w.BlockIndex+1, w.Message)
+			warnColor.Fprintf(os.Stderr, "Warning: line %d: %s\n", w.Line+1, w.Message)
 		} else {
-			fmt.Fprintf(os.Stderr, "Warning: block %d: %s\n", w.BlockIndex+1, w.Message)
+			fmt.Fprintf(os.Stderr, "Warning: line %d: %s\n", w.Line+1, w.Message)
 		}
 	}
 
 	fmt.Fprintln(os.Stderr)
 }
 
-func selectRequestForSend(cmd *cobra.Command, requests []*models.HttpRequest) (*models.HttpRequest, error) {
+// selectRequestForSend picks the request to send by --name, --index or
+// interactively. Only --name reaches the requests of imported files.
+func selectRequestForSend(cmd *cobra.Command, out io.Writer, requests, imported []*models.HttpRequest) (*models.HttpRequest, error) {
 	var request *models.HttpRequest
 	var selectedIndex int
 
@@ -380,10 +536,16 @@ func selectRequestForSend(cmd *cobra.Command, requests []*models.HttpRequest) (*
 			}
 		}
 		if request == nil {
+			for _, req := range imported {
+				if req.Name == requestName || req.Metadata.Name == requestName {
+					fmt.Fprintf(out, "\n%s %s  %s (%s)\n\n", req.Method, stringutil.Truncate(req.URL, 50), requestName, filepath.Base(req.SourceFile))
+					return req, nil
+				}
+			}
 			return nil, errors.NewValidationErrorWithValue("request name", requestName, "request not found")
 		}
 		item := RequestItem{Request: request, Index: selectedIndex}
-		fmt.Printf("\n%s\n\n", item.String())
+		fmt.Fprintf(out, "\n%s\n\n", item.String())
 		return request, nil
 	}
 
@@ -395,7 +557,7 @@ func selectRequestForSend(cmd *cobra.Command, requests []*models.HttpRequest) (*
 		request = requests[internalIndex]
 		selectedIndex = internalIndex
 		item := RequestItem{Request: request, Index: selectedIndex}
-		fmt.Printf("\n%s\n\n", item.String())
+		fmt.Fprintf(out, "\n%s\n\n", item.String())
 		return request, nil
 	}
 
@@ -404,7 +566,7 @@ func selectRequestForSend(cmd *cobra.Command, requests []*models.HttpRequest) (*
 		if err != nil {
 			return nil, err
 		}
-		fmt.Println() // Blank line after selection
+		fmt.Fprintln(out) // Blank line after selection
 		return selected, nil
 	}
 
@@ -426,6 +588,31 @@ func printRequestWarnings(request *models.HttpRequest) {
 	fmt.Fprintln(os.Stderr)
 }
 
+// prepareRequest runs the preparation steps of send: user input, prompts,
+// pre-request script, variable substitution and validation. It returns the
+// flow-control decisions made by the pre-request script, whose logs are
+// printed to out.
+func prepareRequest(out io.Writer, request *models.HttpRequest, filePath string, sessionCfg *session.SessionConfig, varProcessor *variables.VariableProcessor, envStore *session.EnvironmentStore) (scripting.Execution, error) {
+	if err := processSessionInputs(request, filePath); err != nil {
+		return scripting.Execution{}, err
+	}
+
+	if err := applyPromptVariables(request, varProcessor); err != nil {
+		return scripting.Execution{}, err
+	}
+
+	flow, err := runPreRequestScript(out, request, sessionCfg, varProcessor, envStore)
+	if err != nil || flow.Skip {
+		return flow, err
+	}
+
+	if err := processRequestVariables(request, varProcessor); err != nil {
+		return flow, err
+	}
+
+	return flow, validateRequest(request)
+}
+
 func processSessionInputs(request *models.HttpRequest, filePath string) error {
 	if noSession {
 		return nil
@@ -528,9 +715,12 @@ func applyPromptVariables(request *models.HttpRequest, varProcessor *variables.V
 	return nil
 }
 
-func runPreRequestScript(request *models.HttpRequest, sessionCfg *session.SessionConfig, varProcessor *variables.VariableProcessor, envStore *session.EnvironmentStore) error {
+// runPreRequestScript runs the request's pre-request script, returning the
+// flow-control decisions it made through client.execution and printing its
+// logs to out
+func runPreRequestScript(out io.Writer, request *models.HttpRequest, sessionCfg *session.SessionConfig, varProcessor *variables.VariableProcessor, envStore *session.EnvironmentStore) (scripting.Execution, error) {
 	if request.Metadata.PreScript == "" {
-		return nil
+		return scripting.Execution{}, nil
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -551,38 +741,40 @@ func runPreRequestScript(request *models.HttpRequest, sessionCfg *session.Sessio
 	result, err := executor.ExecutePreScriptWithContext(ctx, request.Metadata.PreScript, sessionCfg, request, varProcessor, envStore)
 	if err != nil {
 		if ctx.Err() == context.Canceled {
-			return errors.Wrap(errors.ErrCanceled, "pre-script cancelled")
+			return scripting.Execution{}, errors.Wrap(errors.ErrCanceled, "pre-script cancelled")
 		}
-		return err
+		return scripting.Execution{}, err
 	}
 
 	for _, log := range result.Logs {
-		fmt.Printf("[pre-script] %s\n", log)
+		fmt.Fprintf(out, "[pre-script] %s\n", log)
 	}
 
-	return nil
+	return result.Execution, nil
 }
 
 func processRequestVariables(request *models.HttpRequest, varProcessor *variables.VariableProcessor) error {
-	var varErr error
-
-	request.URL, varErr = varProcessor.Process(request.URL)
-	if varErr != nil {
-		return errors.Wrap(varErr, "failed to process variables in URL")
+	// Fields keep their unresolved value on error, so that it can be reported
+	url, err := varProcessor.Process(request.URL)
+	if err != nil {
+		return errors.Wrap(err, "failed to process variables in URL")
 	}
+	request.URL = url
 
 	for k, v := range request.Headers {
-		request.Headers[k], varErr = varProcessor.Process(v)
-		if varErr != nil {
-			return errors.Wrapf(varErr, "failed to process variables in header %s", k)
+		value, err := varProcessor.Process(v)
+		if err != nil {
+			return errors.Wrapf(err, "failed to process variables in header %s", k)
 		}
+		request.Headers[k] = value
 	}
 
 	if request.RawBody != "" {
-		request.RawBody, varErr = varProcessor.Process(request.RawBody)
-		if varErr != nil {
-			return errors.Wrap(varErr, "failed to process variables in body")
+		body, err := varProcessor.Process(request.RawBody)
+		if err != nil {
+			return errors.Wrap(err, "failed to process variables in body")
 		}
+		request.RawBody = body
 		request.Body = strings.NewReader(request.RawBody)
 	}
 
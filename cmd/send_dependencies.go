@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/ideaspaper/restclient/pkg/executor"
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/session"
+	"github.com/ideaspaper/restclient/pkg/variables"
+)
+
+// newDependencies returns a resolver that sends the named requests a
+// request refers to, looking them up in the file and its imports. Each
+// dependency is prepared like the request itself and reported on one line
+// to out.
+func newDependencies(out io.Writer, filePath string, requests, imported []*models.HttpRequest, sessionCfg *session.SessionConfig, varProcessor *variables.VariableProcessor, envStore *session.EnvironmentStore) *executor.Dependencies {
+	fileSet := append(slices.Clone(requests), imported...)
+
+	return executor.NewDependencies(fileSet, varProcessor, func(ctx context.Context, request *models.HttpRequest) error {
+		// Dynamic variables are resolved again for every request
+		varProcessor.ClearCache()
+
+		flow, err := prepareRequest(out, request, filePath, sessionCfg, varProcessor, envStore)
+		if err != nil || flow.Skip {
+			return err
+		}
+
+		opts := executor.Options{
+			HTTPFilePath:     filePath,
+			SessionName:      sessionName,
+			NoSession:        noSession,
+			NoHistory:        noHistory,
+			Verbose:          verbose,
+			EnvironmentStore: envStore,
+			LogFunc: func(format string, args ...any) {
+				fmt.Fprintf(os.Stderr, format+"\n", args...)
+			},
+		}
+		result, err := executor.New(sessionCfg, varProcessor, opts).ExecuteWithContext(ctx, request)
+		printDependency(out, request, result)
+		return err
+	})
+}
+
+// printDependency prints a dependency sent before the requested request
+func printDependency(out io.Writer, request *models.HttpRequest, result *executor.Result) {
+	line := fmt.Sprintf("%s %s %s  %s", printDimText("[dependency]"), printMethod(request.Method), request.URL, printDimText(requestLabel(request)))
+	if result != nil && result.Response != nil {
+		line += "  " + formatRunStatus(result.Response)
+	}
+	fmt.Fprintf(out, "%s\n\n", line)
+}
+
+// requestLabel returns the name of a request for display
+func requestLabel(request *models.HttpRequest) string {
+	if request.Metadata.Name != "" {
+		return request.Metadata.Name
+	}
+	return request.Name
+}
@@ -3,13 +3,18 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
 	"syscall"
-	"time"
 
+	"github.com/ideaspaper/restclient/internal/constants"
+	"github.com/ideaspaper/restclient/pkg/client"
 	"github.com/ideaspaper/restclient/pkg/config"
+	"github.com/ideaspaper/restclient/pkg/curl"
 	"github.com/ideaspaper/restclient/pkg/errors"
 	"github.com/ideaspaper/restclient/pkg/executor"
 	"github.com/ideaspaper/restclient/pkg/models"
@@ -20,16 +25,24 @@ import (
 )
 
 // sendRequest sends an HTTP request with session management and scripting support
-func sendRequest(httpFilePath string, request *models.HttpRequest, sessionCfg *session.SessionConfig, varProcessor *variables.VariableProcessor, envStore *session.EnvironmentStore) error {
+func sendRequest(out io.Writer, httpFilePath string, request *models.HttpRequest, sessionCfg *session.SessionConfig, varProcessor *variables.VariableProcessor, envStore *session.EnvironmentStore) error {
 	// Create context with cancellation support for interrupt signals
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Handle interrupt signal (Ctrl+C)
+	// Handle interrupt signal (Ctrl+C). During an event stream or WebSocket
+	// the first interrupt only ends the stream, so that what was received so
+	// far is still displayed and passed to the post-response script.
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	stopEvents := make(chan struct{})
+	var eventStreamOpen atomic.Bool
 	go func() {
 		<-sigChan
+		if eventStreamOpen.Load() {
+			close(stopEvents)
+			<-sigChan
+		}
 		if verbose {
 			fmt.Fprintln(os.Stderr, "\nInterrupt received, cancelling request...")
 		}
@@ -37,13 +50,6 @@ func sendRequest(httpFilePath string, request *models.HttpRequest, sessionCfg *s
 	}()
 	defer signal.Stop(sigChan)
 
-	// Apply timeout from session config if set
-	if sessionCfg.HTTP.TimeoutMs > 0 {
-		var timeoutCancel context.CancelFunc
-		ctx, timeoutCancel = context.WithTimeout(ctx, time.Duration(sessionCfg.HTTP.TimeoutMs)*time.Millisecond)
-		defer timeoutCancel()
-	}
-
 	opts := executor.Options{
 		HTTPFilePath:     httpFilePath,
 		SessionName:      sessionName,
@@ -56,93 +62,122 @@ func sendRequest(httpFilePath string, request *models.HttpRequest, sessionCfg *s
 		},
 	}
 
+	formatter := output.NewFormatter(useColors())
+	opts.Events = &client.EventConfig{
+		OnOpen: func() { eventStreamOpen.Store(true) },
+		OnEvent: func(event models.ServerSentEvent) {
+			fmt.Fprintln(out, formatter.FormatEvent(event))
+			fmt.Fprintln(out)
+		},
+		OnMessage: func(message models.WebSocketMessage, sent bool) {
+			fmt.Fprintln(out, formatter.FormatMessage(message, sent))
+			fmt.Fprintln(out)
+		},
+		Stop: stopEvents,
+	}
+
+	stream := newResponseStream()
+	if stream != nil {
+		opts.Stream = stream.config()
+	}
 	exec := executor.New(sessionCfg, varProcessor, opts)
 
 	// Print request info if verbose
 	if verbose {
-		printRequestInfo(request)
+		printRequestInfo(out, request)
 	}
 
 	result, err := exec.ExecuteWithContext(ctx, request)
+	if stream != nil {
+		if closeErr := stream.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
 	if err != nil {
 		// Check for context cancellation
 		if ctx.Err() == context.Canceled {
 			return errors.Wrap(errors.ErrCanceled, "request cancelled")
 		}
-		if ctx.Err() == context.DeadlineExceeded {
-			return errors.Wrap(errors.ErrTimeout, "request timed out")
-		}
 		return err
 	}
 
 	// Print logs from scripts
 	for _, log := range result.Logs {
-		fmt.Printf("[script] %s\n", log)
+		fmt.Fprintf(out, "[script] %s\n", log)
 	}
 
 	// Print test results if any
 	if len(result.TestResults) > 0 {
-		printTestResults(result.TestResults)
+		printTestResults(out, result.TestResults)
 	}
 
-	formatter := output.NewFormatter(useColors())
-	return displayResponse(result.Response, formatter)
+	if streamsToStdout() && result.Response.Streamed {
+		return nil
+	}
+
+	return displayResponse(out, result.Response, formatter)
 }
 
 // printTestResults prints script test results
-func printTestResults(tests []scripting.TestResult) {
-	fmt.Println("Test Results:")
+func printTestResults(out io.Writer, tests []scripting.TestResult) {
+	fmt.Fprintln(out, "Test Results:")
 	for _, test := range tests {
 		if test.Passed {
-			printTestPass(test.Name)
+			fprintTestPass(out, test.Name)
 		} else {
-			printTestFail(test.Name, test.Error)
+			fprintTestFail(out, test.Name, test.Error)
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(out)
 }
 
 // displayResponse formats and displays an HTTP response
-func displayResponse(resp *models.HttpResponse, formatter *output.Formatter) error {
+func displayResponse(out io.Writer, resp *models.HttpResponse, formatter *output.Formatter) error {
 	if outputFile != "" {
-		if err := os.WriteFile(outputFile, resp.BodyBuffer, 0644); err != nil {
-			return errors.Wrap(err, "failed to write output file")
+		// Streamed bodies are already in the file, unless they were empty
+		if !resp.Streamed || resp.BodySizeInBytes == 0 {
+			if err := os.WriteFile(outputFile, resp.BodyBuffer, 0644); err != nil {
+				return errors.Wrap(err, "failed to write output file")
+			}
 		}
-		fmt.Printf("Response saved to %s\n", outputFile)
+		fmt.Fprintf(out, "Response saved to %s\n", outputFile)
 		if showBody {
 			return nil
 		}
 	}
 
 	if showBody {
-		fmt.Println(formatter.FormatBody(resp))
+		// Events and messages were already printed as they arrived
+		if !resp.IsEventStream() && !resp.IsWebSocket() {
+			fmt.Fprintln(out, formatter.FormatBody(resp))
+		}
 		return nil
 	}
 
 	if showHeaders {
-		fmt.Println(formatter.FormatHeaders(resp))
+		fmt.Fprintln(out, formatter.FormatHeaders(resp))
 		return nil
 	}
 
-	fmt.Println(formatter.FormatResponse(resp))
+	fmt.Fprintln(out, formatter.FormatResponse(resp))
 	return nil
 }
 
-// printRequestInfo prints verbose request information
-func printRequestInfo(request *models.HttpRequest) {
+// printRequestInfo prints verbose request information to w
+func printRequestInfo(w io.Writer, request *models.HttpRequest) {
 	if useColors() {
-		headerColor.Printf("=> %s %s\n", request.Method, request.URL)
+		headerColor.Fprintf(w, "=> %s %s\n", request.Method, request.URL)
 	} else {
-		fmt.Printf("=> %s %s\n", request.Method, request.URL)
+		fmt.Fprintf(w, "=> %s %s\n", request.Method, request.URL)
 	}
 	for k, v := range request.Headers {
-		fmt.Printf("   %s: %s\n", k, v)
+		fmt.Fprintf(w, "   %s: %s\n", k, v)
 	}
 	if request.RawBody != "" {
-		fmt.Println()
-		fmt.Println(request.RawBody)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, request.RawBody)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // printDryRun displays a dry run of the request without sending it
@@ -212,8 +247,12 @@ func printDryRun(httpFilePath string, request *models.HttpRequest, cfg *config.C
 	fmt.Println("Request Settings:")
 	fmt.Printf("  Follow Redirects: %v\n", sessionCfg.HTTP.FollowRedirect && !request.Metadata.NoRedirect)
 	fmt.Printf("  Use Cookie Jar: %v\n", sessionCfg.RememberCookies() && !request.Metadata.NoCookieJar)
-	if sessionCfg.HTTP.TimeoutMs > 0 {
-		fmt.Printf("  Timeout: %dms\n", sessionCfg.HTTP.TimeoutMs)
+	clientCfg := executor.ClientConfigFor(sessionCfg, request)
+	if clientCfg.Timeout > 0 {
+		fmt.Printf("  Timeout: %s\n", clientCfg.Timeout)
+	}
+	if clientCfg.ConnectTimeout > 0 {
+		fmt.Printf("  Connect Timeout: %s\n", clientCfg.ConnectTimeout)
 	}
 	if sessionCfg.TLS.Proxy != "" {
 		fmt.Printf("  Proxy: %s\n", sessionCfg.TLS.Proxy)
@@ -221,3 +260,56 @@ func printDryRun(httpFilePath string, request *models.HttpRequest, cfg *config.C
 
 	return nil
 }
+
+// printDryRunCurl prints the request as a curl command, with the default
+// headers, session cookies and client settings it would be sent with
+func printDryRunCurl(httpFilePath string, request *models.HttpRequest, sessionCfg *session.SessionConfig) error {
+	resolved := withSessionState(httpFilePath, request, sessionCfg)
+	clientCfg := executor.ClientConfigFor(sessionCfg, request)
+	command, err := curl.Format(resolved, curl.Options{
+		FollowRedirects: clientCfg.FollowRedirects,
+		InsecureSSL:     clientCfg.InsecureSSL,
+		Proxy:           clientCfg.Proxy,
+		Timeout:         clientCfg.Timeout,
+		ConnectTimeout:  clientCfg.ConnectTimeout,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(command)
+	return nil
+}
+
+// withSessionState returns a copy of the request with the session's
+// default headers and, when the cookie jar is used, its cookies added,
+// as the client would send it
+func withSessionState(httpFilePath string, request *models.HttpRequest, sessionCfg *session.SessionConfig) *models.HttpRequest {
+	resolved := request.Clone()
+	if resolved.Headers == nil {
+		resolved.Headers = make(map[string]string)
+	}
+	for k, v := range sessionCfg.DefaultHeaders() {
+		if _, exists := resolved.Headers[k]; !exists {
+			resolved.Headers[k] = v
+		}
+	}
+
+	if noSession || !sessionCfg.RememberCookies() || request.Metadata.NoCookieJar {
+		return resolved
+	}
+	sessionMgr, err := session.NewSessionManager("", httpFilePath, sessionName)
+	if err != nil || sessionMgr.Load() != nil {
+		return resolved
+	}
+	var cookies []string
+	for _, cookie := range sessionMgr.GetCookiesForURL(request.URL) {
+		cookies = append(cookies, cookie.Name+"="+cookie.Value)
+	}
+	if len(cookies) > 0 {
+		if existing := resolved.Headers[constants.HeaderCookie]; existing != "" {
+			cookies = append([]string{existing}, cookies...)
+		}
+		resolved.Headers[constants.HeaderCookie] = strings.Join(cookies, "; ")
+	}
+	return resolved
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/ideaspaper/restclient/pkg/client"
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// responseStream receives a streamed response body. It writes to the
+// --output file, created when the first bytes arrive so that failed
+// requests leave no empty file behind, or to stdout.
+type responseStream struct {
+	path     string
+	stdout   io.Writer
+	file     *os.File
+	progress *progressBar
+}
+
+// newResponseStream returns the stream for the response body, or nil if the
+// body should be buffered and displayed as usual
+func newResponseStream() *responseStream {
+	if outputFile == "" && !streamBody {
+		return nil
+	}
+
+	stream := &responseStream{path: outputFile, stdout: os.Stdout}
+	// A progress bar would garble a body printed to the same terminal
+	if isTerminal(os.Stderr) && (outputFile != "" || !isTerminal(os.Stdout)) {
+		stream.progress = newProgressBar(os.Stderr)
+	}
+	return stream
+}
+
+// streamsToStdout reports whether the response body is written to stdout as
+// it arrives, leaving stdout to the body alone
+func streamsToStdout() bool {
+	return streamBody && outputFile == ""
+}
+
+// config returns the client configuration for this stream
+func (s *responseStream) config() *client.StreamConfig {
+	cfg := &client.StreamConfig{
+		Writer:        s,
+		MaxBodyMemory: int64(maxBodyMemory) << 20,
+	}
+	if s.progress != nil {
+		cfg.Progress = s.progress.update
+	}
+	return cfg
+}
+
+func (s *responseStream) Write(p []byte) (int, error) {
+	if s.path == "" {
+		return s.stdout.Write(p)
+	}
+	if s.file == nil {
+		file, err := os.Create(s.path)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to create output file")
+		}
+		s.file = file
+	}
+	return s.file.Write(p)
+}
+
+// Close finishes the progress bar and closes the output file
+func (s *responseStream) Close() error {
+	if s.progress != nil {
+		s.progress.finish()
+	}
+	if s.file == nil {
+		return nil
+	}
+	if err := s.file.Close(); err != nil {
+		return errors.Wrap(err, "failed to write output file")
+	}
+	return nil
+}
+
+// isTerminal reports whether f is a terminal
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
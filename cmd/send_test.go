@@ -2,13 +2,21 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 func TestSendCommand_PrintsResolvedURL(t *testing.T) {
@@ -112,3 +120,419 @@ GET {{baseUrl}}/{{version}}/{{resource}}
 		t.Errorf("output should not contain any unresolved variables\nGot: %s", output)
 	}
 }
+
+// executeSendCommand runs the send command and returns its stdout. Stdout is
+// read while the command runs, so large bodies cannot fill the pipe.
+func executeSendCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	defer func() {
+		requestName = ""
+		outputFile = ""
+		streamBody = false
+		maxBodyMemory = 10
+		dryRun = false
+		dryRunAs = ""
+	}()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	var buf bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&buf, r)
+		close(done)
+	}()
+
+	rootCmd.SetArgs(append([]string{"send", "--no-history", "--no-session"}, args...))
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+	<-done
+	return buf.String(), err
+}
+
+func TestSendCommand_StreamToStdout(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	payload := strings.Repeat("0123456789abcdef", 1<<17) // 2 MiB
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	httpFile := filepath.Join(t.TempDir(), "test.http")
+	content := `GET ` + server.URL + `/export
+
+> {%
+client.test("body is capped", function() {
+  client.assert(response.body.length === 1048576, "got " + response.body.length);
+});
+%}
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeSendCommand(t, httpFile, "--stream", "--max-body-memory", "1")
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+
+	if output != payload {
+		t.Errorf("stdout should hold only the body: got %d bytes, want %d", len(output), len(payload))
+	}
+}
+
+func TestSendCommand_StreamToStdoutKeepsStatusOnStderr(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"token": "abc"}`))
+			return
+		}
+		w.Write([]byte("id,name\n1,Ada\n"))
+	}))
+	defer server.Close()
+
+	httpFile := filepath.Join(t.TempDir(), "test.http")
+	content := `# @name login
+POST ` + server.URL + `/login
+
+###
+
+# @name export
+< {%
+client.log("exporting");
+%}
+GET ` + server.URL + `/export?token={{login.response.body.$.token}}
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeSendCommand(t, httpFile, "--name", "export", "--stream")
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+
+	// Selection, dependency and pre-script lines go to stderr
+	if want := "id,name\n1,Ada\n"; output != want {
+		t.Errorf("stdout = %q, want only the body %q", output, want)
+	}
+}
+
+func TestSendCommand_StreamToOutputFile(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+			return
+		}
+		w.Write([]byte("id,name\n1,Ada\n"))
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/export", "/missing"} {
+		tempDir := t.TempDir()
+		httpFile := filepath.Join(tempDir, "test.http")
+		outPath := filepath.Join(tempDir, "out.csv")
+		if err := os.WriteFile(httpFile, []byte("GET "+server.URL+path+"\n"), 0644); err != nil {
+			t.Fatalf("failed to write http file: %v", err)
+		}
+
+		output, err := executeSendCommand(t, httpFile, "--output", outPath)
+		if err != nil {
+			t.Fatalf("%s: command failed: %v", path, err)
+		}
+
+		data, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatalf("%s: output file not written: %v", path, err)
+		}
+		want := "id,name\n1,Ada\n"
+		if path == "/missing" {
+			want = "not found"
+		}
+		if string(data) != want {
+			t.Errorf("%s: file = %q, want %q", path, data, want)
+		}
+		if !strings.Contains(output, "Response saved to "+outPath) {
+			t.Errorf("%s: output missing save message:\n%s", path, output)
+		}
+	}
+}
+
+func TestSendCommand_EventStream(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, "id: %d\nevent: tick\ndata: {\"n\": %d}\n\n", i, i)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	httpFile := filepath.Join(t.TempDir(), "test.http")
+	content := `GET ` + server.URL + `/events
+
+> {%
+client.test("received all ticks", function() {
+  client.assert(response.events.length === 3, "got " + response.events.length);
+  client.assert(response.events[2].data.n === 3, "last tick");
+});
+%}
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeSendCommand(t, httpFile)
+	if err != nil {
+		t.Fatalf("command failed: %v\noutput:\n%s", err, output)
+	}
+
+	for _, want := range []string{"event: tick  id: 1\n{\n  \"n\": 1\n}", "event: tick  id: 3", "(3 events)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestSendCommand_WebSocket(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"echo": `+string(data)+`}`))
+		}
+	}))
+	defer server.Close()
+
+	httpFile := filepath.Join(t.TempDir(), "test.http")
+	content := `WEBSOCKET ` + strings.Replace(server.URL, "http", "ws", 1) + `/live
+
+{"n": 1}
+=== wait-for-server
+{"n": 2}
+=== wait-for-server
+
+> {%
+client.test("received both echoes", function() {
+  client.assert(response.messages.length === 2, "got " + response.messages.length);
+  client.assert(response.messages[1].data.echo.n === 2, "second echo");
+});
+%}
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeSendCommand(t, httpFile)
+	if err != nil {
+		t.Fatalf("command failed: %v\noutput:\n%s", err, output)
+	}
+
+	for _, want := range []string{">> sent\n{\n  \"n\": 1\n}", "<< received\n{\n  \"echo\": {\n    \"n\": 2", "HTTP/1.1 101", "(2 messages received)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestSendCommand_GRPC(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	httpFile := filepath.Join(t.TempDir(), "test.http")
+	content := `GRPC ` + listener.Addr().String() + `/grpc.health.v1.Health/Check
+
+{"service": "orders"}
+
+> {%
+client.test("orders is serving", function() {
+  client.assert(response.status === 200, "status " + response.status);
+  client.assert(response.body.status === "SERVING", "body");
+  client.assert(response.headers.valueOf("grpc-status") === "0", "grpc-status");
+});
+%}
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeSendCommand(t, httpFile)
+	if err != nil {
+		t.Fatalf("command failed: %v\noutput:\n%s", err, output)
+	}
+	if !strings.Contains(output, "\"status\": \"SERVING\"") {
+		t.Errorf("output missing the reply:\n%s", output)
+	}
+}
+
+func TestSendCommand_Import(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method + " " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	common := `@baseUrl = ` + server.URL + `
+
+# @name ping
+HEAD {{baseUrl}}/ping
+`
+	api := `# @import ./common.http
+
+GET {{baseUrl}}/users
+`
+	if err := os.WriteFile(filepath.Join(dir, "common.http"), []byte(common), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+	httpFile := filepath.Join(dir, "api.http")
+	if err := os.WriteFile(httpFile, []byte(api), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeSendCommand(t, httpFile)
+	if err != nil {
+		t.Fatalf("command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "GET /users") {
+		t.Errorf("output should use the imported @baseUrl\nGot: %s", output)
+	}
+
+	output, err = executeSendCommand(t, httpFile, "--name", "ping")
+	if err != nil {
+		t.Fatalf("command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "HEAD "+server.URL+"/ping") {
+		t.Errorf("output should send the imported request\nGot: %s", output)
+	}
+}
+
+func TestSendCommand_Dependencies(t *testing.T) {
+	v.Set("showColors", false)
+	defer v.Set("showColors", true)
+
+	var logins int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			logins++
+			w.Write([]byte(`{"token": "abc"}`))
+		case "/me":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"name": "John"}`))
+		}
+	}))
+	defer server.Close()
+
+	httpFile := filepath.Join(t.TempDir(), "api.http")
+	content := `@baseUrl = ` + server.URL + `
+@token = {{login.response.body.$.token}}
+
+# @name me
+GET {{baseUrl}}/me
+Authorization: Bearer {{token}}
+
+###
+
+# @name login
+POST {{baseUrl}}/login
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeSendCommand(t, httpFile, "--name", "me")
+	if err != nil {
+		t.Fatalf("command failed: %v\nOutput: %s", err, output)
+	}
+	if logins != 1 {
+		t.Errorf("login sent %d times, want 1", logins)
+	}
+	if !strings.Contains(output, "[dependency] POST "+server.URL+"/login  login  200 OK") {
+		t.Errorf("output should report the dependency\nGot: %s", output)
+	}
+	if !strings.Contains(output, "200 OK") || !strings.Contains(output, "John") {
+		t.Errorf("output should show the authorized response\nGot: %s", output)
+	}
+}
+
+func TestSendCommand_DryRunAsCurl(t *testing.T) {
+	httpFile := filepath.Join(t.TempDir(), "api.http")
+	content := `@user = admin
+
+# @timeout 2s
+POST https://api.example.com/users
+Authorization: Basic {{user}}:secret
+Content-Type: application/json
+
+{"name": "John"}
+`
+	if err := os.WriteFile(httpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write http file: %v", err)
+	}
+
+	output, err := executeSendCommand(t, httpFile, "--dry-run", "--as", "curl")
+	if err != nil {
+		t.Fatalf("command failed: %v\nOutput: %s", err, output)
+	}
+	for _, want := range []string{
+		"curl https://api.example.com/users \\\n",
+		"-H 'Authorization: Basic YWRtaW46c2VjcmV0'",
+		`--data-raw '{"name": "John"}`,
+		"--max-time 2\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q\nGot: %s", want, output)
+		}
+	}
+
+	if _, err := executeSendCommand(t, httpFile, "--as", "curl"); err == nil {
+		t.Error("--as without --dry-run should fail")
+	}
+}
@@ -3,8 +3,10 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -18,6 +20,7 @@ var (
 	// Session command flags
 	clearCookies   bool
 	clearVariables bool
+	clearResults   bool
 	clearAllFlag   bool
 	sessionDir     string
 )
@@ -25,8 +28,9 @@ var (
 // sessionCmd represents the session command
 var sessionCmd = &cobra.Command{
 	Use:   "session",
-	Short: "Manage session data (cookies and variables)",
-	Long: `Manage session data including cookies and script variables.
+	Short: "Manage session data (cookies, variables and request results)",
+	Long: `Manage session data including cookies, script variables and the
+results of named requests.
 
 Sessions are scoped by directory (based on .http file location) by default,
 or can be named explicitly using the --session flag with send command.
@@ -50,6 +54,9 @@ Examples:
   # Clear only variables
   restclient session clear --variables
 
+  # Clear only named request results
+  restclient session clear --results
+
   # Clear all sessions
   restclient session clear --all
 
@@ -61,7 +68,7 @@ Examples:
 var sessionShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show session data",
-	Long:  `Show cookies and variables stored in the session.`,
+	Long:  `Show cookies, variables and named request results stored in the session.`,
 	RunE:  runSessionShow,
 }
 
@@ -69,7 +76,7 @@ var sessionShowCmd = &cobra.Command{
 var sessionClearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear session data",
-	Long:  `Clear cookies and/or variables from the session.`,
+	Long:  `Clear cookies, variables and/or named request results from the session.`,
 	RunE:  runSessionClear,
 }
 
@@ -96,6 +103,7 @@ func init() {
 	sessionClearCmd.Flags().StringVar(&sessionDir, "dir", "", "directory to clear session for (defaults to current directory)")
 	sessionClearCmd.Flags().BoolVar(&clearCookies, "cookies", false, "clear only cookies")
 	sessionClearCmd.Flags().BoolVar(&clearVariables, "variables", false, "clear only variables")
+	sessionClearCmd.Flags().BoolVar(&clearResults, "results", false, "clear only named request results")
 	sessionClearCmd.Flags().BoolVar(&clearAllFlag, "all", false, "clear all sessions")
 }
 
@@ -168,9 +176,42 @@ func runSessionShow(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	fmt.Println()
+
+	// Print named request results
+	results := sessionMgr.GetAllResults()
+	printHeader("Request Results:")
+
+	if len(results) == 0 {
+		fmt.Println("  (none)")
+	} else {
+		keys := slices.Sorted(maps.Keys(results))
+		for _, key := range keys {
+			result := results[key]
+			fmt.Printf("  %s: %d, %d bytes\n", resultLabel(key), result.StatusCode, len(result.Body))
+			fmt.Printf("      saved: %s\n", result.SavedAt.Format("2006-01-02 15:04:05"))
+			if !result.ExpiresAt.IsZero() {
+				fmt.Printf("      expires: %s\n", result.ExpiresAt.Format("2006-01-02 15:04:05"))
+			}
+			if result.Body != "" {
+				fmt.Printf("      body: %s\n", truncateValue(strings.Join(strings.Fields(result.Body), " "), 60))
+			}
+		}
+	}
+
 	return nil
 }
 
+// resultLabel shortens the key of a result stored for a request of a
+// specific file, "/abs/dir/auth.http#login", to "auth.http#login"
+func resultLabel(key string) string {
+	file, name, ok := strings.Cut(key, "#")
+	if !ok {
+		return key
+	}
+	return filepath.Base(file) + "#" + name
+}
+
 func runSessionClear(cmd *cobra.Command, args []string) error {
 	// Clear all sessions
 	if clearAllFlag {
@@ -205,25 +246,36 @@ func runSessionClear(cmd *cobra.Command, args []string) error {
 	// Load existing data first
 	sessionMgr.Load()
 
-	// Determine what to clear
-	if clearCookies && !clearVariables {
+	// Without a selection, or with both --cookies and --variables, clear
+	// everything and delete the session directory
+	if (!clearCookies && !clearVariables && !clearResults) || (clearCookies && clearVariables) {
+		if err := sessionMgr.Delete(); err != nil {
+			return errors.Wrap(err, "failed to delete session")
+		}
+		fmt.Println("Session cleared.")
+		return nil
+	}
+
+	if clearCookies {
 		sessionMgr.ClearCookies()
 		if err := sessionMgr.SaveCookies(); err != nil {
 			return errors.Wrap(err, "failed to save session")
 		}
 		fmt.Println("Cookies cleared.")
-	} else if clearVariables && !clearCookies {
+	}
+	if clearVariables {
 		sessionMgr.ClearVariables()
 		if err := sessionMgr.SaveVariables(); err != nil {
 			return errors.Wrap(err, "failed to save session")
 		}
 		fmt.Println("Variables cleared.")
-	} else {
-		// Clear everything and delete the session directory
-		if err := sessionMgr.Delete(); err != nil {
-			return errors.Wrap(err, "failed to delete session")
+	}
+	if clearResults {
+		sessionMgr.ClearResults()
+		if err := sessionMgr.SaveResults(); err != nil {
+			return errors.Wrap(err, "failed to save session")
 		}
-		fmt.Println("Session cleared.")
+		fmt.Println("Request results cleared.")
 	}
 
 	return nil
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ideaspaper/restclient/pkg/session"
+)
+
+// newClearTestSession stores a cookie, a variable and a request result in
+// the session of dir and returns its manager
+func newClearTestSession(t *testing.T, dir string) *session.SessionManager {
+	t.Helper()
+	sessionMgr, err := session.NewSessionManager("", filepath.Join(dir, "dummy.http"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionMgr.SetVariable("token", "abc")
+	sessionMgr.SetResult("login", session.RequestResult{StatusCode: 200, Body: "{}"})
+	if err := sessionMgr.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return sessionMgr
+}
+
+func executeSessionClear(t *testing.T, dir string, cookies, variables bool) {
+	t.Helper()
+	sessionDir, clearCookies, clearVariables = dir, cookies, variables
+	defer func() { sessionDir, clearCookies, clearVariables = "", false, false }()
+
+	oldStdout := os.Stdout
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	err := runSessionClear(sessionClearCmd, nil)
+	os.Stdout = oldStdout
+	if err != nil {
+		t.Fatalf("session clear failed: %v", err)
+	}
+}
+
+func TestSessionClear_CookiesAndVariablesDeletesSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	sessionMgr := newClearTestSession(t, dir)
+
+	executeSessionClear(t, dir, true, true)
+
+	if _, err := os.Stat(sessionMgr.GetSessionPath()); !os.IsNotExist(err) {
+		t.Errorf("session directory should be deleted, stat error = %v", err)
+	}
+}
+
+func TestSessionClear_VariablesKeepsResults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	newClearTestSession(t, dir)
+
+	executeSessionClear(t, dir, false, true)
+
+	sessionMgr, err := session.NewSessionManager("", filepath.Join(dir, "dummy.http"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sessionMgr.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sessionMgr.GetAllVariables()) != 0 {
+		t.Errorf("variables = %v, want none", sessionMgr.GetAllVariables())
+	}
+	if len(sessionMgr.GetAllResults()) != 1 {
+		t.Errorf("results = %v, want the login result", sessionMgr.GetAllResults())
+	}
+}
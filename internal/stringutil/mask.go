@@ -0,0 +1,27 @@
+package stringutil
+
+import "strings"
+
+// sensitiveNameParts are substrings of variable names that hold secrets
+var sensitiveNameParts = []string{"password", "secret", "token", "key", "credential", "auth"}
+
+// IsSensitiveName reports whether a variable name suggests a secret value,
+// such as a password, token or API key
+func IsSensitiveName(name string) bool {
+	lowerName := strings.ToLower(name)
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(lowerName, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mask keeps the first 4 characters of a secret and replaces the rest with
+// asterisks. Values of 4 characters or less are masked entirely.
+func Mask(value string) string {
+	if len(value) > 4 {
+		return value[:4] + strings.Repeat("*", len(value)-4)
+	}
+	return strings.Repeat("*", len(value))
+}
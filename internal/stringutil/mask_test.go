@@ -0,0 +1,43 @@
+package stringutil
+
+import "testing"
+
+func TestIsSensitiveName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"password", true},
+		{"API_KEY", true},
+		{"accessToken", true},
+		{"clientSecret", true},
+		{"authHeader", true},
+		{"baseUrl", false},
+		{"userId", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSensitiveName(tt.name); got != tt.expected {
+				t.Errorf("IsSensitiveName(%q) = %v, want %v", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"secret123", "secr*****"},
+		{"abcd", "****"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Mask(tt.input); got != tt.expected {
+			t.Errorf("Mask(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
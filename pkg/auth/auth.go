@@ -14,6 +14,7 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ideaspaper/restclient/internal/httputil"
@@ -21,8 +22,10 @@ import (
 	"github.com/ideaspaper/restclient/pkg/models"
 )
 
-// Processor handles authentication processing for HTTP requests
+// Processor handles authentication processing for HTTP requests.
+// It is safe for concurrent use.
 type Processor struct {
+	mu          sync.RWMutex
 	digestCreds map[string]DigestCredentials
 }
 
@@ -80,6 +83,8 @@ func (p *Processor) ProcessAuth(request *models.HttpRequest) error {
 
 // GetDigestCredentials returns stored digest credentials for a URL
 func (p *Processor) GetDigestCredentials(urlStr string) (DigestCredentials, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	creds, ok := p.digestCreds[urlStr]
 	return creds, ok
 }
@@ -122,10 +127,12 @@ func (p *Processor) storeDigestCredentials(request *models.HttpRequest, args []s
 		return nil
 	}
 
+	p.mu.Lock()
 	p.digestCreds[request.URL] = DigestCredentials{
 		Username: args[0],
 		Password: strings.Join(args[1:], " "),
 	}
+	p.mu.Unlock()
 
 	// Remove the Authorization header for now (will be added after challenge)
 	DeleteAuthHeader(request)
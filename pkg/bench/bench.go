@@ -0,0 +1,187 @@
+// Package bench provides a load-testing runner that sends a request
+// repeatedly from a pool of workers and aggregates latency statistics.
+package bench
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/client"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// Options configures a benchmark run. At least one of Requests or Duration
+// must be set; if both are set the run stops at whichever limit comes first.
+type Options struct {
+	Concurrency int           // Number of concurrent workers
+	Requests    int           // Total number of requests to send (0 = unlimited)
+	Duration    time.Duration // Maximum run time (0 = unlimited)
+}
+
+// RequestFactory builds the request for a single iteration. Calls are
+// serialized, so the factory may use state that is not goroutine-safe.
+type RequestFactory func() (*models.HttpRequest, error)
+
+// Phases lists the timing phases reported by a benchmark, Total first
+var Phases = append([]models.TimingPhase{
+	{Name: "Total", Value: func(t models.ResponseTiming) time.Duration { return t.Total }},
+}, models.TimingPhases...)
+
+// Report aggregates the results of a benchmark run
+type Report struct {
+	Requests    int                     // Requests attempted
+	Errors      int                     // Requests that failed without a response
+	Elapsed     time.Duration           // Wall-clock duration of the run
+	StatusCodes map[int]int             // Response count by status code
+	ErrorCounts map[string]int          // Error count by message
+	Timings     []models.ResponseTiming // Timings of requests that received a response
+}
+
+// Throughput returns completed requests per second
+func (r *Report) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Elapsed.Seconds()
+}
+
+// Percentile returns the p-th percentile (0-100) of a timing phase using the
+// nearest-rank method. It returns 0 if there are no timings.
+func (r *Report) Percentile(p float64, phase models.TimingPhase) time.Duration {
+	values := make([]time.Duration, len(r.Timings))
+	for i, t := range r.Timings {
+		values[i] = phase.Value(t)
+	}
+	return percentile(values, p)
+}
+
+// Max returns the largest value of a timing phase
+func (r *Report) Max(phase models.TimingPhase) time.Duration {
+	var maxValue time.Duration
+	for _, t := range r.Timings {
+		maxValue = max(maxValue, phase.Value(t))
+	}
+	return maxValue
+}
+
+// percentile returns the nearest-rank percentile of values
+func percentile(values []time.Duration, p float64) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = min(max(rank, 1), len(sorted))
+	return sorted[rank-1]
+}
+
+// Run sends requests built by newRequest through doer until the request
+// count or duration in opts is reached, or ctx is cancelled. A partial report
+// is returned on cancellation.
+func Run(ctx context.Context, opts Options, doer client.HTTPDoer, newRequest RequestFactory) (*Report, error) {
+	if opts.Concurrency < 1 {
+		return nil, errors.NewValidationErrorWithValue("concurrency", strconv.Itoa(opts.Concurrency), "must be at least 1")
+	}
+	if opts.Requests < 0 {
+		return nil, errors.NewValidationErrorWithValue("requests", strconv.Itoa(opts.Requests), "must not be negative")
+	}
+	if opts.Requests == 0 && opts.Duration <= 0 {
+		return nil, errors.NewValidationError("bench", "either a request count or a duration is required")
+	}
+
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	report := &Report{
+		StatusCodes: make(map[int]int),
+		ErrorCounts: make(map[string]int),
+	}
+
+	var (
+		mu       sync.Mutex // guards report, issued, buildErr and newRequest
+		issued   int
+		buildErr error
+	)
+
+	// next returns the request for the next iteration, or nil when done
+	next := func() *models.HttpRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.Err() != nil || buildErr != nil || (opts.Requests > 0 && issued >= opts.Requests) {
+			return nil
+		}
+		request, err := newRequest()
+		if err != nil {
+			buildErr = err
+			return nil
+		}
+		issued++
+		return request
+	}
+
+	record := func(resp *models.HttpResponse, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		report.Requests++
+		if err != nil {
+			report.Errors++
+			report.ErrorCounts[errorMessage(err)]++
+			return
+		}
+		report.StatusCodes[resp.StatusCode]++
+		report.Timings = append(report.Timings, resp.Timing)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				request := next()
+				if request == nil {
+					return
+				}
+				resp, err := doer.SendWithContext(ctx, request)
+				if err != nil && ctx.Err() != nil {
+					// Requests cut off by the deadline or an interrupt are not errors
+					return
+				}
+				record(resp, err)
+			}
+		}()
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+
+	if buildErr != nil {
+		return report, errors.Wrap(buildErr, "failed to build request")
+	}
+	return report, nil
+}
+
+// errorMessage returns the innermost error message, which groups errors by
+// cause rather than by URL (e.g. "connection refused")
+func errorMessage(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	for {
+		inner := errors.Unwrap(err)
+		if inner == nil {
+			return err.Error()
+		}
+		err = inner
+	}
+}
@@ -0,0 +1,175 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/client"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+func newTestClient(t *testing.T) *client.HttpClient {
+	t.Helper()
+	c, err := client.NewHttpClient(client.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+	return c
+}
+
+func TestRun_RequestCount(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1)%5 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	var built int
+	factory := func() (*models.HttpRequest, error) {
+		built++
+		return models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", ""), nil
+	}
+
+	report, err := Run(context.Background(), Options{Concurrency: 4, Requests: 20}, newTestClient(t), factory)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if built != 20 || report.Requests != 20 {
+		t.Errorf("built %d, sent %d requests, want 20", built, report.Requests)
+	}
+	if report.StatusCodes[200] != 16 || report.StatusCodes[503] != 4 {
+		t.Errorf("StatusCodes = %v, want 16x200 and 4x503", report.StatusCodes)
+	}
+	if len(report.Timings) != 20 {
+		t.Errorf("got %d timings, want 20", len(report.Timings))
+	}
+	if report.Percentile(50, Phases[0]) <= 0 {
+		t.Error("p50 total latency should be positive")
+	}
+	if report.Throughput() <= 0 {
+		t.Error("throughput should be positive")
+	}
+}
+
+func TestRun_Duration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	factory := func() (*models.HttpRequest, error) {
+		return models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", ""), nil
+	}
+
+	start := time.Now()
+	report, err := Run(context.Background(), Options{Concurrency: 2, Duration: 100 * time.Millisecond}, newTestClient(t), factory)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("run took %v, want about 100ms", elapsed)
+	}
+	if report.Requests == 0 {
+		t.Error("expected some requests to complete")
+	}
+	if report.Errors != 0 {
+		t.Errorf("requests cut off by the deadline should not count as errors, got %v", report.ErrorCounts)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	factory := func() (*models.HttpRequest, error) {
+		return models.NewHttpRequest("GET", url, map[string]string{}, nil, "", ""), nil
+	}
+
+	report, err := Run(context.Background(), Options{Concurrency: 2, Requests: 4}, newTestClient(t), factory)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Errors != 4 {
+		t.Errorf("Errors = %d, want 4", report.Errors)
+	}
+	if report.ErrorCounts["connection refused"] != 4 {
+		t.Errorf("ErrorCounts = %v, want 4x connection refused", report.ErrorCounts)
+	}
+}
+
+func TestRun_FactoryError(t *testing.T) {
+	calls := 0
+	factory := func() (*models.HttpRequest, error) {
+		calls++
+		return nil, errors.NewValidationError("variable", "undefined")
+	}
+
+	_, err := Run(context.Background(), Options{Concurrency: 3, Requests: 10}, newTestClient(t), factory)
+	if !errors.Is(err, errors.ErrInvalidInput) {
+		t.Errorf("Run() error = %v, want ErrInvalidInput", err)
+	}
+	if calls != 1 {
+		t.Errorf("factory called %d times after failing, want 1", calls)
+	}
+}
+
+func TestRun_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"zero concurrency", Options{Concurrency: 0, Requests: 1}},
+		{"negative requests", Options{Concurrency: 1, Requests: -1}},
+		{"no limit", Options{Concurrency: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Run(context.Background(), tt.opts, newTestClient(t), nil)
+			if !errors.Is(err, errors.ErrInvalidInput) {
+				t.Errorf("Run() error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := make([]time.Duration, 100)
+	for i := range values {
+		values[i] = time.Duration(100-i) * time.Millisecond
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{90, 90 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+		{0, 1 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(values, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile(nil) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,126 @@
+// Package bruno imports Bruno collections, converting their .bru files to
+// .http files through the Postman importer.
+package bruno
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// blockStartRegex matches the first line of a block, such as "auth:bearer {"
+// or "vars:secret ["
+var blockStartRegex = regexp.MustCompile(`^([A-Za-z][\w:-]*)\s*([{\[])\s*$`)
+
+// File is a parsed .bru file, holding the content of each block by name
+// with its indentation removed
+type File map[string]string
+
+// Pair is an entry of a dictionary block. Entries prefixed with ~ are
+// disabled.
+type Pair struct {
+	Key      string
+	Value    string
+	Disabled bool
+}
+
+// Load reads a .bru file
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+	file, err := Parse(string(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	return file, nil
+}
+
+// Parse parses the blocks of a .bru file. A block starts with its name and
+// an opening brace at the start of a line, and ends at the next line that
+// is a lone closing brace.
+func Parse(data string) (File, error) {
+	file := make(File)
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		match := blockStartRegex.FindStringSubmatch(line)
+		if match == nil {
+			return nil, errors.NewValidationErrorWithValue("line", line, "expected the start of a block")
+		}
+		name, end := match[1], "}"
+		if match[2] == "[" {
+			end = "]"
+		}
+
+		var content []string
+		closed := false
+		for i++; i < len(lines); i++ {
+			if strings.TrimRight(lines[i], " \t") == end {
+				closed = true
+				break
+			}
+			content = append(content, strings.TrimPrefix(lines[i], "  "))
+		}
+		if !closed {
+			return nil, errors.NewValidationErrorWithValue("block", name, "is not closed")
+		}
+		file[name] = strings.Join(content, "\n")
+	}
+	return file, nil
+}
+
+// Text returns the content of a text block, such as a body or script
+func (f File) Text(name string) string {
+	return strings.TrimSpace(f[name])
+}
+
+// Dict returns the entries of a dictionary block, such as headers
+func (f File) Dict(name string) []Pair {
+	var pairs []Pair
+	for _, line := range strings.Split(f[name], "\n") {
+		line = strings.TrimSpace(line)
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || key == "" {
+			continue
+		}
+		pair := Pair{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)}
+		if strings.HasPrefix(pair.Key, "~") {
+			pair.Key, pair.Disabled = strings.TrimPrefix(pair.Key, "~"), true
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// Value returns the value of an enabled dictionary entry, or "" if there
+// is none
+func (f File) Value(block, key string) string {
+	for _, pair := range f.Dict(block) {
+		if pair.Key == key && !pair.Disabled {
+			return pair.Value
+		}
+	}
+	return ""
+}
+
+// List returns the items of a list block, such as vars:secret
+func (f File) List(name string) []string {
+	var items []string
+	for _, line := range strings.Split(f[name], "\n") {
+		for _, item := range strings.Split(line, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
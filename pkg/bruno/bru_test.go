@@ -0,0 +1,81 @@
+package bruno
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sampleRequest = `meta {
+  name: Create User
+  type: http
+  seq: 2
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: bearer
+}
+
+headers {
+  Accept: application/json
+  ~X-Debug: 1
+}
+
+body:json {
+  {
+    "name": "Jane"
+  }
+}
+
+vars:secret [
+  token,
+  password
+]
+`
+
+func TestParse(t *testing.T) {
+	file, err := Parse(sampleRequest)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := file.Value("meta", "name"); got != "Create User" {
+		t.Errorf("meta name = %q", got)
+	}
+	if got := file.Value("post", "url"); got != "{{baseUrl}}/users" {
+		t.Errorf("url = %q", got)
+	}
+
+	wantHeaders := []Pair{{Key: "Accept", Value: "application/json"}, {Key: "X-Debug", Value: "1", Disabled: true}}
+	if got := file.Dict("headers"); !reflect.DeepEqual(got, wantHeaders) {
+		t.Errorf("headers = %+v, want %+v", got, wantHeaders)
+	}
+	if got := file.Value("headers", "X-Debug"); got != "" {
+		t.Errorf("a disabled entry should have no value, got %q", got)
+	}
+
+	if got, want := file.Text("body:json"), "{\n  \"name\": \"Jane\"\n}"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+	if got, want := file.List("vars:secret"), []string{"token", "password"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("secrets = %v, want %v", got, want)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unclosed block", "meta {\n  name: x\n"},
+		{"text outside a block", "name: x\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,569 @@
+package bruno
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/postman"
+)
+
+const (
+	// ConfigFile names the collection config at the root of a collection
+	ConfigFile = "bruno.json"
+	// CollectionFile holds the headers, auth and scripts of a collection
+	CollectionFile = "collection.bru"
+	// FolderFile holds the name, headers, auth and scripts of a folder
+	FolderFile = "folder.bru"
+	// EnvironmentsDir holds an environment per .bru file
+	EnvironmentsDir = "environments"
+)
+
+// methods are the blocks holding the method, URL, body and auth mode of a
+// request
+var methods = []string{"get", "post", "put", "delete", "patch", "options", "head", "connect", "trace"}
+
+// bodyTypes maps the body modes of a request to their block and content type
+var bodyTypes = map[string]struct{ block, contentType string }{
+	"json":           {"body:json", "application/json"},
+	"text":           {"body:text", "text/plain"},
+	"xml":            {"body:xml", "application/xml"},
+	"sparql":         {"body:sparql", "application/sparql-query"},
+	"formUrlEncoded": {"body:form-urlencoded", "application/x-www-form-urlencoded"},
+	"multipartForm":  {"body:multipart-form", "multipart/form-data"},
+	"graphql":        {"body:graphql", "application/json"},
+	"file":           {"body:file", ""},
+}
+
+// assertOperators maps the operators of assert blocks to JavaScript
+var assertOperators = map[string]string{
+	"eq": "===", "neq": "!==", "gt": ">", "gte": ">=", "lt": "<", "lte": "<=",
+}
+
+var (
+	// processEnvRegex matches {{process.env.NAME}} variables
+	processEnvRegex = regexp.MustCompile(`\{\{\s*process\.env\.([\w.-]+)\s*\}\}`)
+	// fileRegex matches @file(path) values of multipart and file bodies
+	fileRegex = regexp.MustCompile(`^@file\((.*)\)`)
+	// responseRegex matches the res.* script API
+	responseRegex = regexp.MustCompile(`\bres\.(getStatus\(\)|status|getBody\(\)|body|getHeader\(|getHeaders\(\)|headers)`)
+	// testRegex matches the test() function of Bruno tests
+	testRegex = regexp.MustCompile(`(^|[^\w.])test\(`)
+)
+
+// responseAPI maps the res.* script API to the response object
+var responseAPI = map[string]string{
+	"getStatus()":  "response.status",
+	"status":       "response.status",
+	"getBody()":    "response.body",
+	"body":         "response.body",
+	"getHeader(":   "response.headers.valueOf(",
+	"getHeaders()": "response.headers",
+	"headers":      "response.headers",
+}
+
+// scriptReplacer converts the bru.* script API to client.*
+var scriptReplacer = strings.NewReplacer(
+	"bru.setVar(", "client.global.set(",
+	"bru.getVar(", "client.global.get(",
+	"bru.setEnvVar(", "client.global.set(",
+	"bru.getEnvVar(", "client.global.get(",
+	"bru.setNextRequest(", "client.execution.setNextRequest(",
+	"bru.runner.setNextRequest(", "client.execution.setNextRequest(",
+	"bru.runner.skipRequest()", "client.execution.skipRequest()",
+	"bru.runner.stopExecution()", "client.execution.stop()",
+)
+
+// ImportResult contains information about an imported collection
+type ImportResult struct {
+	postman.ImportResult
+	// Environments holds the variables of each environment by name
+	Environments map[string]map[string]string
+}
+
+// Import reads a Bruno collection, given its directory or bruno.json, and
+// converts it to .http file(s), as a Postman collection would be
+func Import(collectionPath string, opts postman.ImportOptions) (*ImportResult, error) {
+	dir := collectionPath
+	if filepath.Base(collectionPath) == ConfigFile {
+		dir = filepath.Dir(collectionPath)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read collection")
+	}
+	if !info.IsDir() {
+		return nil, errors.NewValidationErrorWithValue("Bruno collection", collectionPath, "must be a directory")
+	}
+
+	c := &converter{}
+	collection, err := c.collection(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := postman.ImportCollection(collection, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	environments, err := c.environments(filepath.Join(dir, EnvironmentsDir))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{ImportResult: *imported, Environments: environments}
+	result.Errors = append(c.warnings, result.Errors...)
+	for _, vars := range environments {
+		result.VariablesCount += len(vars)
+	}
+	return result, nil
+}
+
+// settings are the headers and auth a collection or folder passes down to
+// its requests
+type settings struct {
+	headers []postman.Header
+	auth    *postman.Auth
+	// authHeaders and authQuery hold API keys, which have no Postman auth
+	authHeaders []postman.Header
+	authQuery   []string
+}
+
+type converter struct {
+	warnings []string
+}
+
+func (c *converter) warn(format string, args ...any) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+func (c *converter) collection(dir string) (*postman.Collection, error) {
+	name := filepath.Base(dir)
+	if data, err := os.ReadFile(filepath.Join(dir, ConfigFile)); err == nil {
+		var config struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", ConfigFile)
+		}
+		if config.Name != "" {
+			name = config.Name
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to read %s", ConfigFile)
+	}
+
+	collection := postman.NewCollection(name)
+	inherited := settings{}
+	if file, err := c.load(filepath.Join(dir, CollectionFile)); err != nil {
+		return nil, err
+	} else if file != nil {
+		inherited = c.settings("collection", file, inherited)
+		if docs := file.Text("docs"); docs != "" {
+			collection.Info.Description = &postman.Description{Content: docs}
+		}
+	}
+
+	items, err := c.items(dir, inherited, true)
+	if err != nil {
+		return nil, err
+	}
+	collection.Item = items
+	return collection, nil
+}
+
+// load reads a .bru file, returning nil if it doesn't exist
+func (c *converter) load(path string) (File, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return Load(path)
+}
+
+// settings returns the headers and auth of a collection or folder merged
+// over those it inherits
+func (c *converter) settings(name string, file File, inherited settings) settings {
+	merged := inherited
+	merged.headers = slices.Clone(inherited.headers)
+	for _, h := range file.Dict("headers") {
+		if !h.Disabled {
+			merged.headers = append(merged.headers, postman.Header{Key: h.Key, Value: convertTemplate(h.Value)})
+		}
+	}
+
+	if mode := file.Value("auth", "mode"); mode != "" && mode != "inherit" {
+		merged.auth, merged.authHeaders, merged.authQuery = nil, nil, nil
+		c.auth(name, mode, file, &merged)
+	}
+
+	for _, block := range []string{"script:pre-request", "script:post-response", "tests", "vars:pre-request", "vars:post-response"} {
+		if file.Text(block) != "" {
+			c.warn("%s: %s is not imported", name, block)
+		}
+	}
+	return merged
+}
+
+// items converts the requests and subfolders of a directory, folders first
+// then requests, each ordered by their seq
+func (c *converter) items(dir string, inherited settings, root bool) ([]postman.Item, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", dir)
+	}
+
+	type ordered struct {
+		seq  float64
+		item postman.Item
+	}
+	var folders, requests []ordered
+
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+
+		if entry.IsDir() {
+			if strings.HasPrefix(name, ".") || name == "node_modules" || (root && name == EnvironmentsDir) {
+				continue
+			}
+			folder := postman.Item{Name: name}
+			settings := inherited
+			seq := float64(len(folders) + 1e6)
+			file, err := c.load(filepath.Join(path, FolderFile))
+			if err != nil {
+				return nil, err
+			}
+			if file != nil {
+				if meta := file.Value("meta", "name"); meta != "" {
+					folder.Name = meta
+				}
+				if s, err := strconv.ParseFloat(file.Value("meta", "seq"), 64); err == nil {
+					seq = s
+				}
+				if docs := file.Text("docs"); docs != "" {
+					folder.Description = &postman.Description{Content: docs}
+				}
+				settings = c.settings("folder "+strconv.Quote(folder.Name), file, inherited)
+			}
+			if folder.Item, err = c.items(path, settings, false); err != nil {
+				return nil, err
+			}
+			folders = append(folders, ordered{seq, folder})
+			continue
+		}
+
+		if filepath.Ext(name) != ".bru" || name == FolderFile || name == CollectionFile {
+			continue
+		}
+		file, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		item, ok := c.request(file, strings.TrimSuffix(name, ".bru"), inherited)
+		if !ok {
+			continue
+		}
+		seq, err := strconv.ParseFloat(file.Value("meta", "seq"), 64)
+		if err != nil {
+			seq = float64(len(requests) + 1e6)
+		}
+		requests = append(requests, ordered{seq, item})
+	}
+
+	var items []postman.Item
+	for _, group := range [][]ordered{folders, requests} {
+		slices.SortStableFunc(group, func(a, b ordered) int {
+			switch {
+			case a.seq < b.seq:
+				return -1
+			case a.seq > b.seq:
+				return 1
+			}
+			return strings.Compare(a.item.Name, b.item.Name)
+		})
+		for _, o := range group {
+			items = append(items, o.item)
+		}
+	}
+	return items, nil
+}
+
+// request converts a request file, reporting false for files that aren't
+// HTTP or GraphQL requests
+func (c *converter) request(file File, fileName string, inherited settings) (postman.Item, bool) {
+	name := file.Value("meta", "name")
+	if name == "" {
+		name = fileName
+	}
+	if kind := file.Value("meta", "type"); kind != "" && kind != "http" && kind != "graphql" {
+		c.warn("request %q: %s requests are not imported", name, kind)
+		return postman.Item{}, false
+	}
+
+	method := ""
+	for _, m := range methods {
+		if _, ok := file[m]; ok {
+			method = m
+			break
+		}
+	}
+	if method == "" {
+		c.warn("request %q: has no method block", name)
+		return postman.Item{}, false
+	}
+
+	rawURL := convertTemplate(file.Value(method, "url"))
+	for _, p := range file.Dict("params:path") {
+		rawURL = strings.ReplaceAll(rawURL, ":"+p.Key, convertTemplate(p.Value))
+	}
+	request := &postman.Request{Method: strings.ToUpper(method), URL: &postman.URL{Raw: rawURL}}
+
+	var own []postman.Header
+	for _, h := range file.Dict("headers") {
+		own = append(own, postman.Header{Key: h.Key, Value: convertTemplate(h.Value), Disabled: h.Disabled})
+	}
+	for _, h := range inherited.headers {
+		overridden := slices.ContainsFunc(own, func(o postman.Header) bool { return strings.EqualFold(o.Key, h.Key) })
+		if !overridden {
+			request.Header = append(request.Header, h)
+		}
+	}
+	request.Header = append(request.Header, own...)
+
+	auth := inherited
+	if mode := file.Value(method, "auth"); mode != "" && mode != "inherit" {
+		auth = settings{}
+		c.auth(fmt.Sprintf("request %q", name), mode, file, &auth)
+	}
+	request.Auth = auth.auth
+	request.Header = append(request.Header, auth.authHeaders...)
+	if len(auth.authQuery) > 0 {
+		separator := "?"
+		if strings.Contains(request.URL.Raw, "?") {
+			separator = "&"
+		}
+		request.URL.Raw += separator + strings.Join(auth.authQuery, "&")
+	}
+
+	request.Body = c.body(name, file, file.Value(method, "body"), request)
+
+	item := postman.Item{Name: name, Request: request}
+	if docs := file.Text("docs"); docs != "" {
+		item.Description = &postman.Description{Content: docs}
+	}
+	if script := preRequestScript(file); script != "" {
+		item.Event = append(item.Event, postman.Event{Listen: "prerequest", Script: &postman.Script{Exec: script}})
+	}
+	if script := c.testScript(name, file); script != "" {
+		item.Event = append(item.Event, postman.Event{Listen: "test", Script: &postman.Script{Exec: script}})
+	}
+	return item, true
+}
+
+// auth converts the auth block of a mode, setting the auth of s or, for
+// API keys, its auth headers and query parameters
+func (c *converter) auth(name, mode string, file File, s *settings) {
+	block := "auth:" + mode
+	attr := func(key, field string) postman.AuthAttribute {
+		return postman.AuthAttribute{Key: key, Value: convertTemplate(file.Value(block, field)), Type: "string"}
+	}
+
+	switch mode {
+	case "none":
+	case "basic":
+		s.auth = &postman.Auth{Type: "basic", Basic: []postman.AuthAttribute{attr("username", "username"), attr("password", "password")}}
+	case "digest":
+		s.auth = &postman.Auth{Type: "digest", Digest: []postman.AuthAttribute{attr("username", "username"), attr("password", "password")}}
+	case "bearer":
+		s.auth = &postman.Auth{Type: "bearer", Bearer: []postman.AuthAttribute{attr("token", "token")}}
+	case "awsv4":
+		s.auth = &postman.Auth{Type: "awsv4", AWSv4: []postman.AuthAttribute{
+			attr("accessKey", "accessKeyId"),
+			attr("secretKey", "secretAccessKey"),
+			attr("sessionToken", "sessionToken"),
+			attr("region", "region"),
+			attr("service", "service"),
+		}}
+	case "apikey":
+		key, value := convertTemplate(file.Value(block, "key")), convertTemplate(file.Value(block, "value"))
+		if file.Value(block, "placement") == "queryparams" {
+			s.authQuery = append(s.authQuery, key+"="+value)
+		} else {
+			s.authHeaders = append(s.authHeaders, postman.Header{Key: key, Value: value})
+		}
+	default:
+		c.warn("%s: %s auth is not supported", name, mode)
+	}
+}
+
+// body converts the body of a request, adding a Content-Type header for
+// its mode when the request has none
+func (c *converter) body(name string, file File, mode string, request *postman.Request) *postman.Body {
+	bodyType, ok := bodyTypes[mode]
+	if !ok {
+		if mode != "" && mode != "none" {
+			c.warn("request %q: %s bodies are not supported", name, mode)
+		}
+		return nil
+	}
+
+	var body *postman.Body
+	contentType := bodyType.contentType
+	switch mode {
+	case "formUrlEncoded":
+		body = &postman.Body{Mode: "urlencoded"}
+		for _, p := range file.Dict(bodyType.block) {
+			body.URLEncoded = append(body.URLEncoded, postman.URLEncodedPair{Key: p.Key, Value: convertTemplate(p.Value), Disabled: p.Disabled})
+		}
+	case "multipartForm":
+		body = &postman.Body{Mode: "formdata"}
+		for _, p := range file.Dict(bodyType.block) {
+			field := postman.FormDataPair{Key: p.Key, Value: convertTemplate(p.Value), Disabled: p.Disabled, Type: "text"}
+			if match := fileRegex.FindStringSubmatch(p.Value); match != nil {
+				field.Type, field.Value, field.Src = "file", "", match[1]
+			}
+			body.FormData = append(body.FormData, field)
+		}
+	case "graphql":
+		body = &postman.Body{Mode: "graphql", GraphQL: &postman.GraphQL{
+			Query:     convertTemplate(file.Text(bodyType.block)),
+			Variables: convertTemplate(file.Text("body:graphql:vars")),
+		}}
+	case "file":
+		for _, p := range file.Dict(bodyType.block) {
+			match := fileRegex.FindStringSubmatch(p.Value)
+			if p.Disabled || match == nil {
+				continue
+			}
+			body = &postman.Body{Mode: "file", File: &postman.File{Src: match[1]}}
+			if _, ct, ok := strings.Cut(p.Value, "@contentType("); ok {
+				contentType = strings.TrimSuffix(strings.TrimSpace(ct), ")")
+			}
+			break
+		}
+		if body == nil {
+			return nil
+		}
+	default:
+		text := file.Text(bodyType.block)
+		if text == "" {
+			return nil
+		}
+		body = &postman.Body{Mode: "raw", Raw: convertTemplate(text)}
+	}
+
+	hasContentType := slices.ContainsFunc(request.Header, func(h postman.Header) bool {
+		return strings.EqualFold(h.Key, "Content-Type")
+	})
+	if !hasContentType && contentType != "" {
+		request.Header = append(request.Header, postman.Header{Key: "Content-Type", Value: contentType})
+	}
+	return body
+}
+
+// preRequestScript combines the pre-request vars and script of a request
+func preRequestScript(file File) string {
+	var lines []string
+	for _, v := range file.Dict("vars:pre-request") {
+		if !v.Disabled {
+			lines = append(lines, fmt.Sprintf("client.global.set(%s, %s);", strconv.Quote(v.Key), strconv.Quote(convertTemplate(v.Value))))
+		}
+	}
+	if script := file.Text("script:pre-request"); script != "" {
+		lines = append(lines, convertScript(script))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// testScript combines the post-response script and vars, assertions and
+// tests of a request
+func (c *converter) testScript(name string, file File) string {
+	var lines []string
+	if script := file.Text("script:post-response"); script != "" {
+		lines = append(lines, convertScript(script))
+	}
+	for _, v := range file.Dict("vars:post-response") {
+		if !v.Disabled {
+			lines = append(lines, fmt.Sprintf("client.global.set(%s, %s);", strconv.Quote(v.Key), convertScript(v.Value)))
+		}
+	}
+	for _, a := range file.Dict("assert") {
+		if a.Disabled {
+			continue
+		}
+		operator, operand, _ := strings.Cut(a.Value, " ")
+		js, ok := assertOperators[operator]
+		if !ok {
+			c.warn("request %q: assertion %q is not supported", name, a.Key+": "+a.Value)
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("client.assert(%s %s %s, %s);", convertScript(a.Key), js, strings.TrimSpace(operand), strconv.Quote(a.Key+": "+a.Value)))
+	}
+	if tests := file.Text("tests"); tests != "" {
+		lines = append(lines, convertScript(tests))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// environments reads the environment files of a collection. Secret
+// variables are left out as Bruno keeps their values outside the
+// collection.
+func (c *converter) environments(dir string) (map[string]map[string]string, error) {
+	environments := make(map[string]map[string]string)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return environments, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read environments")
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".bru" {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".bru")
+		file, err := Load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		vars := make(map[string]string)
+		for _, v := range file.Dict("vars") {
+			if !v.Disabled {
+				vars[v.Key] = convertTemplate(v.Value)
+			}
+		}
+		secrets := file.List("vars:secret")
+		for _, secret := range secrets {
+			delete(vars, strings.TrimPrefix(secret, "~"))
+		}
+		if len(secrets) > 0 {
+			c.warn("environment %q: secret variables %s are not imported", name, strings.Join(secrets, ", "))
+		}
+		environments[name] = vars
+	}
+	return environments, nil
+}
+
+// convertTemplate converts {{process.env.NAME}} to {{$processEnv NAME}}
+func convertTemplate(s string) string {
+	return processEnvRegex.ReplaceAllString(s, "{{$$processEnv $1}}")
+}
+
+// convertScript converts the bru.*, res.* and test() script API to the
+// client and response objects
+func convertScript(script string) string {
+	script = scriptReplacer.Replace(script)
+	script = responseRegex.ReplaceAllStringFunc(script, func(match string) string {
+		return responseAPI[strings.TrimPrefix(match, "res.")]
+	})
+	return testRegex.ReplaceAllString(script, "${1}client.test(")
+}
@@ -0,0 +1,276 @@
+package bruno
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/restclient/pkg/postman"
+)
+
+// sampleCollection is a Bruno collection by file path
+var sampleCollection = map[string]string{
+	"bruno.json": `{"version": "1", "name": "Shop API", "type": "collection"}`,
+	"collection.bru": `headers {
+  X-Client: restclient
+}
+
+auth {
+  mode: bearer
+}
+
+auth:bearer {
+  token: {{token}}
+}
+`,
+	"environments/local.bru": `vars {
+  baseUrl: http://localhost:3000
+  ~unused: x
+  apiKey: {{process.env.API_KEY}}
+}
+
+vars:secret [
+  token
+]
+`,
+	"List Users.bru": `meta {
+  name: List Users
+  type: http
+  seq: 2
+}
+
+get {
+  url: {{baseUrl}}/users?page=1
+  body: none
+  auth: inherit
+}
+
+assert {
+  res.status: eq 200
+  res.body.length: gt 0
+  res.body: isJson
+}
+`,
+	"Get User.bru": `meta {
+  name: Get User
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/users/:id
+  body: none
+  auth: apikey
+}
+
+params:path {
+  id: 42
+}
+
+auth:apikey {
+  key: api_key
+  value: {{apiKey}}
+  placement: queryparams
+}
+
+script:post-response {
+  bru.setVar("userName", res.getBody().name);
+}
+
+tests {
+  test("is ok", function() {
+    expect(res.getStatus()).to.equal(200);
+  });
+}
+
+docs {
+  Fetches a single user
+}
+`,
+	"Admin/folder.bru": `meta {
+  name: Administration
+  seq: 1
+}
+
+auth {
+  mode: basic
+}
+
+auth:basic {
+  username: admin
+  password: {{adminPassword}}
+}
+`,
+	"Admin/Upload.bru": `meta {
+  name: Upload
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/admin/upload
+  body: multipartForm
+  auth: inherit
+}
+
+body:multipart-form {
+  title: Report
+  file: @file(report.pdf)
+}
+
+vars:pre-request {
+  requestId: {{$guid}}
+}
+`,
+	"Admin/Login.bru": `meta {
+  name: Login
+  type: http
+  seq: 2
+}
+
+post {
+  url: {{baseUrl}}/admin/login
+  body: formUrlEncoded
+  auth: none
+}
+
+headers {
+  X-Client: admin-ui
+}
+
+body:form-urlencoded {
+  user: admin
+  ~remember: true
+}
+`,
+	"Live.bru": `meta {
+  name: Live
+  type: ws
+  seq: 3
+}
+`,
+}
+
+func writeCollection(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range sampleCollection {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestImport_SingleFile(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "shop.http")
+	opts := postman.DefaultImportOptions()
+	opts.SingleFile = true
+	opts.OutputFile = outputFile
+
+	result, err := Import(writeCollection(t), opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if result.RequestsCount != 4 || result.FoldersCount != 1 {
+		t.Errorf("RequestsCount = %d, FoldersCount = %d", result.RequestsCount, result.FoldersCount)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+
+	checks := []string{
+		"# @name Upload\n< {%\nclient.global.set(\"requestId\", \"{{$guid}}\");\n%}\nPOST {{baseUrl}}/admin/upload\nX-Client: restclient\nContent-Type: multipart/form-data\nAuthorization: Basic admin:{{adminPassword}}\n",
+		"POST {{baseUrl}}/admin/login\nX-Client: admin-ui\nContent-Type: application/x-www-form-urlencoded\n\nuser=admin",
+		"# @note Fetches a single user\nGET {{baseUrl}}/users/42?api_key={{apiKey}}\nX-Client: restclient\n",
+		`client.global.set("userName", response.body.name);`,
+		`client.test("is ok", function() {`,
+		"expect(response.status).to.equal(200);",
+		"GET {{baseUrl}}/users?page=1\nX-Client: restclient\nAuthorization: Bearer {{token}}\n",
+		`client.assert(response.status === 200, "res.status: eq 200");`,
+		`client.assert(response.body.length > 0, "res.body.length: gt 0");`,
+	}
+	for _, check := range checks {
+		if !strings.Contains(content, check) {
+			t.Errorf("content should contain %q\n%s", check, content)
+		}
+	}
+
+	// Folders come first, then requests by seq
+	if !(strings.Index(content, "Upload") < strings.Index(content, "Get User") && strings.Index(content, "Get User") < strings.Index(content, "List Users")) {
+		t.Errorf("requests are out of order\n%s", content)
+	}
+	if !strings.Contains(content, "# remember=true") {
+		t.Error("disabled form fields should be commented out")
+	}
+
+	warnings := strings.Join(result.Errors, "\n")
+	for _, want := range []string{`"Live": ws requests are not imported`, `assertion "res.body: isJson" is not supported`, `secret variables token are not imported`} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("warnings should contain %q, got %v", want, result.Errors)
+		}
+	}
+
+	local := result.Environments["local"]
+	if len(local) != 2 || local["baseUrl"] != "http://localhost:3000" || local["apiKey"] != "{{$processEnv API_KEY}}" {
+		t.Errorf("local = %v", local)
+	}
+}
+
+func TestImport_MultiFile(t *testing.T) {
+	outputDir := t.TempDir()
+	opts := postman.DefaultImportOptions()
+	opts.OutputDir = outputDir
+
+	collectionDir := writeCollection(t)
+	result, err := Import(filepath.Join(collectionDir, ConfigFile), opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(result.FilesCreated) != 2 {
+		t.Fatalf("FilesCreated = %v", result.FilesCreated)
+	}
+	for _, path := range []string{"Shop API/Shop API.http", "Shop API/Administration/Administration.http"} {
+		if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(path))); err != nil {
+			t.Errorf("%s should be created: %v", path, err)
+		}
+	}
+}
+
+func TestImport_NotADirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "request.bru")
+	if err := os.WriteFile(file, []byte("meta {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(file, postman.DefaultImportOptions()); err == nil {
+		t.Error("importing a file should fail")
+	}
+}
+
+func TestConvertScript(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`bru.setEnvVar("id", res.body.id)`, `client.global.set("id", response.body.id)`},
+		{`const ct = res.getHeader("content-type")`, `const ct = response.headers.valueOf("content-type")`},
+		{`bru.runner.skipRequest()`, `client.execution.skipRequest()`},
+		{`bru.runner.stopExecution()`, `client.execution.stop()`},
+		{`bru.setNextRequest("Login")`, `client.execution.setNextRequest("Login")`},
+		{`address.status`, `address.status`},
+		{`mytest()`, `mytest()`},
+	}
+	for _, tt := range tests {
+		if got := convertScript(tt.input); got != tt.want {
+			t.Errorf("convertScript(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
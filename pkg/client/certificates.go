@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"software.sslmate.com/src/go-pkcs12"
+
+	"github.com/ideaspaper/restclient/internal/paths"
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// clientCertificates holds loaded client certificates keyed by lowercase host
+// (either "host" or "host:port", matching the keys of ClientConfig.Certificates)
+type clientCertificates map[string]*tls.Certificate
+
+// certificateHostKey is the context key carrying the host of the request that
+// triggered a TLS handshake
+type certificateHostKey struct{}
+
+// loadClientCertificates loads every configured certificate up front so that
+// missing files or wrong passphrases are reported before any request is sent
+func loadClientCertificates(certs map[string]Certificate) (clientCertificates, error) {
+	loaded := make(clientCertificates, len(certs))
+	for host, cert := range certs {
+		tlsCert, err := loadCertificate(host, cert)
+		if err != nil {
+			return nil, err
+		}
+		if tlsCert != nil {
+			loaded[normalizeCertificateHost(host)] = tlsCert
+		}
+	}
+	return loaded, nil
+}
+
+// loadCertificate loads a single PEM cert/key pair or PFX bundle.
+// It returns nil if neither is configured.
+func loadCertificate(host string, cert Certificate) (*tls.Certificate, error) {
+	if cert.PFX != "" {
+		return loadPFXCertificate(host, cert.PFX, cert.Passphrase)
+	}
+	if cert.Cert == "" && cert.Key == "" {
+		return nil, nil
+	}
+	if cert.Cert == "" {
+		return nil, errors.NewValidationErrorWithValue("certificate", host, "key is configured without cert")
+	}
+
+	certPath, err := resolveCertificatePath(host, cert.Cert)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single PEM file may hold both the certificate and its private key
+	keyPath := certPath
+	if cert.Key != "" {
+		keyPath, err = resolveCertificatePath(host, cert.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsCert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, errors.NewValidationErrorWithValue("certificate", host, err.Error())
+	}
+	return &tlsCert, nil
+}
+
+// loadPFXCertificate decodes a PKCS#12 bundle into a TLS certificate
+func loadPFXCertificate(host, pfxPath, passphrase string) (*tls.Certificate, error) {
+	path, err := resolveCertificatePath(host, pfxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.NewValidationErrorWithValue("certificate file", path, err.Error())
+	}
+
+	key, leaf, caCerts, err := pkcs12.DecodeChain(data, passphrase)
+	if err != nil {
+		if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+			return nil, errors.NewValidationErrorWithValue("certificate passphrase", host, "incorrect passphrase for "+path)
+		}
+		return nil, errors.NewValidationErrorWithValue("certificate file", path, err.Error())
+	}
+
+	tlsCert := &tls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+	for _, ca := range caCerts {
+		tlsCert.Certificate = append(tlsCert.Certificate, ca.Raw)
+	}
+	return tlsCert, nil
+}
+
+// resolveCertificatePath expands a leading ~ and verifies that the file exists
+func resolveCertificatePath(host, path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || rest[0] == '/' || rest[0] == filepath.Separator) {
+		home, err := paths.HomeDir()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to resolve home directory")
+		}
+		path = filepath.Join(home, rest)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", errors.NewValidationErrorWithValue("certificate file", path, "file not found (configured for "+host+")")
+		}
+		return "", errors.NewValidationErrorWithValue("certificate file", path, err.Error())
+	}
+	return path, nil
+}
+
+// normalizeCertificateHost lowercases a configured "host" or "host:port" key
+// and brings a port into the form net.JoinHostPort produces
+func normalizeCertificateHost(host string) string {
+	host = strings.ToLower(host)
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		return net.JoinHostPort(h, port)
+	}
+	return host
+}
+
+// requestHostPort returns the host:port a request URL connects to, adding the
+// default port of its scheme when the URL has none
+func requestHostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "https", "wss":
+			port = "443"
+		case "http", "ws":
+			port = "80"
+		default:
+			return strings.ToLower(u.Hostname())
+		}
+	}
+	return net.JoinHostPort(strings.ToLower(u.Hostname()), port)
+}
+
+// lookup returns the certificate for a host:port as returned by
+// requestHostPort, preferring an exact host:port match
+func (c clientCertificates) lookup(hostPort string) *tls.Certificate {
+	hostPort = normalizeCertificateHost(hostPort)
+	if cert, ok := c[hostPort]; ok {
+		return cert
+	}
+	if host, _, err := net.SplitHostPort(hostPort); err == nil {
+		return c[host]
+	}
+	return nil
+}
+
+// getClientCertificate implements tls.Config.GetClientCertificate, selecting
+// the certificate configured for the host of the request being dialed
+func (c clientCertificates) getClientCertificate(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	host, _ := info.Context().Value(certificateHostKey{}).(string)
+	if cert := c.lookup(host); cert != nil {
+		return cert, nil
+	}
+	// An empty certificate tells the server we have none to offer
+	return &tls.Certificate{}, nil
+}
+
+// certificateRoundTripper tags each outgoing request (including redirects)
+// with its host:port so getClientCertificate can pick the matching certificate
+type certificateRoundTripper struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *certificateRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := context.WithValue(req.Context(), certificateHostKey{}, requestHostPort(req.URL))
+	return t.base.RoundTrip(req.WithContext(ctx))
+}
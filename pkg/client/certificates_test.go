@@ -0,0 +1,279 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// generateClientCert creates a self-signed client certificate with the given common name
+func generateClientCert(t *testing.T, commonName string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert, key
+}
+
+// writePEMPair writes a certificate and key as PEM files and returns their paths
+func writePEMPair(t *testing.T, dir string, cert *x509.Certificate, key *ecdsa.PrivateKey) (string, string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certPath := filepath.Join(dir, "client.crt")
+	keyPath := filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0600); err != nil {
+		t.Fatalf("failed to write cert: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return certPath, keyPath
+}
+
+// newMutualTLSServer starts a TLS server that echoes the client certificate's common name
+func newMutualTLSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientCertificate_PEM(t *testing.T) {
+	server := newMutualTLSServer(t)
+	serverURL, _ := url.Parse(server.URL)
+
+	cert, key := generateClientCert(t, "pem-client")
+	certPath, keyPath := writePEMPair(t, t.TempDir(), cert, key)
+
+	config := DefaultConfig()
+	config.InsecureSSL = true
+	config.Certificates[serverURL.Host] = Certificate{Cert: certPath, Key: keyPath}
+
+	client, err := NewHttpClient(config)
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	resp, err := client.Send(models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", ""))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Body != "pem-client" {
+		t.Errorf("got %d %q, want 200 %q", resp.StatusCode, resp.Body, "pem-client")
+	}
+}
+
+func TestClientCertificate_PFXByHostname(t *testing.T) {
+	server := newMutualTLSServer(t)
+	serverURL, _ := url.Parse(server.URL)
+
+	cert, key := generateClientCert(t, "pfx-client")
+	pfxData, err := pkcs12.Modern.Encode(key, cert, nil, "s3cret")
+	if err != nil {
+		t.Fatalf("failed to encode PFX: %v", err)
+	}
+	pfxPath := filepath.Join(t.TempDir(), "client.pfx")
+	if err := os.WriteFile(pfxPath, pfxData, 0600); err != nil {
+		t.Fatalf("failed to write PFX: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.InsecureSSL = true
+	// Keyed by hostname only; the port should not be required
+	config.Certificates[serverURL.Hostname()] = Certificate{PFX: pfxPath, Passphrase: "s3cret"}
+
+	client, err := NewHttpClient(config)
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	resp, err := client.Send(models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", ""))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.Body != "pfx-client" {
+		t.Errorf("Body = %q, want %q", resp.Body, "pfx-client")
+	}
+}
+
+func TestClientCertificate_OtherHostSendsNone(t *testing.T) {
+	server := newMutualTLSServer(t)
+
+	cert, key := generateClientCert(t, "other-client")
+	certPath, keyPath := writePEMPair(t, t.TempDir(), cert, key)
+
+	config := DefaultConfig()
+	config.InsecureSSL = true
+	config.Certificates["api.example.com"] = Certificate{Cert: certPath, Key: keyPath}
+
+	client, err := NewHttpClient(config)
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	resp, err := client.Send(models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", ""))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("StatusCode = %d, want %d (no certificate for this host)", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestClientCertificate_MissingFile(t *testing.T) {
+	config := DefaultConfig()
+	config.Certificates["api.example.com"] = Certificate{
+		Cert: filepath.Join(t.TempDir(), "missing.crt"),
+		Key:  filepath.Join(t.TempDir(), "missing.key"),
+	}
+
+	_, err := NewHttpClient(config)
+	if err == nil {
+		t.Fatal("NewHttpClient() expected error for missing certificate file")
+	}
+
+	var validationErr *errors.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %T, want *errors.ValidationError", err)
+	}
+	if validationErr.Field != "certificate file" {
+		t.Errorf("Field = %q, want %q", validationErr.Field, "certificate file")
+	}
+}
+
+func TestClientCertificate_WrongPassphrase(t *testing.T) {
+	cert, key := generateClientCert(t, "pfx-client")
+	pfxData, err := pkcs12.Modern.Encode(key, cert, nil, "right")
+	if err != nil {
+		t.Fatalf("failed to encode PFX: %v", err)
+	}
+	pfxPath := filepath.Join(t.TempDir(), "client.pfx")
+	if err := os.WriteFile(pfxPath, pfxData, 0600); err != nil {
+		t.Fatalf("failed to write PFX: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.Certificates["api.example.com"] = Certificate{PFX: pfxPath, Passphrase: "wrong"}
+
+	_, err = NewHttpClient(config)
+	if err == nil {
+		t.Fatal("NewHttpClient() expected error for wrong passphrase")
+	}
+	if !errors.Is(err, errors.ErrInvalidInput) {
+		t.Errorf("error = %v, want ErrInvalidInput", err)
+	}
+
+	var validationErr *errors.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Field != "certificate passphrase" {
+		t.Errorf("Field = %q, want %q", validationErr.Field, "certificate passphrase")
+	}
+}
+
+func TestClientCertificatesLookup(t *testing.T) {
+	exact := &tls.Certificate{}
+	byHost := &tls.Certificate{}
+	certs := clientCertificates{
+		"api.example.com:8443": exact,
+		"api.example.com":      byHost,
+	}
+
+	tests := []struct {
+		hostPort string
+		want     *tls.Certificate
+	}{
+		{"api.example.com:8443", exact},
+		{"API.example.com:8443", exact},
+		{"api.example.com:443", byHost},
+		{"api.example.com", byHost},
+		{"other.example.com", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := certs.lookup(tt.hostPort); got != tt.want {
+			t.Errorf("lookup(%q) = %p, want %p", tt.hostPort, got, tt.want)
+		}
+	}
+}
+
+func TestClientCertificatesLookup_DefaultPort(t *testing.T) {
+	secure := &tls.Certificate{}
+	plain := &tls.Certificate{}
+	certs := clientCertificates{
+		normalizeCertificateHost("API.example.com:443"): secure,
+		normalizeCertificateHost("api.example.com:80"):  plain,
+	}
+
+	tests := []struct {
+		rawURL string
+		want   *tls.Certificate
+	}{
+		{"https://api.example.com/users", secure},
+		{"https://api.example.com:443/users", secure},
+		{"wss://api.example.com/socket", secure},
+		{"http://api.example.com/users", plain},
+		{"https://api.example.com:8443/users", nil},
+		{"https://other.example.com/users", nil},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := certs.lookup(requestHostPort(u)); got != tt.want {
+			t.Errorf("lookup(%q) = %p, want %p", tt.rawURL, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// GraphQL over WebSocket subprotocols, in order of preference
+const (
+	// graphqlTransportWS is the protocol of the graphql-ws library
+	graphqlTransportWS = "graphql-transport-ws"
+	// graphqlWS is the legacy subscriptions-transport-ws protocol
+	graphqlWS = "graphql-ws"
+)
+
+// subscriptionID identifies the single operation run on the socket
+const subscriptionID = "1"
+
+// graphQLMessage is a message of either GraphQL over WebSocket protocol
+type graphQLMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// sendGraphQLSubscription runs a GraphQL subscription over a WebSocket. The
+// request headers go both on the upgrade request and, as the
+// connection_init payload, to servers that authenticate there. Each result
+// is delivered as a received message until the server completes the
+// subscription or it is stopped; the response body is the array of results.
+func (c *HttpClient) sendGraphQLSubscription(ctx context.Context, request *models.HttpRequest) (*models.HttpResponse, error) {
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+
+	handshake, refused, err := c.openWebSocket(ctx, request, []string{graphqlTransportWS, graphqlWS})
+	if err != nil || refused != nil {
+		return refused, err
+	}
+	defer handshake.conn.Close()
+
+	conversation := newWebSocketConversation(handshake.conn, c.eventConfig())
+	defer conversation.stop()
+
+	subscription := &graphQLSubscription{
+		conversation: conversation,
+		legacy:       handshake.conn.Subprotocol() == graphqlWS,
+	}
+	if err := subscription.run(ctx, request); err != nil {
+		return nil, errors.NewRequestErrorWithURL("subscription", request.Method, request.URL, c.timeoutError(handshake.tracer, err))
+	}
+
+	results := make([]json.RawMessage, len(conversation.received))
+	for i, message := range conversation.received {
+		results[i] = json.RawMessage(message.Data)
+	}
+	body, err := json.Marshal(results)
+	if err != nil {
+		return nil, errors.NewRequestErrorWithURL("read response", request.Method, request.URL, err)
+	}
+
+	response := models.NewHttpResponse(handshake.resp, body, handshake.tracer.timing(time.Now()), request)
+	response.Messages = conversation.received
+	return response, nil
+}
+
+// graphQLSubscription speaks graphql-transport-ws or, with legacy set,
+// subscriptions-transport-ws on an open WebSocket
+type graphQLSubscription struct {
+	conversation *webSocketConversation
+	legacy       bool
+}
+
+// run initializes the connection, starts the operation and records its
+// results until it completes or is stopped
+func (s *graphQLSubscription) run(ctx context.Context, request *models.HttpRequest) error {
+	headers, err := json.Marshal(request.Headers)
+	if err != nil {
+		return err
+	}
+	if err := s.send(graphQLMessage{Type: "connection_init", Payload: headers}); err != nil {
+		return err
+	}
+
+	for acknowledged := false; !acknowledged; {
+		message, ok, err := s.next(ctx)
+		if err != nil || !ok {
+			return err
+		}
+		switch message.Type {
+		case "connection_ack":
+			acknowledged = true
+		case "connection_error":
+			return errors.NewValidationErrorWithValue("connection", string(message.Payload), "rejected by the server")
+		case "ping":
+			if err := s.send(graphQLMessage{Type: "pong"}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cfg := s.conversation.cfg
+	if cfg.OnOpen != nil {
+		cfg.OnOpen()
+	}
+
+	start := "subscribe"
+	if s.legacy {
+		start = "start"
+	}
+	if err := s.send(graphQLMessage{ID: subscriptionID, Type: start, Payload: json.RawMessage(request.RawBody)}); err != nil {
+		return err
+	}
+
+	for {
+		message, ok, err := s.next(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if stopped(cfg.Stop) {
+				stop := "complete"
+				if s.legacy {
+					stop = "stop"
+				}
+				s.send(graphQLMessage{ID: subscriptionID, Type: stop})
+			}
+			break
+		}
+
+		switch message.Type {
+		case "next", "data":
+			s.conversation.receive(models.WebSocketMessage{Data: string(message.Payload)})
+		case "error":
+			// graphql-transport-ws sends a list of errors, the legacy protocol one
+			errs := message.Payload
+			if s.legacy {
+				errs = append(append(json.RawMessage("["), errs...), ']')
+			}
+			s.conversation.receive(models.WebSocketMessage{Data: `{"errors":` + string(errs) + `}`})
+			s.conversation.close()
+			return nil
+		case "complete":
+			s.conversation.close()
+			return nil
+		case "ping":
+			if err := s.send(graphQLMessage{Type: "pong"}); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.conversation.close()
+	return nil
+}
+
+// next returns the next protocol message. It returns false when no more
+// messages will arrive; a server closing the socket with an error code,
+// e.g. 4403 Forbidden, is an error.
+func (s *graphQLSubscription) next(ctx context.Context) (graphQLMessage, bool, error) {
+	raw, ok, err := s.conversation.next(ctx)
+	if err != nil || !ok {
+		if closeErr := s.conversation.closeErr; closeErr != nil && closeErr.Code != websocket.CloseNormalClosure {
+			return graphQLMessage{}, false, errors.Wrap(closeErr, "the server closed the connection")
+		}
+		return graphQLMessage{}, false, err
+	}
+
+	var message graphQLMessage
+	if err := json.Unmarshal([]byte(raw.Data), &message); err != nil {
+		return graphQLMessage{}, false, errors.Wrap(err, "invalid message from the server")
+	}
+	return message, true, nil
+}
+
+// send writes a protocol message
+func (s *graphQLSubscription) send(message graphQLMessage) error {
+	return s.conversation.conn.WriteJSON(message)
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// newGraphQLServer accepts a GraphQL over WebSocket connection with the given
+// subprotocol, checks connection_init and starts handler with the operation
+func newGraphQLServer(t *testing.T, subprotocol string, handler func(conn *websocket.Conn, start graphQLMessage)) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{Subprotocols: []string{subprotocol}}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var init graphQLMessage
+		if err := conn.ReadJSON(&init); err != nil || init.Type != "connection_init" {
+			return
+		}
+		var params map[string]string
+		json.Unmarshal(init.Payload, &params)
+		if params["Authorization"] == "" {
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4403, "Forbidden"))
+			return
+		}
+		conn.WriteJSON(graphQLMessage{Type: "connection_ack"})
+
+		var start graphQLMessage
+		if err := conn.ReadJSON(&start); err != nil {
+			return
+		}
+		handler(conn, start)
+	}))
+}
+
+// subscriptionRequest returns a subscription request to a test server
+func subscriptionRequest(server *httptest.Server, headers map[string]string) *models.HttpRequest {
+	body := `{"query":"subscription { messageAdded { id } }","variables":{}}`
+	request := models.NewHttpRequest("POST", server.URL+"/graphql", headers, nil, body, "")
+	request.Metadata.GraphQLSubscription = true
+	return request
+}
+
+func TestSendGraphQLSubscription(t *testing.T) {
+	tests := []struct {
+		name        string
+		subprotocol string
+		start       string
+		result      string
+	}{
+		{"graphql-transport-ws", graphqlTransportWS, "subscribe", "next"},
+		{"legacy graphql-ws", graphqlWS, "start", "data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var start graphQLMessage
+			server := newGraphQLServer(t, tt.subprotocol, func(conn *websocket.Conn, message graphQLMessage) {
+				start = message
+				conn.WriteJSON(graphQLMessage{ID: message.ID, Type: tt.result, Payload: json.RawMessage(`{"data":{"messageAdded":{"id":1}}}`)})
+				conn.WriteJSON(graphQLMessage{ID: message.ID, Type: tt.result, Payload: json.RawMessage(`{"data":{"messageAdded":{"id":2}}}`)})
+				conn.WriteJSON(graphQLMessage{ID: message.ID, Type: "complete"})
+				conn.ReadMessage()
+			})
+			defer server.Close()
+
+			var live []string
+			config := DefaultConfig()
+			config.Events = &EventConfig{
+				OnMessage: func(message models.WebSocketMessage, sent bool) {
+					live = append(live, message.Data)
+				},
+			}
+			client, err := NewHttpClient(config)
+			if err != nil {
+				t.Fatalf("NewHttpClient() error = %v", err)
+			}
+
+			resp, err := client.Send(subscriptionRequest(server, map[string]string{"Authorization": "Bearer token"}))
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if start.Type != tt.start || !strings.Contains(string(start.Payload), "subscription") {
+				t.Errorf("start message = %s %s", start.Type, start.Payload)
+			}
+			if !resp.IsWebSocket() || len(resp.Messages) != 2 || len(live) != 2 {
+				t.Fatalf("got status %d with %d messages, %d live", resp.StatusCode, len(resp.Messages), len(live))
+			}
+			want := `[{"data":{"messageAdded":{"id":1}}},{"data":{"messageAdded":{"id":2}}}]`
+			if resp.Body != want {
+				t.Errorf("Body = %s, want %s", resp.Body, want)
+			}
+		})
+	}
+}
+
+func TestSendGraphQLSubscription_Errors(t *testing.T) {
+	server := newGraphQLServer(t, graphqlTransportWS, func(conn *websocket.Conn, start graphQLMessage) {
+		conn.WriteJSON(graphQLMessage{ID: start.ID, Type: "error", Payload: json.RawMessage(`[{"message":"unknown field"}]`)})
+		conn.ReadMessage()
+	})
+	defer server.Close()
+
+	client, err := NewHttpClient(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	resp, err := client.Send(subscriptionRequest(server, map[string]string{"Authorization": "Bearer token"}))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if want := `[{"errors":[{"message":"unknown field"}]}]`; resp.Body != want {
+		t.Errorf("Body = %s, want %s", resp.Body, want)
+	}
+
+	// Without credentials in connection_init the server closes with 4403
+	if _, err := client.Send(subscriptionRequest(server, map[string]string{})); err == nil || !strings.Contains(err.Error(), "Forbidden") {
+		t.Errorf("Send() error = %v, want the close reason", err)
+	}
+}
+
+func TestSendGraphQLSubscription_Stop(t *testing.T) {
+	stopMessage := make(chan string, 1)
+	server := newGraphQLServer(t, graphqlTransportWS, func(conn *websocket.Conn, start graphQLMessage) {
+		conn.WriteJSON(graphQLMessage{ID: start.ID, Type: "next", Payload: json.RawMessage(`{"data":{}}`)})
+		var message graphQLMessage
+		conn.ReadJSON(&message)
+		stopMessage <- message.Type
+	})
+	defer server.Close()
+
+	stop := make(chan struct{})
+	config := DefaultConfig()
+	config.Events = &EventConfig{
+		OnMessage: func(message models.WebSocketMessage, sent bool) { close(stop) },
+		Stop:      stop,
+	}
+	client, err := NewHttpClient(config)
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	resp, err := client.Send(subscriptionRequest(server, map[string]string{"Authorization": "Bearer token"}))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(resp.Messages) != 1 {
+		t.Errorf("got %d messages, want 1", len(resp.Messages))
+	}
+	if got := <-stopMessage; got != "complete" {
+		t.Errorf("server got %q after stop, want complete", got)
+	}
+}
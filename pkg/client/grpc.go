@@ -0,0 +1,460 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/ideaspaper/restclient/internal/constants"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// Server reflection methods, newest first. Both use the same messages.
+var grpcReflectionMethods = []string{
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+// grpcReservedHeaders are set by the gRPC transport and not sent as metadata
+var grpcReservedHeaders = map[string]bool{
+	"content-type": true, "content-length": true, "user-agent": true,
+	"host": true, "connection": true, "te": true, "transfer-encoding": true,
+}
+
+// sendGRPC calls a gRPC method. The request body is the request message as
+// JSON; a client-streaming method may be sent several JSON objects in a row.
+// The schema comes from the @proto file or from server reflection. The
+// response body holds the reply as JSON (an array of replies for
+// server-streaming methods), and the gRPC status, header and trailing
+// metadata are returned as headers.
+func (c *HttpClient) sendGRPC(ctx context.Context, request *models.HttpRequest) (*models.HttpResponse, error) {
+	if err := c.prepare(request); err != nil {
+		return nil, err
+	}
+
+	target, err := request.GRPCTarget()
+	if err != nil {
+		return nil, errors.NewRequestErrorWithURL("build", request.Method, request.URL, err)
+	}
+
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+
+	conn, err := c.dialGRPC(target, request.Headers[constants.HeaderUserAgent])
+	if err != nil {
+		return nil, errors.NewRequestErrorWithURL("send", request.Method, request.URL, err)
+	}
+	defer conn.Close()
+
+	files, err := grpcSchema(ctx, conn, target.Service, request.Metadata.Proto)
+	if err != nil {
+		return nil, errors.NewRequestErrorWithURL("schema", request.Method, request.URL, c.grpcContextError(ctx, err))
+	}
+	method, err := findGRPCMethod(files, target)
+	if err != nil {
+		return nil, errors.NewRequestErrorWithURL("schema", request.Method, request.URL, err)
+	}
+
+	types := dynamicpb.NewTypes(files)
+	messages, err := grpcRequestMessages(method, request.RawBody, types)
+	if err != nil {
+		return nil, errors.NewRequestErrorWithURL("build", request.Method, request.URL, err)
+	}
+
+	md := metadata.MD{}
+	for k, v := range request.Headers {
+		if key := strings.ToLower(k); !grpcReservedHeaders[key] {
+			md.Append(key, v)
+		}
+	}
+	callCtx := metadata.NewOutgoingContext(ctx, md)
+
+	var header, trailer metadata.MD
+	desc := &grpc.StreamDesc{ServerStreams: method.IsStreamingServer(), ClientStreams: method.IsStreamingClient()}
+	replies, callErr := callGRPC(callCtx, conn, desc, target.FullMethod(), method.Output(), messages, grpc.Header(&header), grpc.Trailer(&trailer))
+	if callErr != nil && ctx.Err() != nil {
+		return nil, errors.NewRequestErrorWithURL("send", request.Method, request.URL, c.grpcContextError(ctx, callErr))
+	}
+
+	body, err := grpcResponseBody(replies, desc.ServerStreams, types)
+	if err != nil {
+		return nil, errors.NewRequestErrorWithURL("read response", request.Method, request.URL, err)
+	}
+
+	st := status.Convert(callErr)
+	resp := &http.Response{
+		StatusCode: grpcHTTPStatus(st.Code()),
+		Status:     st.Code().String(),
+		Proto:      "HTTP/2.0",
+		Header:     http.Header{},
+	}
+	for _, md := range []metadata.MD{header, trailer} {
+		for k, v := range md {
+			resp.Header[k] = append(resp.Header[k], v...)
+		}
+	}
+	// The body is shown as JSON, not in the protobuf wire format
+	resp.Header["content-type"] = []string{constants.MIMEApplicationJSON}
+	resp.Header["grpc-status"] = []string{strconv.Itoa(int(st.Code()))}
+	if st.Message() != "" {
+		resp.Header["grpc-message"] = []string{st.Message()}
+	}
+
+	timing := models.ResponseTiming{Total: time.Since(start)}
+	return models.NewHttpResponse(resp, body, timing, request), nil
+}
+
+// dialGRPC creates a connection to the target, using the client's TLS
+// settings and certificates when the target asks for TLS
+func (c *HttpClient) dialGRPC(target models.GRPCTarget, userAgent string) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if target.TLS {
+		tlsConfig := c.transport.TLSClientConfig.Clone()
+		if len(c.certificates) > 0 {
+			tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				if cert := c.certificates.lookup(target.Address); cert != nil {
+					return cert, nil
+				}
+				return &tls.Certificate{}, nil
+			}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	options := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			MinConnectTimeout: cmp.Or(c.config.ConnectTimeout, defaultConnectTimeout),
+		}),
+	}
+	if userAgent != "" {
+		options = append(options, grpc.WithUserAgent(userAgent))
+	}
+	return grpc.NewClient(target.Address, options...)
+}
+
+// callGRPC sends the request messages and collects the replies. A
+// non-streaming method gets at most one reply.
+func callGRPC(ctx context.Context, conn *grpc.ClientConn, desc *grpc.StreamDesc, fullMethod string, output protoreflect.MessageDescriptor, messages []proto.Message, opts ...grpc.CallOption) ([]proto.Message, error) {
+	stream, err := conn.NewStream(ctx, desc, fullMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, message := range messages {
+		// On io.EOF the server ended the call; its status is read below
+		if err := stream.SendMsg(message); err != nil {
+			break
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var replies []proto.Message
+	for {
+		reply := dynamicpb.NewMessage(output)
+		if err := stream.RecvMsg(reply); err != nil {
+			if err == io.EOF {
+				return replies, nil
+			}
+			return replies, err
+		}
+		replies = append(replies, reply)
+		if !desc.ServerStreams {
+			return replies, nil
+		}
+	}
+}
+
+// grpcRequestMessages decodes the JSON request body into request messages.
+// An empty body is a single empty message, or no message at all for a
+// client-streaming method.
+func grpcRequestMessages(method protoreflect.MethodDescriptor, body string, types *dynamicpb.Types) ([]proto.Message, error) {
+	options := protojson.UnmarshalOptions{Resolver: types}
+	decoder := json.NewDecoder(strings.NewReader(body))
+
+	var messages []proto.Message
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "request body is not JSON")
+		}
+
+		message := dynamicpb.NewMessage(method.Input())
+		if err := options.Unmarshal(raw, message); err != nil {
+			return nil, errors.Wrapf(err, "request body does not match %s", method.Input().FullName())
+		}
+		messages = append(messages, message)
+	}
+
+	if len(messages) == 0 && !method.IsStreamingClient() {
+		messages = append(messages, dynamicpb.NewMessage(method.Input()))
+	}
+	if len(messages) > 1 && !method.IsStreamingClient() {
+		return nil, errors.NewValidationError("request body", string(method.FullName())+" takes a single message")
+	}
+	return messages, nil
+}
+
+// grpcResponseBody formats the replies as indented JSON. protojson output
+// is not stable on purpose, so it is indented by encoding/json instead.
+func grpcResponseBody(replies []proto.Message, streaming bool, types *dynamicpb.Types) ([]byte, error) {
+	if !streaming && len(replies) == 0 {
+		return nil, nil
+	}
+
+	options := protojson.MarshalOptions{Resolver: types}
+	values := make([]json.RawMessage, len(replies))
+	for i, reply := range replies {
+		data, err := options.Marshal(reply)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = data
+	}
+
+	var value any = values
+	if !streaming {
+		value = values[0]
+	}
+	return json.MarshalIndent(value, "", "  ")
+}
+
+// grpcSchema loads the descriptors for a service from a .proto file or,
+// without one, from server reflection
+func grpcSchema(ctx context.Context, conn *grpc.ClientConn, service, protoPath string) (*protoregistry.Files, error) {
+	if protoPath != "" {
+		return compileProto(ctx, protoPath)
+	}
+
+	for _, method := range grpcReflectionMethods {
+		files, err := reflectSchema(ctx, conn, method, service)
+		if status.Code(err) == codes.Unimplemented {
+			continue
+		}
+		return files, err
+	}
+	return nil, errors.NewValidationError("schema", "the server does not support reflection; add # @proto with the path to its .proto file")
+}
+
+// compileProto compiles a .proto file. Imports are resolved relative to its
+// directory, and the well-known types are built in.
+func compileProto(ctx context.Context, path string) (*protoregistry.Files, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: []string{filepath.Dir(path)},
+		}),
+	}
+	compiled, err := compiler.Compile(ctx, filepath.Base(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compile "+path)
+	}
+
+	files := new(protoregistry.Files)
+	for _, file := range compiled {
+		if err := registerFile(files, file); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// registerFile registers a file descriptor after its imports
+func registerFile(files *protoregistry.Files, file protoreflect.FileDescriptor) error {
+	if _, err := files.FindFileByPath(file.Path()); err == nil {
+		return nil
+	}
+	imports := file.Imports()
+	for i := range imports.Len() {
+		if err := registerFile(files, imports.Get(i).FileDescriptor); err != nil {
+			return err
+		}
+	}
+	return files.RegisterFile(file)
+}
+
+// reflectSchema asks the server for the file defining symbol and every file
+// it depends on
+func reflectSchema(ctx context.Context, conn *grpc.ClientConn, method, symbol string) (*protoregistry.Files, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, method)
+	if err != nil {
+		return nil, err
+	}
+
+	ask := func(request *reflectionpb.ServerReflectionRequest) ([]*descriptorpb.FileDescriptorProto, error) {
+		if err := stream.SendMsg(request); err != nil {
+			return nil, err
+		}
+		response := new(reflectionpb.ServerReflectionResponse)
+		if err := stream.RecvMsg(response); err != nil {
+			return nil, err
+		}
+		if e := response.GetErrorResponse(); e != nil {
+			return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+		}
+
+		var protos []*descriptorpb.FileDescriptorProto
+		for _, raw := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := new(descriptorpb.FileDescriptorProto)
+			if err := proto.Unmarshal(raw, fd); err != nil {
+				return nil, errors.Wrap(err, "invalid descriptor from server reflection")
+			}
+			protos = append(protos, fd)
+		}
+		return protos, nil
+	}
+
+	pending, err := ask(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	protos := make(map[string]*descriptorpb.FileDescriptorProto)
+	for len(pending) > 0 {
+		fd := pending[0]
+		pending = pending[1:]
+		if _, ok := protos[fd.GetName()]; ok {
+			continue
+		}
+		protos[fd.GetName()] = fd
+
+		for _, dep := range fd.GetDependency() {
+			if _, ok := protos[dep]; ok {
+				continue
+			}
+			more, err := ask(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			})
+			if err != nil {
+				return nil, err
+			}
+			pending = append(pending, more...)
+		}
+	}
+
+	files := new(protoregistry.Files)
+	for name := range protos {
+		if err := buildFile(files, protos, name); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// buildFile builds and registers a file descriptor after its dependencies
+func buildFile(files *protoregistry.Files, protos map[string]*descriptorpb.FileDescriptorProto, name string) error {
+	if _, err := files.FindFileByPath(name); err == nil {
+		return nil
+	}
+	fd, ok := protos[name]
+	if !ok {
+		return errors.NewValidationErrorWithValue("schema", name, "missing from server reflection")
+	}
+	for _, dep := range fd.GetDependency() {
+		if err := buildFile(files, protos, dep); err != nil {
+			return err
+		}
+	}
+
+	file, err := protodesc.NewFile(fd, files)
+	if err != nil {
+		return errors.Wrap(err, "invalid descriptor for "+name)
+	}
+	return files.RegisterFile(file)
+}
+
+// findGRPCMethod looks up the method called by target
+func findGRPCMethod(files *protoregistry.Files, target models.GRPCTarget) (protoreflect.MethodDescriptor, error) {
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(target.Service))
+	if err != nil {
+		return nil, errors.NewValidationErrorWithValue("service", target.Service, "not found")
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, errors.NewValidationErrorWithValue("service", target.Service, "not a service")
+	}
+	method := service.Methods().ByName(protoreflect.Name(target.Method))
+	if method == nil {
+		return nil, errors.NewValidationErrorWithValue("method", target.Method, "not found in "+target.Service)
+	}
+	return method, nil
+}
+
+// grpcContextError reports a call ended by the context as a timeout or
+// cancellation instead of a gRPC status
+func (c *HttpClient) grpcContextError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return errors.NewTimeoutError(errors.PhaseFirstByte, c.config.Timeout, ctx.Err())
+	case context.Canceled:
+		return ctx.Err()
+	}
+	return err
+}
+
+// grpcHTTPStatus maps a gRPC status code to the closest HTTP status, so that
+// scripts can check response.status as for any other request
+func grpcHTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // Client Closed Request
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
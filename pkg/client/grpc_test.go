@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// authHealthServer is a health service that requires an authorization header
+// and reports it back in the trailer
+type authHealthServer struct {
+	*health.Server
+}
+
+func (s authHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	auth := md.Get("authorization")
+	if len(auth) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing credentials")
+	}
+	grpc.SetTrailer(ctx, metadata.Pairs("x-auth", auth[0]))
+	return s.Server.Check(ctx, req)
+}
+
+// newGRPCServer serves the health service, with reflection if asked
+func newGRPCServer(t *testing.T, withReflection bool) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, authHealthServer{healthServer})
+	if withReflection {
+		reflection.Register(server)
+	}
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestSendGRPC(t *testing.T) {
+	address := newGRPCServer(t, true)
+	client, err := NewHttpClient(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		body       string
+		wantStatus int
+		wantText   string
+		wantBody   string
+		wantHeader map[string]string
+	}{
+		{
+			name:       "OK",
+			headers:    map[string]string{"Authorization": "Bearer token"},
+			body:       `{"service": "orders"}`,
+			wantStatus: 200,
+			wantText:   "OK",
+			wantBody:   `"status": "SERVING"`,
+			wantHeader: map[string]string{"grpc-status": "0", "x-auth": "Bearer token", "Content-Type": "application/json"},
+		},
+		{
+			name:       "error status",
+			headers:    map[string]string{"Authorization": "Bearer token"},
+			body:       `{"service": "billing"}`,
+			wantStatus: 404,
+			wantText:   "NotFound",
+			wantHeader: map[string]string{"grpc-status": "5", "grpc-message": "unknown service"},
+		},
+		{
+			name:       "metadata from headers",
+			headers:    map[string]string{},
+			wantStatus: 401,
+			wantText:   "Unauthenticated",
+			wantHeader: map[string]string{"grpc-message": "missing credentials"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := models.NewHttpRequest("GRPC", address+"/grpc.health.v1.Health/Check", tt.headers, nil, tt.body, "")
+			resp, err := client.Send(request)
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if resp.StatusCode != tt.wantStatus || resp.StatusMessage != tt.wantText {
+				t.Errorf("status = %d %s, want %d %s", resp.StatusCode, resp.StatusMessage, tt.wantStatus, tt.wantText)
+			}
+			if !strings.Contains(resp.Body, tt.wantBody) {
+				t.Errorf("Body = %q, want it to contain %q", resp.Body, tt.wantBody)
+			}
+			for name, want := range tt.wantHeader {
+				if got := resp.GetHeader(name); got != want {
+					t.Errorf("header %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSendGRPC_ProtoFile(t *testing.T) {
+	address := newGRPCServer(t, false)
+	client, err := NewHttpClient(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	request := models.NewHttpRequest("GRPC", "grpc://"+address+"/grpc.health.v1.Health/Check",
+		map[string]string{"Authorization": "Bearer token"}, nil, `{"service": "orders"}`, "")
+
+	// Without reflection the schema must come from a .proto file
+	if _, err := client.Send(request.Clone()); err == nil || !strings.Contains(err.Error(), "@proto") {
+		t.Errorf("Send() without @proto error = %v, want a hint to add @proto", err)
+	}
+
+	protoPath := filepath.Join(t.TempDir(), "health.proto")
+	proto := `syntax = "proto3";
+package grpc.health.v1;
+
+message HealthCheckRequest { string service = 1; }
+message HealthCheckResponse {
+  enum ServingStatus { UNKNOWN = 0; SERVING = 1; NOT_SERVING = 2; SERVICE_UNKNOWN = 3; }
+  ServingStatus status = 1;
+}
+service Health {
+  rpc Check(HealthCheckRequest) returns (HealthCheckResponse);
+}
+`
+	if err := os.WriteFile(protoPath, []byte(proto), 0644); err != nil {
+		t.Fatalf("failed to write proto: %v", err)
+	}
+	request.Metadata.Proto = protoPath
+
+	resp, err := client.Send(request)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.StatusCode != 200 || !strings.Contains(resp.Body, `"SERVING"`) {
+		t.Errorf("got %d %q", resp.StatusCode, resp.Body)
+	}
+}
+
+func TestSendGRPC_BadRequest(t *testing.T) {
+	address := newGRPCServer(t, true)
+	client, err := NewHttpClient(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		body    string
+		wantErr string
+	}{
+		{"unknown method", address + "/grpc.health.v1.Health/Ping", "", "Ping"},
+		{"unknown field", address + "/grpc.health.v1.Health/Check", `{"name": "x"}`, "grpc.health.v1.HealthCheckRequest"},
+		{"several messages for a unary method", address + "/grpc.health.v1.Health/Check", `{} {}`, "single message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Send(models.NewHttpRequest("GRPC", tt.url, map[string]string{}, nil, tt.body, ""))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Send() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
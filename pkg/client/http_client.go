@@ -4,6 +4,7 @@ package client
 
 import (
 	"bytes"
+	"cmp"
 	"compress/gzip"
 	"context"
 	"crypto/tls"
@@ -40,7 +41,8 @@ var _ HTTPDoer = (*HttpClient)(nil)
 
 // ClientConfig holds client configuration
 type ClientConfig struct {
-	Timeout         time.Duration
+	Timeout         time.Duration // Overall request timeout, including reading the body (0 = none)
+	ConnectTimeout  time.Duration // TCP connect timeout (0 = 30s)
 	FollowRedirects bool
 	InsecureSSL     bool
 	Proxy           string
@@ -48,6 +50,13 @@ type ClientConfig struct {
 	RememberCookies bool
 	DefaultHeaders  map[string]string
 	Certificates    map[string]Certificate
+	// MaxIdleConnsPerHost limits idle keep-alive connections per host
+	// (0 uses the net/http default of 2)
+	MaxIdleConnsPerHost int
+	// Stream writes response bodies to a writer as they arrive (optional)
+	Stream *StreamConfig
+	// Events delivers Server-Sent Events as they arrive (optional)
+	Events *EventConfig
 }
 
 // Certificate holds TLS certificate configuration
@@ -58,6 +67,9 @@ type Certificate struct {
 	Passphrase string
 }
 
+// defaultConnectTimeout is used when ClientConfig.ConnectTimeout is not set
+const defaultConnectTimeout = 30 * time.Second
+
 // DefaultConfig returns a default client configuration
 func DefaultConfig() *ClientConfig {
 	return &ClientConfig{
@@ -76,6 +88,8 @@ func DefaultConfig() *ClientConfig {
 type HttpClient struct {
 	config        *ClientConfig
 	client        *http.Client
+	transport     *http.Transport
+	certificates  clientCertificates
 	cookieJar     *cookiejar.Jar
 	authProcessor *auth.Processor
 }
@@ -100,10 +114,11 @@ func NewHttpClient(config *ClientConfig) (*HttpClient, error) {
 			InsecureSkipVerify: config.InsecureSSL,
 		},
 		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
+			Timeout:   cmp.Or(config.ConnectTimeout, defaultConnectTimeout),
 			KeepAlive: 30 * time.Second,
 		}).DialContext,
 		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
@@ -126,8 +141,22 @@ func NewHttpClient(config *ClientConfig) (*HttpClient, error) {
 		}
 	}
 
+	var roundTripper http.RoundTripper = transport
+	var certs clientCertificates
+	if len(config.Certificates) > 0 {
+		var err error
+		certs, err = loadClientCertificates(config.Certificates)
+		if err != nil {
+			return nil, err
+		}
+		if len(certs) > 0 {
+			transport.TLSClientConfig.GetClientCertificate = certs.getClientCertificate
+			roundTripper = &certificateRoundTripper{base: transport}
+		}
+	}
+
 	client := &http.Client{
-		Transport: transport,
+		Transport: roundTripper,
 	}
 
 	// Only set jar if RememberCookies is enabled
@@ -149,6 +178,8 @@ func NewHttpClient(config *ClientConfig) (*HttpClient, error) {
 	return &HttpClient{
 		config:        config,
 		client:        client,
+		transport:     transport,
+		certificates:  certs,
 		cookieJar:     jar,
 		authProcessor: auth.NewProcessor(),
 	}, nil
@@ -161,17 +192,91 @@ func (c *HttpClient) Send(request *models.HttpRequest) (*models.HttpResponse, er
 
 // SendWithContext sends an HTTP request with context
 func (c *HttpClient) SendWithContext(ctx context.Context, request *models.HttpRequest) (*models.HttpResponse, error) {
-	// Process authentication before sending
+	if request.Metadata.GraphQLSubscription {
+		return c.sendGraphQLSubscription(ctx, request)
+	}
+	if request.IsWebSocket() {
+		return c.sendWebSocket(ctx, request)
+	}
+	if request.IsGRPC() {
+		return c.sendGRPC(ctx, request)
+	}
+
+	resp, tracer, err := c.do(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponse(resp)
+
+	if isEventStream(resp) {
+		return c.readEventStream(ctx, request, resp, tracer)
+	}
+
+	stream := c.config.Stream
+	streaming := stream.shouldStream(resp)
+
+	var reader io.Reader = resp.Body
+	if streaming && stream.Progress != nil {
+		// Progress counts bytes on the wire, before decompression
+		reader = &progressReader{reader: resp.Body, total: resp.ContentLength, report: stream.Progress}
+	}
+
+	// Handle gzip encoding
+	if resp.Header.Get(constants.HeaderContentEncoding) == "gzip" {
+		gzReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, errors.NewRequestErrorWithURL("gzip", request.Method, request.URL, err)
+		}
+		reader = gzReader
+		defer gzReader.Close()
+	}
+
+	var body []byte
+	var bodySize int64
+	truncated := false
+	if streaming {
+		buffer := &limitedBuffer{limit: cmp.Or(stream.MaxBodyMemory, DefaultMaxBodyMemory)}
+		bodySize, err = io.Copy(io.MultiWriter(stream.Writer, buffer), reader)
+		body, truncated = buffer.data, buffer.truncated
+	} else {
+		var bodyBuffer bytes.Buffer
+		_, err = io.Copy(&bodyBuffer, reader)
+		body = bodyBuffer.Bytes()
+	}
+	if err != nil {
+		return nil, errors.NewRequestErrorWithURL("read response", request.Method, request.URL, c.timeoutError(tracer, err))
+	}
+	timing := tracer.timing(time.Now())
+
+	response := models.NewHttpResponse(resp, body, timing, request)
+	if streaming {
+		response.Streamed = true
+		response.BodyTruncated = truncated
+		response.BodySizeInBytes = int(bodySize)
+	}
+	return response, nil
+}
+
+// prepare applies authentication and default headers to the request
+func (c *HttpClient) prepare(request *models.HttpRequest) error {
 	if err := c.authProcessor.ProcessAuth(request); err != nil {
-		return nil, errors.NewRequestErrorWithURL("auth", request.Method, request.URL, err)
+		return errors.NewRequestErrorWithURL("auth", request.Method, request.URL, err)
 	}
 
-	// Apply default headers
 	for k, v := range c.config.DefaultHeaders {
 		if _, exists := request.Headers[k]; !exists {
 			request.Headers[k] = v
 		}
 	}
+	return nil
+}
+
+// do sends the request, answering a Digest challenge if the server sends
+// one. The caller must close the response body.
+func (c *HttpClient) do(ctx context.Context, request *models.HttpRequest) (*http.Response, *timingTracer, error) {
+	if err := c.prepare(request); err != nil {
+		return nil, nil, err
+	}
 
 	// Create standard HTTP request
 	var bodyReader io.Reader
@@ -182,7 +287,7 @@ func (c *HttpClient) SendWithContext(ctx context.Context, request *models.HttpRe
 		var err error
 		bodyReader, contentType, err = c.createMultipartBody(request.MultipartParts)
 		if err != nil {
-			return nil, errors.NewRequestErrorWithURL("multipart", request.Method, request.URL, err)
+			return nil, nil, errors.NewRequestErrorWithURL("multipart", request.Method, request.URL, err)
 		}
 	} else if request.RawBody != "" {
 		bodyReader = strings.NewReader(request.RawBody)
@@ -200,7 +305,7 @@ func (c *HttpClient) SendWithContext(ctx context.Context, request *models.HttpRe
 
 	req, err := http.NewRequestWithContext(ctx, request.Method, request.URL, bodyReader)
 	if err != nil {
-		return nil, errors.NewRequestErrorWithURL("build", request.Method, request.URL, err)
+		return nil, nil, errors.NewRequestErrorWithURL("build", request.Method, request.URL, err)
 	}
 
 	for k, v := range request.Headers {
@@ -212,60 +317,65 @@ func (c *HttpClient) SendWithContext(ctx context.Context, request *models.HttpRe
 		req.Header.Set(constants.HeaderContentType, contentType)
 	}
 
-	timing := models.ResponseTiming{}
-	startTime := time.Now()
+	tracer := newTimingTracer()
+	req = req.WithContext(tracer.withContext(ctx))
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, errors.NewRequestErrorWithURL("send", request.Method, request.URL, err)
+		return nil, nil, errors.NewRequestErrorWithURL("send", request.Method, request.URL, c.timeoutError(tracer, err))
 	}
 
-	// Close the response via helper so digest retries share logic
-	closeResponse := func(r *http.Response) {
-		if r != nil && r.Body != nil {
-			r.Body.Close()
-		}
-	}
-	defer closeResponse(resp)
-
-	timing.Total = time.Since(startTime)
-
 	// Handle Digest auth challenge (HTTP 401)
 	if resp.StatusCode == http.StatusUnauthorized {
 		authHeader := resp.Header.Get("WWW-Authenticate")
 		if strings.HasPrefix(strings.ToLower(authHeader), "digest ") {
-			// Retry with digest auth if we have credentials
-			digestResp, digestErr := c.handleDigestAuth(ctx, request, resp, authHeader)
+			// Retry with digest auth if we have credentials; the timing
+			// describes the authenticated request
+			tracer = newTimingTracer()
+			digestResp, digestErr := c.handleDigestAuth(tracer.withContext(ctx), request, resp, authHeader)
 			if digestErr == nil && digestResp != resp {
 				closeResponse(resp)
 				resp = digestResp
-				defer closeResponse(resp)
 			} else if digestErr != nil {
+				closeResponse(resp)
 				closeResponse(digestResp)
-				return nil, errors.NewRequestErrorWithURL("digest", request.Method, request.URL, digestErr)
+				return nil, nil, errors.NewRequestErrorWithURL("digest", request.Method, request.URL, c.timeoutError(tracer, digestErr))
 			}
 		}
 	}
 
-	var bodyBuffer bytes.Buffer
-	reader := resp.Body
+	return resp, tracer, nil
+}
 
-	// Handle gzip encoding
-	if resp.Header.Get(constants.HeaderContentEncoding) == "gzip" {
-		gzReader, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			return nil, errors.NewRequestErrorWithURL("gzip", request.Method, request.URL, err)
-		}
-		reader = gzReader
-		defer gzReader.Close()
+// closeResponse closes the response body, if there is one
+func closeResponse(r *http.Response) {
+	if r != nil && r.Body != nil {
+		r.Body.Close()
 	}
+}
 
-	_, err = io.Copy(&bodyBuffer, reader)
-	if err != nil {
-		return nil, errors.NewRequestErrorWithURL("read response", request.Method, request.URL, err)
+// timeoutError converts a timeout into an errors.TimeoutError naming the
+// phase that was in progress. Other errors are returned unchanged.
+func (c *HttpClient) timeoutError(tracer *timingTracer, err error) error {
+	if !isTimeout(err) {
+		return err
 	}
 
-	return models.NewHttpResponse(resp, bodyBuffer.Bytes(), timing, request), nil
+	phase := tracer.phase()
+	timeout := c.config.Timeout
+	if phase == errors.PhaseConnect && c.config.ConnectTimeout > 0 && (timeout == 0 || c.config.ConnectTimeout < timeout) {
+		timeout = c.config.ConnectTimeout
+	}
+	return errors.NewTimeoutError(phase, timeout, err)
+}
+
+// isTimeout reports whether err is a client, dialer or context timeout
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
 }
 
 // handleDigestAuth handles Digest authentication challenge
@@ -334,7 +444,7 @@ func (c *HttpClient) GetCookies(urlStr string) []*http.Cookie {
 	if c.cookieJar == nil {
 		return nil
 	}
-	parsedURL, err := url.Parse(urlStr)
+	parsedURL, err := cookieURL(urlStr)
 	if err != nil {
 		return nil
 	}
@@ -346,13 +456,29 @@ func (c *HttpClient) SetCookies(urlStr string, cookies []*http.Cookie) {
 	if c.cookieJar == nil || len(cookies) == 0 {
 		return
 	}
-	parsedURL, err := url.Parse(urlStr)
+	parsedURL, err := cookieURL(urlStr)
 	if err != nil {
 		return
 	}
 	c.cookieJar.SetCookies(parsedURL, cookies)
 }
 
+// cookieURL parses a URL for the cookie jar, which only accepts http and
+// https; a WebSocket shares the cookies of its HTTP origin
+func cookieURL(urlStr string) (*url.URL, error) {
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(parsedURL.Scheme) {
+	case "ws":
+		parsedURL.Scheme = "http"
+	case "wss":
+		parsedURL.Scheme = "https"
+	}
+	return parsedURL, nil
+}
+
 // GetResponseCookies extracts cookies from an HTTP response
 func GetResponseCookies(resp *http.Response) []*http.Cookie {
 	if resp == nil {
@@ -0,0 +1,279 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// Event stream defaults
+const (
+	// DefaultEventRetry is the delay before reconnecting to a dropped event
+	// stream, unless the server sends a retry field
+	DefaultEventRetry = time.Second
+	// DefaultMaxReconnects is the number of reconnects in a row, without an
+	// event in between, before an event stream is given up
+	DefaultMaxReconnects = 3
+)
+
+// EventConfig controls how text/event-stream responses and WebSocket
+// conversations are read. Events and received messages are always collected
+// into the HttpResponse; the config adds live delivery and a way to stop a
+// stream that never ends.
+type EventConfig struct {
+	// OnOpen is called when the stream or WebSocket opens (optional)
+	OnOpen func()
+	// OnEvent is called with each event as it arrives (optional)
+	OnEvent func(models.ServerSentEvent)
+	// OnMessage is called with each WebSocket message as it is sent or
+	// received (optional)
+	OnMessage func(message models.WebSocketMessage, sent bool)
+	// Stop ends the stream when closed, returning the events received so
+	// far as a normal response (optional)
+	Stop <-chan struct{}
+	// MaxReconnects limits reconnects after the connection drops
+	// (0 = DefaultMaxReconnects, negative = never reconnect)
+	MaxReconnects int
+}
+
+// isEventStream reports whether resp is a Server-Sent Events stream
+func isEventStream(resp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// readEventStream reads events until the server ends the stream, the stream
+// is stopped or the context is done. When the connection drops, it
+// reconnects with a Last-Event-ID header so the server can resume.
+func (c *HttpClient) readEventStream(ctx context.Context, request *models.HttpRequest, resp *http.Response, tracer *timingTracer) (*models.HttpResponse, error) {
+	cfg := c.eventConfig()
+	maxReconnects := cfg.MaxReconnects
+	if maxReconnects == 0 {
+		maxReconnects = DefaultMaxReconnects
+	}
+	if cfg.OnOpen != nil {
+		cfg.OnOpen()
+	}
+
+	var raw bytes.Buffer
+	var events []models.ServerSentEvent
+	parser := &eventParser{retry: DefaultEventRetry}
+	body := resp.Body
+	failures := 0
+
+	for {
+		received, err := readEvents(body, cfg.Stop, &raw, parser, func(event models.ServerSentEvent) {
+			events = append(events, event)
+			if cfg.OnEvent != nil {
+				cfg.OnEvent(event)
+			}
+		})
+		if received > 0 {
+			failures = 0
+		}
+
+		if stopped(cfg.Stop) || err == nil {
+			break
+		}
+		if ctx.Err() != nil || isTimeout(err) {
+			return nil, errors.NewRequestErrorWithURL("read events", request.Method, request.URL, c.timeoutError(tracer, err))
+		}
+
+		// The connection dropped; reconnect unless we keep failing
+		nextResp, attempts := c.reconnectEventStream(ctx, request, cfg.Stop, parser, maxReconnects-failures)
+		failures += attempts
+		if nextResp == nil {
+			break
+		}
+		defer closeResponse(nextResp)
+		body = nextResp.Body
+	}
+
+	if ctx.Err() != nil {
+		return nil, errors.NewRequestErrorWithURL("read events", request.Method, request.URL, ctx.Err())
+	}
+
+	response := models.NewHttpResponse(resp, raw.Bytes(), tracer.timing(time.Now()), request)
+	response.Events = events
+	return response, nil
+}
+
+// reconnectEventStream reopens a dropped stream, sending the last event ID,
+// in up to the given number of attempts. It returns the new response, or nil
+// if the stream could not be reopened, and the number of attempts made.
+func (c *HttpClient) reconnectEventStream(ctx context.Context, request *models.HttpRequest, stop <-chan struct{}, parser *eventParser, attempts int) (*http.Response, int) {
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if !waitToReconnect(ctx, stop, parser.retry) {
+			return nil, attempt
+		}
+
+		next := request.Clone()
+		if parser.lastID != "" {
+			next.Headers["Last-Event-ID"] = parser.lastID
+		}
+		resp, _, err := c.do(ctx, next)
+		if err != nil {
+			continue
+		}
+		if resp.StatusCode != http.StatusOK || !isEventStream(resp) {
+			// The server no longer offers the stream (e.g. 204 No Content)
+			closeResponse(resp)
+			return nil, attempt
+		}
+		return resp, attempt
+	}
+	return nil, max(attempts, 0)
+}
+
+// readEvents reads events from body until it ends, returning the number of
+// events received. A clean end of the stream returns a nil error. Closing
+// stop closes the body to interrupt a blocked read.
+func readEvents(body io.ReadCloser, stop <-chan struct{}, raw *bytes.Buffer, parser *eventParser, onEvent func(models.ServerSentEvent)) (int, error) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			body.Close()
+		case <-done:
+		}
+	}()
+
+	parser.reset(bufio.NewReader(io.TeeReader(body, raw)))
+	received := 0
+	for {
+		event, err := parser.next()
+		if err == io.EOF {
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+		received++
+		onEvent(event)
+	}
+}
+
+// stopped reports whether stop has been closed
+func stopped(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitToReconnect sleeps for d, returning false if the context is done or
+// stop is closed first
+func waitToReconnect(ctx context.Context, stop <-chan struct{}, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// eventParser parses the text/event-stream format. The last event ID and
+// the reconnection time carry over between connections.
+type eventParser struct {
+	reader *bufio.Reader
+	lastID string
+	retry  time.Duration
+}
+
+// reset makes the parser read from a new connection
+func (p *eventParser) reset(reader *bufio.Reader) {
+	p.reader = reader
+}
+
+// next returns the next complete event. An event cut off by the end of the
+// stream is discarded, as the specification requires.
+func (p *eventParser) next() (models.ServerSentEvent, error) {
+	var event models.ServerSentEvent
+	var data strings.Builder
+	hasData := false
+
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return models.ServerSentEvent{}, err
+		}
+
+		if line == "" {
+			// A blank line dispatches the event; without data there is none
+			if !hasData {
+				event = models.ServerSentEvent{}
+				continue
+			}
+			event.ID = p.lastID
+			event.Data = strings.TrimSuffix(data.String(), "\n")
+			if event.Event == "" {
+				event.Event = "message"
+			}
+			return event, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // Comment, often used as a keep-alive
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				p.lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 && !strings.HasPrefix(value, "+") {
+				p.retry = time.Duration(ms) * time.Millisecond
+				event.Retry = ms
+			}
+		}
+	}
+}
+
+// readLine reads a line ending in CRLF, LF or CR, without the line ending
+func (p *eventParser) readLine() (string, error) {
+	var line []byte
+	for {
+		b, err := p.reader.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				// An unterminated last line is incomplete
+				return "", io.ErrUnexpectedEOF
+			}
+			return "", err
+		}
+		switch b {
+		case '\n':
+			return string(line), nil
+		case '\r':
+			if next, err := p.reader.Peek(1); err == nil && next[0] == '\n' {
+				p.reader.ReadByte()
+			}
+			return string(line), nil
+		}
+		line = append(line, b)
+	}
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+func TestEventParser(t *testing.T) {
+	tests := []struct {
+		name      string
+		stream    string
+		want      []models.ServerSentEvent
+		wantRetry time.Duration
+	}{
+		{
+			name:   "default event type",
+			stream: "data: hello\n\n",
+			want:   []models.ServerSentEvent{{Event: "message", Data: "hello"}},
+		},
+		{
+			name:      "all fields",
+			stream:    "event: update\nid: 7\nretry: 2500\ndata: {\"n\": 1}\n\n",
+			want:      []models.ServerSentEvent{{ID: "7", Event: "update", Data: `{"n": 1}`, Retry: 2500}},
+			wantRetry: 2500 * time.Millisecond,
+		},
+		{
+			name:   "multi-line data and comments",
+			stream: ": keep-alive\ndata: first\ndata:second\n\n",
+			want:   []models.ServerSentEvent{{Event: "message", Data: "first\nsecond"}},
+		},
+		{
+			name:   "ID carries over",
+			stream: "id: 1\ndata: a\n\ndata: b\n\n",
+			want:   []models.ServerSentEvent{{ID: "1", Event: "message", Data: "a"}, {ID: "1", Event: "message", Data: "b"}},
+		},
+		{
+			name:   "CRLF and CR line endings",
+			stream: "data: a\r\n\r\ndata: b\r\r",
+			want:   []models.ServerSentEvent{{Event: "message", Data: "a"}, {Event: "message", Data: "b"}},
+		},
+		{
+			name:   "events without data are not dispatched",
+			stream: "event: ping\n\ndata: x\n\n",
+			want:   []models.ServerSentEvent{{Event: "message", Data: "x"}},
+		},
+		{
+			name:   "invalid retry is ignored",
+			stream: "retry: soon\ndata: x\n\n",
+			want:   []models.ServerSentEvent{{Event: "message", Data: "x"}},
+		},
+		{
+			name:   "incomplete last event is discarded",
+			stream: "data: a\n\ndata: b\n",
+			want:   []models.ServerSentEvent{{Event: "message", Data: "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &eventParser{}
+			parser.reset(bufio.NewReader(strings.NewReader(tt.stream)))
+
+			var got []models.ServerSentEvent
+			for {
+				event, err := parser.next()
+				if err != nil {
+					if err != io.EOF {
+						t.Fatalf("next() error = %v", err)
+					}
+					break
+				}
+				got = append(got, event)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("events = %+v, want %+v", got, tt.want)
+			}
+			if parser.retry != tt.wantRetry {
+				t.Errorf("retry = %v, want %v", parser.retry, tt.wantRetry)
+			}
+		})
+	}
+}
+
+// newEventServer serves an event stream written by handler
+func newEventServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, send func(string))) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		handler(w, r, func(event string) {
+			fmt.Fprint(w, event)
+			w.(http.Flusher).Flush()
+		})
+	}))
+}
+
+func TestSendEventStream(t *testing.T) {
+	server := newEventServer(t, func(w http.ResponseWriter, r *http.Request, send func(string)) {
+		send("event: greeting\ndata: {\"hello\": \"world\"}\n\n")
+		send("data: [DONE]\n\n")
+	})
+	defer server.Close()
+
+	var live []models.ServerSentEvent
+	opened := false
+	config := DefaultConfig()
+	config.Events = &EventConfig{
+		OnOpen:  func() { opened = true },
+		OnEvent: func(event models.ServerSentEvent) { live = append(live, event) },
+	}
+	client, err := NewHttpClient(config)
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	resp, err := client.Send(models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", ""))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if !opened || len(live) != 2 {
+		t.Errorf("opened = %v, live events = %d, want true, 2", opened, len(live))
+	}
+	if len(resp.Events) != 2 || resp.Events[0].Event != "greeting" || resp.Events[1].Data != "[DONE]" {
+		t.Errorf("unexpected events: %+v", resp.Events)
+	}
+	if !resp.IsEventStream() || !strings.Contains(resp.Body, "event: greeting") {
+		t.Errorf("Body should hold the raw stream, got %q", resp.Body)
+	}
+}
+
+func TestSendEventStream_ReconnectsWithLastEventID(t *testing.T) {
+	var connections atomic.Int32
+	var lastEventID atomic.Value
+	server := newEventServer(t, func(w http.ResponseWriter, r *http.Request, send func(string)) {
+		if connections.Add(1) == 1 {
+			send("retry: 10\nid: 1\ndata: first\n\n")
+			// Drop the connection mid-stream
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		lastEventID.Store(r.Header.Get("Last-Event-ID"))
+		send("id: 2\ndata: second\n\n")
+	})
+	defer server.Close()
+
+	client, err := NewHttpClient(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	resp, err := client.Send(models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", ""))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if connections.Load() != 2 {
+		t.Errorf("connections = %d, want 2", connections.Load())
+	}
+	if got, _ := lastEventID.Load().(string); got != "1" {
+		t.Errorf("Last-Event-ID = %q, want \"1\"", got)
+	}
+	if len(resp.Events) != 2 || resp.Events[1].ID != "2" {
+		t.Errorf("unexpected events: %+v", resp.Events)
+	}
+}
+
+func TestSendEventStream_Stop(t *testing.T) {
+	server := newEventServer(t, func(w http.ResponseWriter, r *http.Request, send func(string)) {
+		send("data: tick\n\n")
+		<-r.Context().Done() // Never ends on its own
+	})
+	defer server.Close()
+
+	stop := make(chan struct{})
+	config := DefaultConfig()
+	config.Events = &EventConfig{
+		OnEvent: func(models.ServerSentEvent) { close(stop) },
+		Stop:    stop,
+	}
+	client, err := NewHttpClient(config)
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	resp, err := client.Send(models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", ""))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(resp.Events) != 1 || resp.Events[0].Data != "tick" {
+		t.Errorf("unexpected events: %+v", resp.Events)
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"io"
+	"net/http"
+)
+
+// DefaultMaxBodyMemory is the part of a streamed body kept in memory when
+// StreamConfig.MaxBodyMemory is not set
+const DefaultMaxBodyMemory = 10 << 20 // 10 MiB
+
+// StreamConfig makes the client write successful (2xx) response bodies to
+// Writer as they arrive instead of buffering them. Only the first
+// MaxBodyMemory bytes are kept in HttpResponse.Body for scripts and display.
+// Other responses, such as error pages, are buffered as usual.
+type StreamConfig struct {
+	Writer        io.Writer
+	MaxBodyMemory int64 // 0 = DefaultMaxBodyMemory
+	// Progress is called as the body arrives with the bytes received so far
+	// and the Content-Length, or -1 if unknown (optional)
+	Progress func(received, total int64)
+}
+
+// shouldStream reports whether the response body should be streamed
+func (s *StreamConfig) shouldStream(resp *http.Response) bool {
+	return s != nil && s.Writer != nil && resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest, while still reporting every write as successful
+type limitedBuffer struct {
+	data      []byte
+	limit     int64
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	room := b.limit - int64(len(b.data))
+	if room < int64(len(p)) {
+		b.truncated = true
+	}
+	if room > 0 {
+		b.data = append(b.data, p[:min(room, int64(len(p)))]...)
+	}
+	return len(p), nil
+}
+
+// progressReader reports the bytes read from the underlying reader
+type progressReader struct {
+	reader   io.Reader
+	received int64
+	total    int64
+	report   func(received, total int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.received += int64(n)
+		r.report(r.received, r.total)
+	}
+	return n, err
+}
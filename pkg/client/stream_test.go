@@ -0,0 +1,104 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+func TestSendStream(t *testing.T) {
+	payload := strings.Repeat("0123456789", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			gz.Write([]byte(payload))
+			gz.Close()
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+		default:
+			w.Write([]byte(payload))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		path          string
+		wantStreamed  bool
+		wantBody      string
+		wantTruncated bool
+	}{
+		{"plain", "/", true, payload[:100], true},
+		{"gzip", "/gzip", true, payload[:100], true},
+		{"error responses are buffered", "/missing", false, "not found", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			var received, total int64
+			config := DefaultConfig()
+			config.Stream = &StreamConfig{
+				Writer:        &out,
+				MaxBodyMemory: 100,
+				Progress: func(r, t int64) {
+					received, total = r, t
+				},
+			}
+			client, err := NewHttpClient(config)
+			if err != nil {
+				t.Fatalf("NewHttpClient() error = %v", err)
+			}
+
+			resp, err := client.Send(models.NewHttpRequest("GET", server.URL+tt.path, map[string]string{}, nil, "", ""))
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if resp.Streamed != tt.wantStreamed || resp.BodyTruncated != tt.wantTruncated {
+				t.Errorf("Streamed = %v, BodyTruncated = %v, want %v, %v", resp.Streamed, resp.BodyTruncated, tt.wantStreamed, tt.wantTruncated)
+			}
+			if resp.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", resp.Body, tt.wantBody)
+			}
+			if !tt.wantStreamed {
+				if out.Len() != 0 || received != 0 {
+					t.Errorf("nothing should be streamed, got %d bytes and progress %d", out.Len(), received)
+				}
+				return
+			}
+
+			if out.String() != payload {
+				t.Errorf("streamed %d bytes, want the full %d byte payload", out.Len(), len(payload))
+			}
+			if resp.BodySizeInBytes != len(payload) {
+				t.Errorf("BodySizeInBytes = %d, want %d", resp.BodySizeInBytes, len(payload))
+			}
+			if received == 0 || (total >= 0 && received != total) {
+				t.Errorf("progress = %d of %d, want all bytes reported", received, total)
+			}
+		})
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	buffer := &limitedBuffer{limit: 5}
+
+	for _, chunk := range []string{"abc", "def", "ghi"} {
+		if n, err := buffer.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v, want %d, nil", chunk, n, err, len(chunk))
+		}
+	}
+
+	if string(buffer.data) != "abcde" || !buffer.truncated {
+		t.Errorf("data = %q, truncated = %v, want \"abcde\", true", buffer.data, buffer.truncated)
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// timingTracer records the phases of a request using net/http/httptrace.
+// Hooks may fire from dialer goroutines, so all fields are guarded by mu.
+// When a request is redirected, the phases describe the final hop while
+// Total covers the whole exchange.
+type timingTracer struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	reused       bool
+}
+
+// newTimingTracer creates a tracer whose clock starts now
+func newTimingTracer() *timingTracer {
+	return &timingTracer{start: time.Now()}
+}
+
+// withContext returns a context that reports request phases to the tracer
+func (t *timingTracer) withContext(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// A new hop (e.g. a redirect) starts; forget the previous one's phases
+			t.dnsStart, t.dnsDone = time.Time{}, time.Time{}
+			t.connectStart, t.connectDone = time.Time{}, time.Time{}
+			t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+			t.gotConn = time.Time{}
+			t.wroteRequest, t.firstByte = time.Time{}, time.Time{}
+			t.reused = false
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.record(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.record(&t.dnsDone)
+		},
+		ConnectStart: func(string, string) {
+			// With multiple addresses (e.g. IPv4 and IPv6) keep the first attempt
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				t.record(&t.connectDone)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.record(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				t.record(&t.tlsDone)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.gotConn = time.Now()
+			t.reused = info.Reused
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.record(&t.wroteRequest)
+		},
+		GotFirstResponseByte: func() {
+			t.record(&t.firstByte)
+		},
+	})
+}
+
+// record stores the current time in the given field
+func (t *timingTracer) record(field *time.Time) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*field = now
+}
+
+// timing computes the phase durations, given the time the body was fully read
+func (t *timingTracer) timing(end time.Time) models.ResponseTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// On a reused connection no DNS, connect or TLS hooks fire, so those
+	// phases are zero
+	return models.ResponseTiming{
+		DNSLookup:        since(t.dnsStart, t.dnsDone),
+		TCPConnection:    since(t.connectStart, t.connectDone),
+		TLSHandshake:     since(t.tlsStart, t.tlsDone),
+		ServerProcessing: since(t.wroteRequest, t.firstByte),
+		ContentTransfer:  since(t.firstByte, end),
+		Total:            end.Sub(t.start),
+		ConnectionReused: t.reused,
+	}
+}
+
+// phase returns the phase the request was in, for reporting timeouts
+func (t *timingTracer) phase() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case !t.firstByte.IsZero():
+		return errors.PhaseBodyRead
+	case !t.wroteRequest.IsZero():
+		return errors.PhaseFirstByte
+	case !t.gotConn.IsZero():
+		return errors.PhaseWrite
+	case !t.tlsStart.IsZero():
+		return errors.PhaseTLS
+	case !t.connectStart.IsZero():
+		return errors.PhaseConnect
+	case !t.dnsStart.IsZero() && t.dnsDone.IsZero():
+		return errors.PhaseDNS
+	default:
+		return errors.PhaseConnect
+	}
+}
+
+// since returns end-start, or zero if either timestamp was not recorded
+func since(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
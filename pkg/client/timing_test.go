@@ -0,0 +1,189 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+func TestSendTiming_Phases(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("first chunk\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte("second chunk\n"))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.InsecureSSL = true
+	client, err := NewHttpClient(config)
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	resp, err := client.Send(models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", ""))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	timing := resp.Timing
+
+	if timing.ConnectionReused {
+		t.Error("first request should not reuse a connection")
+	}
+	if timing.TCPConnection <= 0 {
+		t.Errorf("TCPConnection = %v, want > 0", timing.TCPConnection)
+	}
+	if timing.TLSHandshake <= 0 {
+		t.Errorf("TLSHandshake = %v, want > 0", timing.TLSHandshake)
+	}
+	if timing.ServerProcessing < 20*time.Millisecond {
+		t.Errorf("ServerProcessing = %v, want >= 20ms", timing.ServerProcessing)
+	}
+	if timing.ContentTransfer < 30*time.Millisecond {
+		t.Errorf("ContentTransfer = %v, want >= 30ms (body must be read before Total is set)", timing.ContentTransfer)
+	}
+
+	phases := timing.DNSLookup + timing.TCPConnection + timing.TLSHandshake + timing.ServerProcessing + timing.ContentTransfer
+	if timing.Total < phases {
+		t.Errorf("Total = %v, want >= sum of phases %v", timing.Total, phases)
+	}
+}
+
+func TestSendTiming_ConnectionReuse(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.InsecureSSL = true
+	client, err := NewHttpClient(config)
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	for i := range 2 {
+		resp, err := client.Send(models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", ""))
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+
+		if i == 0 {
+			continue
+		}
+		if !resp.Timing.ConnectionReused {
+			t.Error("second request should reuse the connection")
+		}
+		if resp.Timing.TCPConnection != 0 || resp.Timing.TLSHandshake != 0 || resp.Timing.DNSLookup != 0 {
+			t.Errorf("reused connection should have no DNS/TCP/TLS phases, got %+v", resp.Timing)
+		}
+		if resp.Timing.Total <= 0 {
+			t.Errorf("Total = %v, want > 0", resp.Timing.Total)
+		}
+	}
+}
+
+func TestSendTimeout_Phase(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{
+			name: "slow headers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			},
+			want: errors.PhaseFirstByte,
+		},
+		{
+			name: "slow body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("first chunk\n"))
+				w.(http.Flusher).Flush()
+				time.Sleep(200 * time.Millisecond)
+				w.Write([]byte("second chunk\n"))
+			},
+			want: errors.PhaseBodyRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			config := DefaultConfig()
+			config.Timeout = 50 * time.Millisecond
+			client, err := NewHttpClient(config)
+			if err != nil {
+				t.Fatalf("NewHttpClient() error = %v", err)
+			}
+
+			_, err = client.Send(models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", ""))
+			if !errors.Is(err, errors.ErrTimeout) {
+				t.Fatalf("Send() error = %v, want a timeout", err)
+			}
+
+			var timeoutErr *errors.TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("Send() error = %T, want a *errors.TimeoutError in the chain", err)
+			}
+			if timeoutErr.Phase != tt.want || timeoutErr.Timeout != config.Timeout {
+				t.Errorf("timeout = %q after %v, want %q after %v", timeoutErr.Phase, timeoutErr.Timeout, tt.want, config.Timeout)
+			}
+		})
+	}
+}
+
+func TestTimingTracer_Phase(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		tracer *timingTracer
+		want   string
+	}{
+		{"nothing started", &timingTracer{}, errors.PhaseConnect},
+		{"resolving", &timingTracer{dnsStart: now}, errors.PhaseDNS},
+		{"connecting", &timingTracer{dnsStart: now, dnsDone: now, connectStart: now}, errors.PhaseConnect},
+		{"handshaking", &timingTracer{connectStart: now, connectDone: now, tlsStart: now}, errors.PhaseTLS},
+		{"writing", &timingTracer{gotConn: now}, errors.PhaseWrite},
+		{"waiting", &timingTracer{gotConn: now, wroteRequest: now}, errors.PhaseFirstByte},
+		{"reading", &timingTracer{gotConn: now, wroteRequest: now, firstByte: now}, errors.PhaseBodyRead},
+	}
+
+	for _, tt := range tests {
+		if got := tt.tracer.phase(); got != tt.want {
+			t.Errorf("%s: phase() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSince(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       time.Duration
+	}{
+		{"both set", now, now.Add(5 * time.Millisecond), 5 * time.Millisecond},
+		{"start missing", time.Time{}, now, 0},
+		{"end missing", now, time.Time{}, 0},
+		{"end before start", now, now.Add(-time.Millisecond), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := since(tt.start, tt.end); got != tt.want {
+				t.Errorf("since() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+const (
+	// webSocketHandshakeTimeout bounds the opening handshake
+	webSocketHandshakeTimeout = 45 * time.Second
+	// webSocketCloseTimeout is how long to wait for the server to answer a
+	// close frame before dropping the connection
+	webSocketCloseTimeout = time.Second
+)
+
+// sendWebSocket opens a WebSocket and plays the conversation in the request
+// body: messages are sent in order, and a wait-for-server step waits for the
+// next server message. When the body ends with a wait, the socket is closed
+// right after it; otherwise it stays open until the server closes it, the
+// conversation is stopped or the context is done. The upgrade request
+// carries the same authentication, headers and cookies as an HTTP request.
+func (c *HttpClient) sendWebSocket(ctx context.Context, request *models.HttpRequest) (*models.HttpResponse, error) {
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+
+	handshake, refused, err := c.openWebSocket(ctx, request, nil)
+	if err != nil || refused != nil {
+		return refused, err
+	}
+	defer handshake.conn.Close()
+
+	cfg := c.eventConfig()
+	if cfg.OnOpen != nil {
+		cfg.OnOpen()
+	}
+
+	conversation := newWebSocketConversation(handshake.conn, cfg)
+	defer conversation.stop()
+	if err := conversation.play(ctx, request.WebSocketSteps()); err != nil {
+		return nil, errors.NewRequestErrorWithURL("websocket", request.Method, request.URL, c.timeoutError(handshake.tracer, err))
+	}
+
+	data := make([]string, len(conversation.received))
+	for i, message := range conversation.received {
+		data[i] = message.Data
+	}
+	response := models.NewHttpResponse(handshake.resp, []byte(strings.Join(data, "\n")), handshake.tracer.timing(time.Now()), request)
+	response.Messages = conversation.received
+	return response, nil
+}
+
+// webSocketHandshake is an opened WebSocket
+type webSocketHandshake struct {
+	conn   *websocket.Conn
+	resp   *http.Response // The 101 Switching Protocols response
+	tracer *timingTracer
+}
+
+// openWebSocket performs the opening handshake, offering the given
+// subprotocols. If the server answers without upgrading, e.g. with 401
+// Unauthorized, its response is returned instead so that it can be
+// inspected like any other.
+func (c *HttpClient) openWebSocket(ctx context.Context, request *models.HttpRequest, subprotocols []string) (*webSocketHandshake, *models.HttpResponse, error) {
+	if err := c.prepare(request); err != nil {
+		return nil, nil, err
+	}
+
+	wsURL, err := webSocketURL(request.URL)
+	if err != nil {
+		return nil, nil, errors.NewRequestErrorWithURL("build", request.Method, request.URL, err)
+	}
+
+	header := http.Header{}
+	for k, v := range request.Headers {
+		// The handshake headers are set by the dialer
+		switch http.CanonicalHeaderKey(k) {
+		case "Upgrade", "Connection", "Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions":
+			continue
+		case "Sec-Websocket-Protocol":
+			if len(subprotocols) > 0 {
+				continue
+			}
+		}
+		header.Set(k, v)
+	}
+
+	dialer := c.webSocketDialer()
+	dialer.Subprotocols = subprotocols
+	tracer := newTimingTracer()
+	dialCtx := context.WithValue(tracer.withContext(ctx), certificateHostKey{}, hostOf(wsURL))
+	conn, resp, err := dialer.DialContext(dialCtx, wsURL, header)
+	if err != nil {
+		if resp != nil && errors.Is(err, websocket.ErrBadHandshake) {
+			defer closeResponse(resp)
+			body, _ := io.ReadAll(resp.Body)
+			return nil, models.NewHttpResponse(resp, body, tracer.timing(time.Now()), request), nil
+		}
+		return nil, nil, errors.NewRequestErrorWithURL("send", request.Method, request.URL, c.timeoutError(tracer, err))
+	}
+	return &webSocketHandshake{conn: conn, resp: resp, tracer: tracer}, nil, nil
+}
+
+// eventConfig returns the configured EventConfig, or an empty one
+func (c *HttpClient) eventConfig() *EventConfig {
+	if c.config.Events == nil {
+		return &EventConfig{}
+	}
+	return c.config.Events
+}
+
+// webSocketDialer returns a dialer sharing the transport's proxy, TLS
+// settings, connect timeout and the cookie jar
+func (c *HttpClient) webSocketDialer() *websocket.Dialer {
+	dialer := &websocket.Dialer{
+		Proxy:            c.transport.Proxy,
+		NetDialContext:   c.transport.DialContext,
+		TLSClientConfig:  c.transport.TLSClientConfig,
+		HandshakeTimeout: webSocketHandshakeTimeout,
+	}
+	// A nil *cookiejar.Jar in the interface would not read as nil
+	if c.cookieJar != nil {
+		dialer.Jar = c.cookieJar
+	}
+	return dialer
+}
+
+// webSocketURL returns the ws:// or wss:// URL for a request URL, which may
+// also be given as http:// or https:// with the WEBSOCKET method
+func webSocketURL(urlStr string) (string, error) {
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(parsedURL.Scheme) {
+	case "http":
+		parsedURL.Scheme = "ws"
+	case "https":
+		parsedURL.Scheme = "wss"
+	}
+	return parsedURL.String(), nil
+}
+
+// hostOf returns the host and port of a URL, for certificate lookup
+func hostOf(urlStr string) string {
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return ""
+	}
+	return parsedURL.Host
+}
+
+// webSocketConversation sends and receives the messages of an open
+// WebSocket. A reader goroutine hands received messages to the goroutine
+// playing the conversation, so callbacks never run concurrently.
+type webSocketConversation struct {
+	conn     *websocket.Conn
+	cfg      *EventConfig
+	incoming chan models.WebSocketMessage
+	readErr  chan error
+	done     chan struct{}
+	closeErr *websocket.CloseError // Set when the server closed the connection
+	received []models.WebSocketMessage
+}
+
+// newWebSocketConversation starts reading messages from conn
+func newWebSocketConversation(conn *websocket.Conn, cfg *EventConfig) *webSocketConversation {
+	w := &webSocketConversation{
+		conn:     conn,
+		cfg:      cfg,
+		incoming: make(chan models.WebSocketMessage),
+		readErr:  make(chan error, 1),
+		done:     make(chan struct{}),
+	}
+	go w.read()
+	return w
+}
+
+// read receives messages until the connection fails or closes
+func (w *webSocketConversation) read() {
+	for {
+		kind, data, err := w.conn.ReadMessage()
+		if err != nil {
+			w.readErr <- err
+			return
+		}
+		message := models.WebSocketMessage{Data: string(data), Binary: kind == websocket.BinaryMessage}
+		select {
+		case w.incoming <- message:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// stop ends the reader goroutine
+func (w *webSocketConversation) stop() {
+	close(w.done)
+}
+
+// play runs the conversation steps, then closes the socket. Running out of
+// messages to wait for is not an error: the response holds what arrived.
+func (w *webSocketConversation) play(ctx context.Context, steps []models.WebSocketStep) error {
+	for _, step := range steps {
+		if step.WaitForServer {
+			ok, err := w.wait(ctx)
+			if err != nil || !ok {
+				return err
+			}
+			continue
+		}
+
+		w.drain()
+		if err := w.conn.WriteMessage(websocket.TextMessage, []byte(step.Message)); err != nil {
+			return err
+		}
+		if w.cfg.OnMessage != nil {
+			w.cfg.OnMessage(models.WebSocketMessage{Data: step.Message}, true)
+		}
+	}
+
+	if len(steps) == 0 || !steps[len(steps)-1].WaitForServer {
+		for {
+			ok, err := w.wait(ctx)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+		}
+	}
+
+	w.close()
+	return nil
+}
+
+// wait blocks until the next message is received and records it. It
+// returns false when no more messages will arrive: the server closed the
+// socket or the conversation was stopped.
+func (w *webSocketConversation) wait(ctx context.Context) (bool, error) {
+	message, ok, err := w.next(ctx)
+	if ok {
+		w.receive(message)
+	}
+	return ok, err
+}
+
+// next blocks until the next message is received, without recording it
+func (w *webSocketConversation) next(ctx context.Context) (models.WebSocketMessage, bool, error) {
+	select {
+	case message := <-w.incoming:
+		return message, true, nil
+	case err := <-w.readErr:
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) {
+			w.closeErr = closeErr
+			return models.WebSocketMessage{}, false, nil
+		}
+		return models.WebSocketMessage{}, false, err
+	case <-w.cfg.Stop:
+		return models.WebSocketMessage{}, false, nil
+	case <-ctx.Done():
+		return models.WebSocketMessage{}, false, ctx.Err()
+	}
+}
+
+// drain records messages that already arrived, so they are reported before
+// the next message is sent
+func (w *webSocketConversation) drain() {
+	for {
+		select {
+		case message := <-w.incoming:
+			w.receive(message)
+		default:
+			return
+		}
+	}
+}
+
+// receive records a received message
+func (w *webSocketConversation) receive(message models.WebSocketMessage) {
+	w.received = append(w.received, message)
+	if w.cfg.OnMessage != nil {
+		w.cfg.OnMessage(message, false)
+	}
+}
+
+// close sends a close frame and waits briefly for the server to answer it.
+// Messages arriving meanwhile are dropped.
+func (w *webSocketConversation) close() {
+	if w.closeErr != nil {
+		return
+	}
+	deadline := time.Now().Add(webSocketCloseTimeout)
+	message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	if err := w.conn.WriteControl(websocket.CloseMessage, message, deadline); err != nil {
+		return
+	}
+
+	timer := time.NewTimer(webSocketCloseTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-w.incoming:
+		case <-w.readErr:
+			return
+		case <-timer.C:
+			return
+		}
+	}
+}
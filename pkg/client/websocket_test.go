@@ -0,0 +1,178 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// newWebSocketServer upgrades every request and hands the connection to handler
+func newWebSocketServer(t *testing.T, handler func(conn *websocket.Conn, r *http.Request)) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(conn, r)
+	}))
+}
+
+// wsURL returns the ws:// URL of a test server
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestSendWebSocket(t *testing.T) {
+	var authorization, cookie, custom atomic.Value
+	server := newWebSocketServer(t, func(conn *websocket.Conn, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		custom.Store(r.Header.Get("X-Client"))
+		if c, err := r.Cookie("session"); err == nil {
+			cookie.Store(c.Value)
+		}
+		for {
+			kind, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(kind, append([]byte("echo: "), data...))
+		}
+	})
+	defer server.Close()
+
+	type liveMessage struct {
+		data string
+		sent bool
+	}
+	var live []liveMessage
+	config := DefaultConfig()
+	config.Events = &EventConfig{
+		OnMessage: func(message models.WebSocketMessage, sent bool) {
+			live = append(live, liveMessage{message.Data, sent})
+		},
+	}
+	client, err := NewHttpClient(config)
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+	client.SetCookies(wsURL(server), []*http.Cookie{{Name: "session", Value: "abc"}})
+
+	headers := map[string]string{"Authorization": "Basic user:pass", "X-Client": "restclient"}
+	body := "hello\n=== wait-for-server\nworld\n=== wait-for-server"
+	resp, err := client.Send(models.NewHttpRequest("WEBSOCKET", server.URL, headers, nil, body, ""))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols || !resp.IsWebSocket() {
+		t.Errorf("StatusCode = %d, want 101", resp.StatusCode)
+	}
+	if len(resp.Messages) != 2 || resp.Messages[1].Data != "echo: world" {
+		t.Errorf("unexpected messages: %+v", resp.Messages)
+	}
+	if resp.Body != "echo: hello\necho: world" {
+		t.Errorf("Body = %q", resp.Body)
+	}
+	want := []liveMessage{{"hello", true}, {"echo: hello", false}, {"world", true}, {"echo: world", false}}
+	if len(live) != len(want) {
+		t.Fatalf("live messages = %+v, want %+v", live, want)
+	}
+	for i := range want {
+		if live[i] != want[i] {
+			t.Errorf("live message %d = %+v, want %+v", i, live[i], want[i])
+		}
+	}
+
+	if got, _ := authorization.Load().(string); !strings.HasPrefix(got, "Basic ") || got == "Basic user:pass" {
+		t.Errorf("Authorization = %q, want encoded Basic credentials", got)
+	}
+	if got, _ := cookie.Load().(string); got != "abc" {
+		t.Errorf("session cookie = %q, want \"abc\"", got)
+	}
+	if got, _ := custom.Load().(string); got != "restclient" {
+		t.Errorf("X-Client = %q, want \"restclient\"", got)
+	}
+}
+
+func TestSendWebSocket_ServerCloses(t *testing.T) {
+	server := newWebSocketServer(t, func(conn *websocket.Conn, r *http.Request) {
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"n": 1}`))
+		conn.WriteMessage(websocket.BinaryMessage, []byte{0x01, 0x02})
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "bye"))
+		conn.ReadMessage() // Wait for the client's close frame
+	})
+	defer server.Close()
+
+	client, err := NewHttpClient(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	headers := map[string]string{"Authorization": "Bearer token"}
+	resp, err := client.Send(models.NewHttpRequest("GET", wsURL(server), headers, nil, "", ""))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(resp.Messages) != 2 || resp.Messages[0].Data != `{"n": 1}` || !resp.Messages[1].Binary {
+		t.Errorf("unexpected messages: %+v", resp.Messages)
+	}
+}
+
+func TestSendWebSocket_Rejected(t *testing.T) {
+	server := newWebSocketServer(t, func(*websocket.Conn, *http.Request) {})
+	defer server.Close()
+
+	client, err := NewHttpClient(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	resp, err := client.Send(models.NewHttpRequest("WEBSOCKET", wsURL(server), map[string]string{}, nil, "hi", ""))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized || !strings.Contains(resp.Body, "unauthorized") {
+		t.Errorf("got %d %q, want the 401 response", resp.StatusCode, resp.Body)
+	}
+}
+
+func TestSendWebSocket_Stop(t *testing.T) {
+	server := newWebSocketServer(t, func(conn *websocket.Conn, r *http.Request) {
+		conn.WriteMessage(websocket.TextMessage, []byte("tick"))
+		conn.ReadMessage() // Never ends on its own
+	})
+	defer server.Close()
+
+	stop := make(chan struct{})
+	config := DefaultConfig()
+	config.Events = &EventConfig{
+		OnMessage: func(models.WebSocketMessage, bool) { close(stop) },
+		Stop:      stop,
+	}
+	client, err := NewHttpClient(config)
+	if err != nil {
+		t.Fatalf("NewHttpClient() error = %v", err)
+	}
+
+	headers := map[string]string{"Authorization": "Bearer token"}
+	resp, err := client.Send(models.NewHttpRequest("WEBSOCKET", wsURL(server), headers, nil, "", ""))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(resp.Messages) != 1 || resp.Messages[0].Data != "tick" {
+		t.Errorf("unexpected messages: %+v", resp.Messages)
+	}
+}
@@ -0,0 +1,245 @@
+// Package codegen generates code sending a request with the HTTP client of
+// a language or tool, for bug reports and SDK examples.
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ideaspaper/restclient/internal/constants"
+	"github.com/ideaspaper/restclient/internal/httputil"
+	"github.com/ideaspaper/restclient/pkg/auth"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// generators maps a language name to its generator
+var generators = map[string]func(*request) (string, error){
+	"go":         generateGo,
+	"python":     generatePython,
+	"js-fetch":   generateFetch,
+	"node-axios": generateAxios,
+	"httpie":     generateHTTPie,
+	"powershell": generatePowerShell,
+}
+
+// digestLanguages are the languages whose client answers Digest challenges
+var digestLanguages = map[string]bool{"python": true, "httpie": true, "powershell": true}
+
+// Languages returns the names of the supported languages
+func Languages() []string {
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Generate writes code sending a request in a language. The request should
+// be resolved: variables substituted and scripts run. Authorization is
+// processed as when sending, so Basic credentials are encoded and AWS
+// requests signed; Digest credentials use the client's Digest support.
+func Generate(request *models.HttpRequest, lang string) (string, error) {
+	generate, ok := generators[lang]
+	if !ok {
+		return "", errors.NewValidationErrorWithValue("language", lang, "unsupported, expected one of "+strings.Join(Languages(), ", "))
+	}
+	switch {
+	case request.Method == models.MethodWebSocket || request.Method == models.MethodGRPC:
+		return "", errors.NewValidationErrorWithValue("method", request.Method, "is not supported by codegen")
+	case request.Metadata.GraphQLSubscription:
+		return "", errors.NewValidationError("request", "GraphQL subscriptions are not supported by codegen")
+	}
+
+	req, err := newRequest(request)
+	if err != nil {
+		return "", err
+	}
+	if req.Digest != nil && !digestLanguages[lang] {
+		return "", errors.NewValidationError("request", "Digest authentication is not supported by "+lang+", only by "+strings.Join(slices.Sorted(maps.Keys(digestLanguages)), ", "))
+	}
+	return generate(req)
+}
+
+type bodyKind int
+
+const (
+	noBody bodyKind = iota
+	rawBody
+	jsonBody
+	formBody
+	multipartBody
+	graphQLBody
+)
+
+type header struct {
+	Name, Value string
+}
+
+type field struct {
+	Name, Value string
+}
+
+// request is a request as the generators see it: auth processed, headers
+// sorted and the body classified
+type request struct {
+	Method  string
+	URL     string
+	Headers []header
+	Kind    bodyKind
+	// Body is the text of raw bodies, and of JSON bodies indented with two
+	// spaces
+	Body  string
+	Form  []field
+	Parts []models.MultipartPart
+	// Query, OperationName and Variables are the parts of a GraphQL body,
+	// Variables indented with two spaces
+	Query         string
+	OperationName string
+	Variables     string
+	Digest        *auth.DigestCredentials
+}
+
+func newRequest(source *models.HttpRequest) (*request, error) {
+	clone := source.Clone()
+	processor := auth.NewProcessor()
+	if err := processor.ProcessAuth(clone); err != nil {
+		return nil, err
+	}
+
+	req := &request{Method: clone.Method, URL: clone.URL}
+	if creds, ok := processor.GetDigestCredentials(clone.URL); ok {
+		req.Digest = &creds
+	}
+
+	contentType, _ := httputil.GetHeader(clone.Headers, constants.HeaderContentType)
+	switch {
+	case len(clone.MultipartParts) > 0:
+		req.Kind = multipartBody
+		req.Parts = clone.MultipartParts
+	case clone.RawBody == "":
+	case strings.Contains(strings.ToLower(contentType), constants.MIMEApplicationFormURLEncoded):
+		if form, ok := parseForm(clone.RawBody); ok {
+			req.Kind = formBody
+			req.Form = form
+		} else {
+			req.Kind = rawBody
+			req.Body = clone.RawBody
+		}
+	case parseGraphQL(clone.RawBody, req):
+		req.Kind = graphQLBody
+		if contentType == "" {
+			clone.Headers[constants.HeaderContentType] = constants.MIMEApplicationJSON
+		}
+	case isJSON(contentType) && json.Valid([]byte(clone.RawBody)):
+		req.Kind = jsonBody
+		req.Body = indentJSON(clone.RawBody)
+	default:
+		req.Kind = rawBody
+		req.Body = clone.RawBody
+	}
+
+	for name, value := range clone.Headers {
+		// Clients write the Content-Type of multipart bodies with their boundary
+		if req.Kind == multipartBody && strings.EqualFold(name, constants.HeaderContentType) {
+			continue
+		}
+		req.Headers = append(req.Headers, header{Name: name, Value: value})
+	}
+	slices.SortFunc(req.Headers, func(a, b header) int { return strings.Compare(a.Name, b.Name) })
+	return req, nil
+}
+
+// parseForm splits a form-urlencoded body into its decoded fields, in order
+func parseForm(body string) ([]field, bool) {
+	var form []field
+	for pair := range strings.SplitSeq(body, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(name)
+		if err != nil {
+			return nil, false
+		}
+		value, err = url.QueryUnescape(value)
+		if err != nil {
+			return nil, false
+		}
+		form = append(form, field{Name: name, Value: value})
+	}
+	return form, len(form) > 0
+}
+
+// parseGraphQL reads a GraphQL payload, as the parser builds it from a
+// query and its variables, into req
+func parseGraphQL(body string, req *request) bool {
+	var payload struct {
+		Query         *string         `json:"query"`
+		OperationName string          `json:"operationName"`
+		Variables     json.RawMessage `json:"variables"`
+	}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil || payload.Query == nil {
+		return false
+	}
+	req.Query = strings.TrimSpace(*payload.Query)
+	req.OperationName = payload.OperationName
+	req.Variables = "{}"
+	if len(payload.Variables) > 0 && string(payload.Variables) != "null" {
+		req.Variables = indentJSON(string(payload.Variables))
+	}
+	return true
+}
+
+func isJSON(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.Contains(contentType, "/json") || strings.Contains(contentType, "+json")
+}
+
+// indentJSON indents valid JSON with two spaces
+func indentJSON(text string) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(strings.TrimSpace(text)), "", "  "); err != nil {
+		return text
+	}
+	return out.String()
+}
+
+// quoteJSON quotes a string as a JSON string literal, which is also a
+// valid string literal in Python, JavaScript and Go
+func quoteJSON(s string) string {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// fileName returns the file name a multipart file part is sent with
+func fileName(part models.MultipartPart) string {
+	if part.FileName != "" {
+		return part.FileName
+	}
+	if part.FilePath == "" {
+		return part.Name
+	}
+	return part.FilePath[strings.LastIndexAny(part.FilePath, `/\`)+1:]
+}
+
+// inlineFile reports whether a file part carries its content in Value, as
+// the parser reads files that exist into the body
+func inlineFile(part models.MultipartPart) bool {
+	return part.IsFile && part.FilePath == ""
+}
+
+// indent prefixes every line but the first of text with prefix
+func indent(text, prefix string) string {
+	return strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
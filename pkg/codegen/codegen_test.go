@@ -0,0 +1,216 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/parser"
+)
+
+func parseRequest(t *testing.T, content string) *models.HttpRequest {
+	t.Helper()
+	requests, err := parser.NewHttpRequestParser(content, map[string]string{}, "").ParseAll()
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("ParseAll() = %v, %v", requests, err)
+	}
+	return requests[0]
+}
+
+const (
+	jsonRequest = `POST https://api.example.com/users
+Authorization: Basic user:pass
+Content-Type: application/json
+
+{"name": "O'Brien", "admin": true, "manager": null}
+`
+	formRequest = `POST https://api.example.com/login
+Content-Type: application/x-www-form-urlencoded
+
+user=john
+&tag=a%20b
+&tag=c
+`
+	multipartRequest = `POST https://api.example.com/upload
+Content-Type: multipart/form-data; boundary=----FormBoundary
+
+------FormBoundary
+Content-Disposition: form-data; name="title"
+
+Report
+------FormBoundary
+Content-Disposition: form-data; name="file"; filename="report.pdf"
+Content-Type: application/pdf
+
+< ./report.pdf
+------FormBoundary--
+`
+	graphQLRequest = `POST https://api.example.com/graphql
+X-Request-Type: GraphQL
+
+query GetUser($id: ID!) {
+  user(id: $id) { name }
+}
+
+{"id": 1}
+`
+	digestRequest = `DELETE http://api.example.com/users/1
+Authorization: Digest user pass
+`
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		lang    string
+		request string
+		want    []string
+	}{
+		{"go", jsonRequest, []string{
+			"http.NewRequest(http.MethodPost, \"https://api.example.com/users\", body)",
+			"req.Header.Set(\"Authorization\", \"Basic dXNlcjpwYXNz\")",
+			"\"manager\": null\n}`)",
+		}},
+		{"go", formRequest, []string{"form.Add(\"tag\", \"a b\")\n\tform.Add(\"tag\", \"c\")", "strings.NewReader(form.Encode())"}},
+		{"go", multipartRequest, []string{
+			"writer.WriteField(\"title\", \"Report\")",
+			"addFile(writer, \"file\", \"./report.pdf\", \"report.pdf\", \"application/pdf\")",
+			"req.Header.Set(\"Content-Type\", writer.FormDataContentType())",
+			"func addFile(",
+		}},
+		{"go", graphQLRequest, []string{"query := `query GetUser($id: ID!) {", "\"operationName\": \"GetUser\"", "json.RawMessage(`{\n  \"id\": 1\n}`)"}},
+		{"python", formRequest, []string{"data = [\n    (\"user\", \"john\"),\n    (\"tag\", \"a b\"),\n    (\"tag\", \"c\"),\n]", "data=data"}},
+		{"python", multipartRequest, []string{"\"title\": (None, \"Report\"),", "\"file\": (\"report.pdf\", open(\"./report.pdf\", \"rb\"), \"application/pdf\"),", "files=files"}},
+		{"python", graphQLRequest, []string{"query = \"\"\"query GetUser", "\"variables\": {\n        \"id\": 1,\n    },", "json=payload"}},
+		{"python", digestRequest, []string{"requests.delete(url, auth=HTTPDigestAuth(\"user\", \"pass\"))"}},
+		{"js-fetch", jsonRequest, []string{"method: \"POST\"", "\"Authorization\": \"Basic dXNlcjpwYXNz\",", "body: JSON.stringify({\n    \"name\": \"O'Brien\","}},
+		{"js-fetch", formRequest, []string{"const form = new URLSearchParams();", "form.append(\"tag\", \"a b\");", "body: form,"}},
+		{"js-fetch", multipartRequest, []string{"import { openAsBlob } from \"node:fs\";", "form.append(\"file\", await openAsBlob(\"./report.pdf\", { type: \"application/pdf\" }), \"report.pdf\");"}},
+		{"js-fetch", graphQLRequest, []string{"const query = `query GetUser($id: ID!) {", "\"Content-Type\": \"application/json\"", "body: JSON.stringify({\n    query,\n    operationName: \"GetUser\","}},
+		{"node-axios", jsonRequest, []string{"import axios from \"axios\";", "method: \"post\"", "data: {\n    \"name\": \"O'Brien\","}},
+		{"node-axios", multipartRequest, []string{"const form = new FormData();", "data: form,"}},
+		{"httpie", jsonRequest, []string{"http POST https://api.example.com/users \\\n", "'Authorization:Basic dXNlcjpwYXNz'", "--raw '{\n  \"name\": \"O'\\''Brien\","}},
+		{"httpie", formRequest, []string{"http --form POST https://api.example.com/login", "'tag=a b'"}},
+		{"httpie", multipartRequest, []string{"http --multipart POST", "title=Report", "'file@./report.pdf;type=application/pdf'"}},
+		{"httpie", graphQLRequest, []string{"operationName=GetUser", "'variables:={\n  \"id\": 1\n}'"}},
+		{"httpie", digestRequest, []string{"--auth-type=digest --auth user:pass"}},
+		{"powershell", jsonRequest, []string{"-Method Post", "-ContentType 'application/json'", "$body = @'\n{\n  \"name\": \"O'Brien\","}},
+		{"powershell", formRequest, []string{"$body = [ordered]@{\n    'user' = 'john'"}},
+		{"powershell", multipartRequest, []string{"'file' = Get-Item -Path './report.pdf'", "-Form $form"}},
+		{"powershell", digestRequest, []string{"-Method Delete", "[pscredential]::new('user', $password)", "-AllowUnencryptedAuthentication"}},
+	}
+
+	for _, tt := range tests {
+		request := parseRequest(t, tt.request)
+		got, err := Generate(request, tt.lang)
+		if err != nil {
+			t.Errorf("Generate(%s) error = %v", tt.lang, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("Generate(%s) should contain %q\ngot:\n%s", tt.lang, want, got)
+			}
+		}
+		// The request of the caller is left as it was
+		if strings.HasPrefix(request.Headers["Authorization"], "Basic dX") {
+			t.Errorf("Generate(%s) changed the request", tt.lang)
+		}
+	}
+}
+
+func TestGenerate_InlinedFile(t *testing.T) {
+	// The parser reads files that exist into the body, leaving no path
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content := `POST https://api.example.com/upload
+Content-Type: multipart/form-data; boundary=----FormBoundary
+
+------FormBoundary
+Content-Disposition: form-data; name="f"; filename="a.txt"
+Content-Type: text/plain
+
+< ./a.txt
+------FormBoundary--
+`
+	requests, err := parser.NewHttpRequestParser(content, map[string]string{}, dir).ParseAll()
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("ParseAll() = %v, %v", requests, err)
+	}
+	if part := requests[0].MultipartParts[0]; part.FilePath != "" || part.Value != "hello" {
+		t.Fatalf("part = %+v, want the file content inlined", part)
+	}
+
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{"go", []string{"addPart(writer, \"f\", \"a.txt\", \"text/plain\", strings.NewReader(\"hello\"))", "func addPart("}},
+		{"python", []string{"\"f\": (\"a.txt\", \"hello\", \"text/plain\"),"}},
+		{"js-fetch", []string{"form.append(\"f\", new Blob([\"hello\"], { type: \"text/plain\" }), \"a.txt\");"}},
+		{"node-axios", []string{"new Blob([\"hello\"]"}},
+		{"httpie", []string{"printf '%s' hello > \"${TMPDIR:-/tmp}\"/a.txt\n", "f@\"${TMPDIR:-/tmp}\"/a.txt';type=text/plain'"}},
+		{"powershell", []string{"$file1 = Join-Path ([System.IO.Path]::GetTempPath()) 'a.txt'", "Set-Content -Path $file1 -Value 'hello' -NoNewline", "'f' = Get-Item -Path $file1"}},
+	}
+	for _, tt := range tests {
+		got, err := Generate(requests[0], tt.lang)
+		if err != nil {
+			t.Errorf("Generate(%s) error = %v", tt.lang, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("Generate(%s) should contain %q\ngot:\n%s", tt.lang, want, got)
+			}
+		}
+		for _, unwanted := range []string{"open(\"\"", "openAsBlob", "func addFile(", "f@'", "-Path ''"} {
+			if strings.Contains(got, unwanted) {
+				t.Errorf("Generate(%s) should not refer to an empty path (%q)\ngot:\n%s", tt.lang, unwanted, got)
+			}
+		}
+	}
+}
+
+func TestGeneratePython(t *testing.T) {
+	got, err := Generate(parseRequest(t, jsonRequest), "python")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `import requests
+
+url = "https://api.example.com/users"
+headers = {
+    "Authorization": "Basic dXNlcjpwYXNz",
+    "Content-Type": "application/json",
+}
+payload = {
+    "name": "O'Brien",
+    "admin": True,
+    "manager": None,
+}
+
+response = requests.post(url, headers=headers, json=payload)
+print(response.status_code)
+print(response.text)
+`
+	if got != want {
+		t.Errorf("Generate() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	if _, err := Generate(parseRequest(t, jsonRequest), "cobol"); err == nil {
+		t.Error("unknown languages should fail")
+	}
+	for _, lang := range []string{"go", "js-fetch", "node-axios"} {
+		if _, err := Generate(parseRequest(t, digestRequest), lang); err == nil {
+			t.Errorf("Digest authentication should fail for %s", lang)
+		}
+	}
+	if _, err := Generate(&models.HttpRequest{Method: models.MethodWebSocket, URL: "wss://example.com"}, "go"); err == nil {
+		t.Error("WebSocket requests should fail")
+	}
+}
@@ -0,0 +1,162 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// goMethods maps methods to their net/http constants
+var goMethods = map[string]string{
+	http.MethodGet: "MethodGet", http.MethodHead: "MethodHead", http.MethodPost: "MethodPost",
+	http.MethodPut: "MethodPut", http.MethodPatch: "MethodPatch", http.MethodDelete: "MethodDelete",
+	http.MethodConnect: "MethodConnect", http.MethodOptions: "MethodOptions", http.MethodTrace: "MethodTrace",
+}
+
+const goAddFile = `
+// addFile adds a file to a multipart form
+func addFile(writer *multipart.Writer, field, path, filename, contentType string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return addPart(writer, field, filename, contentType, file)
+}
+`
+
+const goAddPart = `
+// addPart adds file content to a multipart form
+func addPart(writer *multipart.Writer, field, filename, contentType string, content io.Reader) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf("form-data; name=%q; filename=%q", field, filename))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, content)
+	return err
+}
+`
+
+// generateGo writes a program using net/http
+func generateGo(req *request) (string, error) {
+	imports := map[string]bool{"fmt": true, "io": true, "log": true, "net/http": true}
+	var main strings.Builder
+	check := "\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n"
+
+	bodyArg := "nil"
+	// hasFiles is set by parts read from a path, hasParts by all file parts
+	hasFiles, hasParts := false, false
+	switch req.Kind {
+	case rawBody, jsonBody:
+		imports["strings"] = true
+		fmt.Fprintf(&main, "\tbody := strings.NewReader(%s)\n\n", goString(req.Body))
+		bodyArg = "body"
+	case formBody:
+		imports["net/url"] = true
+		imports["strings"] = true
+		main.WriteString("\tform := url.Values{}\n")
+		for _, f := range req.Form {
+			fmt.Fprintf(&main, "\tform.Add(%s, %s)\n", goString(f.Name), goString(f.Value))
+		}
+		main.WriteString("\tbody := strings.NewReader(form.Encode())\n\n")
+		bodyArg = "body"
+	case multipartBody:
+		imports["bytes"] = true
+		imports["mime/multipart"] = true
+		main.WriteString("\tbody := &bytes.Buffer{}\n\twriter := multipart.NewWriter(body)\n")
+		for _, part := range req.Parts {
+			if part.IsFile {
+				hasParts = true
+				contentType := part.ContentType
+				if contentType == "" {
+					contentType = "application/octet-stream"
+				}
+				if inlineFile(part) {
+					imports["strings"] = true
+					fmt.Fprintf(&main, "\tif err := addPart(writer, %s, %s, %s, strings.NewReader(%s)); err != nil {\n\t\tlog.Fatal(err)\n\t}\n",
+						goString(part.Name), goString(fileName(part)), goString(contentType), goString(part.Value))
+				} else {
+					hasFiles = true
+					fmt.Fprintf(&main, "\tif err := addFile(writer, %s, %s, %s, %s); err != nil {\n\t\tlog.Fatal(err)\n\t}\n",
+						goString(part.Name), goString(part.FilePath), goString(fileName(part)), goString(contentType))
+				}
+			} else {
+				fmt.Fprintf(&main, "\tif err := writer.WriteField(%s, %s); err != nil {\n\t\tlog.Fatal(err)\n\t}\n", goString(part.Name), goString(part.Value))
+			}
+		}
+		main.WriteString("\tif err := writer.Close(); err != nil {\n\t\tlog.Fatal(err)\n\t}\n\n")
+		bodyArg = "body"
+	case graphQLBody:
+		imports["bytes"] = true
+		imports["encoding/json"] = true
+		fmt.Fprintf(&main, "\tquery := %s\n", goString(req.Query))
+		main.WriteString("\tpayload, err := json.Marshal(map[string]any{\n\t\t\"query\": query,\n")
+		if req.OperationName != "" {
+			fmt.Fprintf(&main, "\t\t\"operationName\": %s,\n", goString(req.OperationName))
+		}
+		fmt.Fprintf(&main, "\t\t\"variables\": json.RawMessage(%s),\n\t})\n%s", goString(req.Variables), check)
+		main.WriteString("\tbody := bytes.NewReader(payload)\n\n")
+		bodyArg = "body"
+	}
+	if hasFiles {
+		imports["os"] = true
+	}
+	if hasParts {
+		imports["net/textproto"] = true
+	}
+
+	method := strconv.Quote(req.Method)
+	if constant, ok := goMethods[req.Method]; ok {
+		method = "http." + constant
+	}
+	fmt.Fprintf(&main, "\treq, err := http.NewRequest(%s, %s, %s)\n%s", method, goString(req.URL), bodyArg, check)
+	for _, h := range req.Headers {
+		fmt.Fprintf(&main, "\treq.Header.Set(%s, %s)\n", goString(h.Name), goString(h.Value))
+	}
+	if req.Kind == multipartBody {
+		main.WriteString("\treq.Header.Set(\"Content-Type\", writer.FormDataContentType())\n")
+	}
+	main.WriteString("\n\tresp, err := http.DefaultClient.Do(req)\n" + check + "\tdefer resp.Body.Close()\n\n")
+	main.WriteString("\trespBody, err := io.ReadAll(resp.Body)\n" + check)
+	main.WriteString("\tfmt.Println(resp.Status)\n\tfmt.Println(string(respBody))\n")
+
+	var program strings.Builder
+	program.WriteString("package main\n\nimport (\n")
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&program, "\t%q\n", path)
+	}
+	program.WriteString(")\n\nfunc main() {\n" + main.String() + "}\n")
+	if hasFiles {
+		program.WriteString(goAddFile)
+	}
+	if hasParts {
+		program.WriteString(goAddPart)
+	}
+
+	formatted, err := format.Source([]byte(program.String()))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to format Go code")
+	}
+	return string(formatted), nil
+}
+
+// goString writes a Go string literal, raw when that is more readable
+func goString(s string) string {
+	if strings.Contains(s, "\n") && !strings.ContainsAny(s, "`\r") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
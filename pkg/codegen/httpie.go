@@ -0,0 +1,105 @@
+package codegen
+
+import (
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// generateHTTPie writes an HTTPie command
+func generateHTTPie(req *request) (string, error) {
+	command := "http"
+	switch req.Kind {
+	case formBody:
+		command += " --form"
+	case multipartBody:
+		command += " --multipart"
+	}
+	command += " " + req.Method + " " + shellQuote(req.URL)
+
+	// HTTPie reads file parts from a path, so files the parser read into the
+	// body are written to the temporary directory first
+	var preamble strings.Builder
+	for _, part := range req.Parts {
+		if inlineFile(part) {
+			preamble.WriteString("printf '%s' " + shellQuote(part.Value) + " > " + httpieTempPath(part) + "\n")
+		}
+	}
+
+	args := []string{command}
+	if req.Digest != nil {
+		args = append(args, "--auth-type=digest --auth "+shellQuote(req.Digest.Username+":"+req.Digest.Password))
+	}
+
+	for _, h := range req.Headers {
+		if h.Value == "" {
+			args = append(args, shellQuote(httpieKey(h.Name)+";"))
+		} else {
+			args = append(args, shellQuote(httpieKey(h.Name)+":"+h.Value))
+		}
+	}
+
+	switch req.Kind {
+	case formBody:
+		for _, f := range req.Form {
+			args = append(args, shellQuote(httpieKey(f.Name)+"="+f.Value))
+		}
+	case multipartBody:
+		for _, part := range req.Parts {
+			if !part.IsFile {
+				args = append(args, shellQuote(httpieKey(part.Name)+"="+part.Value))
+				continue
+			}
+			typ := ""
+			if part.ContentType != "" {
+				typ = ";type=" + part.ContentType
+			}
+			if inlineFile(part) {
+				args = append(args, shellQuote(httpieKey(part.Name)+"@")+httpieTempPath(part)+shellQuote(typ))
+				continue
+			}
+			args = append(args, shellQuote(httpieKey(part.Name)+"@"+part.FilePath+typ))
+		}
+	case graphQLBody:
+		args = append(args, shellQuote("query="+req.Query))
+		if req.OperationName != "" {
+			args = append(args, shellQuote("operationName="+req.OperationName))
+		}
+		args = append(args, shellQuote("variables:="+req.Variables))
+	case rawBody, jsonBody:
+		args = append(args, "--raw "+shellQuote(req.Body))
+	}
+	return preamble.String() + strings.Join(args, " \\\n  ") + "\n", nil
+}
+
+// httpieTempPath returns the shell path an inline file part is written to
+func httpieTempPath(part models.MultipartPart) string {
+	return `"${TMPDIR:-/tmp}"/` + shellQuote(path.Base(fileName(part)))
+}
+
+// httpieKey escapes the characters HTTPie reads as item separators
+func httpieKey(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if strings.ContainsRune(`\:=@;`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// shellQuote quotes an argument for a POSIX shell when it needs quoting
+func shellQuote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	for _, r := range arg {
+		if r >= utf8.RuneSelf || !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@,+%", r)) {
+			return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return arg
+}
@@ -0,0 +1,155 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// generateFetch writes an ES module using fetch, as in Node.js 20 and
+// browsers
+func generateFetch(req *request) (string, error) {
+	var b strings.Builder
+	if hasFileParts(req) {
+		b.WriteString("import { openAsBlob } from \"node:fs\";\n\n")
+	}
+	body := writeJSBody(&b, req, true)
+
+	fmt.Fprintf(&b, "const response = await fetch(%s", quoteJSON(req.URL))
+	var options []string
+	if req.Method != "GET" {
+		options = append(options, "method: "+quoteJSON(req.Method))
+	}
+	if len(req.Headers) > 0 {
+		options = append(options, "headers: "+jsHeaders(req.Headers))
+	}
+	if body != "" {
+		options = append(options, "body: "+body)
+	}
+	if len(options) > 0 {
+		b.WriteString(", {\n")
+		for _, option := range options {
+			fmt.Fprintf(&b, "  %s,\n", indent(option, "  "))
+		}
+		b.WriteString("}")
+	}
+	b.WriteString(");\n\nconsole.log(response.status);\nconsole.log(await response.text());\n")
+	return b.String(), nil
+}
+
+// generateAxios writes an ES module using axios
+func generateAxios(req *request) (string, error) {
+	var b strings.Builder
+	if hasFileParts(req) {
+		b.WriteString("import { openAsBlob } from \"node:fs\";\n")
+	}
+	b.WriteString("import axios from \"axios\";\n\n")
+	data := writeJSBody(&b, req, false)
+
+	options := []string{
+		"method: " + quoteJSON(strings.ToLower(req.Method)),
+		"url: " + quoteJSON(req.URL),
+	}
+	if len(req.Headers) > 0 {
+		options = append(options, "headers: "+jsHeaders(req.Headers))
+	}
+	if data != "" {
+		options = append(options, "data: "+data)
+	}
+	b.WriteString("const response = await axios({\n")
+	for _, option := range options {
+		fmt.Fprintf(&b, "  %s,\n", indent(option, "  "))
+	}
+	b.WriteString("});\n\nconsole.log(response.status);\nconsole.log(response.data);\n")
+	return b.String(), nil
+}
+
+// writeJSBody writes the statements building the body and returns the
+// expression sending it. fetch needs JSON serialized, while axios
+// serializes objects itself.
+func writeJSBody(b *strings.Builder, req *request, stringify bool) string {
+	switch req.Kind {
+	case rawBody:
+		return jsString(req.Body)
+	case jsonBody:
+		if stringify {
+			return "JSON.stringify(" + req.Body + ")"
+		}
+		return req.Body
+	case formBody:
+		b.WriteString("const form = new URLSearchParams();\n")
+		for _, f := range req.Form {
+			fmt.Fprintf(b, "form.append(%s, %s);\n", quoteJSON(f.Name), quoteJSON(f.Value))
+		}
+		b.WriteString("\n")
+		return "form"
+	case multipartBody:
+		b.WriteString("const form = new FormData();\n")
+		for _, part := range req.Parts {
+			if !part.IsFile {
+				fmt.Fprintf(b, "form.append(%s, %s);\n", quoteJSON(part.Name), quoteJSON(part.Value))
+				continue
+			}
+			options := ""
+			if part.ContentType != "" {
+				options = ", { type: " + quoteJSON(part.ContentType) + " }"
+			}
+			blob := fmt.Sprintf("await openAsBlob(%s%s)", quoteJSON(part.FilePath), options)
+			if inlineFile(part) {
+				blob = fmt.Sprintf("new Blob([%s]%s)", quoteJSON(part.Value), options)
+			}
+			fmt.Fprintf(b, "form.append(%s, %s, %s);\n", quoteJSON(part.Name), blob, quoteJSON(fileName(part)))
+		}
+		b.WriteString("\n")
+		return "form"
+	case graphQLBody:
+		fmt.Fprintf(b, "const query = %s;\n\n", jsTemplate(req.Query))
+		payload := "{\n  query,\n"
+		if req.OperationName != "" {
+			payload += "  operationName: " + quoteJSON(req.OperationName) + ",\n"
+		}
+		payload += "  variables: " + indent(req.Variables, "  ") + ",\n}"
+		if stringify {
+			return "JSON.stringify(" + payload + ")"
+		}
+		return payload
+	}
+	return ""
+}
+
+// hasFileParts reports whether a request has file parts read from a path
+func hasFileParts(req *request) bool {
+	for _, part := range req.Parts {
+		if part.IsFile && !inlineFile(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsHeaders writes headers as an object literal
+func jsHeaders(headers []header) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, h := range headers {
+		fmt.Fprintf(&b, "  %s: %s,\n", quoteJSON(h.Name), quoteJSON(h.Value))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// jsString writes a JavaScript string literal, a template literal for
+// multi-line text
+func jsString(s string) string {
+	if strings.Contains(s, "\n") && !strings.Contains(s, "\r") {
+		return jsTemplate(s)
+	}
+	return quoteJSON(s)
+}
+
+// jsTemplate writes a template literal
+func jsTemplate(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "`", "\\`")
+	s = strings.ReplaceAll(s, "${", "\\${")
+	return "`" + s + "`"
+}
@@ -0,0 +1,120 @@
+package codegen
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// powerShellMethods are the methods -Method accepts, in its spelling
+var powerShellMethods = map[string]string{
+	"GET": "Get", "HEAD": "Head", "POST": "Post", "PUT": "Put", "PATCH": "Patch",
+	"DELETE": "Delete", "OPTIONS": "Options", "TRACE": "Trace", "MERGE": "Merge",
+}
+
+// generatePowerShell writes a script using Invoke-WebRequest, as in
+// PowerShell 7
+func generatePowerShell(req *request) (string, error) {
+	var b strings.Builder
+	args := []string{"-Uri " + psString(req.URL)}
+	if method, ok := powerShellMethods[req.Method]; ok {
+		args = append(args, "-Method "+method)
+	} else {
+		args = append(args, "-CustomMethod "+psString(req.Method))
+	}
+
+	// Invoke-WebRequest takes the Content-Type of a body as a parameter
+	contentType := ""
+	var headers []header
+	for _, h := range req.Headers {
+		if strings.EqualFold(h.Name, "Content-Type") && req.Kind != noBody {
+			contentType = h.Value
+			continue
+		}
+		headers = append(headers, h)
+	}
+	if len(headers) > 0 {
+		b.WriteString("$headers = @{\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "    %s = %s\n", psString(h.Name), psString(h.Value))
+		}
+		b.WriteString("}\n")
+		args = append(args, "-Headers $headers")
+	}
+	if contentType != "" {
+		args = append(args, "-ContentType "+psString(contentType))
+	}
+
+	switch req.Kind {
+	case rawBody, jsonBody:
+		fmt.Fprintf(&b, "$body = %s\n", psHereString(req.Body))
+		args = append(args, "-Body $body")
+	case formBody:
+		b.WriteString("$body = [ordered]@{\n")
+		for _, f := range req.Form {
+			fmt.Fprintf(&b, "    %s = %s\n", psString(f.Name), psString(f.Value))
+		}
+		b.WriteString("}\n")
+		args = append(args, "-Body $body")
+	case multipartBody:
+		// -Form reads files from a path, so files the parser read into the
+		// body are written to the temporary directory first
+		files := make([]string, len(req.Parts))
+		for i, part := range req.Parts {
+			if inlineFile(part) {
+				files[i] = fmt.Sprintf("$file%d", i+1)
+				fmt.Fprintf(&b, "%s = Join-Path ([System.IO.Path]::GetTempPath()) %s\n", files[i], psString(path.Base(fileName(part))))
+				fmt.Fprintf(&b, "Set-Content -Path %s -Value %s -NoNewline\n", files[i], psString(part.Value))
+			} else if part.IsFile {
+				files[i] = psString(part.FilePath)
+			}
+		}
+		b.WriteString("$form = [ordered]@{\n")
+		for i, part := range req.Parts {
+			if part.IsFile {
+				fmt.Fprintf(&b, "    %s = Get-Item -Path %s\n", psString(part.Name), files[i])
+			} else {
+				fmt.Fprintf(&b, "    %s = %s\n", psString(part.Name), psString(part.Value))
+			}
+		}
+		b.WriteString("}\n")
+		args = append(args, "-Form $form")
+	case graphQLBody:
+		fmt.Fprintf(&b, "$query = %s\n", psHereString(req.Query))
+		b.WriteString("$body = [ordered]@{\n    query = $query\n")
+		if req.OperationName != "" {
+			fmt.Fprintf(&b, "    operationName = %s\n", psString(req.OperationName))
+		}
+		fmt.Fprintf(&b, "    variables = %s | ConvertFrom-Json\n} | ConvertTo-Json -Depth 100\n", psHereString(req.Variables))
+		args = append(args, "-Body $body")
+	}
+
+	if req.Digest != nil {
+		fmt.Fprintf(&b, "$password = ConvertTo-SecureString %s -AsPlainText -Force\n", psString(req.Digest.Password))
+		fmt.Fprintf(&b, "$credential = [pscredential]::new(%s, $password)\n", psString(req.Digest.Username))
+		args = append(args, "-Credential $credential")
+		if strings.HasPrefix(req.URL, "http:") {
+			args = append(args, "-AllowUnencryptedAuthentication")
+		}
+	}
+
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "$response = Invoke-WebRequest %s\n", strings.Join(args, " `\n    "))
+	b.WriteString("$response.StatusCode\n$response.Content\n")
+	return b.String(), nil
+}
+
+// psString writes a single-quoted PowerShell string, which expands nothing
+func psString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// psHereString writes a literal here-string for multi-line text
+func psHereString(s string) string {
+	if !strings.Contains(s, "\n") || strings.Contains(s, "\n'@") {
+		return psString(s)
+	}
+	return "@'\n" + s + "\n'@"
+}
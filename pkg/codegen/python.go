@@ -0,0 +1,197 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// pythonMethods are the methods with a function of their own in requests
+var pythonMethods = map[string]bool{"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true}
+
+// generatePython writes a script using requests
+func generatePython(req *request) (string, error) {
+	var b strings.Builder
+	b.WriteString("import requests\n")
+	if req.Digest != nil {
+		b.WriteString("from requests.auth import HTTPDigestAuth\n")
+	}
+	fmt.Fprintf(&b, "\nurl = %s\n", quoteJSON(req.URL))
+
+	args := []string{"url"}
+	if len(req.Headers) > 0 {
+		b.WriteString("headers = {\n")
+		for _, h := range req.Headers {
+			fmt.Fprintf(&b, "    %s: %s,\n", quoteJSON(h.Name), quoteJSON(h.Value))
+		}
+		b.WriteString("}\n")
+		args = append(args, "headers=headers")
+	}
+
+	switch req.Kind {
+	case rawBody:
+		fmt.Fprintf(&b, "data = %s\n", pythonString(req.Body))
+		args = append(args, "data=data")
+	case jsonBody:
+		literal, err := pythonLiteral(req.Body)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "payload = %s\n", literal)
+		args = append(args, "json=payload")
+	case formBody:
+		items := make([]field, len(req.Form))
+		for i, f := range req.Form {
+			items[i] = field{Name: quoteJSON(f.Name), Value: quoteJSON(f.Value)}
+		}
+		fmt.Fprintf(&b, "data = %s\n", pythonItems(items))
+		args = append(args, "data=data")
+	case multipartBody:
+		items := make([]field, len(req.Parts))
+		for i, part := range req.Parts {
+			var value string
+			switch {
+			case part.IsFile:
+				content := fmt.Sprintf("open(%s, \"rb\")", quoteJSON(part.FilePath))
+				if inlineFile(part) {
+					content = quoteJSON(part.Value)
+				}
+				if part.ContentType != "" {
+					value = fmt.Sprintf("(%s, %s, %s)", quoteJSON(fileName(part)), content, quoteJSON(part.ContentType))
+				} else {
+					value = fmt.Sprintf("(%s, %s)", quoteJSON(fileName(part)), content)
+				}
+			default:
+				// A file name of None sends the part as a plain field
+				value = fmt.Sprintf("(None, %s)", quoteJSON(part.Value))
+			}
+			items[i] = field{Name: quoteJSON(part.Name), Value: value}
+		}
+		fmt.Fprintf(&b, "files = %s\n", pythonItems(items))
+		args = append(args, "files=files")
+	case graphQLBody:
+		variables, err := pythonLiteral(req.Variables)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "query = %s\n", pythonString(req.Query))
+		b.WriteString("payload = {\n    \"query\": query,\n")
+		if req.OperationName != "" {
+			fmt.Fprintf(&b, "    \"operationName\": %s,\n", quoteJSON(req.OperationName))
+		}
+		fmt.Fprintf(&b, "    \"variables\": %s,\n}\n", indent(variables, "    "))
+		args = append(args, "json=payload")
+	}
+	if req.Digest != nil {
+		args = append(args, fmt.Sprintf("auth=HTTPDigestAuth(%s, %s)", quoteJSON(req.Digest.Username), quoteJSON(req.Digest.Password)))
+	}
+
+	call := "requests.request(" + quoteJSON(req.Method) + ", "
+	if pythonMethods[req.Method] {
+		call = "requests." + strings.ToLower(req.Method) + "("
+	}
+	fmt.Fprintf(&b, "\nresponse = %s%s)\n", call, strings.Join(args, ", "))
+	b.WriteString("print(response.status_code)\nprint(response.text)\n")
+	return b.String(), nil
+}
+
+// pythonItems writes fields as a dict, or as a list of tuples when a name
+// repeats
+func pythonItems(items []field) string {
+	seen := make(map[string]bool)
+	unique := true
+	for _, item := range items {
+		unique = unique && !seen[item.Name]
+		seen[item.Name] = true
+	}
+
+	var b strings.Builder
+	if unique {
+		b.WriteString("{\n")
+		for _, item := range items {
+			fmt.Fprintf(&b, "    %s: %s,\n", item.Name, item.Value)
+		}
+		b.WriteString("}")
+	} else {
+		b.WriteString("[\n")
+		for _, item := range items {
+			fmt.Fprintf(&b, "    (%s, %s),\n", item.Name, item.Value)
+		}
+		b.WriteString("]")
+	}
+	return b.String()
+}
+
+// pythonString writes a Python string literal, triple-quoted for readable
+// multi-line text
+func pythonString(s string) string {
+	if strings.Contains(s, "\n") && !strings.Contains(s, `"""`) && !strings.ContainsAny(s, "\\\r") && !strings.HasSuffix(s, `"`) {
+		return `"""` + s + `"""`
+	}
+	return quoteJSON(s)
+}
+
+// pythonLiteral converts JSON to a Python literal, keeping the order of
+// object keys
+func pythonLiteral(text string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var b strings.Builder
+	if err := writePythonValue(&b, decoder, ""); err != nil {
+		return "", errors.Wrap(err, "invalid JSON body")
+	}
+	return b.String(), nil
+}
+
+func writePythonValue(b *strings.Builder, decoder *json.Decoder, prefix string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch value := token.(type) {
+	case json.Delim:
+		end := "]"
+		if value == '{' {
+			end = "}"
+		}
+		if !decoder.More() {
+			_, err := decoder.Token()
+			b.WriteString(string(value) + end)
+			return err
+		}
+		b.WriteString(string(value) + "\n")
+		for decoder.More() {
+			b.WriteString(prefix + "    ")
+			if value == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				b.WriteString(quoteJSON(key.(string)) + ": ")
+			}
+			if err := writePythonValue(b, decoder, prefix+"    "); err != nil {
+				return err
+			}
+			b.WriteString(",\n")
+		}
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+		b.WriteString(prefix + end)
+	case string:
+		b.WriteString(quoteJSON(value))
+	case json.Number:
+		b.WriteString(value.String())
+	case bool:
+		if value {
+			b.WriteString("True")
+		} else {
+			b.WriteString("False")
+		}
+	case nil:
+		b.WriteString("None")
+	}
+	return nil
+}
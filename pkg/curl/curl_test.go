@@ -0,0 +1,254 @@
+package curl
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/parser"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{`curl https://example.com`, []string{"curl", "https://example.com"}},
+		{"curl 'a b' \"c \\\"d\\\" $e\" f\\ g", []string{"curl", "a b", `c "d" $e`, "f g"}},
+		{"curl \\\n  -H 'X: 1' \\\r\n  url", []string{"curl", "-H", "X: 1", "url"}},
+		{`curl --data-raw $'{"a":"it\'s\n\u00e9"}'`, []string{"curl", "--data-raw", "{\"a\":\"it's\n\u00e9\"}"}},
+		{`curl ''`, []string{"curl", ""}},
+	}
+
+	for _, tt := range tests {
+		got, err := splitArgs(tt.input)
+		if err != nil {
+			t.Errorf("splitArgs(%q) error = %v", tt.input, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{`curl 'open`, `curl "open`, `curl $'open`} {
+		if _, err := splitArgs(input); err == nil {
+			t.Errorf("splitArgs(%q) should fail", input)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{
+			name:    "get",
+			command: `curl https://api.example.com/users`,
+			want:    "GET https://api.example.com/users\n",
+		},
+		{
+			name: "devtools json post",
+			command: `curl 'https://api.example.com/users' \
+  -H 'accept: application/json' \
+  -H 'content-type: application/json' \
+  -b 'session=abc; theme=dark' \
+  --data-raw '{"name":"John"}' \
+  --compressed`,
+			want: "POST https://api.example.com/users\naccept: application/json\ncontent-type: application/json\nCookie: session=abc; theme=dark\nAccept-Encoding: gzip\n\n{\"name\":\"John\"}\n",
+		},
+		{
+			name:    "form data and basic auth",
+			command: `curl -sSL -u admin:s3cret -d name=John -d age=42 https://api.example.com/users`,
+			want:    "POST https://api.example.com/users\nAuthorization: Basic admin:s3cret\nContent-Type: application/x-www-form-urlencoded\n\nname=John&age=42\n",
+		},
+		{
+			name:    "explicit method and digest",
+			command: `curl -XPUT --digest --user admin:pw https://api.example.com/items/1 --data-binary @item.json -H "Content-Type: application/json"`,
+			want:    "PUT https://api.example.com/items/1\nContent-Type: application/json\nAuthorization: Digest admin pw\n\n< item.json\n",
+		},
+		{
+			name:    "get with data",
+			command: `curl -G https://api.example.com/search -d q=go --data-urlencode 'tag=a b'`,
+			want:    "GET https://api.example.com/search?q=go&tag=a+b\n",
+		},
+		{
+			name:    "multipart",
+			command: `curl -F title=Report -F 'file=@./report.pdf;type=application/pdf' -F 'avatar=@me.png;filename=avatar.png' https://api.example.com/upload`,
+			want: "POST https://api.example.com/upload\nContent-Type: multipart/form-data; boundary=----FormBoundary\n\n" +
+				"------FormBoundary\nContent-Disposition: form-data; name=\"title\"\n\nReport\n" +
+				"------FormBoundary\nContent-Disposition: form-data; name=\"file\"; filename=\"report.pdf\"\nContent-Type: application/pdf\n\n< ./report.pdf\n" +
+				"------FormBoundary\nContent-Disposition: form-data; name=\"avatar\"; filename=\"avatar.png\"\n\n< me.png\n" +
+				"------FormBoundary--\n",
+		},
+		{
+			name:    "json and timeouts",
+			command: `curl --json '{"a":1}' --max-time 2.5 --connect-timeout 1 --url https://api.example.com`,
+			want:    "# @timeout 2.5s\n# @connect-timeout 1s\nPOST https://api.example.com\nContent-Type: application/json\nAccept: application/json\n\n{\"a\":1}\n",
+		},
+		{
+			name:    "head and headers",
+			command: `curl -I -A 'agent/1.0' -e https://ref.example.com -H 'X-Empty;' -H 'Accept:' https://example.com`,
+			want:    "HEAD https://example.com\nX-Empty: \nUser-Agent: agent/1.0\nReferer: https://ref.example.com\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := Parse(tt.command)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := req.HTTP(); got != tt.want {
+				t.Errorf("HTTP() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if len(req.Warnings) > 0 {
+				t.Errorf("unexpected warnings %v", req.Warnings)
+			}
+		})
+	}
+}
+
+func TestParseWarningsAndErrors(t *testing.T) {
+	req, err := Parse(`curl -k --proxy http://proxy:8080 --cert me.pem -o out.json -b cookies.txt https://example.com`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(req.Warnings) != 4 {
+		t.Errorf("warnings = %v, want insecure, proxy, cert and cookie file", req.Warnings)
+	}
+
+	for _, command := range []string{"wget https://example.com", "curl -s", "curl https://example.com -H"} {
+		if _, err := Parse(command); err == nil {
+			t.Errorf("Parse(%q) should fail", command)
+		}
+	}
+}
+
+func TestParseGetWithDataFile(t *testing.T) {
+	req, err := Parse(`curl -G -d @q.txt -d page=2 https://x.test/a`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got, want := req.HTTP(), "GET https://x.test/a?page=2\n"; got != want {
+		t.Errorf("HTTP() = %q, want %q", got, want)
+	}
+
+	req, err = Parse(`curl -G -d @q.txt https://x.test/a`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got, want := req.HTTP(), "GET https://x.test/a\n"; got != want {
+		t.Errorf("HTTP() = %q, want %q", got, want)
+	}
+	if len(req.Warnings) != 1 || !strings.Contains(req.Warnings[0], "q.txt") {
+		t.Errorf("warnings = %v, want the file reported", req.Warnings)
+	}
+}
+
+func TestParseNamed(t *testing.T) {
+	req, err := Parse("curl https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Name = "home"
+	if got := req.HTTP(); got != "# @name home\nGET https://example.com\n" {
+		t.Errorf("HTTP() = %q", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *models.HttpRequest
+		options Options
+		want    string
+	}{
+		{
+			name:    "get",
+			request: &models.HttpRequest{Method: "GET", URL: "https://example.com/a?b=1&c=2", Headers: map[string]string{"Accept": "*/*"}},
+			want:    "curl 'https://example.com/a?b=1&c=2' \\\n  -H 'Accept: */*'",
+		},
+		{
+			name: "post with basic auth",
+			request: &models.HttpRequest{Method: "POST", URL: "https://example.com", RawBody: `{"it's":1}`,
+				Headers: map[string]string{"Authorization": "Basic user:pass", "Content-Type": "application/json"}},
+			options: Options{FollowRedirects: true, Timeout: 1500 * time.Millisecond},
+			want:    "curl https://example.com \\\n  -H 'Authorization: Basic dXNlcjpwYXNz' \\\n  -H 'Content-Type: application/json' \\\n  --data-raw '{\"it'\\''s\":1}' \\\n  -L \\\n  --max-time 1.5",
+		},
+		{
+			name:    "digest and put",
+			request: &models.HttpRequest{Method: "PUT", URL: "https://example.com", Headers: map[string]string{"Authorization": "Digest user my pass"}},
+			want:    "curl -X PUT https://example.com \\\n  --digest \\\n  -u 'user:my pass'",
+		},
+		{
+			name: "multipart",
+			request: &models.HttpRequest{Method: "POST", URL: "https://example.com/upload",
+				Headers: map[string]string{"Content-Type": "multipart/form-data; boundary=x"},
+				MultipartParts: []models.MultipartPart{
+					{Name: "title", Value: "Report"},
+					{Name: "raw", Value: "@not-a-file"},
+					{Name: "file", FilePath: "./report.pdf", FileName: "report.pdf", ContentType: "application/pdf", IsFile: true},
+				}},
+			want: "curl https://example.com/upload \\\n  -F title=Report \\\n  --form-string raw=@not-a-file \\\n  -F 'file=@./report.pdf;type=application/pdf'",
+		},
+		{
+			name:    "head",
+			request: &models.HttpRequest{Method: "HEAD", URL: "https://example.com"},
+			want:    "curl --head https://example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format(tt.request, tt.options)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Format(&models.HttpRequest{Method: "WEBSOCKET", URL: "wss://example.com"}, Options{}); err == nil {
+		t.Error("WebSocket requests should not format")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	command := `curl -X PATCH 'https://api.example.com/users/1?x=a b' -H 'Content-Type: application/json' -H 'X-Trace: 1' --data-raw '{"name":"O'\''Brien"}'`
+	imported, err := Parse(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := parser.NewHttpRequestParser(imported.HTTP(), nil, "")
+	requests, err := p.ParseAll()
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("ParseAll() = %v, %v", requests, err)
+	}
+	formatted, err := Format(requests[0], Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := Parse(strings.ReplaceAll(formatted, "\\\n", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Method != "PATCH" || again.URL != imported.URL {
+		t.Errorf("round trip = %+v, want %+v", again, imported)
+	}
+	// The parser keeps the newline ending the body
+	if strings.TrimSuffix(again.Body, "\n") != imported.Body {
+		t.Errorf("round trip body = %q, want %q", again.Body, imported.Body)
+	}
+	if value, _ := again.Header("X-Trace"); value != "1" {
+		t.Errorf("round trip headers = %v", again.Headers)
+	}
+}
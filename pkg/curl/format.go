@@ -0,0 +1,132 @@
+package curl
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/restclient/internal/constants"
+	"github.com/ideaspaper/restclient/pkg/auth"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// Options are the client settings a formatted command reproduces
+type Options struct {
+	FollowRedirects bool
+	InsecureSSL     bool
+	Proxy           string
+	Timeout         time.Duration
+	ConnectTimeout  time.Duration
+}
+
+// Format writes a request as a curl command, one option per line. The
+// request should be resolved: variables substituted and scripts run.
+// Authorization is processed as when sending, so Basic credentials are
+// encoded and AWS requests signed; Digest credentials become --digest.
+func Format(request *models.HttpRequest, options Options) (string, error) {
+	switch {
+	case request.Method == "WEBSOCKET" || request.Method == "GRPC":
+		return "", errors.NewValidationErrorWithValue("method", request.Method, "cannot be sent with curl")
+	case request.Metadata.GraphQLSubscription:
+		return "", errors.NewValidationError("request", "GraphQL subscriptions cannot be sent with curl")
+	}
+
+	req := request.Clone()
+	processor := auth.NewProcessor()
+	if err := processor.ProcessAuth(req); err != nil {
+		return "", err
+	}
+
+	command := "curl"
+	hasBody := req.RawBody != "" || len(req.MultipartParts) > 0
+	switch {
+	case req.Method == "HEAD":
+		command += " --head"
+	case req.Method == "GET" && !hasBody, req.Method == "POST" && hasBody:
+	default:
+		command += " -X " + req.Method
+	}
+	command += " " + quote(req.URL)
+
+	var args []string
+	if creds, ok := processor.GetDigestCredentials(req.URL); ok {
+		args = append(args, "--digest", "-u "+quote(creds.Username+":"+creds.Password))
+	}
+
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		// curl writes the Content-Type of multipart bodies with its boundary
+		if len(req.MultipartParts) > 0 && strings.EqualFold(name, constants.HeaderContentType) {
+			continue
+		}
+		if value := req.Headers[name]; value != "" {
+			args = append(args, "-H "+quote(name+": "+value))
+		} else {
+			args = append(args, "-H "+quote(name+";"))
+		}
+	}
+
+	if len(req.MultipartParts) > 0 {
+		for _, part := range req.MultipartParts {
+			args = append(args, formArg(part))
+		}
+	} else if req.RawBody != "" {
+		args = append(args, "--data-raw "+quote(req.RawBody))
+	}
+
+	if options.FollowRedirects {
+		args = append(args, "-L")
+	}
+	if options.InsecureSSL {
+		args = append(args, "-k")
+	}
+	if options.Proxy != "" {
+		args = append(args, "--proxy "+quote(options.Proxy))
+	}
+	if options.Timeout > 0 {
+		args = append(args, "--max-time "+seconds(options.Timeout))
+	}
+	if options.ConnectTimeout > 0 {
+		args = append(args, "--connect-timeout "+seconds(options.ConnectTimeout))
+	}
+
+	return strings.Join(append([]string{command}, args...), " \\\n  "), nil
+}
+
+// formArg writes a multipart part as a -F option. Text that curl would
+// read as a file or as part parameters is sent with --form-string.
+func formArg(part models.MultipartPart) string {
+	if part.IsFile && part.FilePath != "" {
+		value := part.Name + "=@" + part.FilePath
+		if part.ContentType != "" {
+			value += ";type=" + part.ContentType
+		}
+		if part.FileName != "" && part.FileName != baseName(part.FilePath) {
+			value += ";filename=" + part.FileName
+		}
+		return "-F " + quote(value)
+	}
+
+	value := part.Name + "=" + part.Value
+	if strings.HasPrefix(part.Value, "@") || strings.HasPrefix(part.Value, "<") || strings.ContainsAny(part.Value, ";\"") {
+		return "--form-string " + quote(value)
+	}
+	if part.ContentType != "" {
+		value += ";type=" + part.ContentType
+	}
+	if part.FileName != "" {
+		value += ";filename=" + part.FileName
+	}
+	return "-F " + quote(value)
+}
+
+// seconds writes a duration in seconds, as curl expects
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
@@ -0,0 +1,489 @@
+// Package curl converts between curl command lines and requests: it parses
+// a pasted curl command into an .http request block, and formats a
+// resolved request as a curl command.
+package curl
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/restclient/internal/constants"
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// formBoundary separates the parts of multipart bodies written by Request.HTTP
+const formBoundary = "----FormBoundary"
+
+// Header is a request header, in the order it was given
+type Header struct {
+	Name  string
+	Value string
+}
+
+// FormField is a -F/--form part of a multipart body
+type FormField struct {
+	Name        string
+	Value       string // Text value, empty for files
+	File        string // Path of an uploaded file
+	FileName    string // File name sent for the part, if set with ;filename=
+	ContentType string // Content type of the part, if set with ;type=
+}
+
+// Request is a request parsed from a curl command
+type Request struct {
+	Name    string // Written as # @name when set
+	Method  string
+	URL     string
+	Headers []Header
+	// Body is the text of the body, or "< path" for a body read from a
+	// file
+	Body           string
+	Form           []FormField
+	Timeout        time.Duration
+	ConnectTimeout time.Duration
+	// Warnings describe options that have no .http equivalent and were
+	// ignored
+	Warnings []string
+}
+
+// Header returns the value of the last header with a name
+func (r *Request) Header(name string) (string, bool) {
+	value, found := "", false
+	for _, h := range r.Headers {
+		if strings.EqualFold(h.Name, name) {
+			value, found = h.Value, true
+		}
+	}
+	return value, found
+}
+
+// setHeader adds a header unless the command already set it
+func (r *Request) setHeader(name, value string) {
+	if _, ok := r.Header(name); !ok {
+		r.Headers = append(r.Headers, Header{Name: name, Value: value})
+	}
+}
+
+// valueOptions are the long names of options that take a value. Options
+// that are not listed take none.
+var valueOptions = map[string]bool{
+	"request": true, "header": true, "data": true, "data-ascii": true, "data-raw": true,
+	"data-binary": true, "data-urlencode": true, "json": true, "form": true, "form-string": true,
+	"user": true, "cookie": true, "user-agent": true, "referer": true, "url": true,
+	"upload-file": true, "output": true, "max-time": true, "connect-timeout": true,
+	"proxy": true, "proxy-user": true, "cert": true, "key": true, "cacert": true, "capath": true,
+	"range": true, "write-out": true, "config": true, "cookie-jar": true, "dump-header": true,
+	"resolve": true, "connect-to": true, "retry": true, "retry-delay": true,
+	"retry-max-time": true, "limit-rate": true, "max-redirs": true, "oauth2-bearer": true,
+	"aws-sigv4": true, "interface": true, "unix-socket": true, "cert-type": true,
+	"key-type": true, "pass": true, "ciphers": true, "max-filesize": true, "speed-limit": true,
+	"speed-time": true, "trace": true, "trace-ascii": true, "stderr": true, "expect100-timeout": true,
+}
+
+// shortOptions maps single-letter options to their long names
+var shortOptions = map[byte]string{
+	'X': "request", 'H': "header", 'd': "data", 'F': "form", 'u': "user", 'b': "cookie",
+	'A': "user-agent", 'e': "referer", 'T': "upload-file", 'o': "output", 'm': "max-time",
+	'x': "proxy", 'U': "proxy-user", 'E': "cert", 'r': "range", 'w': "write-out", 'K': "config",
+	'c': "cookie-jar", 'D': "dump-header", 'Y': "speed-limit", 'y': "speed-time",
+	'G': "get", 'I': "head", 'L': "location", 'k': "insecure", 's': "silent", 'S': "show-error",
+	'v': "verbose", 'i': "include", 'f': "fail", 'g': "globoff", 'N': "no-buffer",
+	'O': "remote-name", 'J': "remote-header-name", 'n': "netrc", 'q': "disable",
+	'0': "http1.0", '2': "sslv2", '3': "sslv3", '4': "ipv4", '6': "ipv6", '#': "progress-bar",
+}
+
+// ignoredOptions only change how curl itself behaves and are dropped
+// without a warning
+var ignoredOptions = map[string]bool{
+	"silent": true, "show-error": true, "verbose": true, "include": true, "fail": true,
+	"fail-with-body": true, "globoff": true, "no-buffer": true, "progress-bar": true,
+	"output": true, "remote-name": true, "remote-header-name": true, "write-out": true,
+	"location": true, "max-redirs": true, "http1.1": true, "http2": true, "http1.0": true,
+	"ipv4": true, "ipv6": true, "stderr": true, "trace": true, "trace-ascii": true,
+	"dump-header": true, "basic": true, "path-as-is": true, "raw": true, "tr-encoding": true,
+	"no-progress-meter": true, "no-keepalive": true, "tcp-nodelay": true, "http2-prior-knowledge": true,
+	"location-trusted": true, "no-alpn": true, "no-npn": true,
+}
+
+// dataPart is a -d option value and how curl reads it
+type dataPart struct {
+	value  string
+	option string
+}
+
+// Parse parses a curl command line, as copied from browser devtools or a
+// runbook. Shell quoting and line continuations are understood; options
+// without an .http equivalent are reported in Request.Warnings.
+func Parse(command string) (*Request, error) {
+	args, err := splitArgs(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, errors.NewValidationError("curl command", "must start with curl")
+	}
+
+	r := &Request{}
+	var (
+		method, user string
+		data         []dataPart
+		get, head    bool
+		digest       bool
+		compressed   bool
+		uploadFile   string
+	)
+
+	// Headers curl derives from options, added after the -H headers unless
+	// one of those sets them
+	var derived []Header
+	derive := func(name, value string) {
+		derived = append(derived, Header{Name: name, Value: value})
+	}
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		var options []string // Long names, with the value of the last if it takes one
+		var value string
+		hasValue := false
+
+		switch {
+		case strings.HasPrefix(arg, "--") && len(arg) > 2:
+			options = []string{arg[2:]}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// A cluster of short options such as -sSL or -XPOST
+			for j := 1; j < len(arg); j++ {
+				name, ok := shortOptions[arg[j]]
+				if !ok {
+					r.warn("ignored unknown option -%c", arg[j])
+					continue
+				}
+				options = append(options, name)
+				if valueOptions[name] {
+					if j+1 < len(arg) {
+						value, hasValue = arg[j+1:], true
+					}
+					break
+				}
+			}
+		default:
+			if r.URL == "" {
+				r.URL = arg
+			} else {
+				r.warn("ignored extra URL %s", arg)
+			}
+			continue
+		}
+
+		for k, name := range options {
+			if valueOptions[name] && k == len(options)-1 && !hasValue {
+				if i+1 >= len(args) {
+					return nil, errors.NewValidationErrorWithValue("curl option", "--"+name, "requires a value")
+				}
+				i++
+				value = args[i]
+			}
+
+			switch name {
+			case "request":
+				method = strings.ToUpper(value)
+			case "header":
+				r.addHeader(value)
+			case "data", "data-ascii", "data-binary", "data-raw", "data-urlencode":
+				data = append(data, dataPart{value: value, option: name})
+			case "json":
+				data = append(data, dataPart{value: value, option: name})
+				derive(constants.HeaderContentType, constants.MIMEApplicationJSON)
+				derive(constants.HeaderAccept, constants.MIMEApplicationJSON)
+			case "form", "form-string":
+				r.Form = append(r.Form, parseFormField(value, name == "form-string"))
+			case "user":
+				user = value
+			case "digest":
+				digest = true
+			case "oauth2-bearer":
+				derive(constants.HeaderAuthorization, "Bearer "+value)
+			case "cookie":
+				if strings.Contains(value, "=") {
+					derive(constants.HeaderCookie, value)
+				} else {
+					r.warn("ignored cookie file %s", value)
+				}
+			case "user-agent":
+				derive(constants.HeaderUserAgent, value)
+			case "referer":
+				derive("Referer", value)
+			case "range":
+				derive("Range", "bytes="+value)
+			case "url":
+				r.URL = value
+			case "get":
+				get = true
+			case "head":
+				head = true
+			case "upload-file":
+				uploadFile = value
+			case "compressed":
+				compressed = true
+			case "max-time":
+				r.Timeout = parseSeconds(r, name, value)
+			case "connect-timeout":
+				r.ConnectTimeout = parseSeconds(r, name, value)
+			case "insecure":
+				r.warn("ignored --insecure; set insecureSSL in the session config instead")
+			case "proxy":
+				r.warn("ignored --proxy %s; set proxy in the session config instead", value)
+			default:
+				if !ignoredOptions[name] {
+					if valueOptions[name] {
+						r.warn("ignored option --%s %s", name, value)
+					} else {
+						r.warn("ignored option --%s", name)
+					}
+				}
+			}
+		}
+	}
+
+	if r.URL == "" {
+		return nil, errors.NewValidationError("curl command", "no URL given")
+	}
+
+	for _, h := range derived {
+		r.setHeader(h.Name, h.Value)
+	}
+	if user != "" {
+		r.setAuth(user, digest)
+	}
+	if compressed {
+		r.setHeader(constants.HeaderAcceptEncoding, "gzip")
+	}
+
+	body := r.dataBody(data, get)
+	switch {
+	case get && body != "":
+		separator := "?"
+		if strings.Contains(r.URL, "?") {
+			separator = "&"
+		}
+		r.URL += separator + body
+	case len(r.Form) > 0:
+		r.setMultipart()
+	case body != "":
+		r.Body = body
+		r.setHeader(constants.HeaderContentType, constants.MIMEApplicationFormURLEncoded)
+	case uploadFile != "":
+		r.Body = "< " + uploadFile
+	}
+
+	switch {
+	case method != "":
+		r.Method = method
+	case head:
+		r.Method = "HEAD"
+	case uploadFile != "" && !get:
+		r.Method = "PUT"
+	case (body != "" || len(r.Form) > 0) && !get:
+		r.Method = "POST"
+	default:
+		r.Method = "GET"
+	}
+	return r, nil
+}
+
+func (r *Request) warn(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// addHeader adds a -H value. "Name;" sends an empty header, while "Name:"
+// removes a header curl would send and is dropped.
+func (r *Request) addHeader(value string) {
+	if strings.HasPrefix(value, "@") {
+		r.warn("ignored header file %s", value[1:])
+		return
+	}
+	name, headerValue, ok := strings.Cut(value, ":")
+	if !ok {
+		if before, found := strings.CutSuffix(strings.TrimSpace(value), ";"); found {
+			r.Headers = append(r.Headers, Header{Name: before})
+		}
+		return
+	}
+	headerValue = strings.TrimSpace(headerValue)
+	if headerValue == "" {
+		return
+	}
+	r.Headers = append(r.Headers, Header{Name: strings.TrimSpace(name), Value: headerValue})
+}
+
+// setAuth writes -u user:password as an Authorization header in the form
+// the .http format accepts, which is encoded when the request is sent
+func (r *Request) setAuth(user string, digest bool) {
+	name, password, ok := strings.Cut(user, ":")
+	if !ok {
+		r.warn("no password given for user %s", name)
+	}
+	if digest {
+		r.setHeader(constants.HeaderAuthorization, "Digest "+name+" "+password)
+		return
+	}
+	r.setHeader(constants.HeaderAuthorization, "Basic "+name+":"+password)
+}
+
+// dataBody joins the -d values as curl does. A single @file value becomes
+// a file reference, unless the data goes to the query string with -G.
+func (r *Request) dataBody(data []dataPart, query bool) string {
+	if len(data) == 1 && !query && data[0].option != "data-raw" && data[0].option != "data-urlencode" {
+		if path, ok := strings.CutPrefix(data[0].value, "@"); ok {
+			if path == "-" {
+				r.warn("ignored body read from stdin")
+				return ""
+			}
+			return "< " + path
+		}
+	}
+
+	var parts []string
+	for _, d := range data {
+		switch {
+		case d.option == "data-urlencode":
+			parts = append(parts, r.urlEncode(d.value))
+		case d.option != "data-raw" && strings.HasPrefix(d.value, "@") && query:
+			r.warn("ignored body file %s; file contents cannot be added to the query", d.value[1:])
+		case d.option != "data-raw" && strings.HasPrefix(d.value, "@"):
+			r.warn("ignored body file %s; only a single @file body is supported", d.value[1:])
+		default:
+			parts = append(parts, d.value)
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// urlEncode encodes a --data-urlencode value: "content", "=content" or
+// "name=content"
+func (r *Request) urlEncode(value string) string {
+	name, content, ok := strings.Cut(value, "=")
+	if !ok {
+		if strings.Contains(value, "@") {
+			r.warn("ignored --data-urlencode %s; file contents are not supported", value)
+			return ""
+		}
+		return url.QueryEscape(value)
+	}
+	if name == "" {
+		return url.QueryEscape(content)
+	}
+	return name + "=" + url.QueryEscape(content)
+}
+
+// setMultipart writes the -F fields as a multipart body
+func (r *Request) setMultipart() {
+	contentType := constants.MIMEMultipartFormData + "; boundary=" + formBoundary
+	replaced := false
+	for i, h := range r.Headers {
+		if strings.EqualFold(h.Name, constants.HeaderContentType) {
+			r.Headers[i].Value = contentType
+			replaced = true
+		}
+	}
+	if !replaced {
+		r.Headers = append(r.Headers, Header{Name: constants.HeaderContentType, Value: contentType})
+	}
+
+	var b strings.Builder
+	for _, field := range r.Form {
+		b.WriteString("--" + formBoundary + "\n")
+		disposition := fmt.Sprintf(`Content-Disposition: form-data; name="%s"`, field.Name)
+		fileName := field.FileName
+		if fileName == "" && field.File != "" {
+			fileName = baseName(field.File)
+		}
+		if fileName != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, fileName)
+		}
+		b.WriteString(disposition + "\n")
+		if field.ContentType != "" {
+			b.WriteString(constants.HeaderContentType + ": " + field.ContentType + "\n")
+		}
+		b.WriteString("\n")
+		if field.File != "" {
+			b.WriteString("< " + field.File + "\n")
+		} else {
+			b.WriteString(field.Value + "\n")
+		}
+	}
+	b.WriteString("--" + formBoundary + "--")
+	r.Body = b.String()
+}
+
+// parseFormField parses a -F value: name=value, name=@file or name=<file,
+// each optionally followed by ;type=... and ;filename=...
+func parseFormField(value string, literal bool) FormField {
+	name, content, _ := strings.Cut(value, "=")
+	field := FormField{Name: name, Value: content}
+	if literal {
+		return field
+	}
+
+	if strings.HasPrefix(content, "@") || strings.HasPrefix(content, "<") {
+		params := strings.Split(content[1:], ";")
+		field.File, field.Value = params[0], ""
+		for _, param := range params[1:] {
+			key, paramValue, _ := strings.Cut(param, "=")
+			switch strings.TrimSpace(key) {
+			case "type":
+				field.ContentType = paramValue
+			case "filename":
+				field.FileName = strings.Trim(paramValue, `"`)
+			}
+		}
+		return field
+	}
+
+	if text, params, ok := strings.Cut(content, ";type="); ok {
+		field.Value, field.ContentType = text, params
+	}
+	return field
+}
+
+// parseSeconds parses a --max-time or --connect-timeout value in seconds
+func parseSeconds(r *Request, option, value string) time.Duration {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 {
+		r.warn("ignored --%s %s", option, value)
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// baseName returns the last element of a slash-separated path
+func baseName(path string) string {
+	return path[strings.LastIndexAny(path, `/\`)+1:]
+}
+
+// HTTP writes the request as an .http request block
+func (r *Request) HTTP() string {
+	var b strings.Builder
+	if r.Name != "" {
+		b.WriteString("# @name " + r.Name + "\n")
+	}
+	if r.Timeout > 0 {
+		b.WriteString("# @timeout " + r.Timeout.String() + "\n")
+	}
+	if r.ConnectTimeout > 0 {
+		b.WriteString("# @connect-timeout " + r.ConnectTimeout.String() + "\n")
+	}
+	b.WriteString(r.Method + " " + r.URL + "\n")
+	for _, h := range r.Headers {
+		b.WriteString(h.Name + ": " + h.Value + "\n")
+	}
+	if r.Body != "" {
+		b.WriteString("\n" + r.Body)
+		if !strings.HasSuffix(r.Body, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
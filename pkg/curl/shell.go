@@ -0,0 +1,157 @@
+package curl
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// splitArgs splits a command line into arguments the way a POSIX shell
+// does: single quotes, double quotes, $'...' strings, backslash escapes and
+// backslash-newline continuations
+func splitArgs(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case c == '\\':
+			inArg = true
+			if i+1 >= len(command) {
+				continue
+			}
+			i++
+			switch command[i] {
+			case '\n':
+				// Line continuation, also ends an argument
+				if current.Len() == 0 {
+					inArg = false
+				}
+			case '\r':
+				if i+1 < len(command) && command[i+1] == '\n' {
+					i++
+				}
+				if current.Len() == 0 {
+					inArg = false
+				}
+			default:
+				current.WriteByte(command[i])
+			}
+		case c == '\'':
+			inArg = true
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.NewValidationError("curl command", "unterminated single quote")
+			}
+			current.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+		case c == '$' && i+1 < len(command) && command[i+1] == '\'':
+			inArg = true
+			n, err := ansiString(command[i+2:], &current)
+			if err != nil {
+				return nil, err
+			}
+			i += n + 2
+		case c == '"':
+			inArg = true
+			i++
+			for ; i < len(command) && command[i] != '"'; i++ {
+				if command[i] == '\\' && i+1 < len(command) && strings.IndexByte("$`\"\\\n", command[i+1]) >= 0 {
+					i++
+					if command[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteByte(command[i])
+			}
+			if i >= len(command) {
+				return nil, errors.NewValidationError("curl command", "unterminated double quote")
+			}
+		default:
+			inArg = true
+			current.WriteByte(c)
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// ansiString decodes the body of a $'...' string, as Chrome writes bodies
+// with special characters, and returns the number of bytes read including
+// the closing quote
+func ansiString(s string, out *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\'' {
+			return i + 1, nil
+		}
+		if c != '\\' || i+1 >= len(s) {
+			out.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			out.WriteByte('\n')
+		case 't':
+			out.WriteByte('\t')
+		case 'r':
+			out.WriteByte('\r')
+		case '0':
+			out.WriteByte(0)
+		case 'x', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[s[i]]
+			end := i + 1
+			for end < len(s) && end < i+1+digits && strings.IndexByte("0123456789abcdefABCDEF", s[end]) >= 0 {
+				end++
+			}
+			code, err := strconv.ParseUint(s[i+1:end], 16, 32)
+			if err != nil {
+				out.WriteByte('\\')
+				out.WriteByte(s[i])
+				continue
+			}
+			if s[i] == 'x' {
+				out.WriteByte(byte(code))
+			} else {
+				out.WriteString(string(rune(code)))
+			}
+			i = end - 1
+		default:
+			// \\, \', \" and anything else stand for the character itself
+			out.WriteByte(s[i])
+		}
+	}
+	return 0, errors.NewValidationError("curl command", "unterminated $' quote")
+}
+
+// quote quotes an argument for a POSIX shell when it needs quoting
+func quote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range arg {
+		if !(r < utf8.RuneSelf && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@,+%", r))) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -4,6 +4,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Sentinel errors that can be checked with errors.Is()
@@ -170,6 +171,45 @@ func NewScriptErrorWithCause(script, message string, cause error) *ScriptError {
 	return &ScriptError{Script: script, Message: message, Wrapped: cause}
 }
 
+// Request phases reported by TimeoutError
+const (
+	PhaseDNS       = "DNS lookup"
+	PhaseConnect   = "connect"
+	PhaseTLS       = "TLS handshake"
+	PhaseWrite     = "request write"
+	PhaseFirstByte = "time to first byte"
+	PhaseBodyRead  = "body read"
+)
+
+// TimeoutError represents a request that timed out, naming the phase that
+// was in progress when the timeout expired.
+type TimeoutError struct {
+	Phase   string        // Phase in progress (e.g., PhaseConnect, PhaseFirstByte)
+	Timeout time.Duration // Timeout that expired, 0 if unknown
+	Wrapped error         // Underlying error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("timed out after %s during %s", e.Timeout, e.Phase)
+	}
+	return fmt.Sprintf("timed out during %s", e.Phase)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Wrapped
+}
+
+// Is implements errors.Is for TimeoutError.
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// NewTimeoutError creates a new TimeoutError.
+func NewTimeoutError(phase string, timeout time.Duration, cause error) *TimeoutError {
+	return &TimeoutError{Phase: phase, Timeout: timeout, Wrapped: cause}
+}
+
 // Wrap wraps an error with a message, using %w for proper error chaining.
 // Returns nil if err is nil.
 func Wrap(err error, message string) error {
@@ -194,6 +234,12 @@ func Is(err, target error) bool {
 	return errors.Is(err, target)
 }
 
+// Unwrap returns the result of calling the Unwrap method on err, if any.
+// This is a convenience re-export of errors.Unwrap.
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
 // As finds the first error in err's chain that matches target.
 // This is a convenience re-export of errors.As.
 func As(err error, target any) bool {
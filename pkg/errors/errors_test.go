@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"testing"
+	"time"
 )
 
 func TestSentinelErrors(t *testing.T) {
@@ -182,6 +183,35 @@ func TestScriptError(t *testing.T) {
 	})
 }
 
+func TestTimeoutError(t *testing.T) {
+	t.Run("with timeout", func(t *testing.T) {
+		err := NewTimeoutError(PhaseFirstByte, 5*time.Second, nil)
+		expected := "timed out after 5s during time to first byte"
+		if err.Error() != expected {
+			t.Errorf("expected %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("without timeout", func(t *testing.T) {
+		err := NewTimeoutError(PhaseConnect, 0, nil)
+		expected := "timed out during connect"
+		if err.Error() != expected {
+			t.Errorf("expected %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Is ErrTimeout through wrapping", func(t *testing.T) {
+		cause := errors.New("i/o timeout")
+		err := NewRequestError("send", NewTimeoutError(PhaseTLS, time.Second, cause))
+		if !errors.Is(err, ErrTimeout) {
+			t.Error("TimeoutError should match ErrTimeout with errors.Is")
+		}
+		if !errors.Is(err, cause) {
+			t.Error("TimeoutError should unwrap to its cause")
+		}
+	})
+}
+
 func TestWrap(t *testing.T) {
 	t.Run("wraps error", func(t *testing.T) {
 		cause := errors.New("file not found")
@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/openapi"
+	"github.com/ideaspaper/restclient/pkg/scripting"
+)
+
+// specCache keeps loaded OpenAPI specs for the requests of a run, reloading
+// a spec when its file changes
+var specCache = struct {
+	sync.Mutex
+	specs map[string]cachedSpec
+}{specs: make(map[string]cachedSpec)}
+
+type cachedSpec struct {
+	modTime time.Time
+	spec    *openapi.Spec
+}
+
+// contractSpecPath returns the OpenAPI spec a request's response is
+// validated against: the file's # @openapi, else the session setting
+// relative to the .http file
+func (e *Executor) contractSpecPath(request *models.HttpRequest) string {
+	if request.Metadata.OpenAPI != "" {
+		return request.Metadata.OpenAPI
+	}
+	path := e.sessionConfig.Validation.OpenAPI
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	httpFile := request.SourceFile
+	if httpFile == "" {
+		httpFile = e.options.HTTPFilePath
+	}
+	if httpFile == "" {
+		return path
+	}
+	return filepath.Join(filepath.Dir(httpFile), path)
+}
+
+// loadContractSpec loads the OpenAPI spec of a request, or returns nil if
+// it has none
+func (e *Executor) loadContractSpec(request *models.HttpRequest) (*openapi.Spec, error) {
+	if request.Method == models.MethodWebSocket || request.Method == models.MethodGRPC || request.Metadata.GraphQLSubscription {
+		return nil, nil
+	}
+	path := e.contractSpecPath(request)
+	if path == "" {
+		return nil, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.NewValidationErrorWithValue("OpenAPI spec", path, "file not found")
+	}
+
+	specCache.Lock()
+	defer specCache.Unlock()
+	if cached, ok := specCache.specs[path]; ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.spec, nil
+	}
+	spec, err := openapi.Load(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load OpenAPI spec %s", path)
+	}
+	specCache.specs[path] = cachedSpec{modTime: info.ModTime(), spec: spec}
+	return spec, nil
+}
+
+// checkContract validates a response against its operation in the spec,
+// reporting each check as a test result
+func checkContract(spec *openapi.Spec, request *models.HttpRequest, resp *models.HttpResponse) []scripting.TestResult {
+	body := resp.Body
+	if resp.Streamed || resp.BodyTruncated || resp.IsEventStream() {
+		// Only a complete body can be validated
+		body = ""
+	}
+
+	var results []scripting.TestResult
+	for _, check := range spec.CheckResponse(request.Method, request.URL, resp.StatusCode, resp.Headers, body) {
+		results = append(results, scripting.TestResult{
+			Name:   check.Name,
+			Passed: len(check.Errors) == 0,
+			Error:  strings.Join(check.Errors, "; "),
+		})
+	}
+	return results
+}
@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/variables"
+)
+
+// SendFunc prepares and sends a request, storing its result in the
+// variable processor
+type SendFunc func(ctx context.Context, request *models.HttpRequest) error
+
+// Dependencies sends the named requests that a request refers to through
+// request variables, such as {{login.response.body.$.token}}, when no
+// result is cached for them yet
+type Dependencies struct {
+	requests     []*models.HttpRequest
+	varProcessor *variables.VariableProcessor
+	send         SendFunc
+	sending      []string // Requests being resolved, outermost first
+}
+
+// NewDependencies creates a resolver looking up dependencies in requests,
+// typically the requests of a file and of the files it imports
+func NewDependencies(requests []*models.HttpRequest, varProcessor *variables.VariableProcessor, send SendFunc) *Dependencies {
+	return &Dependencies{
+		requests:     requests,
+		varProcessor: varProcessor,
+		send:         send,
+	}
+}
+
+// Resolve sends the missing dependencies of request, each after its own
+// dependencies. References to requests that are not found are left for
+// variable resolution to report; a dependency cycle is an error.
+func (d *Dependencies) Resolve(ctx context.Context, request *models.HttpRequest) error {
+	d.sending = append(d.sending, requestName(request))
+	defer func() { d.sending = d.sending[:len(d.sending)-1] }()
+
+	for _, ref := range d.references(request) {
+		if d.varProcessor.HasRequestResult(ref) {
+			continue
+		}
+		dependency := d.find(ref)
+		if dependency == nil {
+			continue
+		}
+
+		name := requestName(dependency)
+		if slices.Contains(d.sending, name) {
+			chain := append(slices.Clone(d.sending[slices.Index(d.sending, name):]), name)
+			return errors.NewValidationErrorWithValue("request dependencies", strings.Join(chain, " -> "), "cycle detected")
+		}
+
+		if err := d.Resolve(ctx, dependency); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := d.send(ctx, dependency.Clone()); err != nil {
+			return errors.Wrapf(err, "failed to send dependency %s", name)
+		}
+	}
+	return nil
+}
+
+// references returns the requests referred to by the parts of a request
+// that variables are substituted in
+func (d *Dependencies) references(request *models.HttpRequest) []variables.RequestReference {
+	texts := []string{request.URL, request.RawBody}
+	for _, v := range request.Headers {
+		texts = append(texts, v)
+	}
+	for _, part := range request.MultipartParts {
+		texts = append(texts, part.Value, part.FilePath)
+	}
+
+	var refs []variables.RequestReference
+	for _, text := range texts {
+		for _, ref := range d.varProcessor.RequestReferences(text) {
+			if !slices.Contains(refs, ref) {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// find returns the referenced request. A file-qualified reference only
+// matches a request parsed from that file.
+func (d *Dependencies) find(ref variables.RequestReference) *models.HttpRequest {
+	for _, request := range d.requests {
+		if request.Metadata.Name != ref.Name && request.Name != ref.Name {
+			continue
+		}
+		if ref.File != "" && (request.SourceFile == "" || d.varProcessor.ResultKey(ref) != variables.RequestKey(request.SourceFile, ref.Name)) {
+			continue
+		}
+		return request
+	}
+	return nil
+}
+
+// requestName returns the name of a request, empty if it has none
+func requestName(request *models.HttpRequest) string {
+	if request.Metadata.Name != "" {
+		return request.Metadata.Name
+	}
+	return request.Name
+}
@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/variables"
+)
+
+// namedRequest returns a GET request with the given name and headers
+func namedRequest(name, url string, headers map[string]string) *models.HttpRequest {
+	request := models.NewHttpRequest("GET", url, headers, nil, "", "")
+	request.Metadata.Name = name
+	return request
+}
+
+func TestDependencies_Resolve(t *testing.T) {
+	vp := variables.NewVariableProcessor()
+	vp.SetFileVariables(map[string]string{"token": "{{login.response.body.$.token}}"})
+
+	login := namedRequest("login", "https://api.example.com/login?tenant={{tenant.response.body.$.id}}", nil)
+	tenant := namedRequest("tenant", "https://api.example.com/tenant", nil)
+	profile := namedRequest("profile", "https://api.example.com/me", map[string]string{"Authorization": "Bearer {{token}}"})
+
+	var sent []string
+	send := func(ctx context.Context, request *models.HttpRequest) error {
+		sent = append(sent, request.Metadata.Name)
+		vp.SetRequestResult(request.Metadata.Name, variables.RequestResult{StatusCode: 200, Body: `{"token": "abc", "id": "1"}`})
+		return nil
+	}
+
+	deps := NewDependencies([]*models.HttpRequest{login, tenant, profile}, vp, send)
+	if err := deps.Resolve(context.Background(), profile.Clone()); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if want := []string{"tenant", "login"}; !slices.Equal(sent, want) {
+		t.Errorf("sent %v, want %v", sent, want)
+	}
+
+	// Cached results are not sent again
+	sent = nil
+	if err := deps.Resolve(context.Background(), profile.Clone()); err != nil || len(sent) != 0 {
+		t.Errorf("Resolve() sent %v, %v, want nothing", sent, err)
+	}
+}
+
+func TestDependencies_Expired(t *testing.T) {
+	vp := variables.NewVariableProcessor()
+	vp.SetRequestResult("login", variables.RequestResult{Body: `{}`, ExpiresAt: time.Now().Add(-time.Second)})
+
+	sent := 0
+	deps := NewDependencies([]*models.HttpRequest{namedRequest("login", "https://api.example.com/login", nil)}, vp,
+		func(ctx context.Context, request *models.HttpRequest) error {
+			sent++
+			return nil
+		})
+
+	request := models.NewHttpRequest("GET", "https://api.example.com/{{login.response.body.$.id}}", nil, nil, "", "")
+	if err := deps.Resolve(context.Background(), request); err != nil || sent != 1 {
+		t.Errorf("Resolve() sent %d, %v, want the expired dependency sent again", sent, err)
+	}
+}
+
+func TestDependencies_Cycle(t *testing.T) {
+	vp := variables.NewVariableProcessor()
+	a := namedRequest("a", "https://api.example.com/{{b.response.body.$.id}}", nil)
+	b := namedRequest("b", "https://api.example.com/{{a.response.body.$.id}}", nil)
+
+	deps := NewDependencies([]*models.HttpRequest{a, b}, vp, func(ctx context.Context, request *models.HttpRequest) error {
+		t.Errorf("unexpected send of %s", request.Metadata.Name)
+		return nil
+	})
+
+	err := deps.Resolve(context.Background(), a.Clone())
+	if err == nil || !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Errorf("Resolve() error = %v, want the cycle", err)
+	}
+}
+
+func TestDependencies_OtherFile(t *testing.T) {
+	dir := t.TempDir()
+	vp := variables.NewVariableProcessor()
+	vp.SetCurrentDir(dir)
+
+	local := namedRequest("login", "https://local.example.com/login", nil)
+	local.SourceFile = filepath.Join(dir, "api.http")
+	shared := namedRequest("login", "https://auth.example.com/login", nil)
+	shared.SourceFile = filepath.Join(dir, "auth.http")
+
+	var sent []string
+	deps := NewDependencies([]*models.HttpRequest{local, shared}, vp, func(ctx context.Context, request *models.HttpRequest) error {
+		sent = append(sent, request.URL)
+		return nil
+	})
+
+	request := models.NewHttpRequest("GET", "https://api.example.com/{{auth.http#login.response.body.$.id}}", nil, nil, "", "")
+	if err := deps.Resolve(context.Background(), request); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if want := []string{shared.URL}; !slices.Equal(sent, want) {
+		t.Errorf("sent %v, want %v", sent, want)
+	}
+}
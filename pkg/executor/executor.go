@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"maps"
 	"strings"
+	"time"
 
 	"github.com/ideaspaper/restclient/pkg/client"
 	"github.com/ideaspaper/restclient/pkg/errors"
@@ -34,6 +35,11 @@ type Options struct {
 	LogFunc func(format string, args ...any)
 	// EnvironmentStore provides per-session environment variables (optional)
 	EnvironmentStore *session.EnvironmentStore
+	// Stream writes successful response bodies to a writer as they arrive
+	// instead of buffering them (optional)
+	Stream *client.StreamConfig
+	// Events delivers Server-Sent Events as they arrive (optional)
+	Events *client.EventConfig
 }
 
 // Result contains the execution result
@@ -41,6 +47,7 @@ type Result struct {
 	Response    *models.HttpResponse
 	TestResults []scripting.TestResult
 	Logs        []string
+	Execution   scripting.Execution // Flow-control decisions from the post-response script
 }
 
 // Executor handles HTTP request execution
@@ -73,6 +80,11 @@ func (e *Executor) ExecuteWithContext(ctx context.Context, request *models.HttpR
 
 	result := &Result{}
 
+	spec, err := e.loadContractSpec(request)
+	if err != nil {
+		return nil, err
+	}
+
 	var sessionMgr *session.SessionManager
 	if !e.options.NoSession && e.sessionConfig.RememberCookies() {
 		var err error
@@ -96,9 +108,14 @@ func (e *Executor) ExecuteWithContext(ctx context.Context, request *models.HttpR
 	}
 
 	// Create HTTP client
-	clientCfg := e.sessionConfig.ToClientConfig()
-	if request.Metadata.NoRedirect {
-		clientCfg.FollowRedirects = false
+	clientCfg := ClientConfigFor(e.sessionConfig, request)
+	clientCfg.Events = e.options.Events
+	var streamed *countingWriter
+	if e.options.Stream != nil {
+		stream := *e.options.Stream
+		streamed = &countingWriter{writer: stream.Writer}
+		stream.Writer = streamed
+		clientCfg.Stream = &stream
 	}
 
 	httpClient, err := client.NewHttpClient(clientCfg)
@@ -114,8 +131,8 @@ func (e *Executor) ExecuteWithContext(ctx context.Context, request *models.HttpR
 		}
 	}
 
-	// Send request with context
-	resp, err := httpClient.SendWithContext(ctx, request)
+	// Send request with context, retrying if the request has a retry policy
+	resp, attempts, err := e.sendWithRetry(ctx, httpClient, request, streamed)
 	if err != nil {
 		// Check for context cancellation
 		if ctx.Err() != nil {
@@ -135,7 +152,7 @@ func (e *Executor) ExecuteWithContext(ctx context.Context, request *models.HttpR
 
 	// Save to history
 	if !e.options.NoHistory {
-		e.saveToHistory(request, sessionMgr)
+		e.saveToHistory(request, resp, attempts, sessionMgr)
 	}
 
 	// Store result for request variable references
@@ -144,13 +161,39 @@ func (e *Executor) ExecuteWithContext(ctx context.Context, request *models.HttpR
 		if name == "" {
 			name = request.Metadata.Name
 		}
-		e.varProcessor.SetRequestResult(name, variables.RequestResult{
+		requestResult := variables.RequestResult{
 			StatusCode: resp.StatusCode,
 			Headers:    resp.Headers,
 			Body:       resp.Body,
-		})
+		}
+		if request.Metadata.CacheTTL > 0 {
+			requestResult.ExpiresAt = time.Now().Add(request.Metadata.CacheTTL)
+		}
+		keys := []string{name}
+		if request.SourceFile != "" {
+			keys = append(keys, variables.RequestKey(request.SourceFile, name))
+		}
+		for _, key := range keys {
+			e.varProcessor.SetRequestResult(key, requestResult)
+			// Persist the result for later invocations
+			if sessionMgr != nil {
+				sessionMgr.SetResult(key, session.RequestResult{
+					StatusCode: requestResult.StatusCode,
+					Headers:    requestResult.Headers,
+					Body:       requestResult.Body,
+					ExpiresAt:  requestResult.ExpiresAt,
+				})
+			}
+		}
 	}
 
+	// Validate the response against its OpenAPI operation
+	var contractTests []scripting.TestResult
+	if spec != nil {
+		contractTests = checkContract(spec, request, resp)
+	}
+	result.TestResults = contractTests
+
 	// Execute post-response script with context
 	if request.Metadata.PostScript != "" {
 		scriptResult, err := e.executePostScript(ctx, request, resp, sessionMgr)
@@ -161,13 +204,14 @@ func (e *Executor) ExecuteWithContext(ctx context.Context, request *models.HttpR
 			return result, err
 		}
 		result.Logs = scriptResult.Logs
-		result.TestResults = scriptResult.Tests
+		result.TestResults = append(scriptResult.Tests, contractTests...)
+		result.Execution = scriptResult.Execution
+	}
 
-		// Check for test failures
-		for _, test := range scriptResult.Tests {
-			if !test.Passed {
-				return result, errors.NewScriptError("test", fmt.Sprintf("'%s' failed: %s", test.Name, test.Error))
-			}
+	// Check for test failures
+	for _, test := range result.TestResults {
+		if !test.Passed {
+			return result, errors.NewScriptError("test", fmt.Sprintf("'%s' failed: %s", test.Name, test.Error))
 		}
 	}
 
@@ -245,8 +289,8 @@ func (e *Executor) setupScriptContext(request *models.HttpRequest, resp *models.
 	return scriptCtx
 }
 
-// saveToHistory saves the request to history
-func (e *Executor) saveToHistory(request *models.HttpRequest, sessionMgr *session.SessionManager) {
+// saveToHistory saves the request and a summary of its response to history
+func (e *Executor) saveToHistory(request *models.HttpRequest, resp *models.HttpResponse, attempts []models.RequestAttempt, sessionMgr *session.SessionManager) {
 	histMgr, err := history.NewHistoryManager("")
 	if err != nil {
 		return
@@ -269,7 +313,7 @@ func (e *Executor) saveToHistory(request *models.HttpRequest, sessionMgr *sessio
 		}
 	}
 
-	histMgr.Add(&historyRequest)
+	histMgr.AddWithResponse(&historyRequest, attempts, resp)
 }
 
 // log outputs a log message if LogFunc is configured
@@ -333,3 +377,20 @@ func ExecutePreScriptWithContext(ctx context.Context, script string, sessionCfg
 
 	return result, nil
 }
+
+// ClientConfigFor returns the client configuration for a request: the
+// session settings with the request's @no-redirect, @timeout and
+// @connect-timeout metadata applied
+func ClientConfigFor(sessionCfg *session.SessionConfig, request *models.HttpRequest) *client.ClientConfig {
+	clientCfg := sessionCfg.ToClientConfig()
+	if request.Metadata.NoRedirect {
+		clientCfg.FollowRedirects = false
+	}
+	if request.Metadata.Timeout > 0 {
+		clientCfg.Timeout = request.Metadata.Timeout
+	}
+	if request.Metadata.ConnectTimeout > 0 {
+		clientCfg.ConnectTimeout = request.Metadata.ConnectTimeout
+	}
+	return clientCfg
+}
@@ -3,13 +3,16 @@ package executor
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ideaspaper/restclient/pkg/errors"
 	"github.com/ideaspaper/restclient/pkg/models"
 	"github.com/ideaspaper/restclient/pkg/session"
 	"github.com/ideaspaper/restclient/pkg/variables"
@@ -204,6 +207,70 @@ func TestExecutor_ExecuteWithContext(t *testing.T) {
 	})
 }
 
+func TestExecutor_TimeoutMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	newExecutor := func(timeoutMs int) *Executor {
+		sessionCfg := session.DefaultSessionConfig()
+		sessionCfg.Environment.RememberCookies = false
+		sessionCfg.HTTP.TimeoutMs = timeoutMs
+		return New(sessionCfg, variables.NewVariableProcessor(), Options{
+			NoSession: true,
+			NoHistory: true,
+		})
+	}
+
+	t.Run("shorter than session timeout", func(t *testing.T) {
+		request := models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", "")
+		request.Metadata.Timeout = 50 * time.Millisecond
+
+		_, err := newExecutor(5000).Execute(request)
+		var timeoutErr *errors.TimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("Execute() error = %v, want a TimeoutError", err)
+		}
+		if timeoutErr.Phase != errors.PhaseFirstByte || timeoutErr.Timeout != 50*time.Millisecond {
+			t.Errorf("timeout = %q after %v, want %q after 50ms", timeoutErr.Phase, timeoutErr.Timeout, errors.PhaseFirstByte)
+		}
+	})
+
+	t.Run("longer than session timeout", func(t *testing.T) {
+		request := models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", "")
+		request.Metadata.Timeout = 5 * time.Second
+
+		result, err := newExecutor(50).Execute(request)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if result.Response.StatusCode != http.StatusOK {
+			t.Errorf("StatusCode = %d, want 200", result.Response.StatusCode)
+		}
+	})
+}
+
+func TestClientConfigFor(t *testing.T) {
+	sessionCfg := session.DefaultSessionConfig()
+	sessionCfg.HTTP.TimeoutMs = 2000
+
+	request := models.NewHttpRequest("GET", "http://localhost", map[string]string{}, nil, "", "")
+	cfg := ClientConfigFor(sessionCfg, request)
+	if cfg.Timeout != 2*time.Second || cfg.ConnectTimeout != 0 {
+		t.Errorf("without metadata got Timeout = %v, ConnectTimeout = %v, want 2s, 0", cfg.Timeout, cfg.ConnectTimeout)
+	}
+
+	request.Metadata.Timeout = 10 * time.Second
+	request.Metadata.ConnectTimeout = time.Second
+	request.Metadata.NoRedirect = true
+	cfg = ClientConfigFor(sessionCfg, request)
+	if cfg.Timeout != 10*time.Second || cfg.ConnectTimeout != time.Second || cfg.FollowRedirects {
+		t.Errorf("with metadata got Timeout = %v, ConnectTimeout = %v, FollowRedirects = %v", cfg.Timeout, cfg.ConnectTimeout, cfg.FollowRedirects)
+	}
+}
+
 func TestExecutor_ExecuteWithContext_PreCancelled(t *testing.T) {
 	var hits atomic.Int32
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -619,3 +686,112 @@ func TestExecutor_RequestResultStorage(t *testing.T) {
 		}
 	}
 }
+
+func TestExecutor_OpenAPIValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/users/1" {
+			w.Write([]byte(`{"id": 1}`))
+			return
+		}
+		w.Write([]byte(`{"id": "2"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	spec := `openapi: 3.0.3
+info: {title: Users}
+paths:
+  /users/{id}:
+    get:
+      responses:
+        '200':
+          description: A user
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id: {type: integer}
+`
+	if err := os.WriteFile(filepath.Join(dir, "spec.yaml"), []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionCfg := session.DefaultSessionConfig()
+	sessionCfg.Validation.OpenAPI = "spec.yaml"
+	exec := New(sessionCfg, variables.NewVariableProcessor(), Options{
+		HTTPFilePath: filepath.Join(dir, "api.http"),
+		NoSession:    true,
+		NoHistory:    true,
+	})
+
+	request := &models.HttpRequest{
+		Method:   "GET",
+		URL:      server.URL + "/users/1",
+		Headers:  map[string]string{},
+		Metadata: models.RequestMetadata{PostScript: `client.test("ok", function() {});`},
+	}
+	result, err := exec.Execute(request)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(result.TestResults) != 3 || result.TestResults[0].Name != "ok" || result.TestResults[2].Name != "OpenAPI GET /users/{id}: body" {
+		t.Errorf("Execute() tests = %+v, want the script test then the OpenAPI checks", result.TestResults)
+	}
+
+	request = &models.HttpRequest{Method: "GET", URL: server.URL + "/users/2", Headers: map[string]string{}}
+	result, err = exec.Execute(request)
+	if err == nil || !strings.Contains(err.Error(), "$.id: expected integer, got string") {
+		t.Errorf("Execute() error = %v, want the contract violation", err)
+	}
+	if result == nil || len(result.TestResults) != 2 || result.TestResults[1].Passed {
+		t.Errorf("Execute() tests = %+v", result)
+	}
+
+	request.Metadata.OpenAPI = filepath.Join(dir, "missing.yaml")
+	if _, err := exec.Execute(request); err == nil {
+		t.Error("a missing spec should fail before sending")
+	}
+}
+
+func TestExecutor_OpenAPIValidationOtherHost(t *testing.T) {
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "x"}`))
+	}))
+	defer authServer.Close()
+
+	dir := t.TempDir()
+	spec := `openapi: 3.0.3
+info: {title: Users}
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users/{id}:
+    get:
+      responses:
+        '200': {description: A user}
+`
+	if err := os.WriteFile(filepath.Join(dir, "spec.yaml"), []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionCfg := session.DefaultSessionConfig()
+	sessionCfg.Validation.OpenAPI = "spec.yaml"
+	exec := New(sessionCfg, variables.NewVariableProcessor(), Options{
+		HTTPFilePath: filepath.Join(dir, "api.http"),
+		NoSession:    true,
+		NoHistory:    true,
+	})
+
+	// A request to a host that isn't a server of the spec is not validated
+	request := &models.HttpRequest{Method: "POST", URL: authServer.URL + "/oauth/token", Headers: map[string]string{}}
+	result, err := exec.Execute(request)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(result.TestResults) != 0 {
+		t.Errorf("Execute() tests = %+v, want none", result.TestResults)
+	}
+}
@@ -0,0 +1,143 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/client"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// sendWithRetry sends the request, retrying according to its retry policy.
+// Every attempt sends a fresh copy of the request so that auth headers,
+// digest challenges and multipart bodies are rebuilt from scratch. The
+// attempts are returned only if the request has a retry policy. Once part of
+// a body has been written to the stream, the request is not retried.
+func (e *Executor) sendWithRetry(ctx context.Context, httpClient client.HTTPDoer, request *models.HttpRequest, streamed *countingWriter) (*models.HttpResponse, []models.RequestAttempt, error) {
+	policy := request.Metadata.Retry
+	if !policy.Enabled() {
+		resp, err := httpClient.SendWithContext(ctx, request)
+		return resp, nil, err
+	}
+
+	var attempts []models.RequestAttempt
+	for attempt := 1; ; attempt++ {
+		sent := request.Clone()
+		start := time.Now()
+		resp, err := httpClient.SendWithContext(ctx, sent)
+
+		record := models.RequestAttempt{
+			StartTime:  start.UnixMilli(),
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if resp != nil {
+			record.StatusCode = resp.StatusCode
+		}
+		if err != nil {
+			record.Error = err.Error()
+		}
+		attempts = append(attempts, record)
+
+		reason, retryable := retryReason(policy, resp, err)
+		if retryable && streamed != nil && streamed.written > 0 {
+			reason += ", response already partly written"
+			retryable = false
+		}
+		if !retryable || attempt > policy.Retries || ctx.Err() != nil {
+			// Keep the headers that were actually sent (e.g. processed auth)
+			request.Headers = sent.Headers
+			if attempt > 1 {
+				if retryable {
+					reason += ", giving up"
+				}
+				e.log("Attempt %d/%d: %s", attempt, policy.Retries+1, reason)
+			}
+			return resp, attempts, err
+		}
+
+		delay := policy.BackoffDelay(attempt)
+		if after, ok := retryAfter(resp, time.Now()); ok {
+			delay = after
+		}
+		e.log("Attempt %d/%d: %s, retrying in %s", attempt, policy.Retries+1, reason, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, attempts, errors.Wrap(ctx.Err(), "request cancelled while waiting to retry")
+		case <-timer.C:
+		}
+	}
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	writer  io.Writer
+	written int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// retryReason reports whether an attempt should be retried under the policy,
+// and describes why
+func retryReason(policy models.RetryPolicy, resp *models.HttpResponse, err error) (string, bool) {
+	if err != nil {
+		if isTimeout(err) {
+			return "timeout", policy.RetriesTimeout()
+		}
+		return err.Error(), policy.RetriesError()
+	}
+	if resp == nil {
+		return "", false
+	}
+	return resp.StatusMessage, policy.RetriesStatus(resp.StatusCode)
+}
+
+// isTimeout reports whether err is a timeout from the client or a deadline
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, errors.ErrTimeout) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryAfter returns the delay requested by a Retry-After header, given in
+// seconds or as an HTTP date, capped at models.MaxRetryDelay
+func retryAfter(resp *models.HttpResponse, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	var value string
+	for name, values := range resp.Headers {
+		if strings.EqualFold(name, "Retry-After") && len(values) > 0 {
+			value = strings.TrimSpace(values[0])
+			break
+		}
+	}
+	if value == "" {
+		return 0, false
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = date.Sub(now)
+	} else {
+		return 0, false
+	}
+	return min(max(delay, 0), models.MaxRetryDelay), true
+}
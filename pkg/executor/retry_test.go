@@ -0,0 +1,225 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/session"
+	"github.com/ideaspaper/restclient/pkg/variables"
+)
+
+// newRetryExecutor creates an executor that records its verbose log lines
+func newRetryExecutor(sessionCfg *session.SessionConfig, logs *[]string) *Executor {
+	if sessionCfg == nil {
+		sessionCfg = session.DefaultSessionConfig()
+	}
+	sessionCfg.Environment.RememberCookies = false
+	return New(sessionCfg, variables.NewVariableProcessor(), Options{
+		NoSession: true,
+		NoHistory: true,
+		Verbose:   true,
+		LogFunc: func(format string, args ...any) {
+			*logs = append(*logs, fmt.Sprintf(format, args...))
+		},
+	})
+}
+
+func TestExecutor_RetryRebuildsMultipartBody(t *testing.T) {
+	var hits atomic.Int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("upload")
+		if err != nil {
+			t.Errorf("attempt %d: missing file part: %v", hits.Load()+1, err)
+		} else {
+			data, _ := io.ReadAll(file)
+			bodies = append(bodies, string(data))
+		}
+
+		if hits.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	filePath := filepath.Join(t.TempDir(), "upload.txt")
+	if err := os.WriteFile(filePath, []byte("file content"), 0644); err != nil {
+		t.Fatalf("failed to write upload file: %v", err)
+	}
+
+	request := models.NewHttpRequest("POST", server.URL, map[string]string{}, nil, "", "")
+	request.MultipartParts = []models.MultipartPart{{Name: "upload", FilePath: filePath, IsFile: true}}
+	request.Metadata.Retry = models.RetryPolicy{Retries: 3}
+
+	var logs []string
+	result, err := newRetryExecutor(nil, &logs).Execute(request)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if result.Response.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", result.Response.StatusCode)
+	}
+	if len(bodies) != 3 || bodies[0] != "file content" || bodies[2] != "file content" {
+		t.Errorf("every attempt should send the file, got %q", bodies)
+	}
+	if len(logs) != 3 || !strings.Contains(logs[0], "Attempt 1/4: 503 Service Unavailable, retrying in 0s") || !strings.Contains(logs[2], "Attempt 3/4: 200 OK") {
+		t.Errorf("unexpected attempt logs: %q", logs)
+	}
+}
+
+func TestExecutor_RetryRepeatsDigestChallenge(t *testing.T) {
+	var authenticated atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), `Digest username="user"`) {
+			w.Header().Set("WWW-Authenticate", `Digest realm="test", nonce="abc", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if authenticated.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	request := models.NewHttpRequest("GET", server.URL, map[string]string{"Authorization": "Digest user pass"}, nil, "", "")
+	request.Metadata.Retry = models.RetryPolicy{Retries: 1, Delay: time.Millisecond}
+
+	var logs []string
+	result, err := newRetryExecutor(nil, &logs).Execute(request)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if result.Response.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", result.Response.StatusCode)
+	}
+	if authenticated.Load() != 2 {
+		t.Errorf("expected 2 authenticated attempts, got %d", authenticated.Load())
+	}
+}
+
+func TestExecutor_RetryGivesUp(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	request := models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", "")
+	request.Metadata.Retry = models.RetryPolicy{Retries: 2, Backoff: models.BackoffConstant, Delay: time.Millisecond}
+
+	var logs []string
+	result, err := newRetryExecutor(nil, &logs).Execute(request)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if result.Response.StatusCode != http.StatusBadGateway {
+		t.Errorf("StatusCode = %d, want the last response (502)", result.Response.StatusCode)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", hits.Load())
+	}
+	if len(logs) != 3 || !strings.HasSuffix(logs[2], "giving up") {
+		t.Errorf("unexpected attempt logs: %q", logs)
+	}
+}
+
+func TestExecutor_RetryOnlyListedStatuses(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	request := models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", "")
+	request.Metadata.Retry = models.RetryPolicy{Retries: 3, StatusCodes: []int{502}, Delay: time.Millisecond}
+
+	var logs []string
+	if _, err := newRetryExecutor(nil, &logs).Execute(request); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if hits.Load() != 1 {
+		t.Errorf("503 is not in @retry-on, expected 1 attempt, got %d", hits.Load())
+	}
+}
+
+func TestExecutor_RetryOnTimeout(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	sessionCfg := session.DefaultSessionConfig()
+	sessionCfg.HTTP.TimeoutMs = 50
+
+	request := models.NewHttpRequest("GET", server.URL, map[string]string{}, nil, "", "")
+	request.Metadata.Retry = models.RetryPolicy{Retries: 1, OnTimeout: true, Delay: time.Millisecond}
+
+	var logs []string
+	result, err := newRetryExecutor(sessionCfg, &logs).Execute(request)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if result.Response.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", result.Response.StatusCode)
+	}
+	if len(logs) == 0 || !strings.Contains(logs[0], "Attempt 1/2: timeout") {
+		t.Errorf("unexpected attempt logs: %q", logs)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"missing", "", 0, false},
+		{"seconds", "3", 3 * time.Second, true},
+		{"http date", now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{"date in the past", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"capped", "3600", models.MaxRetryDelay, true},
+		{"invalid", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &models.HttpResponse{Headers: map[string][]string{}}
+			if tt.header != "" {
+				resp.Headers["Retry-After"] = []string{tt.header}
+			}
+
+			got, ok := retryAfter(resp, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// Package formatter formats .http and .rest files in a canonical style.
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/ideaspaper/restclient/internal/constants"
+	"github.com/ideaspaper/restclient/pkg/parser"
+)
+
+// headerNames are headers whose usual spelling differs from
+// http.CanonicalHeaderKey
+var headerNames = map[string]string{
+	"content-md5":      "Content-MD5",
+	"dnt":              "DNT",
+	"etag":             "ETag",
+	"te":               "TE",
+	"www-authenticate": "WWW-Authenticate",
+	"x-xss-protection": "X-XSS-Protection",
+}
+
+// tokenRegex matches a valid header name
+var tokenRegex = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+// metadataRegex splits a metadata line into its comment marker, key and
+// value
+var metadataRegex = regexp.MustCompile(`^\s*(#|//)\s*@([\w-]+)(?:\s+(.*?))?\s*$`)
+
+// Format formats the content of an .http file:
+//   - ### separators are written as "###" followed by their title, with
+//     one blank line before and after
+//   - runs of blank lines outside of bodies become a single blank line
+//   - header names are canonically cased and written as "Name: value"
+//   - consecutive @name = value declarations are aligned on "="
+//   - JSON bodies are indented with two spaces
+//
+// Formatting does not change the requests a file holds, except for
+// whitespace at the end of bodies.
+func Format(content string) string {
+	crlf := strings.Contains(content, "\r\n")
+	if crlf {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+
+	var out []string
+	for _, block := range parser.Parse(content).Blocks {
+		lines := formatBlock(block)
+		if block.Index == 0 {
+			out = append(out, lines...)
+			continue
+		}
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		out = append(out, formatDelimiter(block.Nodes[0].Raw))
+		if len(lines) > 0 {
+			out = append(out, "")
+			out = append(out, lines...)
+		}
+	}
+	if len(out) == 0 {
+		return ""
+	}
+
+	formatted := strings.Join(out, "\n") + "\n"
+	if crlf {
+		formatted = strings.ReplaceAll(formatted, "\n", "\r\n")
+	}
+	return formatted
+}
+
+// formatBlock returns the formatted lines of a block without its
+// delimiter and without leading or trailing blank lines
+func formatBlock(block *parser.Block) []string {
+	var lines []string
+	nodes := block.Nodes
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+		switch n.Kind {
+		case parser.NodeDelimiter:
+		case parser.NodeBlank:
+			if len(lines) > 0 && lines[len(lines)-1] != "" {
+				lines = append(lines, "")
+			}
+		case parser.NodeFileVariable:
+			// Align a run of declarations
+			j := i
+			for j < len(nodes) && nodes[j].Kind == parser.NodeFileVariable {
+				j++
+			}
+			lines = append(lines, formatFileVariables(nodes[i:j])...)
+			i = j - 1
+		case parser.NodeMetadata:
+			lines = append(lines, formatMetadata(n))
+		case parser.NodeRequestLine:
+			lines = append(lines, formatRequestLine(n)...)
+		case parser.NodeHeader:
+			lines = append(lines, formatHeader(n))
+		case parser.NodeBody:
+			body := formatBody(block, n)
+			// The blank line before a body is enough
+			for len(body) > 0 && len(lines) > 0 && lines[len(lines)-1] == "" && strings.TrimSpace(body[0]) == "" {
+				body = body[1:]
+			}
+			lines = append(lines, body...)
+		case parser.NodeComment, parser.NodeImport:
+			lines = append(lines, strings.TrimSpace(n.Raw))
+		default:
+			lines = append(lines, strings.Split(n.Raw, "\n")...)
+		}
+	}
+
+	// Bodies end with their trailing blank lines
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// formatDelimiter writes a delimiter as ### and its title
+func formatDelimiter(raw string) string {
+	if title := strings.TrimSpace(strings.TrimLeft(raw, "#")); title != "" {
+		return parser.RequestDelimiter + " " + title
+	}
+	return parser.RequestDelimiter
+}
+
+// formatFileVariables aligns the = of consecutive declarations
+func formatFileVariables(nodes []*parser.Node) []string {
+	width := 0
+	for _, n := range nodes {
+		width = max(width, len(n.Name))
+	}
+
+	lines := make([]string, len(nodes))
+	for i, n := range nodes {
+		if n.Name == "" {
+			lines[i] = strings.TrimSpace(n.Raw)
+			continue
+		}
+		line := "@" + n.Name + strings.Repeat(" ", width-len(n.Name)) + " ="
+		if n.Value != "" {
+			line += " " + n.Value
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// formatMetadata writes metadata as "# @key value"
+func formatMetadata(n *parser.Node) string {
+	matches := metadataRegex.FindStringSubmatch(n.Raw)
+	if matches == nil {
+		return strings.TrimSpace(n.Raw)
+	}
+	line := matches[1] + " @" + matches[2]
+	if matches[3] != "" {
+		line += " " + matches[3]
+	}
+	return line
+}
+
+// formatRequestLine uppercases the method, separates it from the URL by a
+// single space and indents query string continuations
+func formatRequestLine(n *parser.Node) []string {
+	lines := strings.Split(n.Raw, "\n")
+	first := strings.TrimSpace(lines[0])
+	if method, rest, ok := strings.Cut(first, " "); ok && isMethod(method) {
+		first = strings.ToUpper(method) + " " + strings.TrimSpace(rest)
+	}
+	lines[0] = first
+	for i := 1; i < len(lines); i++ {
+		lines[i] = "    " + strings.TrimSpace(lines[i])
+	}
+	return lines
+}
+
+// isMethod reports whether a word of a request line is a method
+func isMethod(word string) bool {
+	switch strings.ToUpper(word) {
+	case "GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS", "CONNECT", "TRACE",
+		"LOCK", "UNLOCK", "PROPFIND", "PROPPATCH", "COPY", "MOVE", "MKCOL", "MKCALENDAR",
+		"ACL", "SEARCH", "WEBSOCKET", "GRPC":
+		return true
+	}
+	return false
+}
+
+// formatHeader writes a header as "Name: value" with the name canonically
+// cased. Malformed headers are left alone.
+func formatHeader(n *parser.Node) string {
+	if !strings.Contains(n.Raw, ":") || !tokenRegex.MatchString(n.Name) {
+		return strings.TrimSpace(n.Raw)
+	}
+	return headerName(n.Name) + ": " + n.Value
+}
+
+// headerName returns the canonical spelling of a header name, e.g.
+// "Content-Type" for "content-type"
+func headerName(name string) string {
+	if known, ok := headerNames[strings.ToLower(name)]; ok {
+		return known
+	}
+	return http.CanonicalHeaderKey(name)
+}
+
+// formatBody indents a JSON body. Other bodies are left alone.
+func formatBody(block *parser.Block, n *parser.Node) []string {
+	lines := strings.Split(n.Value, "\n")
+	if len(block.All(parser.NodeBody)) != 1 || !isJSON(block) {
+		return lines
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(strings.TrimSpace(n.Value)), "", "  "); err != nil {
+		return lines
+	}
+	return strings.Split(buf.String(), "\n")
+}
+
+// isJSON reports whether the Content-Type of a block is JSON. GraphQL
+// bodies are queries, not JSON.
+func isJSON(block *parser.Block) bool {
+	json := false
+	for _, h := range block.All(parser.NodeHeader) {
+		switch strings.ToLower(h.Name) {
+		case "x-request-type":
+			if strings.EqualFold(h.Value, "GraphQL") {
+				return false
+			}
+		case strings.ToLower(constants.HeaderContentType):
+			json = strings.Contains(strings.ToLower(h.Value), "json")
+		}
+	}
+	return json
+}
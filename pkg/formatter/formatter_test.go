@@ -0,0 +1,108 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/ideaspaper/restclient/pkg/parser"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "headers",
+			input: "get https://api.example.com\ncontent-type:application/json\n  x-request-id :  1\netag: abc\nBad header\n",
+			want:  "GET https://api.example.com\nContent-Type: application/json\nX-Request-Id: 1\nETag: abc\nBad header\n",
+		},
+		{
+			name:  "file variables",
+			input: "@host=api.example.com\n@baseUrl   =   https://{{host}}\n@empty =\n\nGET {{baseUrl}}",
+			want:  "@host    = api.example.com\n@baseUrl = https://{{host}}\n@empty   =\n\nGET {{baseUrl}}\n",
+		},
+		{
+			name:  "json body",
+			input: "POST https://api.example.com\nContent-Type: application/json\n\n{\"name\":\"John\",\"tags\":[\"a\",\"b\"]}\n",
+			want:  "POST https://api.example.com\nContent-Type: application/json\n\n{\n  \"name\": \"John\",\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}\n",
+		},
+		{
+			name:  "json body with variables left alone",
+			input: "POST https://api.example.com\nContent-Type: application/json\n\n{\"id\": {{id}}}\n",
+			want:  "POST https://api.example.com\nContent-Type: application/json\n\n{\"id\": {{id}}}\n",
+		},
+		{
+			name:  "separators",
+			input: "\n\nGET https://a.example.com\n#####   Users  \nGET https://b.example.com\n\n\n\n###\n\n\n# @name  c\nGET https://c.example.com\n\n\n",
+			want:  "GET https://a.example.com\n\n### Users\n\nGET https://b.example.com\n\n###\n\n# @name c\nGET https://c.example.com\n",
+		},
+		{
+			name:  "query continuations",
+			input: "GET https://api.example.com/users\n?page=1\n      &limit=10\n",
+			want:  "GET https://api.example.com/users\n    ?page=1\n    &limit=10\n",
+		},
+		{
+			name:  "scripts and text bodies untouched",
+			input: "POST https://api.example.com\nContent-Type: text/plain\n\n  indented  \n\n\n  text\n\n> {%\n    client.log(1)\n%}\n",
+			want:  "POST https://api.example.com\nContent-Type: text/plain\n\n  indented  \n\n\n  text\n\n> {%\n    client.log(1)\n%}\n",
+		},
+		{
+			name:  "crlf",
+			input: "get https://api.example.com\r\naccept: */*\r\n",
+			want:  "GET https://api.example.com\r\nAccept: */*\r\n",
+		},
+		{
+			name:  "empty",
+			input: "\n\n",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Format(tt.input)
+			if got != tt.want {
+				t.Errorf("Format() =\n%q\nwant\n%q", got, tt.want)
+			}
+			if again := Format(got); again != got {
+				t.Errorf("Format() is not idempotent:\n%q", again)
+			}
+		})
+	}
+}
+
+func TestFormatKeepsRequests(t *testing.T) {
+	input := `@host=api.example.com
+# @name create
+post https://{{host}}/users
+content-type: application/json
+x-api-key:{{key}}
+
+{"name":"John"}
+
+
+###
+GET https://{{host}}/users
+    ?page=1
+accept: application/json
+`
+	before := parser.NewHttpRequestParser(input, nil, "").ParseAllWithWarnings().Requests
+	formatted := Format(input)
+	after := parser.NewHttpRequestParser(formatted, nil, "").ParseAllWithWarnings().Requests
+
+	if len(before) != len(after) {
+		t.Fatalf("got %d requests after formatting, want %d", len(after), len(before))
+	}
+	for i := range before {
+		if before[i].Method != after[i].Method || before[i].URL != after[i].URL || before[i].Metadata.Name != after[i].Metadata.Name {
+			t.Errorf("request %d changed: %s %s -> %s %s", i, before[i].Method, before[i].URL, after[i].Method, after[i].URL)
+		}
+		if len(before[i].Headers) != len(after[i].Headers) {
+			t.Errorf("request %d headers changed: %v -> %v", i, before[i].Headers, after[i].Headers)
+		}
+	}
+	if after[0].Headers["X-Api-Key"] != "{{key}}" {
+		t.Errorf("headers = %v", after[0].Headers)
+	}
+}
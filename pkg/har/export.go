@@ -0,0 +1,276 @@
+package har
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ideaspaper/restclient/internal/constants"
+	"github.com/ideaspaper/restclient/internal/httputil"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// New creates an archive of entries
+func New(entries []Entry) *HAR {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	return &HAR{Log: Log{
+		Version: Version,
+		Creator: Creator{Name: "restclient", Version: creatorVersion()},
+		Entries: entries,
+	}}
+}
+
+// creatorVersion returns the module version of the running binary
+func creatorVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// FromHistory creates an archive of history items, oldest first. History
+// keeps no response bodies, so only their size is exported.
+func FromHistory(items []models.HistoricalHttpRequest) *HAR {
+	entries := make([]Entry, 0, len(items))
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		request := &models.HttpRequest{
+			Method:  item.Method,
+			URL:     item.URL,
+			Headers: item.Headers,
+			RawBody: item.Body,
+		}
+
+		var response *models.HttpResponse
+		if item.Response != nil {
+			response = &models.HttpResponse{
+				StatusCode:      item.Response.StatusCode,
+				StatusMessage:   item.Response.StatusMessage,
+				HttpVersion:     item.Response.HttpVersion,
+				Headers:         item.Response.Headers,
+				BodySizeInBytes: item.Response.BodySize,
+				Timing:          item.Response.Timing,
+			}
+		}
+
+		entry := NewEntry(request, response, time.UnixMilli(item.StartTime))
+		if response == nil && len(item.Attempts) > 0 {
+			entry.Response.Error = item.Attempts[len(item.Attempts)-1].Error
+		}
+		entries = append(entries, entry)
+	}
+	return New(entries)
+}
+
+// NewEntry creates an entry for a request and its response, which is nil
+// if none was received
+func NewEntry(request *models.HttpRequest, response *models.HttpResponse, started time.Time) Entry {
+	entry := Entry{
+		StartedDateTime: started,
+		Request:         newRequest(request),
+		Response: Response{
+			Cookies:     []Cookie{},
+			Headers:     []NameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1},
+	}
+	if response == nil {
+		return entry
+	}
+
+	if response.HttpVersion != "" {
+		entry.Request.HTTPVersion = response.HttpVersion
+	}
+	entry.Response = newResponse(response)
+	entry.Timings = newTimings(response.Timing)
+	entry.Time = entry.Timings.total()
+	return entry
+}
+
+func newRequest(request *models.HttpRequest) Request {
+	result := Request{
+		Method:      request.Method,
+		URL:         request.URL,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []Cookie{},
+		Headers:     []NameValue{},
+		QueryString: queryString(request.URL),
+		HeadersSize: -1,
+		BodySize:    len(request.RawBody),
+	}
+
+	names := make([]string, 0, len(request.Headers))
+	for name := range request.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		value := request.Headers[name]
+		result.Headers = append(result.Headers, NameValue{Name: name, Value: value})
+		if strings.EqualFold(name, constants.HeaderCookie) {
+			if cookies, err := http.ParseCookie(value); err == nil {
+				for _, c := range cookies {
+					result.Cookies = append(result.Cookies, Cookie{Name: c.Name, Value: c.Value})
+				}
+			}
+		}
+	}
+
+	if request.RawBody != "" {
+		contentType, _ := httputil.GetHeader(request.Headers, constants.HeaderContentType)
+		result.PostData = &PostData{MimeType: contentType, Text: request.RawBody}
+	}
+	return result
+}
+
+// queryString returns the query parameters of a URL in order
+func queryString(rawURL string) []NameValue {
+	params := []NameValue{}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.RawQuery == "" {
+		return params
+	}
+	for _, pair := range strings.Split(parsed.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		params = append(params, NameValue{Name: name, Value: value})
+	}
+	return params
+}
+
+func newResponse(response *models.HttpResponse) Response {
+	result := Response{
+		Status:      response.StatusCode,
+		StatusText:  statusText(response),
+		HTTPVersion: response.HttpVersion,
+		Cookies:     []Cookie{},
+		Headers:     []NameValue{},
+		Content: Content{
+			Size:     response.BodySizeInBytes,
+			MimeType: response.ContentType(),
+		},
+		HeadersSize: -1,
+		BodySize:    response.BodySizeInBytes,
+	}
+	if response.HeadersSizeBytes > 0 {
+		result.HeadersSize = response.HeadersSizeBytes
+	}
+	if location, ok := httputil.GetHeaderFromSlice(response.Headers, constants.HeaderLocation); ok {
+		result.RedirectURL = location
+	}
+
+	switch {
+	case utf8.ValidString(response.Body):
+		result.Content.Text = response.Body
+	default:
+		result.Content.Text = base64.StdEncoding.EncodeToString([]byte(response.Body))
+		result.Content.Encoding = "base64"
+	}
+
+	names := make([]string, 0, len(response.Headers))
+	for name := range response.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, value := range response.Headers[name] {
+			result.Headers = append(result.Headers, NameValue{Name: name, Value: value})
+			if !strings.EqualFold(name, constants.HeaderSetCookie) {
+				continue
+			}
+			if c, err := http.ParseSetCookie(value); err == nil {
+				result.Cookies = append(result.Cookies, fromHTTPCookie(c))
+			}
+		}
+	}
+	return result
+}
+
+// statusText returns the reason phrase of a response, which HttpResponse
+// keeps after the status code
+func statusText(response *models.HttpResponse) string {
+	text := strings.TrimPrefix(response.StatusMessage, strconv.Itoa(response.StatusCode))
+	if text = strings.TrimSpace(text); text != "" {
+		return text
+	}
+	return http.StatusText(response.StatusCode)
+}
+
+func fromHTTPCookie(c *http.Cookie) Cookie {
+	cookie := Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		HTTPOnly: c.HttpOnly,
+		Secure:   c.Secure,
+	}
+	if !c.Expires.IsZero() {
+		cookie.Expires = c.Expires.UTC().Format(time.RFC3339)
+	}
+	return cookie
+}
+
+// newTimings maps the phases of a response to HAR timings. Without phase
+// timings the whole request counts as waiting for the response.
+func newTimings(timing models.ResponseTiming) Timings {
+	if !timing.HasPhases() {
+		return Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: milliseconds(timing.Total)}
+	}
+
+	timings := Timings{
+		Blocked: -1,
+		DNS:     optionalMilliseconds(timing.DNSLookup),
+		Connect: optionalMilliseconds(timing.TCPConnection + timing.TLSHandshake),
+		SSL:     optionalMilliseconds(timing.TLSHandshake),
+		Wait:    milliseconds(timing.ServerProcessing),
+		Receive: milliseconds(timing.ContentTransfer),
+	}
+	// Time not covered by a phase, such as redirects, was spent before the
+	// final connection
+	if rest := timing.Total - timing.DNSLookup - timing.TCPConnection - timing.TLSHandshake -
+		timing.ServerProcessing - timing.ContentTransfer; rest > 0 {
+		timings.Blocked = milliseconds(rest)
+	}
+	return timings
+}
+
+// total returns the sum of the phases that applied
+func (t Timings) total() float64 {
+	total := 0.0
+	for _, phase := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if phase > 0 {
+			total += phase
+		}
+	}
+	return total
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func optionalMilliseconds(d time.Duration) float64 {
+	if d <= 0 {
+		return -1
+	}
+	return milliseconds(d)
+}
@@ -0,0 +1,154 @@
+// Package har reads and writes HTTP Archive (HAR) 1.2 files, converting
+// browser captures to .http requests and recorded exchanges to HAR.
+package har
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// Version is the HAR version written by this package
+const Version = "1.2"
+
+// HAR is the root of an HTTP Archive
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log holds the entries of an archive
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator names the application that wrote an archive
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is a single request and its response
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"` // Sum of the timings, in milliseconds
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	Cache           struct{}  `json:"cache"`
+	Timings         Timings   `json:"timings"`
+	ResourceType    string    `json:"_resourceType,omitempty"` // Set by Chrome, e.g. "xhr" or "image"
+}
+
+// Request is the request of an entry
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Response is the response of an entry. Status is 0 if none was received.
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+	Error       string      `json:"_error,omitempty"` // Why no response was received
+}
+
+// NameValue is a header or query parameter
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Cookie is a cookie sent with a request or set by a response
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+// PostData is the body of a request
+type PostData struct {
+	MimeType string  `json:"mimeType"`
+	Params   []Param `json:"params,omitempty"`
+	Text     string  `json:"text"`
+}
+
+// Param is a field of a form body
+type Param struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// Content is the body of a response
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"` // "base64" for binary text
+}
+
+// Timings are the phases of an entry in milliseconds, -1 when a phase did
+// not apply. Connect includes SSL.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// Load reads a HAR file
+func Load(path string) (*HAR, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read HAR file")
+	}
+	return Parse(data)
+}
+
+// Parse parses a HAR document
+func Parse(data []byte) (*HAR, error) {
+	var archive struct {
+		Log *Log `json:"log"`
+	}
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, errors.Wrap(err, "failed to parse HAR file")
+	}
+	if archive.Log == nil {
+		return nil, errors.NewValidationError("HAR file", "missing log")
+	}
+	return &HAR{Log: *archive.Log}, nil
+}
+
+// Write writes an archive as indented JSON
+func Write(w io.Writer, archive *HAR) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return errors.Wrap(encoder.Encode(archive), "failed to write HAR")
+}
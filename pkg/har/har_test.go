@@ -0,0 +1,306 @@
+package har
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/parser"
+)
+
+const capture = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "WebInspector", "version": "537.36"},
+    "entries": [
+      {
+        "_resourceType": "document",
+        "startedDateTime": "2024-01-02T03:04:05.123Z",
+        "time": 80.5,
+        "request": {
+          "method": "POST",
+          "url": "https://app.example.com/login",
+          "httpVersion": "http/2.0",
+          "headers": [
+            {"name": ":authority", "value": "app.example.com"},
+            {"name": "content-type", "value": "application/x-www-form-urlencoded"},
+            {"name": "accept-encoding", "value": "gzip, deflate, br, zstd"},
+            {"name": "content-length", "value": "24"},
+            {"name": "cookie", "value": "theme=dark"}
+          ],
+          "cookies": [{"name": "theme", "value": "dark"}],
+          "queryString": [],
+          "postData": {
+            "mimeType": "application/x-www-form-urlencoded",
+            "params": [{"name": "user", "value": "ann lee"}, {"name": "pass", "value": "s&cret"}]
+          },
+          "headersSize": -1,
+          "bodySize": 24
+        },
+        "response": {
+          "status": 302,
+          "statusText": "Found",
+          "httpVersion": "http/2.0",
+          "headers": [{"name": "set-cookie", "value": "sid=abc; Path=/; HttpOnly; Secure"}],
+          "cookies": [],
+          "content": {"size": 0, "mimeType": "text/html"},
+          "redirectURL": "/home",
+          "headersSize": -1,
+          "bodySize": 0
+        },
+        "cache": {},
+        "timings": {"blocked": 1, "dns": -1, "connect": -1, "send": 0.2, "wait": 70, "receive": 9.3, "ssl": -1}
+      },
+      {
+        "_resourceType": "image",
+        "startedDateTime": "2024-01-02T03:04:06Z",
+        "time": 5,
+        "request": {"method": "GET", "url": "https://cdn.example.com/logo.png", "httpVersion": "http/2.0", "headers": [], "cookies": [], "queryString": [], "headersSize": -1, "bodySize": 0},
+        "response": {"status": 200, "statusText": "", "httpVersion": "http/2.0", "headers": [], "cookies": [], "content": {"size": 10, "mimeType": "image/png"}, "redirectURL": "", "headersSize": -1, "bodySize": 10},
+        "cache": {},
+        "timings": {"send": 0, "wait": 4, "receive": 1}
+      },
+      {
+        "startedDateTime": "2024-01-02T03:04:07Z",
+        "time": 30,
+        "request": {
+          "method": "POST",
+          "url": "https://app.example.com/api/upload?draft=true",
+          "httpVersion": "HTTP/1.1",
+          "headers": [{"Name": "Cookie", "value": "sid=abc"}],
+          "cookies": [],
+          "queryString": [{"name": "draft", "value": "true"}],
+          "postData": {
+            "mimeType": "multipart/form-data; boundary=----WebKitFormBoundary",
+            "params": [{"name": "title", "value": "Cat"}, {"name": "photo", "fileName": "cat.jpg", "contentType": "image/jpeg"}]
+          },
+          "headersSize": -1,
+          "bodySize": 200
+        },
+        "response": {
+          "status": 201,
+          "statusText": "Created",
+          "httpVersion": "HTTP/1.1",
+          "headers": [{"name": "Content-Type", "value": "application/json"}],
+          "cookies": [{"name": "theme", "value": "", "expires": "1970-01-01T00:00:00Z"}],
+          "content": {"size": 2, "mimeType": "application/json", "text": "{}"},
+          "redirectURL": "",
+          "headersSize": -1,
+          "bodySize": 2
+        },
+        "cache": {},
+        "timings": {"send": 1, "wait": 25, "receive": 4}
+      },
+      {
+        "startedDateTime": "2024-01-02T03:04:08Z",
+        "time": 0,
+        "request": {"method": "GET", "url": "data:image/png;base64,AAAA", "httpVersion": "", "headers": [], "cookies": [], "queryString": [], "headersSize": -1, "bodySize": 0},
+        "response": {"status": 200, "statusText": "", "httpVersion": "", "headers": [], "cookies": [], "content": {"size": 3, "mimeType": "image/png"}, "redirectURL": "", "headersSize": -1, "bodySize": 3},
+        "cache": {},
+        "timings": {"send": 0, "wait": 0, "receive": 0}
+      }
+    ]
+  }
+}`
+
+func TestParse(t *testing.T) {
+	archive, err := Parse([]byte(capture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.Log.Entries) != 4 {
+		t.Fatalf("got %d entries, want 4", len(archive.Log.Entries))
+	}
+	entry := archive.Log.Entries[0]
+	if entry.ResourceType != "document" || !entry.StartedDateTime.Equal(time.Date(2024, 1, 2, 3, 4, 5, 123e6, time.UTC)) || entry.Timings.Wait != 70 {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	if _, err := Parse([]byte(`{"info": {}}`)); !errors.Is(err, errors.ErrInvalidInput) {
+		t.Errorf("Parse() without log error = %v, want ErrInvalidInput", err)
+	}
+	if _, err := Parse([]byte(`{`)); err == nil {
+		t.Error("Parse() of invalid JSON should fail")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	archive, err := Parse([]byte(capture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, result := Convert(archive, ImportOptions{})
+	if result.RequestsCount != 2 || result.SkippedCount != 2 {
+		t.Errorf("Convert() = %d requests, %d skipped, want 2, 2", result.RequestsCount, result.SkippedCount)
+	}
+
+	want := "POST https://app.example.com/login\n" +
+		"content-type: application/x-www-form-urlencoded\n" +
+		"\n" +
+		"user=ann+lee&pass=s%26cret\n" +
+		"\n###\n\n" +
+		"POST https://app.example.com/api/upload?draft=true\n" +
+		"Content-Type: multipart/form-data; boundary=----RestClientBoundary\n" +
+		"\n" +
+		"------RestClientBoundary\n" +
+		"Content-Disposition: form-data; name=\"title\"\n" +
+		"\n" +
+		"Cat\n" +
+		"------RestClientBoundary\n" +
+		"Content-Disposition: form-data; name=\"photo\"; filename=\"cat.jpg\"\n" +
+		"Content-Type: image/jpeg\n" +
+		"\n" +
+		"< ./cat.jpg\n" +
+		"------RestClientBoundary--\n"
+	if content != want {
+		t.Errorf("Convert() =\n%s\nwant\n%s", content, want)
+	}
+
+	parsed := parser.NewHttpRequestParser(content, map[string]string{}, "").ParseAllWithWarnings()
+	if len(parsed.Requests) != 2 || len(parsed.Warnings) != 0 {
+		t.Fatalf("ParseAllWithWarnings() = %d requests, warnings %v", len(parsed.Requests), parsed.Warnings)
+	}
+	parts := parsed.Requests[1].MultipartParts
+	if len(parts) != 2 || parts[0].Value != "Cat" || !parts[1].IsFile || parts[1].FilePath != "./cat.jpg" {
+		t.Errorf("multipart parts = %+v", parts)
+	}
+
+	// The upload response deleted the theme cookie set before
+	cookies := result.Cookies["https://app.example.com"]
+	if len(result.Cookies) != 1 || len(cookies) != 1 || cookies[0].Name != "sid" || cookies[0].Value != "abc" || !cookies[0].HttpOnly {
+		t.Errorf("Cookies = %+v", result.Cookies)
+	}
+
+	_, result = Convert(archive, ImportOptions{IncludeStatic: true})
+	if result.RequestsCount != 3 || result.SkippedCount != 1 {
+		t.Errorf("Convert() with static = %d requests, %d skipped, want 3, 1", result.RequestsCount, result.SkippedCount)
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	harPath := filepath.Join(dir, "capture.har")
+	if err := os.WriteFile(harPath, []byte(capture), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "requests", "capture.http")
+	result, err := Import(harPath, ImportOptions{OutputFile: output})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.OutputFile != output || result.RequestsCount != 2 {
+		t.Errorf("Import() = %+v", result)
+	}
+	if data, err := os.ReadFile(output); err != nil || !strings.HasPrefix(string(data), "POST https://app.example.com/login\n") {
+		t.Errorf("output = %q, %v", data, err)
+	}
+
+	empty := filepath.Join(dir, "empty.har")
+	if err := os.WriteFile(empty, []byte(`{"log": {"version": "1.2", "entries": []}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(empty, ImportOptions{OutputFile: filepath.Join(dir, "empty.http")}); !errors.Is(err, errors.ErrInvalidInput) {
+		t.Errorf("Import() of an empty HAR error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestFromHistory(t *testing.T) {
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	items := []models.HistoricalHttpRequest{
+		{
+			Method:    "GET",
+			URL:       "https://api.example.com/down",
+			StartTime: started.Add(time.Minute).UnixMilli(),
+			Attempts:  []models.RequestAttempt{{Error: "timeout"}, {Error: "connection refused"}},
+		},
+		{
+			Method:    "POST",
+			URL:       "https://api.example.com/users?notify=1&tag=a%20b",
+			Headers:   map[string]string{"Content-Type": "application/json", "Cookie": "sid=abc; theme=dark"},
+			Body:      `{"name": "Ann"}`,
+			StartTime: started.UnixMilli(),
+			Response: &models.HistoricalResponse{
+				StatusCode:    201,
+				StatusMessage: "201 Created",
+				HttpVersion:   "HTTP/1.1",
+				Headers:       map[string][]string{"Content-Type": {"application/json"}, "Set-Cookie": {"sid=def; Path=/; Secure"}},
+				BodySize:      42,
+				Timing: models.ResponseTiming{
+					ServerProcessing: 30 * time.Millisecond,
+					ContentTransfer:  2500 * time.Microsecond,
+					Total:            40 * time.Millisecond,
+					ConnectionReused: true,
+				},
+			},
+		},
+	}
+
+	archive := FromHistory(items)
+	if archive.Log.Version != "1.2" || archive.Log.Creator.Name != "restclient" || len(archive.Log.Entries) != 2 {
+		t.Fatalf("log = %+v", archive.Log)
+	}
+
+	created := archive.Log.Entries[0]
+	if !created.StartedDateTime.Equal(started) {
+		t.Errorf("StartedDateTime = %v, want %v", created.StartedDateTime, started)
+	}
+	if len(created.Request.Cookies) != 2 || created.Request.Cookies[1] != (Cookie{Name: "theme", Value: "dark"}) {
+		t.Errorf("request cookies = %+v", created.Request.Cookies)
+	}
+	if len(created.Request.QueryString) != 2 || created.Request.QueryString[1] != (NameValue{Name: "tag", Value: "a b"}) {
+		t.Errorf("queryString = %+v", created.Request.QueryString)
+	}
+	if created.Response.StatusText != "Created" || created.Response.Content.Size != 42 || created.Response.Content.MimeType != "application/json" {
+		t.Errorf("response = %+v", created.Response)
+	}
+	if len(created.Response.Cookies) != 1 || created.Response.Cookies[0] != (Cookie{Name: "sid", Value: "def", Path: "/", Secure: true}) {
+		t.Errorf("response cookies = %+v", created.Response.Cookies)
+	}
+	// A reused connection has no DNS or connect phase; the rest of the
+	// total is reported as blocked
+	wantTimings := Timings{Blocked: 7.5, DNS: -1, Connect: -1, SSL: -1, Wait: 30, Receive: 2.5}
+	if created.Timings != wantTimings || created.Time != 40 {
+		t.Errorf("timings = %+v (time %v), want %+v", created.Timings, created.Time, wantTimings)
+	}
+
+	down := archive.Log.Entries[1]
+	if down.Response.Status != 0 || down.Response.Error != "connection refused" || down.Time != 0 {
+		t.Errorf("failed entry = %+v", down)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, archive); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(buf.Bytes()); err != nil {
+		t.Errorf("written archive does not parse: %v", err)
+	}
+}
+
+func TestNewTimings(t *testing.T) {
+	timing := models.ResponseTiming{
+		DNSLookup:        2 * time.Millisecond,
+		TCPConnection:    3 * time.Millisecond,
+		TLSHandshake:     4 * time.Millisecond,
+		ServerProcessing: 10 * time.Millisecond,
+		ContentTransfer:  time.Millisecond,
+		Total:            20 * time.Millisecond,
+	}
+	want := Timings{Blocked: -1, DNS: 2, Connect: 7, SSL: 4, Wait: 10, Receive: 1}
+	if got := newTimings(timing); got != want {
+		t.Errorf("newTimings() = %+v, want %+v", got, want)
+	}
+
+	// Without phases the whole request is waiting
+	want = Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: 15}
+	if got := newTimings(models.ResponseTiming{Total: 15 * time.Millisecond}); got != want {
+		t.Errorf("newTimings() without phases = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,267 @@
+package har
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/restclient/internal/constants"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/parser"
+)
+
+// formBoundary separates the parts of multipart bodies rebuilt from form
+// params
+const formBoundary = "----RestClientBoundary"
+
+// staticTypes are the Chrome resource types skipped unless static assets
+// are included
+var staticTypes = []string{"image", "font", "stylesheet", "script", "media", "manifest", "texttrack", "preflight"}
+
+// skippedHeaders are request headers that are set when sending or do not
+// carry over from a browser. Cookies go to the session instead, and
+// Accept-Encoding is dropped because browsers accept encodings the client
+// cannot decode.
+var skippedHeaders = []string{"host", "content-length", "connection", "cookie", "accept-encoding"}
+
+// ImportOptions configures the conversion of a HAR file
+type ImportOptions struct {
+	// OutputFile is the .http file to write (default: the HAR file name
+	// with a .http extension, in the current directory)
+	OutputFile string
+	// IncludeStatic keeps static assets and CORS preflight requests
+	IncludeStatic bool
+}
+
+// ImportResult contains the results of a conversion
+type ImportResult struct {
+	OutputFile    string
+	RequestsCount int
+	SkippedCount  int
+	// Cookies holds the cookies sent and set by the imported requests,
+	// keyed by origin (scheme://host)
+	Cookies map[string][]*http.Cookie
+}
+
+// Import reads a HAR file and writes its requests to a .http file
+func Import(harPath string, opts ImportOptions) (*ImportResult, error) {
+	archive, err := Load(harPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.OutputFile == "" {
+		name := strings.TrimSuffix(filepath.Base(harPath), filepath.Ext(harPath))
+		opts.OutputFile = name + ".http"
+	}
+
+	content, result := Convert(archive, opts)
+	if result.RequestsCount == 0 {
+		return nil, errors.NewValidationErrorWithValue("HAR file", harPath, "has no requests to import")
+	}
+	if dir := filepath.Dir(opts.OutputFile); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, errors.Wrap(err, "failed to create output directory")
+		}
+	}
+	if err := os.WriteFile(opts.OutputFile, []byte(content), 0644); err != nil {
+		return nil, errors.Wrapf(err, "failed to write %s", opts.OutputFile)
+	}
+	result.OutputFile = opts.OutputFile
+	return result, nil
+}
+
+// Convert converts the entries of an archive to .http requests
+func Convert(archive *HAR, opts ImportOptions) (string, *ImportResult) {
+	result := &ImportResult{Cookies: make(map[string][]*http.Cookie)}
+
+	var blocks []string
+	for i := range archive.Log.Entries {
+		entry := &archive.Log.Entries[i]
+		parsed, err := url.Parse(entry.Request.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || (!opts.IncludeStatic && isStatic(entry)) {
+			result.SkippedCount++
+			continue
+		}
+
+		blocks = append(blocks, writeRequest(&entry.Request))
+		result.RequestsCount++
+		addCookies(result.Cookies, parsed.Scheme+"://"+parsed.Host, entry)
+	}
+
+	return strings.Join(blocks, "\n"+parser.RequestDelimiter+"\n\n"), result
+}
+
+// isStatic reports whether an entry loaded a static asset, from its Chrome
+// resource type or else its response type
+func isStatic(entry *Entry) bool {
+	if entry.ResourceType != "" {
+		return slices.Contains(staticTypes, strings.ToLower(entry.ResourceType))
+	}
+	if strings.EqualFold(entry.Request.Method, http.MethodOptions) {
+		return false
+	}
+
+	mimeType := strings.ToLower(entry.Response.Content.MimeType)
+	for _, prefix := range []string{"image/", "font/", "audio/", "video/", "text/css", "text/javascript", "application/javascript", "application/font-"} {
+		if strings.HasPrefix(mimeType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeRequest(request *Request) string {
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(request.Method) + " " + request.URL + "\n")
+
+	body := ""
+	contentType := ""
+	if request.PostData != nil {
+		body = request.PostData.Text
+		contentType = request.PostData.MimeType
+		if body == "" && len(request.PostData.Params) > 0 {
+			body, contentType = formBody(request.PostData)
+		}
+	}
+
+	hasContentType := false
+	for _, h := range request.Headers {
+		name := strings.ToLower(h.Name)
+		if strings.HasPrefix(name, ":") || slices.Contains(skippedHeaders, name) {
+			continue
+		}
+		value := h.Value
+		if name == "content-type" {
+			hasContentType = true
+			if contentType != "" {
+				value = contentType
+			}
+		}
+		b.WriteString(h.Name + ": " + value + "\n")
+	}
+	if !hasContentType && body != "" && contentType != "" {
+		b.WriteString(constants.HeaderContentType + ": " + contentType + "\n")
+	}
+
+	if body != "" {
+		b.WriteString("\n" + body)
+		if !strings.HasSuffix(body, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// formBody rebuilds a form body from its params, returning the body and
+// its content type
+func formBody(postData *PostData) (string, string) {
+	if !strings.HasPrefix(strings.ToLower(postData.MimeType), constants.MIMEMultipartFormData) {
+		fields := make([]string, 0, len(postData.Params))
+		for _, p := range postData.Params {
+			fields = append(fields, url.QueryEscape(p.Name)+"="+url.QueryEscape(p.Value))
+		}
+		return strings.Join(fields, "&"), constants.MIMEApplicationFormURLEncoded
+	}
+
+	var b strings.Builder
+	for _, p := range postData.Params {
+		b.WriteString("--" + formBoundary + "\n")
+		disposition := fmt.Sprintf(`Content-Disposition: form-data; name="%s"`, p.Name)
+		if p.FileName != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, p.FileName)
+		}
+		b.WriteString(disposition + "\n")
+		if p.ContentType != "" {
+			b.WriteString(constants.HeaderContentType + ": " + p.ContentType + "\n")
+		}
+		b.WriteString("\n")
+		if p.FileName != "" {
+			// Browsers don't capture file contents
+			b.WriteString("< ./" + p.FileName + "\n")
+		} else {
+			b.WriteString(p.Value + "\n")
+		}
+	}
+	b.WriteString("--" + formBoundary + "--")
+	return b.String(), constants.MIMEMultipartFormData + "; boundary=" + formBoundary
+}
+
+// addCookies records the cookies an entry sent and received for its
+// origin, later values replacing earlier ones
+func addCookies(cookies map[string][]*http.Cookie, origin string, entry *Entry) {
+	var sent []*http.Cookie
+	if len(entry.Request.Cookies) > 0 {
+		for _, c := range entry.Request.Cookies {
+			sent = append(sent, toHTTPCookie(c))
+		}
+	} else {
+		for _, h := range entry.Request.Headers {
+			if !strings.EqualFold(h.Name, constants.HeaderCookie) {
+				continue
+			}
+			if parsed, err := http.ParseCookie(h.Value); err == nil {
+				sent = append(sent, parsed...)
+			}
+		}
+	}
+	// A sent cookie only carries its value, so keep the attributes of a
+	// cookie already set by a response
+	for _, c := range sent {
+		index := slices.IndexFunc(cookies[origin], func(existing *http.Cookie) bool { return existing.Name == c.Name })
+		if index >= 0 {
+			cookies[origin][index].Value = c.Value
+		} else {
+			cookies[origin] = append(cookies[origin], c)
+		}
+	}
+
+	var set []*http.Cookie
+	if len(entry.Response.Cookies) > 0 {
+		for _, c := range entry.Response.Cookies {
+			set = append(set, toHTTPCookie(c))
+		}
+	} else {
+		for _, h := range entry.Response.Headers {
+			if !strings.EqualFold(h.Name, constants.HeaderSetCookie) {
+				continue
+			}
+			if parsed, err := http.ParseSetCookie(h.Value); err == nil {
+				set = append(set, parsed)
+			}
+		}
+	}
+	for _, c := range set {
+		cookies[origin] = slices.DeleteFunc(cookies[origin], func(existing *http.Cookie) bool {
+			return existing.Name == c.Name
+		})
+		// A Max-Age of zero or a past expiry deletes the cookie
+		if c.MaxAge >= 0 && (c.Expires.IsZero() || c.Expires.After(time.Now())) {
+			cookies[origin] = append(cookies[origin], c)
+		}
+	}
+
+	if len(cookies[origin]) == 0 {
+		delete(cookies, origin)
+	}
+}
+
+func toHTTPCookie(c Cookie) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		HttpOnly: c.HTTPOnly,
+		Secure:   c.Secure,
+	}
+	if expires, err := time.Parse(time.RFC3339, c.Expires); err == nil {
+		cookie.Expires = expires
+	}
+	return cookie
+}
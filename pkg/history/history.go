@@ -79,12 +79,29 @@ func isNotExist(err error) bool {
 
 // Add adds a request to history
 func (h *HistoryManager) Add(request *models.HttpRequest) error {
+	return h.AddWithAttempts(request, nil)
+}
+
+// AddWithAttempts adds a retried request to history along with its attempts
+func (h *HistoryManager) AddWithAttempts(request *models.HttpRequest, attempts []models.RequestAttempt) error {
+	return h.AddWithResponse(request, attempts, nil)
+}
+
+// AddWithResponse adds a request to history along with its attempts and a
+// summary of its response, which may be nil
+func (h *HistoryManager) AddWithResponse(request *models.HttpRequest, attempts []models.RequestAttempt, response *models.HttpResponse) error {
 	item := models.HistoricalHttpRequest{
 		Method:    request.Method,
 		URL:       request.URL,
 		Headers:   request.Headers,
 		Body:      request.RawBody,
 		StartTime: time.Now().UnixMilli(),
+		Attempts:  attempts,
+	}
+	if response != nil {
+		item.Response = models.NewHistoricalResponse(response)
+		// The request started before its response was received
+		item.StartTime -= response.Timing.Total.Milliseconds()
 	}
 
 	h.items = append([]models.HistoricalHttpRequest{item}, h.items...)
@@ -282,6 +299,13 @@ func (f *Formatter) FormatDetails(item models.HistoricalHttpRequest) string {
 
 	sb.WriteString(fmt.Sprintf("%s %s\n", f.FormatMethod(item.Method), item.URL))
 	sb.WriteString(fmt.Sprintf("Time: %s\n", time.UnixMilli(item.StartTime).Format("2006-01-02 15:04:05")))
+	if item.Response != nil {
+		status := item.Response.StatusMessage
+		if status == "" {
+			status = fmt.Sprintf("%d", item.Response.StatusCode)
+		}
+		sb.WriteString(fmt.Sprintf("Response: %s (%dms)\n", status, item.Response.Timing.Total.Milliseconds()))
+	}
 
 	if len(item.Headers) > 0 {
 		sb.WriteString("\nHeaders:\n")
@@ -296,6 +320,20 @@ func (f *Formatter) FormatDetails(item models.HistoricalHttpRequest) string {
 		sb.WriteString(body)
 	}
 
+	if len(item.Attempts) > 0 {
+		if !strings.HasSuffix(sb.String(), "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\nAttempts:\n")
+		for i, attempt := range item.Attempts {
+			outcome := attempt.Error
+			if attempt.StatusCode != 0 {
+				outcome = fmt.Sprintf("%d", attempt.StatusCode)
+			}
+			sb.WriteString(fmt.Sprintf("  %d. %s (%dms)\n", i+1, outcome, attempt.DurationMs))
+		}
+	}
+
 	return sb.String()
 }
 
@@ -3,6 +3,7 @@ package history
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -81,6 +82,89 @@ func TestHistoryManager_Add(t *testing.T) {
 	}
 }
 
+func TestHistoryManager_AddWithAttempts(t *testing.T) {
+	hm, err := NewHistoryManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewHistoryManager failed: %v", err)
+	}
+
+	request := &models.HttpRequest{
+		Method:  "GET",
+		URL:     "https://api.example.com/users",
+		Headers: map[string]string{},
+	}
+	attempts := []models.RequestAttempt{
+		{StatusCode: 503, DurationMs: 120},
+		{Error: "connection reset", DurationMs: 5},
+		{StatusCode: 200, DurationMs: 80},
+	}
+
+	if err := hm.AddWithAttempts(request, attempts); err != nil {
+		t.Fatalf("AddWithAttempts failed: %v", err)
+	}
+
+	item := hm.GetAll()[0]
+	if len(item.Attempts) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(item.Attempts))
+	}
+
+	details := DefaultFormatter().FormatDetails(item)
+	for _, want := range []string{"Attempts:", "1. 503 (120ms)", "2. connection reset (5ms)", "3. 200 (80ms)"} {
+		if !strings.Contains(details, want) {
+			t.Errorf("details should contain %q\nGot:\n%s", want, details)
+		}
+	}
+}
+
+func TestHistoryManager_AddWithResponse(t *testing.T) {
+	dir := t.TempDir()
+	hm, err := NewHistoryManager(dir)
+	if err != nil {
+		t.Fatalf("NewHistoryManager failed: %v", err)
+	}
+
+	request := &models.HttpRequest{Method: "GET", URL: "https://api.example.com/users"}
+	response := &models.HttpResponse{
+		StatusCode:      200,
+		StatusMessage:   "200 OK",
+		HttpVersion:     "HTTP/1.1",
+		Headers:         map[string][]string{"Content-Type": {"application/json"}},
+		Body:            `{"users": []}`,
+		BodySizeInBytes: 13,
+		Timing:          models.ResponseTiming{DNSLookup: 5 * time.Millisecond, ServerProcessing: 40 * time.Millisecond, Total: 120 * time.Millisecond},
+	}
+	if err := hm.AddWithResponse(request, nil, response); err != nil {
+		t.Fatalf("AddWithResponse failed: %v", err)
+	}
+	added := time.Now().UnixMilli()
+
+	// Reload to check the summary survives a restart
+	hm, err = NewHistoryManager(dir)
+	if err != nil {
+		t.Fatalf("NewHistoryManager (2) failed: %v", err)
+	}
+	item := hm.GetAll()[0]
+	want := &models.HistoricalResponse{
+		StatusCode:    200,
+		StatusMessage: "200 OK",
+		HttpVersion:   "HTTP/1.1",
+		Headers:       map[string][]string{"Content-Type": {"application/json"}},
+		BodySize:      13,
+		Timing:        response.Timing,
+	}
+	if !reflect.DeepEqual(item.Response, want) {
+		t.Errorf("Response = %+v, want %+v", item.Response, want)
+	}
+	if item.StartTime > added-120 {
+		t.Errorf("StartTime = %d, want at least 120ms before %d", item.StartTime, added)
+	}
+
+	details := DefaultFormatter().FormatDetails(item)
+	if !strings.Contains(details, "Response: 200 OK (120ms)") {
+		t.Errorf("details should contain the response\nGot:\n%s", details)
+	}
+}
+
 func TestHistoryManager_Add_MaxItems(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "restclient-history-test")
 	if err != nil {
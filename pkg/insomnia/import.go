@@ -0,0 +1,387 @@
+package insomnia
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/postman"
+)
+
+// SharedEnvironment is the restclient environment the base environment of
+// a workspace is imported to, so that sub environments override it as in
+// Insomnia
+const SharedEnvironment = "$shared"
+
+var (
+	// variableRegex matches Nunjucks variables such as {{ _.baseUrl }}
+	variableRegex = regexp.MustCompile(`\{\{\s*(?:_\.)?([A-Za-z0-9_$][A-Za-z0-9_.$-]*)\s*\}\}`)
+	// tagRegex matches Nunjucks template tags such as {% uuid 'v4' %}
+	tagRegex = regexp.MustCompile(`\{%\s*(\w+)\s*([^%]*?)\s*%\}`)
+)
+
+// ImportResult contains information about an imported export
+type ImportResult struct {
+	postman.ImportResult
+	// Environments holds the variables of each environment by name, with
+	// the base environment as $shared
+	Environments map[string]map[string]string
+}
+
+// Import reads an Insomnia export and converts each of its workspaces to
+// .http file(s), as a Postman collection would be
+func Import(exportPath string, opts postman.ImportOptions) (*ImportResult, error) {
+	export, err := Load(exportPath)
+	if err != nil {
+		return nil, err
+	}
+	return ImportExport(export, opts)
+}
+
+// ImportExport converts a parsed Insomnia export to .http file(s)
+func ImportExport(export *Export, opts postman.ImportOptions) (*ImportResult, error) {
+	result := &ImportResult{Environments: make(map[string]map[string]string)}
+
+	workspaces := 0
+	for _, workspace := range export.Resources {
+		if workspace.Type != TypeWorkspace {
+			continue
+		}
+		workspaces++
+
+		c := newConverter(export)
+		collection := c.collection(workspace)
+		imported, err := postman.ImportCollection(collection, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		result.FilesCreated = append(result.FilesCreated, imported.FilesCreated...)
+		result.RequestsCount += imported.RequestsCount
+		result.FoldersCount += imported.FoldersCount
+		result.Errors = append(result.Errors, c.warnings...)
+		result.Errors = append(result.Errors, imported.Errors...)
+		for name, vars := range c.environments(workspace) {
+			if result.Environments[name] == nil {
+				result.Environments[name] = make(map[string]string)
+			}
+			maps.Copy(result.Environments[name], vars)
+			result.VariablesCount += len(vars)
+		}
+	}
+
+	if workspaces == 0 {
+		return nil, errors.NewValidationError("Insomnia export", "has no workspace")
+	}
+	return result, nil
+}
+
+// converter builds a Postman collection from the resources of a workspace
+type converter struct {
+	children map[string][]Resource
+	warnings []string
+}
+
+func newConverter(export *Export) *converter {
+	c := &converter{children: make(map[string][]Resource)}
+	for _, r := range export.Resources {
+		c.children[r.ParentID] = append(c.children[r.ParentID], r)
+	}
+	for _, children := range c.children {
+		slices.SortStableFunc(children, func(a, b Resource) int {
+			switch {
+			case a.SortKey < b.SortKey:
+				return -1
+			case a.SortKey > b.SortKey:
+				return 1
+			}
+			return 0
+		})
+	}
+	return c
+}
+
+func (c *converter) warn(format string, args ...any) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+func (c *converter) collection(workspace Resource) *postman.Collection {
+	collection := postman.NewCollection(workspace.Name)
+	if workspace.Description != "" {
+		collection.Info.Description = &postman.Description{Content: workspace.Description}
+	}
+	collection.Item = c.items(workspace.ID, nil)
+	return collection
+}
+
+// items converts the folders and requests under a parent, passing down
+// the auth inherited from enclosing folders
+func (c *converter) items(parentID string, inherited *Authentication) []postman.Item {
+	var items []postman.Item
+	for _, r := range c.children[parentID] {
+		switch r.Type {
+		case TypeRequestGroup:
+			auth := inherited
+			if r.Authentication != nil && r.Authentication.Type != "" {
+				auth = r.Authentication
+			}
+			if len(r.Environment) > 0 {
+				c.warn("folder %q: folder environment variables are not imported", r.Name)
+			}
+			if r.PreRequestScript != "" || r.AfterResponseScript != "" {
+				c.warn("folder %q: folder scripts are not imported", r.Name)
+			}
+			folder := postman.Item{Name: r.Name, Item: c.items(r.ID, auth)}
+			if r.Description != "" {
+				folder.Description = &postman.Description{Content: r.Description}
+			}
+			items = append(items, folder)
+		case TypeRequest:
+			items = append(items, c.request(r, inherited))
+		case TypeGRPCRequest, TypeWebSocketRequest:
+			c.warn("request %q: %s requests are not imported", r.Name, strings.TrimSuffix(r.Type, "_request"))
+		}
+	}
+	return items
+}
+
+func (c *converter) request(r Resource, inherited *Authentication) postman.Item {
+	request := &postman.Request{
+		Method: strings.ToUpper(r.Method),
+		URL:    &postman.URL{Raw: convertTemplate(r.URL)},
+	}
+
+	var query []string
+	for _, p := range r.Parameters {
+		if p.Disabled || p.Name == "" {
+			continue
+		}
+		query = append(query, convertTemplate(p.Name)+"="+convertTemplate(p.Value))
+	}
+
+	for _, h := range r.Headers {
+		if h.Name == "" {
+			continue
+		}
+		request.Header = append(request.Header, postman.Header{Key: convertTemplate(h.Name), Value: convertTemplate(h.Value), Disabled: h.Disabled})
+	}
+
+	auth := inherited
+	if r.Authentication != nil && r.Authentication.Type != "" {
+		auth = r.Authentication
+	}
+	if auth != nil && !auth.Disabled {
+		if param := c.auth(r.Name, auth, request); param != "" {
+			query = append(query, param)
+		}
+	}
+
+	if len(query) > 0 {
+		separator := "?"
+		if strings.Contains(request.URL.Raw, "?") {
+			separator = "&"
+		}
+		request.URL.Raw += separator + strings.Join(query, "&")
+	}
+
+	request.Body = c.body(r, request)
+
+	item := postman.Item{Name: r.Name, Request: request}
+	if r.Description != "" {
+		item.Description = &postman.Description{Content: r.Description}
+	}
+	if r.PreRequestScript != "" {
+		item.Event = append(item.Event, postman.Event{Listen: "prerequest", Script: &postman.Script{Exec: convertScript(r.PreRequestScript)}})
+	}
+	if r.AfterResponseScript != "" {
+		item.Event = append(item.Event, postman.Event{Listen: "test", Script: &postman.Script{Exec: convertScript(r.AfterResponseScript)}})
+	}
+	return item
+}
+
+// auth sets the auth of a request, returning a query parameter for API
+// keys sent in the query
+func (c *converter) auth(name string, auth *Authentication, request *postman.Request) string {
+	attr := func(key, value string) postman.AuthAttribute {
+		return postman.AuthAttribute{Key: key, Value: convertTemplate(value), Type: "string"}
+	}
+
+	switch auth.Type {
+	case "none":
+	case "basic":
+		request.Auth = &postman.Auth{Type: "basic", Basic: []postman.AuthAttribute{attr("username", auth.Username), attr("password", auth.Password)}}
+	case "digest":
+		request.Auth = &postman.Auth{Type: "digest", Digest: []postman.AuthAttribute{attr("username", auth.Username), attr("password", auth.Password)}}
+	case "bearer":
+		if auth.Prefix != "" && !strings.EqualFold(auth.Prefix, "Bearer") {
+			request.Header = append(request.Header, postman.Header{Key: "Authorization", Value: auth.Prefix + " " + convertTemplate(auth.Token)})
+			break
+		}
+		request.Auth = &postman.Auth{Type: "bearer", Bearer: []postman.AuthAttribute{attr("token", auth.Token)}}
+	case "iam":
+		request.Auth = &postman.Auth{Type: "awsv4", AWSv4: []postman.AuthAttribute{
+			attr("accessKey", auth.AccessKeyID),
+			attr("secretKey", auth.SecretAccessKey),
+			attr("sessionToken", auth.SessionToken),
+			attr("region", auth.Region),
+			attr("service", auth.Service),
+		}}
+	case "apikey":
+		key, value := convertTemplate(auth.Key), convertTemplate(auth.Value)
+		switch auth.AddTo {
+		case "queryParams":
+			return key + "=" + value
+		case "cookie":
+			request.Header = append(request.Header, postman.Header{Key: "Cookie", Value: key + "=" + value})
+		default:
+			request.Header = append(request.Header, postman.Header{Key: key, Value: value})
+		}
+	default:
+		c.warn("request %q: %s authentication is not supported", name, auth.Type)
+	}
+	return ""
+}
+
+// body converts the body of a request, adding a Content-Type header from
+// its MIME type when the request has none
+func (c *converter) body(r Resource, request *postman.Request) *postman.Body {
+	mimeType := r.Body.MimeType
+	var body *postman.Body
+	switch {
+	case r.Body.FileName != "":
+		body = &postman.Body{Mode: "file", File: &postman.File{Src: r.Body.FileName}}
+	case mimeType == "application/x-www-form-urlencoded":
+		body = &postman.Body{Mode: "urlencoded"}
+		for _, p := range r.Body.Params {
+			body.URLEncoded = append(body.URLEncoded, postman.URLEncodedPair{Key: convertTemplate(p.Name), Value: convertTemplate(p.Value), Disabled: p.Disabled})
+		}
+	case mimeType == "multipart/form-data":
+		body = &postman.Body{Mode: "formdata"}
+		for _, p := range r.Body.Params {
+			field := postman.FormDataPair{Key: convertTemplate(p.Name), Value: convertTemplate(p.Value), Disabled: p.Disabled, Type: "text"}
+			if p.Type == "file" {
+				field.Type, field.Value, field.Src = "file", "", p.FileName
+			}
+			body.FormData = append(body.FormData, field)
+		}
+	case mimeType == "application/graphql":
+		var gql struct {
+			Query     string `json:"query"`
+			Variables any    `json:"variables"`
+		}
+		if err := json.Unmarshal([]byte(r.Body.Text), &gql); err != nil {
+			c.warn("request %q: invalid GraphQL body: %v", r.Name, err)
+			return nil
+		}
+		body = &postman.Body{Mode: "graphql", GraphQL: &postman.GraphQL{Query: convertTemplate(gql.Query)}}
+		if gql.Variables != nil {
+			if variables, err := json.Marshal(gql.Variables); err == nil {
+				body.GraphQL.Variables = convertTemplate(string(variables))
+			}
+		}
+		mimeType = "application/json"
+	case r.Body.Text != "":
+		body = &postman.Body{Mode: "raw", Raw: convertTemplate(r.Body.Text)}
+	default:
+		return nil
+	}
+
+	hasContentType := slices.ContainsFunc(request.Header, func(h postman.Header) bool {
+		return strings.EqualFold(h.Key, "Content-Type")
+	})
+	if !hasContentType && mimeType != "" {
+		request.Header = append(request.Header, postman.Header{Key: "Content-Type", Value: mimeType})
+	}
+	return body
+}
+
+// environments returns the base environment of a workspace as $shared
+// and its sub environments by name
+func (c *converter) environments(workspace Resource) map[string]map[string]string {
+	envs := make(map[string]map[string]string)
+	for _, base := range c.children[workspace.ID] {
+		if base.Type != TypeEnvironment {
+			continue
+		}
+		envs[SharedEnvironment] = flatten(base.Data)
+		for _, sub := range c.children[base.ID] {
+			if sub.Type == TypeEnvironment {
+				envs[sub.Name] = flatten(sub.Data)
+			}
+		}
+	}
+	return envs
+}
+
+// flatten converts environment data to variables, naming nested values
+// with dots as Insomnia's {{ _.a.b }} does
+func flatten(data map[string]any) map[string]string {
+	vars := make(map[string]string)
+	var walk func(prefix string, value any)
+	walk = func(prefix string, value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			for key, nested := range v {
+				walk(prefix+"."+key, nested)
+			}
+		case string:
+			vars[prefix] = convertTemplate(v)
+		case nil:
+			vars[prefix] = ""
+		default:
+			encoded, _ := json.Marshal(v)
+			vars[prefix] = string(encoded)
+		}
+	}
+	for key, value := range data {
+		walk(key, value)
+	}
+	return vars
+}
+
+// convertTemplate converts Nunjucks variables to {{name}} and the uuid and
+// now tags to system variables
+func convertTemplate(s string) string {
+	if !strings.Contains(s, "{") {
+		return s
+	}
+	s = variableRegex.ReplaceAllString(s, "{{$1}}")
+	return tagRegex.ReplaceAllStringFunc(s, func(tag string) string {
+		match := tagRegex.FindStringSubmatch(tag)
+		args := strings.Trim(match[2], `'" `)
+		switch match[1] {
+		case "uuid":
+			return "{{$guid}}"
+		case "now":
+			switch args {
+			case "millis":
+				return "{{$timestamp}}000"
+			case "unix":
+				return "{{$timestamp}}"
+			default:
+				return "{{$datetime iso8601}}"
+			}
+		}
+		return tag
+	})
+}
+
+// scriptReplacer converts the insomnia.* script API to the pm.* forms the
+// Postman importer understands, or to client.* directly
+var scriptReplacer = strings.NewReplacer(
+	"insomnia.environment.set(", "client.global.set(",
+	"insomnia.environment.get(", "client.global.get(",
+	"insomnia.collectionVariables.set(", "client.global.set(",
+	"insomnia.collectionVariables.get(", "client.global.get(",
+	"insomnia.variables.set(", "client.global.set(",
+	"insomnia.variables.get(", "client.global.get(",
+	"insomnia.", "pm.",
+)
+
+func convertScript(script string) string {
+	return scriptReplacer.Replace(script)
+}
@@ -0,0 +1,182 @@
+package insomnia
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/restclient/pkg/postman"
+)
+
+// Sample Insomnia v4 export for testing
+const sampleExport = `{
+	"_type": "export",
+	"__export_format": 4,
+	"resources": [
+		{"_id": "wrk_1", "_type": "workspace", "parentId": null, "name": "Shop API"},
+		{"_id": "env_base", "_type": "environment", "parentId": "wrk_1", "name": "Base Environment", "data": {"baseUrl": "https://api.example.com", "auth": {"token": "secret"}}},
+		{"_id": "env_prod", "_type": "environment", "parentId": "env_base", "name": "production", "data": {"baseUrl": "https://shop.example.com"}},
+		{"_id": "req_2", "_type": "request", "parentId": "wrk_1", "name": "Create Order", "metaSortKey": -5, "method": "post", "url": "{{ _.baseUrl }}/orders",
+			"body": {"mimeType": "application/json", "text": "{\"id\": \"{% uuid 'v4' %}\"}"},
+			"headers": [], "authentication": {"type": "bearer", "token": "{{ _.auth.token }}"},
+			"afterResponseScript": "insomnia.test('created', () => { insomnia.expect(insomnia.response.code === 201).to.be.true; });\ninsomnia.environment.set('orderId', insomnia.response.json().id);"},
+		{"_id": "req_1", "_type": "request", "parentId": "wrk_1", "name": "List Orders", "metaSortKey": -10, "method": "GET", "url": "{{ _.baseUrl }}/orders",
+			"parameters": [{"name": "page", "value": "1"}, {"name": "debug", "value": "true", "disabled": true}],
+			"headers": [{"name": "Accept", "value": "application/json"}, {"name": "X-Trace", "value": "1", "disabled": true}],
+			"authentication": {"type": "apikey", "key": "X-Api-Key", "value": "{{ apiKey }}", "addTo": "queryParams"}},
+		{"_id": "fld_1", "_type": "request_group", "parentId": "wrk_1", "name": "Admin", "metaSortKey": 0, "authentication": {"type": "basic", "username": "admin", "password": "pw"}},
+		{"_id": "req_3", "_type": "request", "parentId": "fld_1", "name": "Login", "method": "POST", "url": "{{ _.baseUrl }}/login",
+			"body": {"mimeType": "application/x-www-form-urlencoded", "params": [{"name": "user", "value": "admin"}]},
+			"authentication": {}},
+		{"_id": "req_4", "_type": "request", "parentId": "fld_1", "name": "Search", "method": "POST", "url": "{{ _.baseUrl }}/graphql",
+			"body": {"mimeType": "application/graphql", "text": "{\"query\": \"{ orders { id } }\", \"variables\": {\"first\": 10}}"},
+			"authentication": {"type": "oauth2"}},
+		{"_id": "ws_1", "_type": "websocket_request", "parentId": "wrk_1", "name": "Live"}
+	]
+}`
+
+func writeExport(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImport_SingleFile(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "shop.http")
+	opts := postman.DefaultImportOptions()
+	opts.SingleFile = true
+	opts.OutputFile = outputFile
+
+	result, err := Import(writeExport(t, sampleExport), opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if result.RequestsCount != 4 || result.FoldersCount != 1 {
+		t.Errorf("RequestsCount = %d, FoldersCount = %d", result.RequestsCount, result.FoldersCount)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+
+	checks := []string{
+		"GET {{baseUrl}}/orders?page=1&X-Api-Key={{apiKey}}\nAccept: application/json\n# X-Trace: 1\n",
+		"POST {{baseUrl}}/orders\nContent-Type: application/json\nAuthorization: Bearer {{auth.token}}\n",
+		`{"id": "{{$guid}}"}`,
+		"client.test('created'",
+		"client.assert(response.status === 201)",
+		"client.global.set('orderId', response.body.id)",
+		"POST {{baseUrl}}/login\nContent-Type: application/x-www-form-urlencoded\nAuthorization: Basic ",
+		"user=admin",
+		`"query": "{ orders { id } }"`,
+		`"first": 10`,
+	}
+	for _, check := range checks {
+		if !strings.Contains(content, check) {
+			t.Errorf("content should contain %q\n%s", check, content)
+		}
+	}
+
+	// Requests are ordered by their sort key
+	if strings.Index(content, "List Orders") > strings.Index(content, "Create Order") {
+		t.Error("List Orders should come before Create Order")
+	}
+	if strings.Contains(content, "debug=true") {
+		t.Error("disabled query parameters should be skipped")
+	}
+
+	warnings := strings.Join(result.Errors, "\n")
+	for _, want := range []string{"oauth2 authentication is not supported", `"Live": websocket requests are not imported`} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("warnings should contain %q, got %v", want, result.Errors)
+		}
+	}
+
+	shared := result.Environments[SharedEnvironment]
+	if shared["baseUrl"] != "https://api.example.com" || shared["auth.token"] != "secret" {
+		t.Errorf("$shared = %v", shared)
+	}
+	if result.Environments["production"]["baseUrl"] != "https://shop.example.com" {
+		t.Errorf("production = %v", result.Environments["production"])
+	}
+}
+
+func TestImport_MultiFile(t *testing.T) {
+	outputDir := t.TempDir()
+	opts := postman.DefaultImportOptions()
+	opts.OutputDir = outputDir
+	opts.IncludeScripts = false
+
+	result, err := Import(writeExport(t, sampleExport), opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(result.FilesCreated) != 2 {
+		t.Fatalf("FilesCreated = %v", result.FilesCreated)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "Shop API", "Admin", "Admin.http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# @name Login") {
+		t.Errorf("Admin.http = %s", data)
+	}
+
+	root, err := os.ReadFile(filepath.Join(outputDir, "Shop API", "Shop API.http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(root), "client.test") {
+		t.Error("scripts should be left out")
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"invalid JSON", `{`},
+		{"wrong format", `{"_type": "export", "__export_format": 3, "resources": []}`},
+		{"not an export", `{"info": {}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := Import(writeExport(t, `{"_type": "export", "__export_format": 4, "resources": []}`), postman.DefaultImportOptions()); err == nil {
+		t.Error("an export without workspaces should fail")
+	}
+}
+
+func TestConvertTemplate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"{{ _.baseUrl }}/users", "{{baseUrl}}/users"},
+		{"{{token}}", "{{token}}"},
+		{"{{ _.auth.token }}", "{{auth.token}}"},
+		{"{% uuid 'v4' %}", "{{$guid}}"},
+		{"{% now 'unix' %}", "{{$timestamp}}"},
+		{"{% now 'iso-8601' %}", "{{$datetime iso8601}}"},
+		{"{% response 'body', 'req_1', '$.id' %}", "{% response 'body', 'req_1', '$.id' %}"},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := convertTemplate(tt.input); got != tt.want {
+			t.Errorf("convertTemplate(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package insomnia imports Insomnia v4 exports, converting their
+// workspaces to .http files through the Postman importer.
+package insomnia
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// ExportFormat is the version of the Insomnia export format read by this
+// package
+const ExportFormat = 4
+
+// Resource types of an export
+const (
+	TypeWorkspace        = "workspace"
+	TypeRequestGroup     = "request_group"
+	TypeRequest          = "request"
+	TypeEnvironment      = "environment"
+	TypeGRPCRequest      = "grpc_request"
+	TypeWebSocketRequest = "websocket_request"
+)
+
+// Export is an Insomnia v4 export
+type Export struct {
+	Type      string     `json:"_type"`
+	Format    int        `json:"__export_format"`
+	Resources []Resource `json:"resources"`
+}
+
+// Resource is a workspace, folder, request or environment. Fields not
+// used by a type are empty.
+type Resource struct {
+	ID          string  `json:"_id"`
+	ParentID    string  `json:"parentId"`
+	Type        string  `json:"_type"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	SortKey     float64 `json:"metaSortKey"`
+
+	// Requests
+	Method         string          `json:"method"`
+	URL            string          `json:"url"`
+	Body           Body            `json:"body"`
+	Parameters     []Pair          `json:"parameters"`
+	Headers        []Pair          `json:"headers"`
+	Authentication *Authentication `json:"authentication"`
+
+	// Requests and folders (Insomnia 8 and later)
+	PreRequestScript    string `json:"preRequestScript"`
+	AfterResponseScript string `json:"afterResponseScript"`
+
+	// Environments, and the variables of folders
+	Data        map[string]any `json:"data"`
+	Environment map[string]any `json:"environment"`
+}
+
+// Pair is a header, query parameter or form field
+type Pair struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Type     string `json:"type"`     // "file" for file fields
+	FileName string `json:"fileName"` // Path of a file field
+}
+
+// Body is the body of a request
+type Body struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Params   []Pair `json:"params"`
+	FileName string `json:"fileName"` // Path of a file body
+}
+
+// Authentication is the auth of a request or folder
+type Authentication struct {
+	Type            string `json:"type"`
+	Disabled        bool   `json:"disabled"`
+	Username        string `json:"username"`
+	Password        string `json:"password"`
+	Token           string `json:"token"`
+	Prefix          string `json:"prefix"`
+	Key             string `json:"key"`
+	Value           string `json:"value"`
+	AddTo           string `json:"addTo"` // "header", "queryParams" or "cookie"
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	SessionToken    string `json:"sessionToken"`
+	Region          string `json:"region"`
+	Service         string `json:"service"`
+}
+
+// Load reads an Insomnia export file
+func Load(path string) (*Export, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read export file")
+	}
+	return Parse(data)
+}
+
+// Parse parses an Insomnia export
+func Parse(data []byte) (*Export, error) {
+	var export Export
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, errors.Wrap(err, "failed to parse export JSON")
+	}
+	if export.Type != "export" || export.Format != ExportFormat {
+		return nil, errors.NewValidationErrorWithValue("Insomnia export format", fmt.Sprint(export.Format), fmt.Sprintf("unsupported (expected %d)", ExportFormat))
+	}
+	return &export, nil
+}
@@ -0,0 +1,225 @@
+// Package lint checks .http and .rest files for likely mistakes and fixes
+// some of them.
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/parser"
+)
+
+// Severity is how serious an issue of a rule is
+type Severity int
+
+const (
+	SeverityOff Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "off"
+	}
+}
+
+// ParseSeverity parses "off", "warning" or "error"
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "off":
+		return SeverityOff, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	}
+	return SeverityOff, errors.NewValidationErrorWithValue("severity", s, "must be off, warning or error")
+}
+
+// Issue is a problem found in a file
+type Issue struct {
+	Rule     string
+	Severity Severity
+	Span     parser.Span
+	Message  string
+	Fixable  bool
+
+	fix func()
+}
+
+// File is a file to lint
+type File struct {
+	Path    string
+	Content string
+	// Variables are the names of the environment and session variables
+	// available to the file, from all environments
+	Variables []string
+}
+
+// Options configures a lint run
+type Options struct {
+	// Rules overrides the default severity of rules by name
+	Rules map[string]Severity
+	// Fix applies the fixes of fixable issues
+	Fix bool
+}
+
+// Result holds the issues of a file
+type Result struct {
+	Path   string
+	Issues []Issue // Remaining issues, in source order
+	// Fixed is the content of the file with fixes applied. It equals the
+	// content of the file when nothing was fixed.
+	Fixed      string
+	FixedCount int
+}
+
+// HasErrors reports whether any remaining issue is an error
+func (r *Result) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// source is a parsed file: one being linted or one it imports
+type source struct {
+	path    string // Absolute path
+	content string
+	ast     *parser.File
+}
+
+// checker holds what the rules of a file look at
+type checker struct {
+	file      File
+	src       *source
+	imports   []*source // Files the linted file imports, in import order
+	importers []*source // Linted files importing this file
+	linted    []*source // All linted files
+	importErr error
+
+	rule     *Rule
+	severity Severity
+	issues   []Issue
+}
+
+// report adds an issue for the current rule. fix may be nil.
+func (c *checker) report(span parser.Span, message string, fix func()) {
+	c.issues = append(c.issues, Issue{
+		Rule:     c.rule.Name,
+		Severity: c.severity,
+		Span:     span,
+		Message:  message,
+		Fixable:  fix != nil,
+		fix:      fix,
+	})
+}
+
+// Lint checks files. Files importing one another are linted together, so
+// that a variable declared in one file and used by another is not
+// reported as unused.
+func Lint(files []File, options Options) ([]Result, error) {
+	severities := make(map[string]Severity)
+	for _, rule := range rules {
+		severities[rule.Name] = rule.Severity
+	}
+	for name, severity := range options.Rules {
+		if _, ok := severities[name]; !ok {
+			return nil, errors.NewValidationErrorWithValue("rule", name, "unknown lint rule")
+		}
+		severities[name] = severity
+	}
+
+	checkers := make([]*checker, len(files))
+	linted := make([]*source, len(files))
+	for i, file := range files {
+		path, err := filepath.Abs(file.Path)
+		if err != nil {
+			path = file.Path
+		}
+		linted[i] = &source{path: path, content: file.Content, ast: parser.Parse(file.Content)}
+		checkers[i] = &checker{file: file, src: linted[i], linted: linted}
+	}
+
+	for _, c := range checkers {
+		imported, err := parser.ResolveImports(c.src.path, c.src.content)
+		c.importErr = err
+		for _, file := range imported {
+			c.imports = append(c.imports, &source{path: file.Path, content: file.Content, ast: parser.Parse(file.Content)})
+		}
+	}
+	for _, c := range checkers {
+		for _, other := range checkers {
+			if other != c && slices.ContainsFunc(other.imports, func(s *source) bool { return s.path == c.src.path }) {
+				c.importers = append(c.importers, other.src)
+			}
+		}
+	}
+
+	results := make([]Result, len(checkers))
+	for i, c := range checkers {
+		for _, rule := range rules {
+			if severities[rule.Name] == SeverityOff {
+				continue
+			}
+			c.rule, c.severity = rule, severities[rule.Name]
+			rule.check(c)
+		}
+
+		result := Result{Path: c.file.Path, Fixed: c.file.Content}
+		for _, issue := range c.issues {
+			if options.Fix && issue.fix != nil {
+				issue.fix()
+				result.FixedCount++
+				continue
+			}
+			result.Issues = append(result.Issues, issue)
+		}
+		if result.FixedCount > 0 {
+			result.Fixed = parser.Print(c.src.ast)
+		}
+		slices.SortStableFunc(result.Issues, func(a, b Issue) int {
+			return a.Span.Start.Offset - b.Span.Start.Offset
+		})
+		results[i] = result
+	}
+	return results, nil
+}
+
+// removeNode deletes a node, and the line it is on, from a file
+func removeNode(file *parser.File, n *parser.Node) {
+	for _, block := range file.Blocks {
+		if i := slices.Index(block.Nodes, n); i >= 0 {
+			block.Nodes = slices.Delete(block.Nodes, i, i+1)
+			return
+		}
+	}
+}
+
+// readScript returns the code of an inline script or of the script file it
+// references, relative to the file holding it
+func readScript(src *source, n *parser.Node) string {
+	if n.Path == "" {
+		return n.Value
+	}
+	path := n.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(src.path), path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
@@ -0,0 +1,412 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ideaspaper/restclient/internal/constants"
+	"github.com/ideaspaper/restclient/internal/stringutil"
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/parser"
+)
+
+// Rule is a check run on every linted file
+type Rule struct {
+	Name        string
+	Description string
+	Severity    Severity // Default severity
+	Fixable     bool
+
+	check func(c *checker)
+}
+
+var rules = []*Rule{
+	{Name: "invalid-import", Description: "@import of a missing file or an import cycle", Severity: SeverityError, check: checkImports},
+	{Name: "duplicate-name", Description: "@name used by more than one request", Severity: SeverityError, check: checkDuplicateNames},
+	{Name: "undefined-variable", Description: "{{variable}} that is not declared anywhere", Severity: SeverityError, check: checkUndefinedVariables},
+	{Name: "get-with-body", Description: "GET request with a body", Severity: SeverityWarning, check: checkGetWithBody},
+	{Name: "invalid-json", Description: "JSON body that does not parse", Severity: SeverityError, check: checkInvalidJSON},
+	{Name: "missing-content-type", Description: "request with a body but no Content-Type header", Severity: SeverityWarning, Fixable: true, check: checkMissingContentType},
+	{Name: "unused-variable", Description: "file variable that is never used", Severity: SeverityWarning, Fixable: true, check: checkUnusedVariables},
+	{Name: "hardcoded-credential", Description: "password, token or key written in the file instead of a variable", Severity: SeverityWarning, check: checkHardcodedCredentials},
+}
+
+// Rules returns the rules in the order they run
+func Rules() []Rule {
+	list := make([]Rule, len(rules))
+	for i, rule := range rules {
+		list[i] = *rule
+	}
+	return list
+}
+
+var (
+	// variableRegex matches a {{variable}} reference
+	variableRegex = regexp.MustCompile(`\{\{(.+?)\}\}`)
+	// globalSetRegex matches client.global.set("name", ...) in scripts
+	globalSetRegex = regexp.MustCompile("client\\.global\\.set\\(\\s*[\"'`]([^\"'`]+)[\"'`]")
+	// userinfoRegex matches the password of user:password@ in a URL
+	userinfoRegex = regexp.MustCompile(`://[^/\s:@]+:([^/\s@]+)@`)
+)
+
+// reference is a {{variable}} in a node
+type reference struct {
+	name  string // Without surrounding space and the % of URL encoding
+	start int    // Byte offsets of {{ and past }} in the Raw of the node
+	end   int
+}
+
+// references returns the variables referenced by a node, except system
+// variables and {{:name}} user input, which is asked for when sending
+func references(n *parser.Node) []reference {
+	var refs []reference
+	for _, m := range variableRegex.FindAllStringSubmatchIndex(n.Raw, -1) {
+		name := strings.TrimPrefix(strings.TrimSpace(n.Raw[m[2]:m[3]]), "%")
+		if name == "" || strings.HasPrefix(name, "$") || strings.HasPrefix(name, ":") {
+			continue
+		}
+		refs = append(refs, reference{name: name, start: m[0], end: m[1]})
+	}
+	return refs
+}
+
+// spanOf returns the span of part of the Raw of a node
+func spanOf(n *parser.Node, start, end int) parser.Span {
+	return parser.Span{Start: positionOf(n, start), End: positionOf(n, end)}
+}
+
+func positionOf(n *parser.Node, offset int) parser.Position {
+	pos := n.Span.Start
+	pos.Offset += offset
+	before := n.Raw[:offset]
+	if i := strings.LastIndex(before, "\n"); i >= 0 {
+		pos.Line += strings.Count(before, "\n")
+		pos.Column = offset - i - 1
+	} else {
+		pos.Column += offset
+	}
+	return pos
+}
+
+// requestBlocks returns the blocks of a file holding a request
+func requestBlocks(file *parser.File) []*parser.Block {
+	var blocks []*parser.Block
+	for _, block := range file.Blocks {
+		if block.First(parser.NodeRequestLine) != nil {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// allNodes returns the nodes of a kind in a file
+func allNodes(file *parser.File, kind parser.NodeKind) []*parser.Node {
+	var nodes []*parser.Node
+	for _, block := range file.Blocks {
+		nodes = append(nodes, block.All(kind)...)
+	}
+	return nodes
+}
+
+// header returns the value of the last header of a block with a name
+func header(block *parser.Block, name string) (string, bool) {
+	value, found := "", false
+	for _, h := range block.All(parser.NodeHeader) {
+		if strings.EqualFold(h.Name, name) {
+			value, found = h.Value, true
+		}
+	}
+	return value, found
+}
+
+// method returns the method of a request, GET when it is omitted
+func method(block *parser.Block) string {
+	fields := strings.Fields(block.First(parser.NodeRequestLine).Value)
+	if len(fields) > 1 && !strings.ContainsAny(fields[0], "/:{") {
+		return strings.ToUpper(fields[0])
+	}
+	return "GET"
+}
+
+// body returns the body node of a request when it holds more than
+// whitespace
+func body(block *parser.Block) *parser.Node {
+	n := block.First(parser.NodeBody)
+	if n == nil || strings.TrimSpace(n.Value) == "" {
+		return nil
+	}
+	return n
+}
+
+// isGraphQL reports whether a request is a GraphQL query, whose body is
+// not JSON
+func isGraphQL(block *parser.Block) bool {
+	value, _ := header(block, "X-Request-Type")
+	return strings.EqualFold(value, "GraphQL")
+}
+
+// bodyJSON returns the text of a body with variables replaced by a JSON
+// value, or false when the body is a file reference
+func bodyJSON(n *parser.Node) (string, bool) {
+	text := strings.TrimSpace(n.Value)
+	if strings.HasPrefix(text, "<") {
+		return "", false
+	}
+	return variableRegex.ReplaceAllString(text, "0"), true
+}
+
+func checkImports(c *checker) {
+	if c.importErr == nil {
+		return
+	}
+	span := parser.Span{}
+	var parseErr *errors.ParseError
+	hasLine := errors.As(c.importErr, &parseErr) && parseErr.File == c.src.path && parseErr.Line > 0
+	for _, n := range allNodes(c.src.ast, parser.NodeImport) {
+		if !hasLine || n.Span.Start.Line == parseErr.Line-1 {
+			span = n.Span
+			break
+		}
+	}
+	c.report(span, c.importErr.Error(), nil)
+}
+
+func checkDuplicateNames(c *checker) {
+	first := make(map[string]*parser.Node)
+	for _, block := range requestBlocks(c.src.ast) {
+		n := block.Metadata("name")
+		if n == nil || n.Value == "" {
+			continue
+		}
+		if earlier, ok := first[n.Value]; ok {
+			c.report(n.Span, fmt.Sprintf("duplicate @name '%s', first used on line %d", n.Value, earlier.Span.Start.Line+1), nil)
+			continue
+		}
+		first[n.Value] = n
+	}
+}
+
+func checkUndefinedVariables(c *checker) {
+	defined := make(map[string]bool)
+	for _, name := range c.file.Variables {
+		defined[name] = true
+	}
+	// Script globals are shared by all files of a run
+	for _, src := range append(slices.Clone(c.linted), c.imports...) {
+		for _, kind := range []parser.NodeKind{parser.NodePreScript, parser.NodePostScript} {
+			for _, n := range allNodes(src.ast, kind) {
+				for _, m := range globalSetRegex.FindAllStringSubmatch(readScript(src, n), -1) {
+					defined[m[1]] = true
+				}
+			}
+		}
+	}
+	for _, src := range append([]*source{c.src}, c.imports...) {
+		for _, n := range allNodes(src.ast, parser.NodeFileVariable) {
+			defined[n.Name] = true
+		}
+	}
+
+	for _, block := range c.src.ast.Blocks {
+		prompts := make(map[string]bool)
+		for _, n := range block.All(parser.NodeMetadata) {
+			if fields := strings.Fields(n.Value); n.Name == "prompt" && len(fields) > 0 {
+				prompts[fields[0]] = true
+			}
+		}
+
+		for _, n := range block.Nodes {
+			switch n.Kind {
+			case parser.NodeFileVariable, parser.NodeRequestLine, parser.NodeHeader, parser.NodeBody:
+			default:
+				continue
+			}
+			for _, ref := range references(n) {
+				span := spanOf(n, ref.start, ref.end)
+				if strings.Contains(ref.name, ".response.") || strings.Contains(ref.name, ".request.") {
+					if message := c.checkRequestReference(ref.name); message != "" {
+						c.report(span, message, nil)
+					}
+					continue
+				}
+				if !defined[ref.name] && !prompts[ref.name] {
+					c.report(span, fmt.Sprintf("variable '%s' is not defined", ref.name), nil)
+				}
+			}
+		}
+	}
+}
+
+// checkRequestReference returns why the request a request variable refers
+// to does not exist, or "" when it does
+func (c *checker) checkRequestReference(ref string) string {
+	end := len(ref)
+	for _, part := range []string{".response.", ".request."} {
+		if i := strings.Index(ref, part); i >= 0 && i < end {
+			end = i
+		}
+	}
+	name := ref[:end]
+	files := append([]*source{c.src}, c.imports...)
+
+	if hash := strings.Index(name, "#"); hash > 0 {
+		path := name[:hash]
+		name = name[hash+1:]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(c.src.path), path)
+		}
+		path = filepath.Clean(path)
+		files = nil
+		for _, src := range append(slices.Clone(c.linted), c.imports...) {
+			if src.path == path {
+				files = []*source{src}
+				break
+			}
+		}
+		if files == nil {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Sprintf("file '%s' of request '%s' not found", ref[:hash], name)
+			}
+			files = []*source{{path: path, content: string(content), ast: parser.Parse(string(content))}}
+		}
+	}
+
+	for _, src := range files {
+		for _, block := range requestBlocks(src.ast) {
+			if n := block.Metadata("name"); n != nil && n.Value == name {
+				return ""
+			}
+		}
+	}
+	return fmt.Sprintf("request '%s' is not defined", name)
+}
+
+func checkGetWithBody(c *checker) {
+	for _, block := range requestBlocks(c.src.ast) {
+		if n := body(block); n != nil && method(block) == "GET" {
+			c.report(n.Span, "GET request has a body, which many servers ignore", nil)
+		}
+	}
+}
+
+func checkInvalidJSON(c *checker) {
+	for _, block := range requestBlocks(c.src.ast) {
+		contentType, _ := header(block, constants.HeaderContentType)
+		n := body(block)
+		if n == nil || !strings.Contains(strings.ToLower(contentType), "json") || isGraphQL(block) {
+			continue
+		}
+		text, ok := bodyJSON(n)
+		if !ok {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			c.report(n.Span, "body is not valid JSON: "+err.Error(), nil)
+		}
+	}
+}
+
+func checkMissingContentType(c *checker) {
+	for _, block := range requestBlocks(c.src.ast) {
+		n := body(block)
+		if n == nil || isGraphQL(block) {
+			continue
+		}
+		if m := method(block); m == "WEBSOCKET" || m == "GRPC" {
+			continue
+		}
+		if _, ok := header(block, constants.HeaderContentType); ok {
+			continue
+		}
+
+		// A JSON body gets a JSON Content-Type, after the last header
+		var fix func()
+		if text, ok := bodyJSON(n); ok && json.Valid([]byte(text)) {
+			anchor := block.First(parser.NodeRequestLine)
+			if headers := block.All(parser.NodeHeader); len(headers) > 0 {
+				anchor = headers[len(headers)-1]
+			}
+			fix = func() {
+				eol := ""
+				if strings.HasSuffix(anchor.Raw, "\r") {
+					eol = "\r"
+				}
+				anchor.Raw += "\n" + constants.HeaderContentType + ": " + constants.MIMEApplicationJSON + eol
+			}
+		}
+		c.report(block.First(parser.NodeRequestLine).Span, "request has a body but no Content-Type header", fix)
+	}
+}
+
+func checkUnusedVariables(c *checker) {
+	// Files without requests hold variables for the files importing them
+	if len(requestBlocks(c.src.ast)) == 0 && len(c.importers) == 0 {
+		return
+	}
+
+	used := make(map[string]bool)
+	for _, src := range append(append([]*source{c.src}, c.imports...), c.importers...) {
+		for _, block := range src.ast.Blocks {
+			for _, n := range block.Nodes {
+				for _, ref := range references(n) {
+					if n.Kind != parser.NodeFileVariable || n.Name != ref.name {
+						used[ref.name] = true
+					}
+				}
+			}
+		}
+	}
+
+	file := c.src.ast
+	for _, n := range allNodes(file, parser.NodeFileVariable) {
+		if n.Name != "" && !used[n.Name] {
+			c.report(n.Span, fmt.Sprintf("file variable '%s' is never used", n.Name), func() {
+				removeNode(file, n)
+			})
+		}
+	}
+}
+
+func checkHardcodedCredentials(c *checker) {
+	literal := func(value string) bool {
+		return strings.TrimSpace(value) != "" && !strings.Contains(value, "{{")
+	}
+
+	for _, n := range allNodes(c.src.ast, parser.NodeFileVariable) {
+		value := n.Value
+		if stringutil.IsSensitiveName(n.Name) && literal(value) && !strings.Contains(value, "://") && !strings.HasPrefix(value, "/") {
+			c.report(n.Span, fmt.Sprintf("file variable '%s' holds a hard-coded credential; use an environment variable or secret", n.Name), nil)
+		}
+	}
+
+	for _, block := range requestBlocks(c.src.ast) {
+		line := block.First(parser.NodeRequestLine)
+		if m := userinfoRegex.FindStringSubmatch(line.Value); m != nil && literal(m[1]) {
+			c.report(line.Span, "URL holds a hard-coded password; use a variable", nil)
+		}
+		if _, query, ok := strings.Cut(line.Value, "?"); ok {
+			query, _, _ = strings.Cut(query, " ")
+			for param := range strings.SplitSeq(query, "&") {
+				name, value, _ := strings.Cut(param, "=")
+				if stringutil.IsSensitiveName(name) && literal(value) {
+					c.report(line.Span, fmt.Sprintf("query parameter '%s' holds a hard-coded credential; use a variable", name), nil)
+				}
+			}
+		}
+
+		for _, h := range block.All(parser.NodeHeader) {
+			lower := strings.ToLower(h.Name)
+			sensitive := lower == "authorization" || lower == "proxy-authorization" || stringutil.IsSensitiveName(h.Name)
+			if sensitive && literal(h.Value) {
+				c.report(h.Span, fmt.Sprintf("header '%s' holds a hard-coded credential; use a variable", h.Name), nil)
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// codeLens shows a "Send request" lens above every request line
+func (s *Server) codeLens(ctx context.Context, params json.RawMessage) (any, error) {
+	var p documentParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	doc := s.document(p.TextDocument.URI)
+	if doc == nil || doc.path == "" {
+		return []CodeLens{}, nil
+	}
+
+	lenses := []CodeLens{}
+	for i, parsed := range s.workspace(doc).requests() {
+		lenses = append(lenses, CodeLens{
+			Range: doc.lineRange(parsed.requestLine),
+			Command: &Command{
+				Title:     "Send request",
+				Command:   sendCommand,
+				Arguments: []any{doc.uri, i + 1, parsed.request.Metadata.Name},
+			},
+		})
+	}
+	return lenses, nil
+}
+
+// executeCommand runs the send command of a code lens. The request is
+// sent by restclient send, from the file as saved; the response is shown
+// in the editor's log of the server and returned as the result.
+func (s *Server) executeCommand(ctx context.Context, params json.RawMessage) (any, error) {
+	var p executeCommandParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Command != sendCommand {
+		return nil, errors.NewValidationErrorWithValue("command", p.Command, "unknown command")
+	}
+
+	var uri, name string
+	var index int
+	if len(p.Arguments) < 2 ||
+		json.Unmarshal(p.Arguments[0], &uri) != nil ||
+		json.Unmarshal(p.Arguments[1], &index) != nil ||
+		(len(p.Arguments) > 2 && json.Unmarshal(p.Arguments[2], &name) != nil) {
+		return nil, errors.NewValidationError("arguments", "expected a document URI, a request index and a request name")
+	}
+	path := uriToPath(uri)
+	if path == "" {
+		return nil, errors.NewValidationErrorWithValue("document", uri, "not a file")
+	}
+
+	if doc := s.document(uri); doc != nil {
+		if saved, err := os.ReadFile(path); err == nil && strings.ReplaceAll(string(saved), "\r\n", "\n") != doc.text {
+			s.showMessage(MessageWarning, "Sending "+filepath.Base(path)+" as saved; unsaved changes are not sent")
+		}
+	}
+
+	output, err := s.send(ctx, path, index, name)
+	s.logMessage(MessageInfo, output)
+	if err != nil {
+		s.showMessage(MessageError, lastLine(output))
+		return nil, err
+	}
+	s.showMessage(MessageInfo, summary(output))
+	return output, nil
+}
+
+// send runs restclient send for a request, selected by name if it has one
+// so that unsaved edits above it do not shift its index
+func (s *Server) send(ctx context.Context, path string, index int, name string) (string, error) {
+	executable := s.options.Executable
+	if executable == "" {
+		var err error
+		if executable, err = os.Executable(); err != nil {
+			return "", errors.Wrap(err, "failed to find the restclient executable")
+		}
+	}
+
+	args := []string{"send", path}
+	if name != "" {
+		args = append(args, "--name", name)
+	} else {
+		args = append(args, "--index", strconv.Itoa(index))
+	}
+	if s.options.SessionName != "" {
+		args = append(args, "--session", s.options.SessionName)
+	}
+	if s.options.Environment != "" {
+		args = append(args, "--env", s.options.Environment)
+	}
+
+	// Without a terminal, requests that prompt for input fail instead of
+	// waiting for an answer
+	cmd := exec.CommandContext(ctx, executable, args...)
+	cmd.Dir = filepath.Dir(path)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), errors.NewRequestError("send", errors.Wrap(err, lastLine(string(output))))
+	}
+	return string(output), nil
+}
+
+// summary returns the status line of the output of send, e.g.
+// "HTTP/1.1 200 OK"
+func summary(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "HTTP/") {
+			return line
+		}
+	}
+	return "Request sent"
+}
+
+// lastLine returns the last line of output with text, which holds the
+// error when send fails
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,144 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ideaspaper/restclient/internal/stringutil"
+)
+
+// systemVariables are the system variables offered for completion
+var systemVariables = []struct {
+	name        string
+	description string
+}{
+	{"$guid", "A random UUID v4"},
+	{"$uuid", "A random UUID v4"},
+	{"$timestamp", "The current UTC Unix timestamp, optionally offset: `{{$timestamp -1 d}}`"},
+	{"$datetime", "The current UTC time as `rfc1123`, `iso8601` or a custom format: `{{$datetime iso8601 1 h}}`"},
+	{"$localDatetime", "The current local time, formatted like `$datetime`"},
+	{"$randomInt", "A random integer between min (inclusive) and max (exclusive): `{{$randomInt 1 100}}`"},
+	{"$processEnv", "A variable of the process environment: `{{$processEnv HOME}}`"},
+	{"$dotenv", "A variable of the `.env` file next to the `.http` file: `{{$dotenv API_KEY}}`"},
+	{"$prompt", "A value asked for when sending: `{{$prompt name description}}`"},
+}
+
+func (s *Server) completion(ctx context.Context, params json.RawMessage) (any, error) {
+	doc, pos, err := s.positionDocument(params)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+
+	col := doc.column(pos)
+	if col < 0 {
+		return nil, nil
+	}
+	before := doc.lines[pos.Line][:col]
+	open := strings.LastIndex(before, "{{")
+	if open < 0 || strings.Contains(before[open:], "}}") {
+		return []CompletionItem{}, nil
+	}
+
+	// Completions replace what was typed since {{
+	start := open + 2
+	for start < col && (before[start] == ' ' || before[start] == '%') {
+		start++
+	}
+	replace := doc.span(pos.Line, start, col)
+
+	ws := s.workspace(doc)
+	return ws.completionItems(pos.Line, replace), nil
+}
+
+// completionItems lists the variables visible to a line: file, prompt,
+// environment and session variables, named requests and system variables
+func (ws *workspace) completionItems(line int, replace Range) []CompletionItem {
+	items := []CompletionItem{}
+	item := func(label, insert string, kind int, detail, documentation string) {
+		completion := CompletionItem{
+			Label:    label,
+			Kind:     kind,
+			Detail:   detail,
+			TextEdit: &TextEdit{Range: replace, NewText: insert},
+		}
+		if documentation != "" {
+			completion.Documentation = &MarkupContent{Kind: "markdown", Value: documentation}
+		}
+		items = append(items, completion)
+	}
+
+	for _, name := range ws.names(line) {
+		b, _ := ws.lookup(name, line)
+		item(name, name, CompletionKindVariable, b.scope, ws.describe(name, b))
+	}
+
+	for i, file := range ws.files {
+		for _, def := range file.requests {
+			label := def.name
+			if i > 0 {
+				label = filepath.Base(file.path) + "#" + def.name
+			}
+			item(label, label+".response.body.$.", CompletionKindReference, "request", "Response of the `"+def.name+"` request")
+		}
+	}
+
+	for _, system := range systemVariables {
+		item(system.name, system.name, CompletionKindFunction, "system variable", system.description)
+	}
+
+	return items
+}
+
+// describe returns the declared value of a variable for display, masked
+// if it looks like a secret
+func (ws *workspace) describe(name string, b binding) string {
+	if b.scope == scopePrompt {
+		return b.def.value // The description of the prompt
+	}
+	value, ok := ws.declaredValue(name, b)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("`%s`", maskSecret(name, value))
+}
+
+// declaredValue returns the value a variable is declared with, before its
+// own variables are resolved. Prompt variables have none.
+func (ws *workspace) declaredValue(name string, b binding) (string, bool) {
+	switch {
+	case b.scope == scopeSession:
+		return ws.sessionVars[name], true
+	case b.scope == scopePrompt:
+		return "", false
+	case b.file != nil:
+		return b.def.value, true
+	default:
+		value, ok := ws.environmentVariables()[name]
+		return value, ok
+	}
+}
+
+// maskSecret masks a value if it is a secret
+func maskSecret(name, value string) string {
+	if isSecret(name, value) {
+		return stringutil.Mask(value)
+	}
+	return value
+}
+
+// isSecret reports whether the name of a variable, or of one that its value
+// refers to, suggests a secret
+func isSecret(name, value string) bool {
+	if stringutil.IsSensitiveName(name) {
+		return true
+	}
+	for _, match := range referenceRegex.FindAllStringSubmatch(value, -1) {
+		if stringutil.IsSensitiveName(match[1]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package lsp
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/parser"
+	"github.com/ideaspaper/restclient/pkg/variables"
+)
+
+// publishDiagnostics checks a document and sends its problems to the client
+func (s *Server) publishDiagnostics(doc *document) {
+	s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         doc.uri,
+		Diagnostics: s.workspace(doc).diagnostics(),
+	})
+}
+
+// diagnostics reports the import errors and parse warnings of the
+// document, and the warnings and validation errors of its requests
+func (ws *workspace) diagnostics() []Diagnostic {
+	doc := ws.doc
+	diagnostics := []Diagnostic{}
+	add := func(r Range, severity int, message string) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    r,
+			Severity: severity,
+			Source:   "restclient",
+			Message:  message,
+		})
+	}
+
+	if ws.importErr != nil {
+		line := 0
+		var parseErr *errors.ParseError
+		if errors.As(ws.importErr, &parseErr) && parseErr.File == doc.path && parseErr.Line > 0 {
+			line = parseErr.Line - 1
+		}
+		add(doc.lineRange(line), SeverityError, ws.importErr.Error())
+	}
+
+	httpParser := parser.NewHttpRequestParser(doc.text, ws.defaultHeaders, filepath.Dir(doc.path))
+	for _, warning := range httpParser.ParseAllWithWarnings().Warnings {
+		add(doc.nodeRange(warning.Span), SeverityWarning, warning.Message)
+	}
+
+	for _, parsed := range ws.requests() {
+		for _, warning := range parsed.warnings {
+			add(doc.nodeRange(warning.Span), SeverityWarning, warning.Message)
+		}
+		for _, message := range validate(parsed.request, ws.processor) {
+			add(doc.lineRange(parsed.requestLine), SeverityError, message)
+		}
+	}
+
+	return diagnostics
+}
+
+// validate returns the validation errors of a request. Variables are
+// substituted where they resolve without prompting; unresolved variables
+// are not reported since they may only be known when sending.
+func validate(request *models.HttpRequest, processor *variables.VariableProcessor) []string {
+	clone := request.Clone()
+	if url, err := processor.Process(clone.URL); err == nil {
+		clone.URL = url
+	}
+	for name, value := range clone.Headers {
+		if resolved, err := processor.Process(value); err == nil {
+			clone.Headers[name] = resolved
+		}
+	}
+
+	var messages []string
+	for _, err := range clone.Validate().Errors {
+		if strings.Contains(err.Message, "unresolved variables") {
+			continue
+		}
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
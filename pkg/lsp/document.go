@@ -0,0 +1,199 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ideaspaper/restclient/pkg/parser"
+)
+
+var (
+	// referenceRegex matches a {{variable}} reference, like variables.Process
+	referenceRegex = regexp.MustCompile(`\{\{(.+?)\}\}`)
+
+	// fileVariableRegex matches an @name = value declaration, like
+	// variables.ParseFileVariables
+	fileVariableRegex = regexp.MustCompile(`^\s*@([^\s=]+)\s*=\s*(.*?)\s*$`)
+
+	// requestNameRegex matches a # @name or // @name metadata line
+	requestNameRegex = regexp.MustCompile(`^\s*(?:#|//)\s*@name\s+(\S+)`)
+
+	// promptRegex matches a # @prompt name [description] metadata line
+	promptRegex = regexp.MustCompile(`^\s*(?:#|//)\s*@prompt\s+(\S+)(?:\s+(.*?))?\s*$`)
+)
+
+// document is an open .http or .rest file
+type document struct {
+	uri   string
+	path  string // Empty for URIs that are not files
+	text  string
+	lines []string
+}
+
+func newDocument(uri, text string) *document {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return &document{
+		uri:   uri,
+		path:  uriToPath(uri),
+		text:  text,
+		lines: strings.Split(text, "\n"),
+	}
+}
+
+// uriToPath returns the path of a file:// URI, or "" for other schemes
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// pathToURI returns the file:// URI of a path
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// position converts a byte column of a line to an LSP position
+func (d *document) position(line, col int) Position {
+	return Position{Line: line, Character: utf16Len(d.lines[line][:col])}
+}
+
+// span returns the range between two byte columns of a line
+func (d *document) span(line, start, end int) Range {
+	return Range{Start: d.position(line, start), End: d.position(line, end)}
+}
+
+// nodeRange returns the range of a node of the syntax tree, without the
+// indentation of its first line
+func (d *document) nodeRange(span parser.Span) Range {
+	r := d.lineRange(span.Start.Line)
+	if span.End.Line > span.Start.Line && span.End.Line < len(d.lines) {
+		r.End = d.position(span.End.Line, span.End.Column)
+	}
+	return r
+}
+
+// lineRange returns the range of a line without its indentation
+func (d *document) lineRange(line int) Range {
+	if line < 0 || line >= len(d.lines) {
+		return Range{}
+	}
+	text := d.lines[line]
+	start := len(text) - len(strings.TrimLeft(text, " \t"))
+	return d.span(line, start, len(text))
+}
+
+// column converts an LSP position to a byte column, or -1 if the position
+// is outside the document
+func (d *document) column(pos Position) int {
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return -1
+	}
+	line := d.lines[pos.Line]
+	units := 0
+	for i, r := range line {
+		if units >= pos.Character {
+			return i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(line)
+}
+
+// utf16Len returns the length of s in UTF-16 code units
+func utf16Len(s string) int {
+	n := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return n
+}
+
+// reference is a {{variable}} in a document
+type reference struct {
+	name  string // Variable name, without braces, spaces or a leading %
+	line  int
+	start int // Byte column of {{
+	end   int // Byte column after }}
+}
+
+// isSystem reports whether the reference is a system variable like $guid
+func (r reference) isSystem() bool {
+	return strings.HasPrefix(r.name, "$")
+}
+
+// isRequest reports whether the reference is a request variable like
+// login.response.body.$.token
+func (r reference) isRequest() bool {
+	return strings.Contains(r.name, ".response.") || strings.Contains(r.name, ".request.")
+}
+
+// referenceAt returns the reference at a position
+func (d *document) referenceAt(pos Position) (reference, bool) {
+	col := d.column(pos)
+	if col < 0 {
+		return reference{}, false
+	}
+	for _, match := range referenceRegex.FindAllStringSubmatchIndex(d.lines[pos.Line], -1) {
+		if col >= match[0] && col <= match[1] {
+			name := strings.TrimPrefix(strings.TrimSpace(d.lines[pos.Line][match[2]:match[3]]), "%")
+			return reference{name: name, line: pos.Line, start: match[0], end: match[1]}, true
+		}
+	}
+	return reference{}, false
+}
+
+// definition is a declaration of a name in a file
+type definition struct {
+	name  string
+	value string // Value of a file variable, description of a prompt
+	line  int
+	start int // Byte column of the name
+	end   int
+}
+
+// fileVariables returns the @name = value declarations of lines
+func fileVariables(lines []string) []definition {
+	return findDefinitions(lines, fileVariableRegex)
+}
+
+// requestNames returns the # @name declarations of lines
+func requestNames(lines []string) []definition {
+	return findDefinitions(lines, requestNameRegex)
+}
+
+// findDefinitions returns the lines matching re, whose first group is the
+// name and second group, if any, the value
+func findDefinitions(lines []string, re *regexp.Regexp) []definition {
+	var defs []definition
+	for i, line := range lines {
+		match := re.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		def := definition{name: line[match[2]:match[3]], line: i, start: match[2], end: match[3]}
+		if len(match) > 4 && match[4] >= 0 {
+			def.value = line[match[4]:match[5]]
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// findDefinition returns the last definition of name, which is the one
+// variables.ParseFileVariables keeps
+func findDefinition(defs []definition, name string) (definition, bool) {
+	for i := len(defs) - 1; i >= 0; i-- {
+		if defs[i].name == name {
+			return defs[i], true
+		}
+	}
+	return definition{}, false
+}
@@ -0,0 +1,144 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ideaspaper/restclient/internal/stringutil"
+)
+
+// definition jumps from a {{variable}} to its @name = value declaration,
+// from a request variable to the # @name of the request and from a prompt
+// variable to its # @prompt
+func (s *Server) definition(ctx context.Context, params json.RawMessage) (any, error) {
+	doc, pos, err := s.positionDocument(params)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	ref, ok := doc.referenceAt(pos)
+	if !ok || ref.isSystem() {
+		return nil, nil
+	}
+
+	ws := s.workspace(doc)
+	if ref.isRequest() {
+		if file, def, ok := ws.findRequest(ref.name); ok {
+			return ws.location(file, def), nil
+		}
+		return nil, nil
+	}
+	if b, ok := ws.lookup(ref.name, ref.line); ok && b.file != nil {
+		return ws.location(b.file, b.def), nil
+	}
+	return nil, nil
+}
+
+// location returns the location of a declaration
+func (ws *workspace) location(file *sourceFile, def definition) Location {
+	uri := ws.doc.uri
+	if file != &ws.files[0] {
+		uri = pathToURI(file.path)
+	}
+	line := file.lines[def.line]
+	return Location{
+		URI: uri,
+		Range: Range{
+			Start: Position{Line: def.line, Character: utf16Len(line[:def.start])},
+			End:   Position{Line: def.line, Character: utf16Len(line[:def.end])},
+		},
+	}
+}
+
+// hover shows where a {{variable}} is declared and its value, masking
+// secrets
+func (s *Server) hover(ctx context.Context, params json.RawMessage) (any, error) {
+	doc, pos, err := s.positionDocument(params)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	ref, ok := doc.referenceAt(pos)
+	if !ok {
+		return nil, nil
+	}
+
+	ws := s.workspace(doc)
+	var contents string
+	switch {
+	case ref.isSystem():
+		contents = systemVariableHover(ref.name)
+	case ref.isRequest():
+		contents = ws.requestHover(ref.name)
+	default:
+		contents = ws.variableHover(ref.name, ref.line)
+	}
+	if contents == "" {
+		return nil, nil
+	}
+
+	span := doc.span(ref.line, ref.start, ref.end)
+	return Hover{Contents: MarkupContent{Kind: "markdown", Value: contents}, Range: &span}, nil
+}
+
+// systemVariableHover describes a system variable
+func systemVariableHover(name string) string {
+	fields := strings.Fields(name)
+	for _, system := range systemVariables {
+		if system.name == fields[0] {
+			return fmt.Sprintf("**%s** — system variable\n\n%s", system.name, system.description)
+		}
+	}
+	return fmt.Sprintf("**%s** — unknown system variable", fields[0])
+}
+
+// requestHover describes a request variable and its value if the request
+// has a stored result
+func (ws *workspace) requestHover(name string) string {
+	file, request := splitRequestReference(name)
+	if file != "" {
+		request = file + "#" + request
+	}
+	if _, _, ok := ws.findRequest(name); !ok {
+		return fmt.Sprintf("**%s** — request not found", request)
+	}
+
+	text := fmt.Sprintf("**%s** — request variable", request)
+	value, err := ws.processor.Process("{{" + name + "}}")
+	if err != nil {
+		return text + "\n\nNot available until the request is sent"
+	}
+	return text + fmt.Sprintf("\n\n`%s`", maskSecret(name, value))
+}
+
+// variableHover describes a variable: its scope, declared value and the
+// value it resolves to
+func (ws *workspace) variableHover(name string, line int) string {
+	b, ok := ws.lookup(name, line)
+	if !ok {
+		return fmt.Sprintf("**%s** — undefined variable", name)
+	}
+
+	text := fmt.Sprintf("**%s** — %s", name, b.scope)
+	if b.scope == scopePrompt {
+		if b.def.value != "" {
+			text += "\n\n" + b.def.value
+		}
+		return text + "\n\nAsked for when sending"
+	}
+
+	declared, _ := ws.declaredValue(name, b)
+	secret := isSecret(name, declared)
+	mask := func(value string) string {
+		if secret {
+			return stringutil.Mask(value)
+		}
+		return value
+	}
+
+	text += fmt.Sprintf("\n\n`%s`", mask(declared))
+	if resolved, err := ws.processor.Process("{{" + name + "}}"); err == nil && resolved != declared {
+		text += fmt.Sprintf(" → `%s`", mask(resolved))
+	}
+	return text
+}
@@ -0,0 +1,255 @@
+// Package lsp implements a Language Server Protocol server for .http and
+// .rest files, speaking JSON-RPC over stdio to editors such as Neovim,
+// Helix and Zed.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// sendCommand is the command run by the "Send request" code lens
+const sendCommand = "restclient.send"
+
+// Options configures the server
+type Options struct {
+	SessionName string // Named session to use instead of the directory session of each file
+	Environment string // Environment overriding the current one of each session
+	Executable  string // restclient binary that sends requests
+	Version     string
+}
+
+// Server is a language server for .http and .rest files
+type Server struct {
+	options Options
+	conn    *conn
+
+	mu        sync.Mutex
+	documents map[string]*document // URI -> open document
+	shutdown  bool
+	sending   sync.WaitGroup
+}
+
+// NewServer creates a language server
+func NewServer(options Options) *Server {
+	return &Server{
+		options:   options,
+		documents: make(map[string]*document),
+	}
+}
+
+// handler answers a request. Its result is sent back unless the message
+// is a notification.
+type handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Run serves the client connected to r and w until it sends exit or closes
+// its input
+func (s *Server) Run(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	defer s.sending.Wait()
+
+	handlers := map[string]handler{
+		"initialize":              s.initialize,
+		"initialized":             ignore,
+		"shutdown":                s.handleShutdown,
+		"textDocument/didOpen":    s.didOpen,
+		"textDocument/didChange":  s.didChange,
+		"textDocument/didSave":    s.didSave,
+		"textDocument/didClose":   s.didClose,
+		"textDocument/completion": s.completion,
+		"textDocument/definition": s.definition,
+		"textDocument/hover":      s.hover,
+		"textDocument/codeLens":   s.codeLens,
+	}
+
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			var parseErr *errors.ParseError
+			if errors.As(err, &parseErr) {
+				s.conn.replyError(nil, codeParseError, err.Error())
+				continue
+			}
+			return err
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+		if s.isShutdown() {
+			if !msg.isNotification() {
+				s.conn.replyError(msg.ID, codeInvalidRequest, "server is shutting down")
+			}
+			continue
+		}
+
+		// Sending a request takes a while; it runs without blocking the
+		// other requests
+		if msg.Method == "workspace/executeCommand" {
+			s.sending.Add(1)
+			go func() {
+				defer s.sending.Done()
+				result, err := s.executeCommand(ctx, msg.Params)
+				s.respond(msg, result, err)
+			}()
+			continue
+		}
+
+		h, ok := handlers[msg.Method]
+		if !ok {
+			if !msg.isNotification() {
+				s.conn.replyError(msg.ID, codeMethodNotFound, "method not found: "+msg.Method)
+			}
+			continue
+		}
+		result, err := h(ctx, msg.Params)
+		s.respond(msg, result, err)
+	}
+}
+
+// respond answers a request with the result of its handler
+func (s *Server) respond(msg *message, result any, err error) {
+	if msg.isNotification() {
+		return
+	}
+	if err != nil {
+		code := codeInternalError
+		var validationErr *errors.ValidationError
+		if errors.As(err, &validationErr) {
+			code = codeInvalidParams
+		}
+		s.conn.replyError(msg.ID, code, err.Error())
+		return
+	}
+	s.conn.reply(msg.ID, result)
+}
+
+// ignore handles notifications that need no action
+func ignore(ctx context.Context, params json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+func (s *Server) initialize(ctx context.Context, params json.RawMessage) (any, error) {
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync": map[string]any{
+				"openClose": true,
+				"change":    1, // Full document on every change
+				"save":      true,
+			},
+			"completionProvider":     map[string]any{"triggerCharacters": []string{"{", "$", "."}},
+			"definitionProvider":     true,
+			"hoverProvider":          true,
+			"codeLensProvider":       map[string]any{"resolveProvider": false},
+			"executeCommandProvider": map[string]any{"commands": []string{sendCommand}},
+		},
+		"serverInfo": map[string]any{"name": "restclient", "version": s.options.Version},
+	}, nil
+}
+
+func (s *Server) handleShutdown(ctx context.Context, params json.RawMessage) (any, error) {
+	s.mu.Lock()
+	s.shutdown = true
+	s.mu.Unlock()
+	return nil, nil
+}
+
+// isShutdown reports whether the client asked the server to shut down
+func (s *Server) isShutdown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shutdown
+}
+
+func (s *Server) didOpen(ctx context.Context, params json.RawMessage) (any, error) {
+	var p didOpenParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	s.open(newDocument(p.TextDocument.URI, p.TextDocument.Text))
+	return nil, nil
+}
+
+func (s *Server) didChange(ctx context.Context, params json.RawMessage) (any, error) {
+	var p didChangeParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	if len(p.ContentChanges) == 0 {
+		return nil, nil
+	}
+	// With full sync, the last change holds the whole document
+	s.open(newDocument(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text))
+	return nil, nil
+}
+
+func (s *Server) didSave(ctx context.Context, params json.RawMessage) (any, error) {
+	var p documentParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	// Imported files may have changed as well
+	if doc := s.document(p.TextDocument.URI); doc != nil {
+		s.publishDiagnostics(doc)
+	}
+	return nil, nil
+}
+
+func (s *Server) didClose(ctx context.Context, params json.RawMessage) (any, error) {
+	var p documentParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	delete(s.documents, p.TextDocument.URI)
+	s.mu.Unlock()
+	return nil, s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []Diagnostic{}})
+}
+
+// open stores a new version of a document and checks it
+func (s *Server) open(doc *document) {
+	s.mu.Lock()
+	s.documents[doc.uri] = doc
+	s.mu.Unlock()
+	s.publishDiagnostics(doc)
+}
+
+// document returns an open document, nil if it is not open
+func (s *Server) document(uri string) *document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.documents[uri]
+}
+
+// positionDocument decodes position params and returns their document
+func (s *Server) positionDocument(params json.RawMessage) (*document, Position, error) {
+	var p positionParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, Position{}, err
+	}
+	return s.document(p.TextDocument.URI), p.Position, nil
+}
+
+// showMessage displays a message in the editor
+func (s *Server) showMessage(messageType int, message string) {
+	s.conn.notify("window/showMessage", messageParams{Type: messageType, Message: message})
+}
+
+// logMessage writes a message to the editor's log of the server
+func (s *Server) logMessage(messageType int, message string) {
+	s.conn.notify("window/logMessage", messageParams{Type: messageType, Message: message})
+}
+
+func unmarshalParams(params json.RawMessage, v any) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return errors.NewValidationError("params", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/restclient/pkg/session"
+)
+
+// testClient talks to a server running in the background
+type testClient struct {
+	t             *testing.T
+	conn          *conn
+	nextID        int
+	notifications []testMessage
+}
+
+type testMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+// startServer runs a server with its session data in a temporary home
+// directory and initializes it
+func startServer(t *testing.T, options Options) *testClient {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer(options).Run(context.Background(), serverIn, serverOut)
+		serverOut.Close()
+	}()
+
+	client := &testClient{t: t, conn: newConn(clientIn, clientOut)}
+	t.Cleanup(func() {
+		client.notify("exit", nil)
+		if err := <-done; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	})
+
+	client.request("initialize", map[string]any{"capabilities": map[string]any{}}, nil)
+	client.notify("initialized", map[string]any{})
+	return client
+}
+
+// request sends a request and decodes its result into result
+func (c *testClient) request(method string, params, result any) {
+	c.t.Helper()
+	c.nextID++
+	id := json.RawMessage(strings.TrimSpace(string(mustMarshal(c.t, c.nextID))))
+	if err := c.conn.write(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params}); err != nil {
+		c.t.Fatalf("write %s: %v", method, err)
+	}
+
+	for {
+		msg := c.read()
+		if msg.Method != "" {
+			c.notifications = append(c.notifications, msg)
+			continue
+		}
+		if string(msg.ID) != string(id) {
+			c.t.Fatalf("response id = %s, want %s", msg.ID, id)
+		}
+		if msg.Error != nil {
+			c.t.Fatalf("%s error = %s", method, msg.Error.Message)
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				c.t.Fatalf("decode %s result %s: %v", method, msg.Result, err)
+			}
+		}
+		return
+	}
+}
+
+// notify sends a notification
+func (c *testClient) notify(method string, params any) {
+	c.t.Helper()
+	if err := c.conn.write(map[string]any{"jsonrpc": "2.0", "method": method, "params": params}); err != nil {
+		c.t.Fatalf("write %s: %v", method, err)
+	}
+}
+
+func (c *testClient) read() testMessage {
+	c.t.Helper()
+	body, err := c.conn.readBody()
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+	var msg testMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatalf("decode %s: %v", body, err)
+	}
+	return msg
+}
+
+// open opens a document and returns the diagnostics published for it
+func (c *testClient) open(path, text string) []Diagnostic {
+	c.t.Helper()
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		c.t.Fatalf("failed to write %s: %v", path, err)
+	}
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": pathToURI(path), "languageId": "http", "version": 1, "text": text},
+	})
+
+	msg := c.read()
+	if msg.Method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("got %s, want diagnostics", msg.Method)
+	}
+	var params publishDiagnosticsParams
+	json.Unmarshal(msg.Params, &params)
+	return params.Diagnostics
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func at(path string, line, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": pathToURI(path)},
+		"position":     map[string]any{"line": line, "character": character},
+	}
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	client := startServer(t, Options{})
+	path := filepath.Join(t.TempDir(), "api.http")
+
+	diagnostics := client.open(path, `@baseUrl = https://api.example.com
+
+# @retry-on teapot
+GET {{baseUrl}}/users
+
+###
+
+GET /relative
+Bad Header: value
+`)
+
+	want := []struct {
+		line    int
+		message string
+	}{
+		{2, "@retry-on: 'teapot'"},
+		{7, "URL must include scheme"},
+		{7, "header name contains invalid characters"},
+	}
+	if len(diagnostics) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %+v", len(diagnostics), len(want), diagnostics)
+	}
+	for i, w := range want {
+		if diagnostics[i].Range.Start.Line != w.line || !strings.Contains(diagnostics[i].Message, w.message) {
+			t.Errorf("diagnostic %d = line %d %q, want line %d %q", i, diagnostics[i].Range.Start.Line, diagnostics[i].Message, w.line, w.message)
+		}
+	}
+}
+
+func TestServer_DoesNotCreateSession(t *testing.T) {
+	client := startServer(t, Options{})
+	path := filepath.Join(t.TempDir(), "api.http")
+	client.open(path, "GET https://example.com\n")
+
+	sessionMgr, err := session.NewSessionManager("", path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(sessionMgr.GetSessionPath()); !os.IsNotExist(err) {
+		t.Errorf("opening a document should not create the session directory, stat error = %v", err)
+	}
+}
+
+func TestServer_Completion(t *testing.T) {
+	client := startServer(t, Options{})
+	path := filepath.Join(t.TempDir(), "api.http")
+	client.open(path, `@host = api.example.com
+
+# @name login
+POST https://{{host}}/login
+
+###
+
+# @prompt otp One-time password
+GET https://{{ho
+`)
+
+	var items []CompletionItem
+	client.request("textDocument/completion", at(path, 8, 16), &items)
+
+	var labels []string
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	for _, want := range []string{"host", "otp", "login", "$guid", "$randomInt"} {
+		if !slices.Contains(labels, want) {
+			t.Errorf("completion labels %v missing %s", labels, want)
+		}
+	}
+
+	host := items[slices.Index(labels, "host")]
+	if host.TextEdit == nil || host.TextEdit.Range.Start.Character != 14 || host.TextEdit.Range.End.Character != 16 {
+		t.Errorf("host text edit = %+v, want to replace the typed prefix", host.TextEdit)
+	}
+	if login := items[slices.Index(labels, "login")]; login.TextEdit.NewText != "login.response.body.$." {
+		t.Errorf("login inserts %q", login.TextEdit.NewText)
+	}
+
+	// Outside of {{ }} nothing is completed
+	client.request("textDocument/completion", at(path, 3, 3), &items)
+	if len(items) != 0 {
+		t.Errorf("got %d completions outside a variable", len(items))
+	}
+}
+
+func TestServer_Definition(t *testing.T) {
+	client := startServer(t, Options{})
+	dir := t.TempDir()
+	auth := filepath.Join(dir, "auth.http")
+	if err := os.WriteFile(auth, []byte("# @name login\nPOST https://auth.example.com/login\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "api.http")
+	client.open(path, `# @import ./auth.http
+@host = api.example.com
+
+GET https://{{host}}/me
+Authorization: Bearer {{auth.http#login.response.body.$.token}}
+`)
+
+	var location Location
+	client.request("textDocument/definition", at(path, 3, 15), &location)
+	if location.URI != pathToURI(path) || location.Range.Start != (Position{Line: 1, Character: 1}) {
+		t.Errorf("definition of host = %+v", location)
+	}
+
+	client.request("textDocument/definition", at(path, 4, 30), &location)
+	if location.URI != pathToURI(auth) || location.Range.Start != (Position{Line: 0, Character: 8}) {
+		t.Errorf("definition of login = %+v", location)
+	}
+}
+
+func TestServer_Hover(t *testing.T) {
+	client := startServer(t, Options{})
+	path := filepath.Join(t.TempDir(), "api.http")
+	client.open(path, `@host = api.example.com
+@baseUrl = https://{{host}}
+@apiToken = supersecret
+@header = Bearer {{apiToken}}
+
+GET {{baseUrl}}/users
+Authorization: {{header}}
+X-Id: {{$guid}}
+`)
+
+	tests := []struct {
+		line, character int
+		want            []string
+		notWant         string
+	}{
+		{5, 6, []string{"**baseUrl** — file variable", "`https://{{host}}` → `https://api.example.com`"}, ""},
+		{6, 18, []string{"**header** — file variable", "`Bear"}, "supersecret"},
+		{7, 9, []string{"**$guid** — system variable"}, ""},
+	}
+	for _, tt := range tests {
+		var hover Hover
+		client.request("textDocument/hover", at(path, tt.line, tt.character), &hover)
+		for _, want := range tt.want {
+			if !strings.Contains(hover.Contents.Value, want) {
+				t.Errorf("hover at %d:%d = %q, want %q", tt.line, tt.character, hover.Contents.Value, want)
+			}
+		}
+		if tt.notWant != "" && strings.Contains(hover.Contents.Value, tt.notWant) {
+			t.Errorf("hover at %d:%d = %q shows the secret", tt.line, tt.character, hover.Contents.Value)
+		}
+	}
+}
+
+func TestServer_CodeLens(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the restclient executable")
+	}
+
+	// A stand-in for restclient that prints its arguments as the response
+	dir := t.TempDir()
+	executable := filepath.Join(dir, "restclient")
+	if err := os.WriteFile(executable, []byte("#!/bin/sh\necho \"$@\"\necho 'HTTP/1.1 200 OK'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	client := startServer(t, Options{Executable: executable, Environment: "staging"})
+	path := filepath.Join(dir, "api.http")
+	client.open(path, `GET https://api.example.com/users
+
+###
+
+# @name create
+POST https://api.example.com/users
+`)
+
+	var lenses []CodeLens
+	client.request("textDocument/codeLens", map[string]any{"textDocument": map[string]any{"uri": pathToURI(path)}}, &lenses)
+	if len(lenses) != 2 || lenses[0].Range.Start.Line != 0 || lenses[1].Range.Start.Line != 5 {
+		t.Fatalf("code lenses = %+v, want one per request line", lenses)
+	}
+
+	wantArgs := []string{"send " + path + " --index 1 --env staging", "send " + path + " --name create --env staging"}
+	for i, lens := range lenses {
+		var output string
+		client.request("workspace/executeCommand", map[string]any{"command": lens.Command.Command, "arguments": lens.Command.Arguments}, &output)
+		if !strings.Contains(output, wantArgs[i]) {
+			t.Errorf("lens %d ran %q, want %q", i, output, wantArgs[i])
+		}
+	}
+}
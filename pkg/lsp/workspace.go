@@ -0,0 +1,294 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ideaspaper/restclient/internal/filesystem"
+	"github.com/ideaspaper/restclient/pkg/models"
+	"github.com/ideaspaper/restclient/pkg/parser"
+	"github.com/ideaspaper/restclient/pkg/session"
+	"github.com/ideaspaper/restclient/pkg/variables"
+)
+
+// sourceFile is the document or a file it imports
+type sourceFile struct {
+	path      string
+	lines     []string
+	variables []definition
+	requests  []definition
+}
+
+func newSourceFile(path, content string) sourceFile {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	return sourceFile{path: path, lines: lines, variables: fileVariables(lines), requests: requestNames(lines)}
+}
+
+// workspace holds what the variables of a document resolve against: its
+// imports and the environments, variables and request results of its
+// session
+type workspace struct {
+	doc            *document
+	files          []sourceFile // The document, then its imports in import order
+	importErr      error
+	environment    string
+	envVariables   map[string]map[string]string
+	sessionVars    map[string]string
+	defaultHeaders map[string]string
+	processor      *variables.VariableProcessor
+}
+
+// Scopes of variables that are not file or environment variables
+const (
+	scopeSession = "session variable"
+	scopePrompt  = "prompt variable"
+)
+
+// binding is where a variable is declared
+type binding struct {
+	scope string      // Description of the scope, e.g. "file variable"
+	file  *sourceFile // Declaring file, nil for environment and session variables
+	def   definition
+}
+
+// workspace loads the scopes of a document. Documents that are not files
+// only see their own file variables.
+func (s *Server) workspace(doc *document) *workspace {
+	ws := &workspace{
+		doc:          doc,
+		files:        []sourceFile{newSourceFile(doc.path, doc.text)},
+		envVariables: make(map[string]map[string]string),
+		sessionVars:  make(map[string]string),
+	}
+	ws.processor = variables.NewVariableProcessor()
+
+	if doc.path != "" {
+		ws.loadSession(s.options)
+
+		imported, err := parser.ResolveImports(doc.path, doc.text)
+		ws.importErr = err
+		for _, file := range imported {
+			ws.files = append(ws.files, newSourceFile(file.Path, file.Content))
+		}
+		ws.processor.SetCurrentDir(filepath.Dir(doc.path))
+	}
+
+	// Same precedence as send: the document over its imports, later
+	// imports over earlier ones, session variables over all of them
+	for _, file := range ws.files[1:] {
+		ws.processor.SetFileVariables(variables.ParseFileVariables(strings.Join(file.lines, "\n")))
+	}
+	ws.processor.SetFileVariables(variables.ParseFileVariables(doc.text))
+	ws.processor.SetFileVariables(ws.sessionVars)
+	ws.processor.SetEnvironment(ws.environment)
+	ws.processor.SetEnvironmentVariables(ws.envVariables)
+
+	return ws
+}
+
+// loadSession loads the environments, variables and request results of
+// the session of the document
+func (ws *workspace) loadSession(options Options) {
+	sessionMgr, err := session.NewSessionManager("", ws.doc.path, options.SessionName)
+	if err != nil {
+		return
+	}
+	sessionPath := sessionMgr.GetSessionPath()
+
+	sessionCfg, err := session.LoadSessionConfig(filesystem.Default, sessionPath)
+	if err != nil {
+		sessionCfg = session.DefaultSessionConfig()
+	}
+	if options.Environment != "" {
+		sessionCfg.SetCurrentEnvironment(options.Environment)
+	}
+	ws.environment = sessionCfg.CurrentEnvironment()
+	ws.defaultHeaders = sessionCfg.DefaultHeaders()
+
+	if envStore, err := session.LoadEnvironmentStore(filesystem.Default, sessionPath); err == nil {
+		ws.envVariables = envStore.EnvironmentVariables
+	}
+
+	// Missing files are not an error; whatever could be read is used
+	_ = sessionMgr.Load()
+	for name, value := range sessionMgr.GetAllVariables() {
+		if str, ok := value.(string); ok {
+			ws.sessionVars[name] = str
+		} else {
+			ws.sessionVars[name] = fmt.Sprintf("%v", value)
+		}
+	}
+	for key, result := range sessionMgr.GetAllResults() {
+		ws.processor.SetRequestResult(key, variables.RequestResult{
+			StatusCode: result.StatusCode,
+			Headers:    result.Headers,
+			Body:       result.Body,
+			ExpiresAt:  result.ExpiresAt,
+		})
+	}
+}
+
+// environmentVariables returns the variables of the current environment,
+// overridden by those of $shared as variables.VariableProcessor does
+func (ws *workspace) environmentVariables() map[string]string {
+	vars := make(map[string]string)
+	for name, value := range ws.envVariables[ws.environment] {
+		vars[name] = value
+	}
+	for name, value := range ws.envVariables["$shared"] {
+		vars[name] = value
+	}
+	return vars
+}
+
+// lookup returns where a variable is declared, in the order send resolves
+// it: session, prompts of the request, files, then environments
+func (ws *workspace) lookup(name string, line int) (binding, bool) {
+	if _, ok := ws.sessionVars[name]; ok {
+		return binding{scope: scopeSession}, true
+	}
+	if def, ok := findDefinition(ws.prompts(line), name); ok {
+		return binding{scope: scopePrompt, file: &ws.files[0], def: def}, true
+	}
+	if def, ok := findDefinition(ws.files[0].variables, name); ok {
+		return binding{scope: "file variable", file: &ws.files[0], def: def}, true
+	}
+	for i := len(ws.files) - 1; i > 0; i-- {
+		if def, ok := findDefinition(ws.files[i].variables, name); ok {
+			return binding{scope: "file variable from " + filepath.Base(ws.files[i].path), file: &ws.files[i], def: def}, true
+		}
+	}
+	if _, ok := ws.envVariables["$shared"][name]; ok {
+		return binding{scope: "environment variable ($shared)"}, true
+	}
+	if _, ok := ws.envVariables[ws.environment][name]; ok {
+		return binding{scope: fmt.Sprintf("environment variable (%s)", ws.environment)}, true
+	}
+	return binding{}, false
+}
+
+// prompts returns the @prompt declarations of the request block holding a
+// line of the document
+func (ws *workspace) prompts(line int) []definition {
+	var defs []definition
+	block := blockAt(ws.doc, line)
+	for _, def := range findDefinitions(ws.doc.lines, promptRegex) {
+		if blockAt(ws.doc, def.line) == block {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// findRequest returns the file and declaration of the named request a
+// request variable refers to. A request of another file is prefixed with
+// its path, e.g. auth.http#login.response.body.$.token.
+func (ws *workspace) findRequest(ref string) (*sourceFile, definition, bool) {
+	file, name := splitRequestReference(ref)
+	for i := range ws.files {
+		if file != "" && !ws.isFile(ws.files[i].path, file) {
+			continue
+		}
+		for _, def := range ws.files[i].requests {
+			if def.name == name {
+				return &ws.files[i], def, true
+			}
+		}
+	}
+	return nil, definition{}, false
+}
+
+// isFile reports whether path is the file a reference names, relative to
+// the document
+func (ws *workspace) isFile(path, name string) bool {
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(filepath.Dir(ws.doc.path), name)
+	}
+	return filepath.Clean(name) == path
+}
+
+// splitRequestReference splits a request variable into the file and the
+// name of the request it refers to
+func splitRequestReference(ref string) (file, name string) {
+	end := strings.Index(ref, ".response.")
+	if i := strings.Index(ref, ".request."); i >= 0 && (end < 0 || i < end) {
+		end = i
+	}
+	if end < 0 {
+		end = len(ref)
+	}
+	if hash := strings.Index(ref[:end], "#"); hash > 0 {
+		return ref[:hash], ref[hash+1 : end]
+	}
+	return "", ref[:end]
+}
+
+// blockAt returns the index of the request block holding a line
+func blockAt(doc *document, line int) int {
+	index := 0
+	for _, block := range parser.Parse(doc.text).Blocks {
+		if len(block.Nodes) > 0 && block.Span.Start.Line <= line {
+			index = block.Index
+		}
+	}
+	return index
+}
+
+// parsedBlock is a request block that parses into a request
+type parsedBlock struct {
+	requestLine int // Line of the document holding the request line
+	request     *models.HttpRequest
+	warnings    []parser.ParseWarning
+}
+
+// requests parses the request blocks of the document, indexed like the
+// requests of send --index
+func (ws *workspace) requests() []parsedBlock {
+	httpParser := parser.NewHttpRequestParser(ws.doc.text, ws.defaultHeaders, filepath.Dir(ws.doc.path))
+	httpParser.SetSourceFile(ws.doc.path)
+
+	var parsed []parsedBlock
+	for _, block := range parser.Parse(ws.doc.text).Blocks {
+		if block.IsEmpty() {
+			continue
+		}
+		request, warnings, err := httpParser.ParseBlock(block)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, parsedBlock{
+			requestLine: block.First(parser.NodeRequestLine).Span.Start.Line,
+			request:     request,
+			warnings:    warnings,
+		})
+	}
+	return parsed
+}
+
+// names returns the names of all variables visible to a line, sorted
+func (ws *workspace) names(line int) []string {
+	var names []string
+	add := func(name string) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for name := range ws.sessionVars {
+		add(name)
+	}
+	for _, def := range ws.prompts(line) {
+		add(def.name)
+	}
+	for _, file := range ws.files {
+		for _, def := range file.variables {
+			add(def.name)
+		}
+	}
+	for name := range ws.environmentVariables() {
+		add(name)
+	}
+	slices.Sort(names)
+	return names
+}
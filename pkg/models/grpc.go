@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// MethodGRPC is the request line method of a gRPC call
+const MethodGRPC = "GRPC"
+
+// GRPCTarget is the server and method a gRPC request calls, parsed from a
+// URL such as "localhost:50051/package.Service/Method"
+type GRPCTarget struct {
+	Address string // host:port
+	Service string // Fully qualified service name, e.g. "package.Service"
+	Method  string // Method name, e.g. "Method"
+	TLS     bool   // Set by a grpcs:// or https:// URL
+}
+
+// FullMethod returns the method path used on the wire, "/package.Service/Method"
+func (t GRPCTarget) FullMethod() string {
+	return "/" + t.Service + "/" + t.Method
+}
+
+// IsGRPC returns true if the request is a gRPC call
+func (r *HttpRequest) IsGRPC() bool {
+	return strings.EqualFold(r.Method, MethodGRPC)
+}
+
+// GRPCTarget parses the request URL of a gRPC call. Without a scheme, or
+// with grpc:// or http://, the connection is plaintext; grpcs:// and
+// https:// use TLS. A port is required.
+func (r *HttpRequest) GRPCTarget() (GRPCTarget, error) {
+	var target GRPCTarget
+	rest := r.URL
+	if scheme, after, ok := strings.Cut(rest, "://"); ok {
+		switch strings.ToLower(scheme) {
+		case "grpc", "http":
+		case "grpcs", "https":
+			target.TLS = true
+		default:
+			return target, errors.NewValidationErrorWithValue("gRPC URL", r.URL, "unsupported scheme "+scheme+" (use grpc or grpcs)")
+		}
+		rest = after
+	}
+
+	address, path, ok := strings.Cut(rest, "/")
+	if !ok || address == "" {
+		return target, errors.NewValidationErrorWithValue("gRPC URL", r.URL, "expected host:port/package.Service/Method")
+	}
+	if !strings.Contains(address, ":") {
+		return target, errors.NewValidationErrorWithValue("gRPC URL", r.URL, "the address needs a port")
+	}
+
+	service, method, ok := strings.Cut(strings.Trim(path, "/"), "/")
+	if !ok || service == "" || method == "" || strings.Contains(method, "/") {
+		return target, errors.NewValidationErrorWithValue("gRPC URL", r.URL, "expected package.Service/Method after the address")
+	}
+
+	target.Address = address
+	target.Service = service
+	target.Method = method
+	return target, nil
+}
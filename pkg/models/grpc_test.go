@@ -0,0 +1,48 @@
+package models
+
+import "testing"
+
+func TestHttpRequest_GRPCTarget(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    GRPCTarget
+		wantErr bool
+	}{
+		{
+			url:  "localhost:50051/orders.v1.Orders/GetOrder",
+			want: GRPCTarget{Address: "localhost:50051", Service: "orders.v1.Orders", Method: "GetOrder"},
+		},
+		{
+			url:  "grpc://localhost:50051/orders.v1.Orders/GetOrder",
+			want: GRPCTarget{Address: "localhost:50051", Service: "orders.v1.Orders", Method: "GetOrder"},
+		},
+		{
+			url:  "grpcs://api.example.com:443/orders.v1.Orders/GetOrder",
+			want: GRPCTarget{Address: "api.example.com:443", Service: "orders.v1.Orders", Method: "GetOrder", TLS: true},
+		},
+		{url: "localhost/orders.v1.Orders/GetOrder", wantErr: true},
+		{url: "localhost:50051/orders.v1.Orders", wantErr: true},
+		{url: "localhost:50051", wantErr: true},
+		{url: "ftp://localhost:50051/orders.v1.Orders/GetOrder", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req := &HttpRequest{Method: MethodGRPC, URL: tt.url}
+			got, err := req.GRPCTarget()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GRPCTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want && !tt.wantErr {
+				t.Errorf("GRPCTarget() = %+v, want %+v", got, tt.want)
+			}
+			if result := req.Validate(); result.IsValid() == tt.wantErr {
+				t.Errorf("Validate() valid = %v, want %v", result.IsValid(), !tt.wantErr)
+			}
+		})
+	}
+
+	if path := (GRPCTarget{Service: "orders.v1.Orders", Method: "GetOrder"}).FullMethod(); path != "/orders.v1.Orders/GetOrder" {
+		t.Errorf("FullMethod() = %q", path)
+	}
+}
@@ -5,10 +5,13 @@ package models
 import (
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ideaspaper/restclient/internal/httputil"
 )
@@ -62,6 +65,7 @@ type HttpRequest struct {
 	Metadata       RequestMetadata
 	MultipartParts []MultipartPart // For multipart/form-data
 	Warnings       []string        // Parsing warnings (e.g., unknown method, malformed headers)
+	SourceFile     string          // Path of the .http file the request was parsed from, if known
 }
 
 // MultipartPart represents a part in a multipart/form-data request
@@ -83,6 +87,28 @@ type RequestMetadata struct {
 	Prompts     []PromptVariable
 	PreScript   string // JavaScript to run before the request
 	PostScript  string // JavaScript to run after the response
+	Retry       RetryPolicy
+
+	// GraphQLSubscription is set by the parser for a GraphQL subscription
+	// operation, which runs over a WebSocket instead of a POST
+	GraphQLSubscription bool
+
+	// Proto is the .proto file describing a gRPC service; without it the
+	// schema comes from server reflection
+	Proto string
+
+	// OpenAPI is the OpenAPI spec responses are validated against. It is
+	// set for every request of a file by # @openapi in any of them.
+	OpenAPI string
+
+	// Timeout and ConnectTimeout override the session timeout for this
+	// request (0 = use the session setting)
+	Timeout        time.Duration
+	ConnectTimeout time.Duration
+
+	// CacheTTL is how long the result of a named request is reused by the
+	// requests that depend on it (0 = no expiry)
+	CacheTTL time.Duration
 }
 
 // PromptVariable represents a variable that requires user input
@@ -105,6 +131,25 @@ func NewHttpRequest(method, url string, headers map[string]string, body io.Reade
 	}
 }
 
+// Clone returns a deep copy of the request that can be prepared and sent
+// independently. The body is recreated from RawBody.
+func (r *HttpRequest) Clone() *HttpRequest {
+	clone := *r
+	clone.Headers = maps.Clone(r.Headers)
+	if clone.Headers == nil {
+		clone.Headers = make(map[string]string)
+	}
+	clone.MultipartParts = slices.Clone(r.MultipartParts)
+	clone.Warnings = slices.Clone(r.Warnings)
+	clone.Metadata.Prompts = slices.Clone(r.Metadata.Prompts)
+	clone.Metadata.Retry.StatusCodes = slices.Clone(r.Metadata.Retry.StatusCodes)
+	clone.Body = nil
+	if r.RawBody != "" {
+		clone.Body = strings.NewReader(r.RawBody)
+	}
+	return &clone
+}
+
 // ContentType returns the content type of the request
 func (r *HttpRequest) ContentType() string {
 	if ct, ok := httputil.GetHeader(r.Headers, "content-type"); ok {
@@ -133,7 +178,7 @@ var validMethods = map[string]bool{
 	"PATCH": true, "HEAD": true, "OPTIONS": true, "CONNECT": true,
 	"TRACE": true, "LOCK": true, "UNLOCK": true, "PROPFIND": true,
 	"PROPPATCH": true, "COPY": true, "MOVE": true, "MKCOL": true,
-	"MKCALENDAR": true, "ACL": true, "SEARCH": true,
+	"MKCALENDAR": true, "ACL": true, "SEARCH": true, MethodWebSocket: true, MethodGRPC: true,
 }
 
 // headerNameRegex validates header names (RFC 7230)
@@ -172,6 +217,13 @@ func (r *HttpRequest) validateURL(result *ValidationResult) {
 		return
 	}
 
+	if r.IsGRPC() {
+		if _, err := r.GRPCTarget(); err != nil {
+			result.AddError("URL", err.Error())
+		}
+		return
+	}
+
 	// Parse and validate URL
 	parsedURL, err := url.Parse(r.URL)
 	if err != nil {
@@ -187,8 +239,13 @@ func (r *HttpRequest) validateURL(result *ValidationResult) {
 
 	// Validate scheme
 	scheme := strings.ToLower(parsedURL.Scheme)
-	if scheme != "http" && scheme != "https" {
-		result.AddError("URL", fmt.Sprintf("unsupported URL scheme: %s (use http or https)", parsedURL.Scheme))
+	if isWebSocketScheme(scheme) {
+		if method := strings.ToUpper(r.Method); method != "GET" && method != MethodWebSocket {
+			result.AddError("URL", fmt.Sprintf("%s URLs can only be opened with GET or WEBSOCKET, not %s", scheme, r.Method))
+			return
+		}
+	} else if scheme != "http" && scheme != "https" {
+		result.AddError("URL", fmt.Sprintf("unsupported URL scheme: %s (use http, https, ws or wss)", parsedURL.Scheme))
 		return
 	}
 
@@ -245,11 +302,36 @@ func (r *HttpRequest) validateHeaders(result *ValidationResult) {
 
 // HistoricalHttpRequest represents a saved request in history
 type HistoricalHttpRequest struct {
-	Method    string            `json:"method"`
-	URL       string            `json:"url"`
-	Headers   map[string]string `json:"headers"`
-	Body      string            `json:"body,omitempty"`
-	StartTime int64             `json:"startTime"`
+	Method    string              `json:"method"`
+	URL       string              `json:"url"`
+	Headers   map[string]string   `json:"headers"`
+	Body      string              `json:"body,omitempty"`
+	StartTime int64               `json:"startTime"`
+	Attempts  []RequestAttempt    `json:"attempts,omitempty"` // Set when the request was retried
+	Response  *HistoricalResponse `json:"response,omitempty"` // Set when a response was received
+}
+
+// HistoricalResponse summarizes the response to a saved request. The body
+// is not kept, only its size.
+type HistoricalResponse struct {
+	StatusCode    int                 `json:"statusCode"`
+	StatusMessage string              `json:"statusMessage,omitempty"`
+	HttpVersion   string              `json:"httpVersion,omitempty"`
+	Headers       map[string][]string `json:"headers,omitempty"`
+	BodySize      int                 `json:"bodySize"`
+	Timing        ResponseTiming      `json:"timing"`
+}
+
+// NewHistoricalResponse creates a response summary from an HttpResponse
+func NewHistoricalResponse(resp *HttpResponse) *HistoricalResponse {
+	return &HistoricalResponse{
+		StatusCode:    resp.StatusCode,
+		StatusMessage: resp.StatusMessage,
+		HttpVersion:   resp.HttpVersion,
+		Headers:       resp.Headers,
+		BodySize:      resp.BodySizeInBytes,
+		Timing:        resp.Timing,
+	}
 }
 
 // NewHistoricalHttpRequest creates a historical request from an HttpRequest
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"io"
 	"testing"
 )
 
@@ -70,6 +71,25 @@ func TestHttpRequestValidate(t *testing.T) {
 			wantValid:  false,
 			wantFields: []string{"URL"},
 		},
+		{
+			name: "WebSocket URL",
+			request: &HttpRequest{
+				Method:  "WEBSOCKET",
+				URL:     "wss://api.example.com/live",
+				Headers: map[string]string{},
+			},
+			wantValid: true,
+		},
+		{
+			name: "WebSocket URL with POST",
+			request: &HttpRequest{
+				Method:  "POST",
+				URL:     "ws://api.example.com/live",
+				Headers: map[string]string{},
+			},
+			wantValid:  false,
+			wantFields: []string{"URL"},
+		},
 		{
 			name: "URL with invalid scheme",
 			request: &HttpRequest{
@@ -248,11 +268,43 @@ func TestValidationErrorString(t *testing.T) {
 	}
 }
 
+func TestHttpRequestClone(t *testing.T) {
+	original := &HttpRequest{
+		Method:         "POST",
+		URL:            "https://api.example.com/{{id}}",
+		Headers:        map[string]string{"X-Id": "{{$uuid}}"},
+		RawBody:        `{"id": "{{$uuid}}"}`,
+		MultipartParts: []MultipartPart{{Name: "field", Value: "a"}},
+		Metadata:       RequestMetadata{Name: "create", Prompts: []PromptVariable{{Name: "id"}}},
+	}
+
+	clone := original.Clone()
+	clone.URL = "https://api.example.com/1"
+	clone.Headers["X-Id"] = "resolved"
+	clone.MultipartParts[0].Value = "b"
+	clone.Metadata.Prompts[0].Name = "changed"
+
+	if original.URL != "https://api.example.com/{{id}}" || original.Headers["X-Id"] != "{{$uuid}}" {
+		t.Errorf("modifying the clone changed the original: %+v", original)
+	}
+	if original.MultipartParts[0].Value != "a" || original.Metadata.Prompts[0].Name != "id" {
+		t.Errorf("clone shares slices with the original: %+v", original)
+	}
+	if clone.Metadata.Name != "create" {
+		t.Errorf("clone Metadata.Name = %q, want %q", clone.Metadata.Name, "create")
+	}
+
+	body, _ := io.ReadAll(clone.Body)
+	if string(body) != original.RawBody {
+		t.Errorf("clone body = %q, want %q", body, original.RawBody)
+	}
+}
+
 func TestValidateAllMethods(t *testing.T) {
 	validMethods := []string{
 		"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS",
 		"CONNECT", "TRACE", "LOCK", "UNLOCK", "PROPFIND", "PROPPATCH",
-		"COPY", "MOVE", "MKCOL", "MKCALENDAR", "ACL", "SEARCH",
+		"COPY", "MOVE", "MKCOL", "MKCALENDAR", "ACL", "SEARCH", "WEBSOCKET",
 	}
 
 	for _, method := range validMethods {
@@ -19,18 +19,45 @@ type HttpResponse struct {
 	BodySizeInBytes  int
 	HeadersSizeBytes int
 	BodyBuffer       []byte
+	Streamed         bool               // Body was written to a stream as it arrived
+	BodyTruncated    bool               // Body holds only the start of a streamed body
+	Events           []ServerSentEvent  // Events of a text/event-stream response
+	Messages         []WebSocketMessage // Messages received over a WebSocket
 	Timing           ResponseTiming
 	Request          *HttpRequest
 }
 
-// ResponseTiming contains timing information for the response
+// ResponseTiming contains timing information for the response.
+// Phases that did not happen (e.g. DNS and TLS on a reused connection) are zero.
 type ResponseTiming struct {
-	DNSLookup        time.Duration
-	TCPConnection    time.Duration
-	TLSHandshake     time.Duration
-	ServerProcessing time.Duration
-	ContentTransfer  time.Duration
-	Total            time.Duration
+	DNSLookup        time.Duration `json:"dnsLookup,omitempty"`
+	TCPConnection    time.Duration `json:"tcpConnection,omitempty"`
+	TLSHandshake     time.Duration `json:"tlsHandshake,omitempty"`
+	ServerProcessing time.Duration `json:"serverProcessing,omitempty"` // Request written to first response byte
+	ContentTransfer  time.Duration `json:"contentTransfer,omitempty"`  // First response byte to body fully read
+	Total            time.Duration `json:"total"`
+	ConnectionReused bool          `json:"connectionReused,omitempty"`
+}
+
+// TimingPhase names a single phase of a ResponseTiming
+type TimingPhase struct {
+	Name  string
+	Value func(ResponseTiming) time.Duration
+}
+
+// TimingPhases lists the request phases in the order they occur
+var TimingPhases = []TimingPhase{
+	{"DNS Lookup", func(t ResponseTiming) time.Duration { return t.DNSLookup }},
+	{"TCP Connection", func(t ResponseTiming) time.Duration { return t.TCPConnection }},
+	{"TLS Handshake", func(t ResponseTiming) time.Duration { return t.TLSHandshake }},
+	{"Server Processing", func(t ResponseTiming) time.Duration { return t.ServerProcessing }},
+	{"Content Transfer", func(t ResponseTiming) time.Duration { return t.ContentTransfer }},
+}
+
+// HasPhases returns true if per-phase timings were recorded
+func (t ResponseTiming) HasPhases() bool {
+	return t.DNSLookup > 0 || t.TCPConnection > 0 || t.TLSHandshake > 0 ||
+		t.ServerProcessing > 0 || t.ContentTransfer > 0
 }
 
 // NewHttpResponse creates a new HttpResponse from an http.Response
@@ -92,6 +119,19 @@ func (r *HttpResponse) IsXML() bool {
 	return strings.Contains(ct, "application/xml") || strings.Contains(ct, "text/xml") || strings.Contains(ct, "+xml")
 }
 
+// IsEventStream returns true if the response is a Server-Sent Events stream
+func (r *HttpResponse) IsEventStream() bool {
+	return strings.Contains(r.ContentType(), "text/event-stream")
+}
+
+// ServerSentEvent is a single event received from a text/event-stream response
+type ServerSentEvent struct {
+	ID    string `json:"id,omitempty"` // Last event ID at the time of the event
+	Event string `json:"event"`        // Event type, "message" if not given
+	Data  string `json:"data"`
+	Retry int    `json:"retry,omitempty"` // Reconnection time in milliseconds, if sent with the event
+}
+
 // IsHTML returns true if the response is HTML
 func (r *HttpResponse) IsHTML() bool {
 	ct := r.ContentType()
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Backoff strategies for RetryPolicy
+const (
+	BackoffConstant    = "constant"
+	BackoffLinear      = "linear"
+	BackoffExponential = "exponential"
+)
+
+// Retry defaults used when @retry-on or @retry-backoff are not given
+var (
+	DefaultRetryStatusCodes = []int{429, 502, 503, 504}
+	DefaultRetryBackoff     = BackoffExponential
+	DefaultRetryDelay       = 200 * time.Millisecond
+)
+
+// MaxRetryDelay caps the delay between attempts, including delays requested
+// by a Retry-After header
+const MaxRetryDelay = time.Minute
+
+// RetryPolicy controls how a request is retried after a transient failure.
+// It is set with the @retry, @retry-on and @retry-backoff metadata.
+type RetryPolicy struct {
+	Retries     int           // Retries after the first attempt (0 = never retry)
+	StatusCodes []int         // Status codes that trigger a retry
+	OnTimeout   bool          // Retry when the request times out
+	OnError     bool          // Retry on connection errors (refused, reset, DNS)
+	Backoff     string        // constant, linear or exponential
+	Delay       time.Duration // Base delay between attempts
+}
+
+// Enabled reports whether the request should be retried at all
+func (p RetryPolicy) Enabled() bool {
+	return p.Retries > 0
+}
+
+// hasConditions reports whether @retry-on was given
+func (p RetryPolicy) hasConditions() bool {
+	return len(p.StatusCodes) > 0 || p.OnTimeout || p.OnError
+}
+
+// RetriesStatus reports whether a response with the given status code should
+// be retried
+func (p RetryPolicy) RetriesStatus(code int) bool {
+	if !p.hasConditions() {
+		return slices.Contains(DefaultRetryStatusCodes, code)
+	}
+	return slices.Contains(p.StatusCodes, code)
+}
+
+// RetriesTimeout reports whether a timed out request should be retried
+func (p RetryPolicy) RetriesTimeout() bool {
+	return p.OnTimeout || !p.hasConditions()
+}
+
+// RetriesError reports whether a request that failed to connect should be
+// retried
+func (p RetryPolicy) RetriesError() bool {
+	return p.OnError || !p.hasConditions()
+}
+
+// BackoffDelay returns the delay before the given retry (1 for the first)
+func (p RetryPolicy) BackoffDelay(retry int) time.Duration {
+	delay := p.Delay
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+
+	backoff := p.Backoff
+	if backoff == "" {
+		backoff = DefaultRetryBackoff
+	}
+
+	switch backoff {
+	case BackoffLinear:
+		delay *= time.Duration(retry)
+	case BackoffExponential:
+		for i := 1; i < retry && delay < MaxRetryDelay; i++ {
+			delay *= 2
+		}
+	}
+	return min(delay, MaxRetryDelay)
+}
+
+// RequestAttempt records a single attempt of a retried request
+type RequestAttempt struct {
+	StatusCode int    `json:"statusCode,omitempty"` // 0 if no response was received
+	Error      string `json:"error,omitempty"`
+	StartTime  int64  `json:"startTime"`
+	DurationMs int64  `json:"durationMs"`
+}
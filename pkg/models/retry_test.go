@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_BackoffDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		want   time.Duration
+	}{
+		{"default is exponential 200ms", RetryPolicy{}, 1, 200 * time.Millisecond},
+		{"default third retry", RetryPolicy{}, 3, 800 * time.Millisecond},
+		{"constant", RetryPolicy{Backoff: BackoffConstant, Delay: time.Second}, 4, time.Second},
+		{"linear", RetryPolicy{Backoff: BackoffLinear, Delay: 500 * time.Millisecond}, 3, 1500 * time.Millisecond},
+		{"exponential", RetryPolicy{Backoff: BackoffExponential, Delay: 100 * time.Millisecond}, 4, 800 * time.Millisecond},
+		{"capped", RetryPolicy{Backoff: BackoffExponential, Delay: time.Second}, 20, MaxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.BackoffDelay(tt.retry); got != tt.want {
+				t.Errorf("BackoffDelay(%d) = %v, want %v", tt.retry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Conditions(t *testing.T) {
+	defaults := RetryPolicy{Retries: 1}
+	if !defaults.RetriesStatus(503) || defaults.RetriesStatus(500) {
+		t.Error("default policy should retry 503 but not 500")
+	}
+	if !defaults.RetriesTimeout() || !defaults.RetriesError() {
+		t.Error("default policy should retry timeouts and connection errors")
+	}
+
+	custom := RetryPolicy{Retries: 1, StatusCodes: []int{500}}
+	if !custom.RetriesStatus(500) || custom.RetriesStatus(503) {
+		t.Error("@retry-on should replace the default status codes")
+	}
+	if custom.RetriesTimeout() || custom.RetriesError() {
+		t.Error("@retry-on without timeout or error should not retry them")
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MethodWebSocket is the request line method that opens a WebSocket
+const MethodWebSocket = "WEBSOCKET"
+
+// WebSocketDelimiter separates the messages in a WebSocket request body. A
+// delimiter line of "=== wait-for-server" also waits for a server message
+// before the next message is sent.
+const WebSocketDelimiter = "==="
+
+const waitForServer = "wait-for-server"
+
+// WebSocketStep is one step of a WebSocket conversation: either a message
+// to send or a wait for the next server message
+type WebSocketStep struct {
+	Message       string
+	WaitForServer bool
+}
+
+// WebSocketMessage is a single message received over a WebSocket
+type WebSocketMessage struct {
+	Data   string `json:"data"`
+	Binary bool   `json:"binary,omitempty"`
+}
+
+// IsWebSocket returns true if the request opens a WebSocket, either with the
+// WEBSOCKET method or a ws:// or wss:// URL
+func (r *HttpRequest) IsWebSocket() bool {
+	if strings.EqualFold(r.Method, MethodWebSocket) {
+		return true
+	}
+	parsedURL, err := url.Parse(r.URL)
+	return err == nil && isWebSocketScheme(parsedURL.Scheme)
+}
+
+// WebSocketSteps splits the request body into messages and waits. Blank
+// lines around each message are dropped; messages left empty are skipped.
+func (r *HttpRequest) WebSocketSteps() []WebSocketStep {
+	var steps []WebSocketStep
+	var message []string
+
+	flush := func() {
+		text := strings.Trim(strings.Join(message, "\n"), "\r\n")
+		if strings.TrimSpace(text) != "" {
+			steps = append(steps, WebSocketStep{Message: text})
+		}
+		message = nil
+	}
+
+	for line := range strings.SplitSeq(r.RawBody, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, WebSocketDelimiter) {
+			message = append(message, line)
+			continue
+		}
+		option := strings.TrimSpace(strings.TrimPrefix(trimmed, WebSocketDelimiter))
+		if option != "" && !strings.EqualFold(option, waitForServer) {
+			message = append(message, line)
+			continue
+		}
+		flush()
+		if option != "" {
+			steps = append(steps, WebSocketStep{WaitForServer: true})
+		}
+	}
+	flush()
+
+	return steps
+}
+
+// IsWebSocket returns true if the response is an opened WebSocket, for a
+// WebSocket request or a GraphQL subscription
+func (r *HttpResponse) IsWebSocket() bool {
+	return r.StatusCode == http.StatusSwitchingProtocols
+}
+
+// isWebSocketScheme reports whether scheme is ws or wss
+func isWebSocketScheme(scheme string) bool {
+	return strings.EqualFold(scheme, "ws") || strings.EqualFold(scheme, "wss")
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHttpRequest_IsWebSocket(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		want   bool
+	}{
+		{"WEBSOCKET", "https://api.example.com/live", true},
+		{"GET", "ws://api.example.com/live", true},
+		{"GET", "WSS://api.example.com/live", true},
+		{"GET", "https://api.example.com/ws", false},
+	}
+
+	for _, tt := range tests {
+		req := &HttpRequest{Method: tt.method, URL: tt.url}
+		if got := req.IsWebSocket(); got != tt.want {
+			t.Errorf("IsWebSocket(%s %s) = %v, want %v", tt.method, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestHttpRequest_WebSocketSteps(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []WebSocketStep
+	}{
+		{
+			name: "empty body",
+			body: "",
+			want: nil,
+		},
+		{
+			name: "single message",
+			body: `{"type": "ping"}`,
+			want: []WebSocketStep{{Message: `{"type": "ping"}`}},
+		},
+		{
+			name: "messages and waits",
+			body: "{\n  \"a\": 1\n}\n\n===\nsecond\n=== wait-for-server\nthird\n=== wait-for-server",
+			want: []WebSocketStep{
+				{Message: "{\n  \"a\": 1\n}"},
+				{Message: "second"},
+				{WaitForServer: true},
+				{Message: "third"},
+				{WaitForServer: true},
+			},
+		},
+		{
+			name: "empty messages are skipped",
+			body: "===\n\n===\nonly",
+			want: []WebSocketStep{{Message: "only"}},
+		},
+		{
+			name: "other lines starting with the delimiter are content",
+			body: "==== heading\ntext",
+			want: []WebSocketStep{{Message: "==== heading\ntext"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &HttpRequest{RawBody: tt.body}
+			got := req.WebSocketSteps()
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("WebSocketSteps() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
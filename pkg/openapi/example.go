@@ -0,0 +1,246 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Example returns an example value of a schema for a request: its example,
+// default or first enum value, or one built from its type. Read-only
+// properties are left out.
+func Example(schema *Schema) *yaml.Node {
+	return example(schema, make(map[*Schema]bool))
+}
+
+func example(schema *Schema, visiting map[*Schema]bool) *yaml.Node {
+	if schema == nil {
+		return nullNode()
+	}
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+	if visiting[schema] {
+		// A recursive schema ends where it repeats
+		return nullNode()
+	}
+	visiting[schema] = true
+	defer delete(visiting, schema)
+
+	if len(schema.AllOf) > 0 {
+		return example(merge(schema), visiting)
+	}
+	if len(schema.OneOf) > 0 {
+		return example(schema.OneOf[0], visiting)
+	}
+	if len(schema.AnyOf) > 0 {
+		return example(schema.AnyOf[0], visiting)
+	}
+
+	switch exampleType(schema) {
+	case "object":
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, property := range schema.Properties {
+			if property.Schema.ReadOnly {
+				continue
+			}
+			value := example(property.Schema, visiting)
+			node.Content = append(node.Content, stringNode(property.Name), value)
+		}
+		return node
+	case "array":
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if schema.Items != nil {
+			node.Content = append(node.Content, example(schema.Items, visiting))
+		}
+		return node
+	case "integer":
+		if schema.Minimum != nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(int(*schema.Minimum))}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "0"}
+	case "number":
+		if schema.Minimum != nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: strconv.FormatFloat(*schema.Minimum, 'f', -1, 64)}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "0"}
+	case "boolean":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"}
+	case "null":
+		return nullNode()
+	}
+
+	switch schema.Format {
+	case "date-time":
+		return stringNode("2024-01-01T00:00:00Z")
+	case "date":
+		return stringNode("2024-01-01")
+	case "time":
+		return stringNode("00:00:00Z")
+	case "email":
+		return stringNode("user@example.com")
+	case "uuid":
+		return stringNode("00000000-0000-0000-0000-000000000000")
+	case "uri", "url":
+		return stringNode("https://example.com")
+	case "hostname":
+		return stringNode("example.com")
+	case "ipv4":
+		return stringNode("127.0.0.1")
+	case "ipv6":
+		return stringNode("::1")
+	case "binary", "byte":
+		return stringNode("")
+	}
+	return stringNode("string")
+}
+
+// exampleType returns the type to build an example of
+func exampleType(schema *Schema) string {
+	for _, t := range schema.Types {
+		if t != "null" {
+			return t
+		}
+	}
+	switch {
+	case len(schema.Properties) > 0 || schema.AdditionalProperties != nil:
+		return "object"
+	case schema.Items != nil:
+		return "array"
+	case len(schema.Types) > 0:
+		return "null"
+	}
+	return "string"
+}
+
+// merge combines a schema and those of its allOf into one
+func merge(schema *Schema) *Schema {
+	merged := *schema
+	merged.AllOf = nil
+	for _, part := range schema.AllOf {
+		if len(part.AllOf) > 0 {
+			part = merge(part)
+		}
+		if len(merged.Types) == 0 {
+			merged.Types = part.Types
+		}
+		if merged.Format == "" {
+			merged.Format = part.Format
+		}
+		if merged.Items == nil {
+			merged.Items = part.Items
+		}
+		merged.Properties = append(merged.Properties, part.Properties...)
+		merged.Required = append(merged.Required, part.Required...)
+	}
+	return &merged
+}
+
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func nullNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
+
+// JSON writes a value as indented JSON, keeping the order of keys
+func JSON(node *yaml.Node) string {
+	var b strings.Builder
+	writeJSON(&b, node, "")
+	return b.String()
+}
+
+func writeJSON(b *strings.Builder, node *yaml.Node, indent string) {
+	if node == nil {
+		b.WriteString("null")
+		return
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) > 0 {
+			writeJSON(b, node.Content[0], indent)
+			return
+		}
+		b.WriteString("null")
+	case yaml.AliasNode:
+		writeJSON(b, node.Alias, indent)
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, _ := json.Marshal(node.Content[i].Value)
+			b.WriteString(indent + "  " + string(key) + ": ")
+			writeJSON(b, node.Content[i+1], indent+"  ")
+			if i+2 < len(node.Content) {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "}")
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteString("[\n")
+		for i, item := range node.Content {
+			b.WriteString(indent + "  ")
+			writeJSON(b, item, indent+"  ")
+			if i+1 < len(node.Content) {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "]")
+	default:
+		b.WriteString(scalarJSON(node))
+	}
+}
+
+// scalarJSON writes a scalar as a JSON value
+func scalarJSON(node *yaml.Node) string {
+	switch node.ShortTag() {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		if value, err := strconv.ParseBool(node.Value); err == nil {
+			return strconv.FormatBool(value)
+		}
+	case "!!int":
+		if value, err := strconv.ParseInt(node.Value, 0, 64); err == nil {
+			return strconv.FormatInt(value, 10)
+		}
+	case "!!float":
+		if value, err := strconv.ParseFloat(node.Value, 64); err == nil {
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	}
+	value, _ := json.Marshal(node.Value)
+	return string(value)
+}
+
+// Text returns a value as text, as in form fields: scalars as they are and
+// others as compact JSON
+func Text(node *yaml.Node) string {
+	if node != nil && node.Kind == yaml.ScalarNode {
+		if node.ShortTag() == "!!null" {
+			return ""
+		}
+		return node.Value
+	}
+	var compact bytes.Buffer
+	_ = json.Compact(&compact, []byte(JSON(node)))
+	return compact.String()
+}
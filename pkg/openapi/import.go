@@ -0,0 +1,337 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/postman"
+)
+
+// ImportOptions configures how specifications are imported
+type ImportOptions struct {
+	// OutputDir is the directory where the folder of .http files is created
+	OutputDir string
+}
+
+// ImportResult contains information about imported operations
+type ImportResult struct {
+	FilesCreated    []string
+	OperationsCount int
+	TagsCount       int
+}
+
+// defaultTag names the file of operations without tags
+const defaultTag = "default"
+
+// multipartBoundary separates the parts of multipart bodies
+const multipartBoundary = "----RestClientBoundary"
+
+// pathParamRegex matches the parameters of path templates
+var pathParamRegex = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Import reads a specification file and converts it to .http files
+func Import(specPath string, opts ImportOptions) (*ImportResult, error) {
+	spec, err := Load(specPath)
+	if err != nil {
+		return nil, err
+	}
+	return ImportSpec(spec, opts)
+}
+
+// ImportSpec converts a specification to .http files, one per tag, in a
+// folder named after the API
+func ImportSpec(spec *Spec, opts ImportOptions) (*ImportResult, error) {
+	dir := filepath.Join(opts.OutputDir, postman.SanitizeFileName(spec.Title))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create output directory")
+	}
+
+	result := &ImportResult{OperationsCount: len(spec.Operations)}
+	for _, tag := range groupByTag(spec) {
+		content := generateHttpFile(spec, tag.Tag, tag.operations)
+		filePath := filepath.Join(dir, postman.SanitizeFileName(tag.Name)+".http")
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			return nil, errors.Wrapf(err, "failed to write file %s", filePath)
+		}
+		result.FilesCreated = append(result.FilesCreated, filePath)
+		result.TagsCount++
+	}
+	return result, nil
+}
+
+type taggedOperations struct {
+	Tag
+	operations []*Operation
+}
+
+// groupByTag groups operations by their first tag, with declared tags first
+// and in their order
+func groupByTag(spec *Spec) []*taggedOperations {
+	var groups []*taggedOperations
+	find := func(name string) *taggedOperations {
+		for _, group := range groups {
+			if group.Name == name {
+				return group
+			}
+		}
+		group := &taggedOperations{Tag: Tag{Name: name}}
+		groups = append(groups, group)
+		return group
+	}
+	for _, tag := range spec.Tags {
+		find(tag.Name).Description = tag.Description
+	}
+	for _, operation := range spec.Operations {
+		name := defaultTag
+		if len(operation.Tags) > 0 {
+			name = operation.Tags[0]
+		}
+		group := find(name)
+		group.operations = append(group.operations, operation)
+	}
+	return slices.DeleteFunc(groups, func(group *taggedOperations) bool {
+		return len(group.operations) == 0
+	})
+}
+
+// generateHttpFile generates the .http file of a tag
+func generateHttpFile(spec *Spec, tag Tag, operations []*Operation) string {
+	var requests strings.Builder
+	nameTracker := postman.NewNameTracker()
+	var variables []string
+	for i, operation := range operations {
+		// The first request shares its block with the file variables, so
+		// its title is a plain comment
+		if i == 0 {
+			fmt.Fprintf(&requests, "\n# %s\n", operationTitle(operation))
+		} else {
+			fmt.Fprintf(&requests, "\n### %s\n", operationTitle(operation))
+		}
+		for _, variable := range writeRequest(&requests, spec, operation, nameTracker) {
+			if !slices.Contains(variables, variable) {
+				variables = append(variables, variable)
+			}
+		}
+	}
+
+	var content strings.Builder
+	if tag.Description != "" {
+		writeComment(&content, tag.Description)
+		content.WriteString("\n")
+	}
+	if len(variables) > 0 {
+		fmt.Fprintf(&content, "# Set %s in your environment\n\n", strings.Join(variables, ", "))
+	}
+	baseURL := ""
+	if len(spec.Servers) > 0 {
+		baseURL = spec.Servers[0]
+	}
+	fmt.Fprintf(&content, "@baseUrl = %s\n", baseURL)
+	content.WriteString(requests.String())
+	return content.String()
+}
+
+// operationTitle returns the title of an operation's block
+func operationTitle(operation *Operation) string {
+	title := operation.Summary
+	if title == "" {
+		title = operation.Method + " " + operation.Path
+	}
+	return strings.ReplaceAll(title, "\n", " ")
+}
+
+// writeRequest writes the request of an operation and returns the
+// variables its authentication uses
+func writeRequest(content *strings.Builder, spec *Spec, operation *Operation, nameTracker *postman.NameTracker) []string {
+	if operation.OperationID != "" {
+		fmt.Fprintf(content, "# @name %s\n", nameTracker.GetUniqueName(operation.OperationID))
+	}
+	if operation.Description != "" {
+		fmt.Fprintf(content, "# @note %s\n", strings.Join(strings.Fields(operation.Description), " "))
+	}
+
+	path := pathParamRegex.ReplaceAllStringFunc(operation.Path, func(match string) string {
+		return "{{:" + paramName(match[1:len(match)-1]) + "}}"
+	})
+
+	var query, headers, cookies []string
+	for _, param := range operation.Parameters {
+		value := "{{:" + paramName(param.Name) + "}}"
+		switch param.In {
+		case "query":
+			query = append(query, url.QueryEscape(param.Name)+"="+value)
+		case "header":
+			headers = append(headers, param.Name+": "+value)
+		case "cookie":
+			cookies = append(cookies, param.Name+"="+value)
+		}
+	}
+
+	var variables []string
+	if len(operation.Security) > 0 {
+		for _, name := range operation.Security[0] {
+			scheme := spec.SecuritySchemes[name]
+			if scheme == nil {
+				continue
+			}
+			switch scheme.Type {
+			case "http":
+				switch scheme.Scheme {
+				case "basic":
+					headers = append(headers, "Authorization: Basic {{username}}:{{password}}")
+					variables = append(variables, "username", "password")
+				case "digest":
+					headers = append(headers, "Authorization: Digest {{username}} {{password}}")
+					variables = append(variables, "username", "password")
+				default:
+					headers = append(headers, "Authorization: Bearer {{token}}")
+					variables = append(variables, "token")
+				}
+			case "oauth2", "openIdConnect":
+				headers = append(headers, "Authorization: Bearer {{token}}")
+				variables = append(variables, "token")
+			case "apiKey":
+				variable := paramName(name)
+				value := "{{" + variable + "}}"
+				switch scheme.In {
+				case "query":
+					query = append(query, url.QueryEscape(scheme.Name)+"="+value)
+				case "cookie":
+					cookies = append(cookies, scheme.Name+"="+value)
+				default:
+					headers = append(headers, scheme.Name+": "+value)
+				}
+				variables = append(variables, variable)
+			}
+		}
+	}
+
+	fmt.Fprintf(content, "%s {{baseUrl}}%s\n", operation.Method, path)
+	for i, q := range query {
+		if i == 0 {
+			fmt.Fprintf(content, "    ?%s\n", q)
+		} else {
+			fmt.Fprintf(content, "    &%s\n", q)
+		}
+	}
+	for _, header := range headers {
+		content.WriteString(header + "\n")
+	}
+	if len(cookies) > 0 {
+		fmt.Fprintf(content, "Cookie: %s\n", strings.Join(cookies, "; "))
+	}
+	if operation.RequestBody != nil {
+		writeBody(content, operation.RequestBody)
+	}
+	return variables
+}
+
+// writeBody writes the Content-Type header and an example body
+func writeBody(content *strings.Builder, body *RequestBody) {
+	example := body.Example
+	if example == nil && body.Schema != nil {
+		example = Example(body.Schema)
+	}
+
+	switch {
+	case isJSON(body.ContentType):
+		fmt.Fprintf(content, "Content-Type: %s\n\n%s\n", body.ContentType, JSON(example))
+	case body.ContentType == "application/x-www-form-urlencoded":
+		fmt.Fprintf(content, "Content-Type: %s\n\n", body.ContentType)
+		for i, field := range fields(example) {
+			if i > 0 {
+				content.WriteString("&")
+			}
+			fmt.Fprintf(content, "%s=%s\n", url.QueryEscape(field.name), url.QueryEscape(Text(field.value)))
+		}
+	case body.ContentType == "multipart/form-data":
+		fmt.Fprintf(content, "Content-Type: multipart/form-data; boundary=%s\n\n", multipartBoundary)
+		for _, field := range fields(example) {
+			fmt.Fprintf(content, "--%s\n", multipartBoundary)
+			if isFile(body.Schema, field.name) {
+				fmt.Fprintf(content, "Content-Disposition: form-data; name=%q; filename=%q\n\n< ./%s\n", field.name, field.name, field.name)
+			} else {
+				fmt.Fprintf(content, "Content-Disposition: form-data; name=%q\n\n%s\n", field.name, Text(field.value))
+			}
+		}
+		fmt.Fprintf(content, "--%s--\n", multipartBoundary)
+	default:
+		fmt.Fprintf(content, "Content-Type: %s\n", body.ContentType)
+		switch {
+		case body.Schema != nil && body.Schema.Format == "binary":
+			content.WriteString("\n< ./body\n")
+		case example != nil && example.Kind == yaml.ScalarNode && Text(example) != "":
+			fmt.Fprintf(content, "\n%s\n", Text(example))
+		}
+	}
+}
+
+type field struct {
+	name  string
+	value *yaml.Node
+}
+
+// fields returns the properties of an object example
+func fields(example *yaml.Node) []field {
+	var result []field
+	for name, value := range pairs(example) {
+		result = append(result, field{name: name, value: value})
+	}
+	return result
+}
+
+// isFile reports whether a form property is a file upload
+func isFile(schema *Schema, name string) bool {
+	if schema == nil {
+		return false
+	}
+	if len(schema.AllOf) > 0 {
+		schema = merge(schema)
+	}
+	for _, property := range schema.Properties {
+		if property.Name == name {
+			s := property.Schema
+			if s.Items != nil {
+				s = s.Items
+			}
+			return s.Format == "binary" || s.Format == "base64" || s.HasType("file")
+		}
+	}
+	return false
+}
+
+// paramName makes a parameter name usable as a {{:name}} prompt
+func paramName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+		default:
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "value"
+	}
+	return b.String()
+}
+
+// writeComment writes a multi-line comment
+func writeComment(content *strings.Builder, text string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		content.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+	}
+}
@@ -0,0 +1,285 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/restclient/pkg/parser"
+)
+
+const petstore = `openapi: 3.0.3
+info:
+  title: Pet Store
+servers:
+  - url: https://{region}.example.com/v1/
+    variables:
+      region:
+        default: eu
+tags:
+  - name: pets
+    description: Everything about pets
+security:
+  - bearerAuth: []
+paths:
+  /pets:
+    get:
+      tags: [pets]
+      summary: List pets
+      operationId: listPets
+      parameters:
+        - name: limit
+          in: query
+          schema: {type: integer}
+        - name: tag-filter
+          in: query
+          schema: {type: string}
+        - name: X-Request-Id
+          in: header
+          schema: {type: string}
+    post:
+      tags: [pets]
+      summary: Create a pet
+      operationId: createPet
+      description: |
+        Adds a pet
+        to the store.
+      requestBody:
+        content:
+          application/xml:
+            schema: {$ref: '#/components/schemas/Pet'}
+          application/json:
+            schema: {$ref: '#/components/schemas/Pet'}
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema: {type: string}
+    get:
+      tags: [pets]
+      operationId: getPet
+      security:
+        - apiKey: []
+    delete:
+      tags: [pets]
+      operationId: getPet
+      security: []
+  /pets/{petId}/photo:
+    put:
+      summary: Upload a photo
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              properties:
+                caption: {type: string, example: Rex}
+                file: {type: string, format: binary}
+components:
+  securitySchemes:
+    bearerAuth: {type: http, scheme: bearer}
+    apiKey: {type: apiKey, in: header, name: X-API-Key}
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        id: {type: integer, format: int64, readOnly: true}
+        name: {type: string, example: Rex}
+        born: {type: string, format: date}
+        tags:
+          type: array
+          items: {type: string}
+        owner: {$ref: '#/components/schemas/Owner'}
+        parent: {$ref: '#/components/schemas/Pet'}
+    Owner:
+      allOf:
+        - type: object
+          properties:
+            email: {type: string, format: email}
+        - type: object
+          properties:
+            vip: {type: boolean}
+`
+
+const swagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Legacy API"},
+  "host": "legacy.example.com",
+  "basePath": "/api",
+  "schemes": ["http"],
+  "securityDefinitions": {"basic": {"type": "basic"}},
+  "paths": {
+    "/login": {
+      "post": {
+        "operationId": "login",
+        "security": [{"basic": []}],
+        "consumes": ["application/x-www-form-urlencoded"],
+        "parameters": [
+          {"name": "user", "in": "formData", "type": "string", "default": "john doe"},
+          {"name": "remember", "in": "formData", "type": "boolean"}
+        ]
+      }
+    },
+    "/items": {
+      "post": {
+        "operationId": "createItem",
+        "parameters": [
+          {"name": "item", "in": "body", "schema": {"type": "object", "properties": {"count": {"type": "integer", "minimum": 1}}}}
+        ]
+      }
+    }
+  }
+}`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Title != "Pet Store" || len(spec.Servers) != 1 || spec.Servers[0] != "https://eu.example.com/v1" {
+		t.Errorf("Parse() title = %q, servers = %v", spec.Title, spec.Servers)
+	}
+	if len(spec.Operations) != 5 {
+		t.Fatalf("Parse() operations = %d, want 5", len(spec.Operations))
+	}
+	getPet := spec.Operations[2]
+	if getPet.Method != "GET" || getPet.Path != "/pets/{petId}" || len(getPet.Parameters) != 1 {
+		t.Errorf("Parse() operation = %+v", getPet)
+	}
+	if len(getPet.Security) != 1 || getPet.Security[0][0] != "apiKey" {
+		t.Errorf("operation security should override global security, got %v", getPet.Security)
+	}
+	if spec.Operations[3].Security != nil {
+		t.Errorf("empty operation security should remove authentication, got %v", spec.Operations[3].Security)
+	}
+	if body := spec.Operations[1].RequestBody; body == nil || body.ContentType != "application/json" {
+		t.Errorf("JSON should be the preferred media type, got %+v", body)
+	}
+
+	if _, err := Parse([]byte("openapi: 4.0.0\n")); err == nil {
+		t.Error("unsupported versions should fail")
+	}
+	if _, err := Parse([]byte("- a\n")); err == nil {
+		t.Error("documents that are not mappings should fail")
+	}
+}
+
+func TestExample(t *testing.T) {
+	spec, err := Parse([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := JSON(Example(spec.Operations[1].RequestBody.Schema))
+	want := `{
+  "name": "Rex",
+  "born": "2024-01-01",
+  "tags": [
+    "string"
+  ],
+  "owner": {
+    "email": "user@example.com",
+    "vip": true
+  },
+  "parent": null
+}`
+	if got != want {
+		t.Errorf("Example() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestImportSpec(t *testing.T) {
+	spec, err := Parse([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	result, err := ImportSpec(spec, ImportOptions{OutputDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.OperationsCount != 5 || result.TagsCount != 2 || len(result.FilesCreated) != 2 {
+		t.Fatalf("ImportSpec() = %+v", result)
+	}
+
+	pets := readFile(t, filepath.Join(dir, "Pet Store", "pets.http"))
+	for _, want := range []string{
+		"# Everything about pets\n\n# Set token, apiKey in your environment\n\n@baseUrl = https://eu.example.com/v1\n",
+		"\n# List pets\n# @name listPets\nGET {{baseUrl}}/pets\n    ?limit={{:limit}}\n    &tag-filter={{:tag_filter}}\nX-Request-Id: {{:X_Request_Id}}\nAuthorization: Bearer {{token}}\n",
+		"# @name createPet\n# @note Adds a pet to the store.\nPOST {{baseUrl}}/pets\nAuthorization: Bearer {{token}}\nContent-Type: application/json\n\n{\n  \"name\": \"Rex\",",
+		"# @name getPet\nGET {{baseUrl}}/pets/{{:petId}}\nX-API-Key: {{apiKey}}\n",
+		"### DELETE /pets/{petId}\n# @name getPet_2\nDELETE {{baseUrl}}/pets/{{:petId}}\n",
+	} {
+		if !strings.Contains(pets, want) {
+			t.Errorf("pets.http should contain %q\ngot:\n%s", want, pets)
+		}
+	}
+
+	parsed := parser.NewHttpRequestParser(pets, map[string]string{}, "").ParseAllWithWarnings()
+	if len(parsed.Requests) != 4 || len(parsed.Warnings) != 0 {
+		t.Fatalf("ParseAllWithWarnings() = %d requests, warnings %v", len(parsed.Requests), parsed.Warnings)
+	}
+	requests := parsed.Requests
+	if requests[1].Headers["Content-Type"] != "application/json" || !strings.Contains(requests[1].RawBody, `"born": "2024-01-01"`) {
+		t.Errorf("createPet request = %+v", requests[1])
+	}
+
+	photo := readFile(t, filepath.Join(dir, "Pet Store", "default.http"))
+	requests, err = parser.NewHttpRequestParser(photo, map[string]string{}, "").ParseAll()
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("ParseAll() = %d requests, %v", len(requests), err)
+	}
+	parts := requests[0].MultipartParts
+	if len(parts) != 2 || parts[0].Name != "caption" || parts[0].Value != "Rex" || !parts[1].IsFile || parts[1].FilePath != "./file" {
+		t.Errorf("multipart parts = %+v", parts)
+	}
+}
+
+func TestImportSwagger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swagger.json")
+	if err := os.WriteFile(path, []byte(swagger), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if _, err := Import(path, ImportOptions{OutputDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readFile(t, filepath.Join(dir, "Legacy API", "default.http"))
+	want := `# Set username, password in your environment
+
+@baseUrl = http://legacy.example.com/api
+
+# POST /login
+# @name login
+POST {{baseUrl}}/login
+Authorization: Basic {{username}}:{{password}}
+Content-Type: application/x-www-form-urlencoded
+
+user=john+doe
+&remember=true
+
+### POST /items
+# @name createItem
+POST {{baseUrl}}/items
+Content-Type: application/json
+
+{
+  "count": 1
+}
+`
+	if got != want {
+		t.Errorf("Import() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,663 @@
+// Package openapi reads OpenAPI 3 and Swagger 2 specifications and
+// converts their operations to .http requests.
+package openapi
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// Spec is an OpenAPI 3 or Swagger 2 specification, with references
+// resolved and the differences between the versions smoothed over
+type Spec struct {
+	Title       string
+	Description string
+	// Servers are the base URLs, with server variables set to their
+	// defaults
+	Servers    []string
+	Tags       []Tag
+	Operations []*Operation
+	// SecuritySchemes are keyed by name
+	SecuritySchemes map[string]*SecurityScheme
+}
+
+// Tag groups operations
+type Tag struct {
+	Name        string
+	Description string
+}
+
+// Operation is a method on a path
+type Operation struct {
+	Method string
+	// Path is the path template, such as /users/{id}
+	Path        string
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	Parameters  []*Parameter
+	RequestBody *RequestBody
+	// Security lists alternative requirements, each naming the schemes
+	// that apply together. Nil uses none.
+	Security [][]string
+	// Responses are keyed by status code, range such as 2XX, or default
+	Responses map[string]*Response
+}
+
+// Response is a documented response of an operation
+type Response struct {
+	Headers []*Parameter
+	Content []MediaType
+}
+
+// MediaType is a documented content type of a response
+type MediaType struct {
+	ContentType string
+	Schema      *Schema
+}
+
+// Parameter is a path, query, header or cookie parameter
+type Parameter struct {
+	Name     string
+	In       string
+	Required bool
+	Schema   *Schema
+}
+
+// RequestBody is the body of an operation, in its preferred media type
+type RequestBody struct {
+	ContentType string
+	Schema      *Schema
+	// Example is an example of the whole body, if the spec gives one
+	Example *yaml.Node
+}
+
+// SecurityScheme describes how a request is authenticated
+type SecurityScheme struct {
+	// Type is apiKey, http, oauth2 or openIdConnect; Swagger 2 basic
+	// schemes are http schemes with the basic scheme
+	Type string
+	// Scheme is the HTTP scheme of http schemes, such as bearer or basic
+	Scheme string
+	// Name and In are the parameter of apiKey schemes
+	Name string
+	In   string
+}
+
+// Schema is a JSON schema
+type Schema struct {
+	// Types lists the allowed types; empty allows any
+	Types []string
+	// Nullable schemas also allow null, as with nullable: true or a null
+	// type
+	Nullable   bool
+	Format     string
+	Enum       []*yaml.Node
+	Example    *yaml.Node
+	Default    *yaml.Node
+	Properties []Property
+	Required   []string
+	// AdditionalProperties is the schema of properties that are not
+	// listed, or nil; NoAdditionalProperties forbids them
+	AdditionalProperties   *Schema
+	NoAdditionalProperties bool
+	Items                  *Schema
+	AllOf                  []*Schema
+	OneOf                  []*Schema
+	AnyOf                  []*Schema
+	MinLength, MaxLength   *int
+	MinItems, MaxItems     *int
+	Minimum, Maximum       *float64
+	ExclusiveMinimum       bool
+	ExclusiveMaximum       bool
+	Pattern                string
+	// ReadOnly properties are only sent in responses, WriteOnly ones only
+	// in requests
+	ReadOnly, WriteOnly bool
+}
+
+// Property is a named property of an object schema
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// HasType reports whether the schema allows a type
+func (s *Schema) HasType(t string) bool {
+	for _, typ := range s.Types {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
+
+// methods are the operations of a path item, in the order they are written
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Load reads a specification from a YAML or JSON file
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read specification")
+	}
+	return Parse(data)
+}
+
+// Parse reads a specification from YAML or JSON
+func Parse(data []byte) (*Spec, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, errors.Wrap(err, "failed to parse specification")
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.NewValidationError("specification", "expected a mapping at the top level")
+	}
+
+	l := &loader{root: document.Content[0], schemas: make(map[*yaml.Node]*Schema)}
+	swagger := scalar(l.root, "swagger")
+	openapi := scalar(l.root, "openapi")
+	switch {
+	case strings.HasPrefix(swagger, "2."):
+		l.swagger = true
+	case strings.HasPrefix(openapi, "3."):
+	default:
+		return nil, errors.NewValidationErrorWithValue("specification version", swagger+openapi, "unsupported, expected OpenAPI 3 or Swagger 2")
+	}
+	return l.spec()
+}
+
+type loader struct {
+	root    *yaml.Node
+	swagger bool
+	// schemas holds the schemas converted so far, so that recursive
+	// schemas are converted once
+	schemas map[*yaml.Node]*Schema
+}
+
+func (l *loader) spec() (*Spec, error) {
+	info := l.resolve(get(l.root, "info"))
+	spec := &Spec{
+		Title:           scalar(info, "title"),
+		Description:     scalar(info, "description"),
+		Servers:         l.servers(),
+		SecuritySchemes: make(map[string]*SecurityScheme),
+	}
+
+	for _, tag := range items(get(l.root, "tags")) {
+		spec.Tags = append(spec.Tags, Tag{Name: scalar(tag, "name"), Description: scalar(tag, "description")})
+	}
+
+	schemesKey := []string{"components", "securitySchemes"}
+	if l.swagger {
+		schemesKey = []string{"securityDefinitions"}
+	}
+	for name, node := range pairs(get(l.root, schemesKey...)) {
+		node = l.resolve(node)
+		scheme := &SecurityScheme{
+			Type:   scalar(node, "type"),
+			Scheme: strings.ToLower(scalar(node, "scheme")),
+			Name:   scalar(node, "name"),
+			In:     scalar(node, "in"),
+		}
+		if scheme.Type == "basic" {
+			scheme.Type, scheme.Scheme = "http", "basic"
+		}
+		spec.SecuritySchemes[name] = scheme
+	}
+
+	globalSecurity := security(get(l.root, "security"))
+	for path, item := range pairs(get(l.root, "paths")) {
+		item = l.resolve(item)
+		shared := l.parameters(get(item, "parameters"))
+		for _, method := range methods {
+			node := l.resolve(get(item, method))
+			if node == nil {
+				continue
+			}
+			operation, err := l.operation(method, path, node, shared)
+			if err != nil {
+				return nil, err
+			}
+			operation.Security = globalSecurity
+			if node := get(node, "security"); node != nil {
+				operation.Security = security(node)
+			}
+			spec.Operations = append(spec.Operations, operation)
+		}
+	}
+	return spec, nil
+}
+
+// servers returns the base URLs of the API
+func (l *loader) servers() []string {
+	if l.swagger {
+		host := scalar(l.root, "host")
+		if host == "" {
+			return nil
+		}
+		scheme := "https"
+		if schemes := items(get(l.root, "schemes")); len(schemes) > 0 {
+			scheme = schemes[0].Value
+		}
+		return []string{scheme + "://" + host + strings.TrimSuffix(scalar(l.root, "basePath"), "/")}
+	}
+
+	var servers []string
+	for _, server := range items(get(l.root, "servers")) {
+		u := scalar(server, "url")
+		for name, variable := range pairs(get(server, "variables")) {
+			u = strings.ReplaceAll(u, "{"+name+"}", scalar(variable, "default"))
+		}
+		servers = append(servers, strings.TrimSuffix(u, "/"))
+	}
+	return servers
+}
+
+func (l *loader) operation(method, path string, node *yaml.Node, shared []*Parameter) (*Operation, error) {
+	operation := &Operation{
+		Method:      strings.ToUpper(method),
+		Path:        path,
+		OperationID: scalar(node, "operationId"),
+		Summary:     scalar(node, "summary"),
+		Description: scalar(node, "description"),
+	}
+	for _, tag := range items(get(node, "tags")) {
+		operation.Tags = append(operation.Tags, tag.Value)
+	}
+
+	// Parameters of the operation override those of the path
+	own := l.parameters(get(node, "parameters"))
+	for _, p := range shared {
+		overridden := false
+		for _, o := range own {
+			overridden = overridden || (o.Name == p.Name && o.In == p.In)
+		}
+		if !overridden {
+			operation.Parameters = append(operation.Parameters, p)
+		}
+	}
+	operation.Parameters = append(operation.Parameters, own...)
+
+	if l.swagger {
+		operation.RequestBody = l.swaggerBody(node, operation)
+	} else if body := l.resolve(get(node, "requestBody")); body != nil {
+		operation.RequestBody = l.mediaType(get(body, "content"))
+	}
+
+	for status, response := range pairs(get(node, "responses")) {
+		if operation.Responses == nil {
+			operation.Responses = make(map[string]*Response)
+		}
+		operation.Responses[strings.ToUpper(status)] = l.response(node, l.resolve(response))
+	}
+	return operation, nil
+}
+
+// response converts a response object of an operation
+func (l *loader) response(operation, node *yaml.Node) *Response {
+	response := &Response{}
+	for name, header := range pairs(get(node, "headers")) {
+		// The Content-Type header is described by the content
+		if strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		header = l.resolve(header)
+		param := &Parameter{Name: name, In: "header", Required: scalar(header, "required") == "true"}
+		if schema := get(header, "schema"); schema != nil {
+			param.Schema = l.schema(schema)
+		} else {
+			param.Schema = l.schema(header)
+		}
+		response.Headers = append(response.Headers, param)
+	}
+
+	if !l.swagger {
+		for contentType, media := range pairs(get(node, "content")) {
+			mediaType := MediaType{ContentType: contentType}
+			if schema := get(media, "schema"); schema != nil {
+				mediaType.Schema = l.schema(schema)
+			}
+			response.Content = append(response.Content, mediaType)
+		}
+		return response
+	}
+
+	// Swagger 2 responses have one schema for the types the operation
+	// produces
+	schema := get(node, "schema")
+	if schema == nil {
+		return response
+	}
+	produces := items(get(operation, "produces"))
+	if produces == nil {
+		produces = items(get(l.root, "produces"))
+	}
+	if produces == nil {
+		produces = []*yaml.Node{{Kind: yaml.ScalarNode, Value: "application/json"}}
+	}
+	for _, contentType := range produces {
+		response.Content = append(response.Content, MediaType{ContentType: contentType.Value, Schema: l.schema(schema)})
+	}
+	return response
+}
+
+// parameters converts parameter objects, leaving out Swagger 2 body and
+// form parameters, which describe the body
+func (l *loader) parameters(node *yaml.Node) []*Parameter {
+	var params []*Parameter
+	for _, item := range items(node) {
+		item = l.resolve(item)
+		in := scalar(item, "in")
+		if in == "body" || in == "formData" {
+			continue
+		}
+		param := &Parameter{Name: scalar(item, "name"), In: in, Required: scalar(item, "required") == "true"}
+		if schema := get(item, "schema"); schema != nil {
+			param.Schema = l.schema(schema)
+		} else {
+			// Swagger 2 and simple parameters describe the value inline
+			param.Schema = l.schema(item)
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// mediaType picks the media type of a content map to write requests with:
+// JSON, then forms, then the first one
+func (l *loader) mediaType(content *yaml.Node) *RequestBody {
+	var chosen string
+	var chosenNode *yaml.Node
+	rank := func(contentType string) int {
+		switch {
+		case isJSON(contentType):
+			return 3
+		case contentType == "application/x-www-form-urlencoded":
+			return 2
+		case contentType == "multipart/form-data":
+			return 1
+		}
+		return 0
+	}
+	for contentType, node := range pairs(content) {
+		if chosenNode == nil || rank(contentType) > rank(chosen) {
+			chosen, chosenNode = contentType, node
+		}
+	}
+	if chosenNode == nil {
+		return nil
+	}
+
+	body := &RequestBody{ContentType: chosen}
+	if schema := get(chosenNode, "schema"); schema != nil {
+		body.Schema = l.schema(schema)
+	}
+	if example := get(chosenNode, "example"); example != nil {
+		body.Example = example
+	} else {
+		for _, example := range pairs(get(chosenNode, "examples")) {
+			if value := get(l.resolve(example), "value"); value != nil {
+				body.Example = value
+				break
+			}
+		}
+	}
+	return body
+}
+
+// swaggerBody converts the body and form parameters of a Swagger 2
+// operation
+func (l *loader) swaggerBody(node *yaml.Node, operation *Operation) *RequestBody {
+	consumes := items(get(node, "consumes"))
+	if consumes == nil {
+		consumes = items(get(l.root, "consumes"))
+	}
+	contentType := "application/json"
+	if len(consumes) > 0 {
+		contentType = consumes[0].Value
+	}
+
+	form := &Schema{Types: []string{"object"}}
+	for _, item := range items(get(node, "parameters")) {
+		item = l.resolve(item)
+		switch scalar(item, "in") {
+		case "body":
+			if contentType == "application/x-www-form-urlencoded" || contentType == "multipart/form-data" {
+				contentType = "application/json"
+			}
+			return &RequestBody{ContentType: contentType, Schema: l.schema(get(item, "schema"))}
+		case "formData":
+			name := scalar(item, "name")
+			schema := l.schema(item)
+			if schema.HasType("file") {
+				schema.Types, schema.Format = []string{"string"}, "binary"
+				contentType = "multipart/form-data"
+			}
+			form.Properties = append(form.Properties, Property{Name: name, Schema: schema})
+			if scalar(item, "required") == "true" {
+				form.Required = append(form.Required, name)
+			}
+		}
+	}
+	if len(form.Properties) == 0 {
+		return nil
+	}
+	if contentType != "multipart/form-data" {
+		contentType = "application/x-www-form-urlencoded"
+	}
+	return &RequestBody{ContentType: contentType, Schema: form}
+}
+
+// schema converts a schema object
+func (l *loader) schema(node *yaml.Node) *Schema {
+	node = l.resolve(node)
+	if node == nil {
+		return &Schema{}
+	}
+	if schema, ok := l.schemas[node]; ok {
+		return schema
+	}
+	schema := &Schema{}
+	l.schemas[node] = schema
+
+	if t := get(node, "type"); t != nil {
+		if t.Kind == yaml.SequenceNode {
+			for _, item := range t.Content {
+				schema.Types = append(schema.Types, item.Value)
+			}
+		} else {
+			schema.Types = []string{t.Value}
+		}
+	}
+	schema.Nullable = scalar(node, "nullable") == "true" || scalar(node, "x-nullable") == "true" || schema.HasType("null")
+	if schema.Nullable && len(schema.Types) > 0 && !schema.HasType("null") {
+		schema.Types = append(schema.Types, "null")
+	}
+	schema.Format = scalar(node, "format")
+	schema.Pattern = scalar(node, "pattern")
+	schema.Enum = items(get(node, "enum"))
+	schema.Example = get(node, "example")
+	if schema.Example == nil {
+		if examples := items(get(node, "examples")); len(examples) > 0 {
+			schema.Example = examples[0]
+		}
+	}
+	schema.Default = get(node, "default")
+	schema.ReadOnly = scalar(node, "readOnly") == "true"
+	schema.WriteOnly = scalar(node, "writeOnly") == "true"
+
+	for name, property := range pairs(get(node, "properties")) {
+		schema.Properties = append(schema.Properties, Property{Name: name, Schema: l.schema(property)})
+	}
+	for _, required := range items(get(node, "required")) {
+		schema.Required = append(schema.Required, required.Value)
+	}
+	if additional := get(node, "additionalProperties"); additional != nil {
+		switch {
+		case additional.Kind == yaml.ScalarNode && additional.Value == "false":
+			schema.NoAdditionalProperties = true
+		case additional.Kind == yaml.MappingNode:
+			schema.AdditionalProperties = l.schema(additional)
+		}
+	}
+	if items := get(node, "items"); items != nil {
+		schema.Items = l.schema(items)
+	}
+	for _, item := range items(get(node, "allOf")) {
+		schema.AllOf = append(schema.AllOf, l.schema(item))
+	}
+	for _, item := range items(get(node, "oneOf")) {
+		schema.OneOf = append(schema.OneOf, l.schema(item))
+	}
+	for _, item := range items(get(node, "anyOf")) {
+		schema.AnyOf = append(schema.AnyOf, l.schema(item))
+	}
+
+	schema.MinLength = intValue(get(node, "minLength"))
+	schema.MaxLength = intValue(get(node, "maxLength"))
+	schema.MinItems = intValue(get(node, "minItems"))
+	schema.MaxItems = intValue(get(node, "maxItems"))
+	schema.Minimum = floatValue(get(node, "minimum"))
+	schema.Maximum = floatValue(get(node, "maximum"))
+	// OpenAPI 3.0 and Swagger 2 write exclusive bounds as booleans, 3.1 as
+	// numbers
+	if exclusive := get(node, "exclusiveMinimum"); exclusive != nil {
+		if value := floatValue(exclusive); value != nil {
+			schema.Minimum = value
+		}
+		schema.ExclusiveMinimum = exclusive.Value != "false"
+	}
+	if exclusive := get(node, "exclusiveMaximum"); exclusive != nil {
+		if value := floatValue(exclusive); value != nil {
+			schema.Maximum = value
+		}
+		schema.ExclusiveMaximum = exclusive.Value != "false"
+	}
+	return schema
+}
+
+// resolve follows $ref pointers within the document
+func (l *loader) resolve(node *yaml.Node) *yaml.Node {
+	for range 32 {
+		if node == nil {
+			return nil
+		}
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+			continue
+		}
+		ref := scalar(node, "$ref")
+		if ref == "" {
+			return node
+		}
+		pointer, ok := strings.CutPrefix(ref, "#/")
+		if !ok {
+			// References to other files are not followed
+			return nil
+		}
+		target := l.root
+		for _, key := range strings.Split(pointer, "/") {
+			key, _ = url.PathUnescape(key)
+			key = strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+			target = get(target, key)
+		}
+		node = target
+	}
+	return nil
+}
+
+// get returns the value of a path of keys in a mapping node, or nil
+func get(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				value = node.Content[i+1]
+				break
+			}
+		}
+		node = value
+	}
+	return node
+}
+
+// scalar returns the value of a scalar in a mapping node, or ""
+func scalar(node *yaml.Node, key string) string {
+	if value := get(node, key); value != nil && value.Kind == yaml.ScalarNode {
+		return value.Value
+	}
+	return ""
+}
+
+// items returns the items of a sequence node
+func items(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+// pairs iterates over the keys and values of a mapping node in order
+func pairs(node *yaml.Node) func(yield func(string, *yaml.Node) bool) {
+	return func(yield func(string, *yaml.Node) bool) {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if !yield(node.Content[i].Value, node.Content[i+1]) {
+				return
+			}
+		}
+	}
+}
+
+// security converts a list of security requirements
+func security(node *yaml.Node) [][]string {
+	var requirements [][]string
+	for _, item := range items(node) {
+		var names []string
+		for name := range pairs(item) {
+			names = append(names, name)
+		}
+		requirements = append(requirements, names)
+	}
+	return requirements
+}
+
+func intValue(node *yaml.Node) *int {
+	if node == nil {
+		return nil
+	}
+	value, err := strconv.Atoi(node.Value)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+func floatValue(node *yaml.Node) *float64 {
+	if node == nil {
+		return nil
+	}
+	value, err := strconv.ParseFloat(node.Value, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+func isJSON(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.HasSuffix(contentType, "/json") || strings.HasSuffix(contentType, "+json") || strings.Contains(contentType, "/json;")
+}
@@ -0,0 +1,551 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// maxErrors caps the violations reported by one check
+const maxErrors = 10
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Check is the outcome of checking one part of a response against its
+// operation. It passed if there are no errors.
+type Check struct {
+	Name   string
+	Errors []string
+}
+
+// FindOperation returns the operation a request is for, matching its method
+// and its path, without the path of a server, against the path templates.
+// Literal path segments take precedence over parameters.
+func (s *Spec) FindOperation(method, rawURL string) *Operation {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	segments := pathSegments(u.EscapedPath())
+
+	var best *Operation
+	bestScore := -1
+	for _, base := range s.basePaths() {
+		if len(segments) < len(base) || !slices.Equal(segments[:len(base)], base) {
+			continue
+		}
+		rest := segments[len(base):]
+		for _, operation := range s.Operations {
+			if !strings.EqualFold(operation.Method, method) {
+				continue
+			}
+			if score := matchPath(pathSegments(operation.Path), rest); score > bestScore {
+				best, bestScore = operation, score
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return nil
+}
+
+// basePaths returns the paths of the servers split into segments, longest
+// first, ending with the root
+func (s *Spec) basePaths() [][]string {
+	var bases [][]string
+	for _, server := range s.Servers {
+		u, err := url.Parse(server)
+		if err != nil {
+			continue
+		}
+		if base := pathSegments(u.EscapedPath()); len(base) > 0 {
+			bases = append(bases, base)
+		}
+	}
+	slices.SortStableFunc(bases, func(a, b []string) int { return len(b) - len(a) })
+	return append(bases, nil)
+}
+
+// serves reports whether a request is for one of the servers of the spec.
+// A spec without servers, or with a server without a host such as /v1,
+// serves every host.
+func (s *Spec) serves(request *url.URL) bool {
+	if len(s.Servers) == 0 {
+		return true
+	}
+	for _, server := range s.Servers {
+		if u, err := url.Parse(server); err == nil && sameHost(u, request) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameHost reports whether a server is for the host of a request, ignoring
+// default ports. Servers and requests without a host match any host.
+func sameHost(server, request *url.URL) bool {
+	if server.Host == "" || request.Host == "" {
+		return true
+	}
+	return strings.EqualFold(server.Hostname(), request.Hostname()) && hostPort(server) == hostPort(request)
+}
+
+// hostPort returns the port of a URL, or the default port of its scheme
+func hostPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if strings.EqualFold(u.Scheme, "http") || strings.EqualFold(u.Scheme, "ws") {
+		return "80"
+	}
+	return "443"
+}
+
+// pathSegments splits a path into unescaped segments
+func pathSegments(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segments[i] = unescaped
+		}
+	}
+	return segments
+}
+
+// matchPath returns the number of literal segments of a path template
+// that match a path, or -1 if it does not match
+func matchPath(template, segments []string) int {
+	if len(template) != len(segments) {
+		return -1
+	}
+	score := 0
+	for i, part := range template {
+		if !strings.Contains(part, "{") {
+			if part != segments[i] {
+				return -1
+			}
+			score++
+			continue
+		}
+		// A segment such as {id}.json mixes parameters and text
+		if matched, err := regexp.MatchString(segmentPattern(part), segments[i]); err != nil || !matched {
+			return -1
+		}
+	}
+	return score
+}
+
+// segmentPattern turns a path template segment into a regular expression
+func segmentPattern(part string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for {
+		loc := pathParamRegex.FindStringIndex(part)
+		if loc == nil {
+			break
+		}
+		b.WriteString(regexp.QuoteMeta(part[:loc[0]]))
+		b.WriteString("[^/]+")
+		part = part[loc[1]:]
+	}
+	b.WriteString(regexp.QuoteMeta(part))
+	b.WriteString("$")
+	return b.String()
+}
+
+// CheckResponse checks a response to a request against the operation it is
+// for: that its status is documented, that the documented headers are
+// present and valid, and that a JSON body matches its schema. Operations
+// are found on any host, so that local and staging servers are checked,
+// but a request matching none on a host that isn't a server of the spec,
+// such as an auth server, is not checked.
+func (s *Spec) CheckResponse(method, rawURL string, status int, headers map[string][]string, body string) []Check {
+	operation := s.FindOperation(method, rawURL)
+	if u, err := url.Parse(rawURL); operation == nil && err == nil && !s.serves(u) {
+		return nil
+	}
+	if operation == nil {
+		path := rawURL
+		if u, err := url.Parse(rawURL); err == nil {
+			path = u.Path
+		}
+		return []Check{{
+			Name:   fmt.Sprintf("OpenAPI %s %s: operation", strings.ToUpper(method), path),
+			Errors: []string{"no operation in the spec matches the request"},
+		}}
+	}
+	prefix := "OpenAPI " + operation.Method + " " + operation.Path + ": "
+
+	if len(operation.Responses) == 0 {
+		return nil
+	}
+	response := operation.response(status)
+	if response == nil {
+		return []Check{{
+			Name:   prefix + "status",
+			Errors: []string{fmt.Sprintf("status %d is not documented", status)},
+		}}
+	}
+	checks := []Check{{Name: prefix + "status"}}
+
+	if len(response.Headers) > 0 {
+		check := Check{Name: prefix + "headers"}
+		for _, header := range response.Headers {
+			values := headerValues(headers, header.Name)
+			if len(values) == 0 {
+				if header.Required {
+					check.Errors = append(check.Errors, fmt.Sprintf("header %s is missing", header.Name))
+				}
+				continue
+			}
+			value := headerValue(header.Schema, strings.Join(values, ", "))
+			for _, err := range Validate(header.Schema, value) {
+				check.Errors = append(check.Errors, "header "+header.Name+strings.TrimPrefix(err, "$"))
+			}
+		}
+		checks = append(checks, limit(check))
+	}
+
+	if len(response.Content) > 0 && body != "" {
+		check := Check{Name: prefix + "body"}
+		contentType := strings.Join(headerValues(headers, "Content-Type"), ", ")
+		mediaType := response.mediaType(contentType)
+		switch {
+		case mediaType == nil:
+			var documented []string
+			for _, m := range response.Content {
+				documented = append(documented, m.ContentType)
+			}
+			check.Errors = append(check.Errors, fmt.Sprintf("content type %q is not documented, expected %s", contentType, strings.Join(documented, ", ")))
+		case mediaType.Schema != nil && isJSON(contentType):
+			decoder := json.NewDecoder(strings.NewReader(body))
+			decoder.UseNumber()
+			var value any
+			if err := decoder.Decode(&value); err != nil {
+				check.Errors = append(check.Errors, "body is not valid JSON: "+err.Error())
+			} else {
+				check.Errors = Validate(mediaType.Schema, value)
+			}
+		}
+		checks = append(checks, limit(check))
+	}
+	return checks
+}
+
+// response returns the documented response of a status: its code, its
+// range such as 2XX, or the default response
+func (o *Operation) response(status int) *Response {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", "DEFAULT"} {
+		if response, ok := o.Responses[key]; ok {
+			return response
+		}
+	}
+	return nil
+}
+
+// mediaType returns the documented content type matching a Content-Type,
+// preferring exact matches to wildcards
+func (r *Response) mediaType(contentType string) *MediaType {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	kind, _, _ := strings.Cut(mediaType, "/")
+	for _, candidate := range []string{mediaType, kind + "/*", "*/*"} {
+		for i := range r.Content {
+			documented, _, err := mime.ParseMediaType(r.Content[i].ContentType)
+			if err != nil {
+				documented = strings.ToLower(r.Content[i].ContentType)
+			}
+			if documented == candidate {
+				return &r.Content[i]
+			}
+		}
+	}
+	return nil
+}
+
+// headerValues returns the values of a header, matching its name without
+// regard to case
+func headerValues(headers map[string][]string, name string) []string {
+	for key, values := range headers {
+		if strings.EqualFold(key, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// headerValue converts the text of a header to the type of its schema
+func headerValue(schema *Schema, text string) any {
+	switch exampleType(schema) {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			return json.Number(text)
+		}
+	case "boolean":
+		if value, err := strconv.ParseBool(text); err == nil {
+			return value
+		}
+	case "array":
+		var values []any
+		for _, item := range strings.Split(text, ",") {
+			values = append(values, headerValue(schema.Items, strings.TrimSpace(item)))
+		}
+		return values
+	}
+	return text
+}
+
+// limit caps the errors of a check
+func limit(check Check) Check {
+	if len(check.Errors) > maxErrors {
+		more := len(check.Errors) - maxErrors
+		check.Errors = append(check.Errors[:maxErrors], fmt.Sprintf("and %d more", more))
+	}
+	return check
+}
+
+// Validate checks a value decoded from JSON, with numbers as json.Number,
+// against a schema. Errors start with the JSON path of the value they are
+// about, such as $.items[0].name.
+func Validate(schema *Schema, value any) []string {
+	var errs []string
+	validate(schema, value, "$", &errs, make(map[*Schema]int))
+	return errs
+}
+
+func validate(schema *Schema, value any, path string, errs *[]string, depth map[*Schema]int) {
+	if schema == nil || value == nil && schema.Nullable {
+		return
+	}
+	// Recursive schemas are followed as deep as the value goes, which a
+	// decoded value bounds, but guard against schemas that refer to
+	// themselves without nesting
+	if depth[schema] > 64 {
+		return
+	}
+	depth[schema]++
+	defer func() { depth[schema]-- }()
+
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	for _, part := range schema.AllOf {
+		validate(part, value, path, errs, depth)
+	}
+	if len(schema.OneOf) > 0 {
+		if matches := countMatches(schema.OneOf, value, depth); matches != 1 {
+			fail("matches %d of the oneOf schemas, expected 1", matches)
+		}
+	}
+	if len(schema.AnyOf) > 0 && countMatches(schema.AnyOf, value, depth) == 0 {
+		fail("matches none of the anyOf schemas")
+	}
+
+	typ := jsonType(value)
+	if len(schema.Types) > 0 && !schema.HasType(typ) && !(typ == "integer" && schema.HasType("number")) && !schema.HasType("file") {
+		fail("expected %s, got %s", strings.Join(schema.Types, " or "), typ)
+		return
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(node *yaml.Node) bool { return equalJSON(node, value) }) {
+		var allowed []string
+		for _, node := range schema.Enum {
+			allowed = append(allowed, scalarJSON(node))
+		}
+		fail("%s is not one of %s", compactJSON(value), strings.Join(allowed, ", "))
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			fail("length %d is shorter than %d", length, *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fail("length %d is longer than %d", length, *schema.MaxLength)
+		}
+		if schema.Pattern != "" {
+			if re, err := regexp.Compile(schema.Pattern); err == nil && !re.MatchString(v) {
+				fail("%q does not match %s", v, schema.Pattern)
+			}
+		}
+		if !validFormat(schema.Format, v) {
+			fail("%q is not a valid %s", v, schema.Format)
+		}
+	case json.Number:
+		n, _ := v.Float64()
+		if schema.Minimum != nil && (n < *schema.Minimum || schema.ExclusiveMinimum && n == *schema.Minimum) {
+			fail("%s is less than the minimum %v", v, *schema.Minimum)
+		}
+		if schema.Maximum != nil && (n > *schema.Maximum || schema.ExclusiveMaximum && n == *schema.Maximum) {
+			fail("%s is greater than the maximum %v", v, *schema.Maximum)
+		}
+	case []any:
+		if schema.MinItems != nil && len(v) < *schema.MinItems {
+			fail("has %d items, fewer than %d", len(v), *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(v) > *schema.MaxItems {
+			fail("has %d items, more than %d", len(v), *schema.MaxItems)
+		}
+		for i, item := range v {
+			validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), errs, depth)
+		}
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok && !writeOnly(schema, name) {
+				fail("missing required property %q", name)
+			}
+		}
+		known := make(map[string]bool)
+		for _, property := range schema.Properties {
+			known[property.Name] = true
+			if item, ok := v[property.Name]; ok {
+				validate(property.Schema, item, propertyPath(path, property.Name), errs, depth)
+			}
+		}
+		var extra []string
+		for name := range v {
+			if !known[name] {
+				extra = append(extra, name)
+			}
+		}
+		slices.Sort(extra)
+		for _, name := range extra {
+			switch {
+			case schema.NoAdditionalProperties && len(schema.AllOf) == 0:
+				fail("unexpected property %q", name)
+			case schema.AdditionalProperties != nil:
+				validate(schema.AdditionalProperties, v[name], propertyPath(path, name), errs, depth)
+			}
+		}
+	}
+}
+
+// countMatches returns the number of schemas a value is valid against
+func countMatches(schemas []*Schema, value any, depth map[*Schema]int) int {
+	matches := 0
+	for _, schema := range schemas {
+		var errs []string
+		validate(schema, value, "$", &errs, depth)
+		if len(errs) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// writeOnly reports whether a property is only sent in requests, so it is
+// not required in responses
+func writeOnly(schema *Schema, name string) bool {
+	for _, property := range schema.Properties {
+		if property.Name == name {
+			return property.Schema.WriteOnly
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON schema type of a decoded value
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if n, err := v.Float64(); err == nil && n == math.Trunc(n) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// validFormat checks the string formats that are commonly relied on
+func validFormat(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "uuid":
+		return uuidRegex.MatchString(value)
+	case "email":
+		_, err := mail.ParseAddress(value)
+		return err == nil
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	}
+	return true
+}
+
+// equalJSON reports whether a decoded value equals a value of the spec
+func equalJSON(node *yaml.Node, value any) bool {
+	decoder := json.NewDecoder(strings.NewReader(JSON(node)))
+	decoder.UseNumber()
+	var expected any
+	if err := decoder.Decode(&expected); err != nil {
+		return false
+	}
+	return compactJSON(expected) == compactJSON(value) || numbersEqual(expected, value)
+}
+
+func numbersEqual(a, b any) bool {
+	x, ok := a.(json.Number)
+	y, ok2 := b.(json.Number)
+	if !ok || !ok2 {
+		return false
+	}
+	m, err := x.Float64()
+	n, err2 := y.Float64()
+	return err == nil && err2 == nil && m == n
+}
+
+// compactJSON writes a decoded value as compact JSON
+func compactJSON(value any) string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// propertyPath appends a property to a JSON path
+func propertyPath(path, name string) string {
+	for _, r := range name {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return path + "[" + strconv.Quote(name) + "]"
+		}
+	}
+	return path + "." + name
+}
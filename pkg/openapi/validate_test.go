@@ -0,0 +1,165 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+const usersSpec = `openapi: 3.0.3
+info: {title: Users}
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users/{id}:
+    get:
+      responses:
+        '200':
+          description: A user
+          headers:
+            X-Rate-Limit:
+              required: true
+              schema: {type: integer, minimum: 0}
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+        4XX:
+          description: An error
+          content:
+            application/problem+json:
+              schema:
+                type: object
+                required: [title]
+                properties:
+                  title: {type: string}
+  /users/me:
+    get:
+      responses:
+        '204': {description: Empty}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id, email, role]
+      additionalProperties: false
+      properties:
+        id: {type: integer}
+        email: {type: string, format: email}
+        role: {type: string, enum: [admin, member]}
+        password: {type: string, writeOnly: true}
+        tags:
+          type: array
+          maxItems: 2
+          items: {type: string, minLength: 1}
+        manager:
+          nullable: true
+          allOf:
+            - $ref: '#/components/schemas/User'
+`
+
+func TestFindOperation(t *testing.T) {
+	spec, err := Parse([]byte(usersSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method, url, want string
+	}{
+		{"GET", "https://api.example.com/v1/users/42", "/users/{id}"},
+		{"GET", "http://localhost:8080/v1/users/me?x=1", "/users/me"},
+		{"get", "https://staging.example.com/users/7", "/users/{id}"},
+		{"POST", "https://api.example.com/v1/users/42", ""},
+		{"GET", "https://api.example.com/v1/users/42/posts", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if operation := spec.FindOperation(tt.method, tt.url); operation != nil {
+			got = operation.Path
+		}
+		if got != tt.want {
+			t.Errorf("FindOperation(%s %s) = %q, want %q", tt.method, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	spec, err := Parse([]byte(usersSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonHeaders := map[string][]string{"Content-Type": {"application/json; charset=utf-8"}, "X-Rate-Limit": {"10"}}
+
+	tests := []struct {
+		name    string
+		url     string
+		status  int
+		headers map[string][]string
+		body    string
+		want    []Check
+	}{
+		{
+			name: "valid", url: "https://api.example.com/v1/users/1", status: 200, headers: jsonHeaders,
+			body: `{"id": 1, "email": "a@example.com", "role": "admin", "manager": null}`,
+			want: []Check{{Name: "OpenAPI GET /users/{id}: status"}, {Name: "OpenAPI GET /users/{id}: headers"}, {Name: "OpenAPI GET /users/{id}: body"}},
+		},
+		{
+			name: "drift", url: "https://api.example.com/v1/users/1", status: 200,
+			headers: map[string][]string{"Content-Type": {"application/json"}, "X-Rate-Limit": {"many"}},
+			body:    `{"id": "1", "email": "nope", "role": "owner", "tags": ["", "b", "c"], "manager": {"id": 2.5, "email": "b@example.com", "role": "member"}, "extra": true}`,
+			want: []Check{
+				{Name: "OpenAPI GET /users/{id}: status"},
+				{Name: "OpenAPI GET /users/{id}: headers", Errors: []string{"header X-Rate-Limit: expected integer, got string"}},
+				{Name: "OpenAPI GET /users/{id}: body", Errors: []string{
+					"$.id: expected integer, got string",
+					`$.email: "nope" is not a valid email`,
+					`$.role: "owner" is not one of "admin", "member"`,
+					"$.tags: has 3 items, more than 2",
+					"$.tags[0]: length 0 is shorter than 1",
+					"$.manager.id: expected integer, got number",
+					`$: unexpected property "extra"`,
+				}},
+			},
+		},
+		{
+			name: "error range", url: "https://api.example.com/v1/users/1", status: 404,
+			headers: map[string][]string{"content-type": {"application/problem+json"}}, body: `{"detail": "gone"}`,
+			want: []Check{{Name: "OpenAPI GET /users/{id}: status"}, {Name: "OpenAPI GET /users/{id}: body", Errors: []string{`$: missing required property "title"`}}},
+		},
+		{
+			name: "undocumented", url: "https://api.example.com/v1/users/1", status: 500,
+			want: []Check{{Name: "OpenAPI GET /users/{id}: status", Errors: []string{"status 500 is not documented"}}},
+		},
+		{
+			name: "content type", url: "https://api.example.com/v1/users/1", status: 200,
+			headers: map[string][]string{"Content-Type": {"text/html"}, "X-Rate-Limit": {"1"}}, body: "<html>",
+			want: []Check{
+				{Name: "OpenAPI GET /users/{id}: status"},
+				{Name: "OpenAPI GET /users/{id}: headers"},
+				{Name: "OpenAPI GET /users/{id}: body", Errors: []string{`content type "text/html" is not documented, expected application/json`}},
+			},
+		},
+		{
+			name: "missing header", url: "https://api.example.com/v1/users/me", status: 204,
+			want: []Check{{Name: "OpenAPI GET /users/me: status"}},
+		},
+		{
+			name: "unknown operation", url: "https://api.example.com/v1/teams", status: 200,
+			want: []Check{{Name: "OpenAPI GET /v1/teams: operation", Errors: []string{"no operation in the spec matches the request"}}},
+		},
+		{
+			name: "unknown operation on the default port", url: "https://api.example.com:443/v1/teams", status: 200,
+			want: []Check{{Name: "OpenAPI GET /v1/teams: operation", Errors: []string{"no operation in the spec matches the request"}}},
+		},
+		{
+			name: "another host", url: "https://auth.example.com/oauth/token", status: 200,
+			body: `{"access_token": "x"}`,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		got := spec.CheckResponse("GET", tt.url, tt.status, tt.headers, tt.body)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: CheckResponse() =\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
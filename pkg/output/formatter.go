@@ -11,6 +11,7 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 
@@ -72,7 +73,29 @@ func (f *Formatter) FormatResponse(resp *models.HttpResponse) string {
 	buf.WriteString("\n")
 
 	// Body
-	if resp.Body != "" {
+	if resp.IsEventStream() {
+		// Events are printed as they arrive, see FormatEvent
+		note := fmt.Sprintf("(%d events)", len(resp.Events))
+		if f.colorEnabled {
+			note = color.New(color.FgHiBlack).Sprint(note)
+		}
+		buf.WriteString(note + "\n")
+	} else if resp.IsWebSocket() {
+		// Messages are printed as they arrive, see FormatMessage
+		note := fmt.Sprintf("(%d messages received)", len(resp.Messages))
+		if f.colorEnabled {
+			note = color.New(color.FgHiBlack).Sprint(note)
+		}
+		buf.WriteString(note + "\n")
+	} else if resp.BodyTruncated {
+		// Only the start of a streamed body was kept; it may not even be
+		// valid JSON or XML
+		note := fmt.Sprintf("(body of %d bytes not shown)", resp.BodySizeInBytes)
+		if f.colorEnabled {
+			note = color.New(color.FgHiBlack).Sprint(note)
+		}
+		buf.WriteString(note + "\n")
+	} else if resp.Body != "" {
 		buf.WriteString(f.FormatBody(resp))
 	}
 
@@ -127,6 +150,45 @@ func (f *Formatter) FormatBody(resp *models.HttpResponse) string {
 	return body
 }
 
+// FormatEvent formats a Server-Sent Event: a line with its type and ID,
+// followed by its data, pretty-printed if it is JSON
+func (f *Formatter) FormatEvent(event models.ServerSentEvent) string {
+	header := "event: " + event.Event
+	if event.ID != "" {
+		header += "  id: " + event.ID
+	}
+	if f.colorEnabled {
+		header = f.headerName.Sprint(header)
+	}
+
+	data := f.formatJSON(event.Data)
+	if data == "" {
+		data = event.Data
+	}
+	return header + "\n" + data
+}
+
+// FormatMessage formats a WebSocket message: a line telling whether it was
+// sent or received, followed by its data, pretty-printed if it is JSON
+func (f *Formatter) FormatMessage(message models.WebSocketMessage, sent bool) string {
+	header := "<< received"
+	if sent {
+		header = ">> sent"
+	}
+	if f.colorEnabled {
+		header = f.headerName.Sprint(header)
+	}
+
+	if message.Binary {
+		return header + "\n" + fmt.Sprintf("(%d bytes of binary data)", len(message.Data))
+	}
+	data := f.formatJSON(message.Data)
+	if data == "" {
+		data = message.Data
+	}
+	return header + "\n" + data
+}
+
 // formatStatusLine formats the HTTP status line
 func (f *Formatter) formatStatusLine(resp *models.HttpResponse) string {
 	statusColor := f.getStatusColor(resp.StatusCode)
@@ -155,7 +217,8 @@ func (f *Formatter) getStatusColor(code int) *color.Color {
 	}
 }
 
-// formatTiming formats the timing information
+// formatTiming formats the timing information. When per-phase timings are
+// available, a waterfall is rendered below the summary line.
 func (f *Formatter) formatTiming(resp *models.HttpResponse) string {
 	t := resp.Timing
 
@@ -167,17 +230,72 @@ func (f *Formatter) formatTiming(resp *models.HttpResponse) string {
 
 	sizeInfo := fmt.Sprintf("Response: %d bytes", resp.BodySizeInBytes)
 
+	var summary string
 	if len(parts) == 0 {
+		summary = fmt.Sprintf("(%s)", sizeInfo)
+	} else {
+		summary = fmt.Sprintf("(%s, %s)", strings.Join(parts, ", "), sizeInfo)
+	}
+	if f.colorEnabled {
+		summary = color.New(color.FgHiBlack).Sprint(summary)
+	}
+
+	if !t.HasPhases() || t.Total <= 0 {
+		return summary
+	}
+	return summary + "\n" + f.formatWaterfall(t)
+}
+
+// waterfallWidth is the number of columns used for waterfall bars
+const waterfallWidth = 40
+
+// formatWaterfall renders each phase as a bar offset by the phases before it
+func (f *Formatter) formatWaterfall(t models.ResponseTiming) string {
+	var buf bytes.Buffer
+	bar := color.New(color.FgCyan)
+	dim := color.New(color.FgHiBlack)
+
+	columns := func(d time.Duration) int {
+		return int(int64(d) * waterfallWidth / int64(t.Total))
+	}
+
+	var offset time.Duration
+	for _, phase := range models.TimingPhases {
+		d := phase.Value(t)
+		if d <= 0 {
+			continue
+		}
+
+		start := min(columns(offset), waterfallWidth-1)
+		width := min(max(columns(d), 1), waterfallWidth-start)
+		offset += d
+
+		bars := strings.Repeat("█", width)
 		if f.colorEnabled {
-			return color.New(color.FgHiBlack).Sprintf("(%s)", sizeInfo)
+			bars = bar.Sprint(bars)
 		}
-		return fmt.Sprintf("(%s)", sizeInfo)
+		padding := strings.Repeat(" ", waterfallWidth-start-width)
+		buf.WriteString(fmt.Sprintf("  %-18s %s%s%s %s\n", phase.Name, strings.Repeat(" ", start), bars, padding, formatPhaseDuration(d)))
 	}
 
-	if f.colorEnabled {
-		return color.New(color.FgHiBlack).Sprintf("(%s, %s)", strings.Join(parts, ", "), sizeInfo)
+	buf.WriteString(fmt.Sprintf("  %-18s %s %s", "Total", strings.Repeat(" ", waterfallWidth), formatPhaseDuration(t.Total)))
+	if t.ConnectionReused {
+		note := "  (connection reused)"
+		if f.colorEnabled {
+			note = dim.Sprint(note)
+		}
+		buf.WriteString(note)
+	}
+
+	return buf.String()
+}
+
+// formatPhaseDuration formats a phase duration with millisecond precision
+func formatPhaseDuration(d time.Duration) string {
+	if d >= time.Second {
+		return fmt.Sprintf("%.2fs", d.Seconds())
 	}
-	return fmt.Sprintf("(%s, %s)", strings.Join(parts, ", "), sizeInfo)
+	return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
 }
 
 // formatJSON formats and colorizes JSON
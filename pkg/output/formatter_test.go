@@ -3,6 +3,7 @@ package output
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ideaspaper/restclient/pkg/models"
 )
@@ -51,6 +52,102 @@ func TestFormatResponse(t *testing.T) {
 	}
 }
 
+func TestFormatResponse_TruncatedBody(t *testing.T) {
+	f := NewFormatter(false)
+
+	resp := &models.HttpResponse{
+		StatusCode:      200,
+		HttpVersion:     "HTTP/1.1",
+		Headers:         map[string][]string{"Content-Type": {"application/json"}},
+		Body:            `{"items": [`,
+		BodySizeInBytes: 5000000,
+		Streamed:        true,
+		BodyTruncated:   true,
+	}
+
+	formatted := f.FormatResponse(resp)
+
+	if strings.Contains(formatted, "items") {
+		t.Error("Should not print a truncated body")
+	}
+	if !strings.Contains(formatted, "(body of 5000000 bytes not shown)") {
+		t.Errorf("Should note the body size, got:\n%s", formatted)
+	}
+}
+
+func TestFormatEvent(t *testing.T) {
+	f := NewFormatter(false)
+
+	tests := []struct {
+		event models.ServerSentEvent
+		want  string
+	}{
+		{models.ServerSentEvent{Event: "message", Data: "plain text"}, "event: message\nplain text"},
+		{models.ServerSentEvent{ID: "7", Event: "update", Data: `{"n":1}`}, "event: update  id: 7\n{\n  \"n\": 1\n}"},
+	}
+
+	for _, tt := range tests {
+		if got := f.FormatEvent(tt.event); got != tt.want {
+			t.Errorf("FormatEvent(%+v) = %q, want %q", tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestFormatResponse_EventStream(t *testing.T) {
+	f := NewFormatter(false)
+
+	resp := &models.HttpResponse{
+		StatusCode:  200,
+		HttpVersion: "HTTP/1.1",
+		Headers:     map[string][]string{"Content-Type": {"text/event-stream"}},
+		Body:        "data: a\n\ndata: b\n\n",
+		Events:      []models.ServerSentEvent{{Event: "message", Data: "a"}, {Event: "message", Data: "b"}},
+	}
+
+	formatted := f.FormatResponse(resp)
+	if strings.Contains(formatted, "data: a") || !strings.Contains(formatted, "(2 events)") {
+		t.Errorf("Should summarize the events instead of printing the stream, got:\n%s", formatted)
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	f := NewFormatter(false)
+
+	tests := []struct {
+		message models.WebSocketMessage
+		sent    bool
+		want    string
+	}{
+		{models.WebSocketMessage{Data: "ping"}, true, ">> sent\nping"},
+		{models.WebSocketMessage{Data: `{"n":1}`}, false, "<< received\n{\n  \"n\": 1\n}"},
+		{models.WebSocketMessage{Data: "\x01\x02", Binary: true}, false, "<< received\n(2 bytes of binary data)"},
+	}
+
+	for _, tt := range tests {
+		if got := f.FormatMessage(tt.message, tt.sent); got != tt.want {
+			t.Errorf("FormatMessage(%+v, %v) = %q, want %q", tt.message, tt.sent, got, tt.want)
+		}
+	}
+}
+
+func TestFormatResponse_WebSocket(t *testing.T) {
+	f := NewFormatter(false)
+
+	resp := &models.HttpResponse{
+		StatusCode:  101,
+		HttpVersion: "HTTP/1.1",
+		Headers:     map[string][]string{"Upgrade": {"websocket"}},
+		Body:        "a\nb",
+		Messages:    []models.WebSocketMessage{{Data: "a"}, {Data: "b"}},
+		Request:     &models.HttpRequest{Method: "WEBSOCKET", URL: "ws://localhost/live"},
+	}
+
+	formatted := f.FormatResponse(resp)
+	if !strings.Contains(formatted, "(2 messages received)") {
+		t.Errorf("Should summarize the messages, got:\n%s", formatted)
+	}
+}
+
 func TestFormatHeaders(t *testing.T) {
 	f := NewFormatter(false)
 
@@ -373,3 +470,74 @@ type testError struct {
 func (e *testError) Error() string {
 	return e.msg
 }
+
+func TestFormatTiming_Waterfall(t *testing.T) {
+	f := NewFormatter(false)
+
+	resp := &models.HttpResponse{
+		BodySizeInBytes: 100,
+		Timing: models.ResponseTiming{
+			DNSLookup:        10 * time.Millisecond,
+			TCPConnection:    10 * time.Millisecond,
+			ServerProcessing: 60 * time.Millisecond,
+			ContentTransfer:  20 * time.Millisecond,
+			Total:            100 * time.Millisecond,
+		},
+	}
+
+	formatted := f.formatTiming(resp)
+	lines := strings.Split(formatted, "\n")
+
+	if !strings.Contains(lines[0], "Total: 100ms") {
+		t.Errorf("first line should be the summary, got %q", lines[0])
+	}
+	if strings.Contains(formatted, "TLS Handshake") {
+		t.Error("phases that did not happen should be omitted")
+	}
+
+	tcp := findLine(lines, "TCP Connection")
+	server := findLine(lines, "Server Processing")
+	if tcp == "" || server == "" {
+		t.Fatalf("waterfall missing phases:\n%s", formatted)
+	}
+	if strings.Index(server, "█") <= strings.Index(tcp, "█") {
+		t.Errorf("server processing bar should start after TCP bar:\n%s", formatted)
+	}
+	if strings.Count(server, "█") != 24 {
+		t.Errorf("server processing should span 60%% of %d columns, got %d", waterfallWidth, strings.Count(server, "█"))
+	}
+	if !strings.Contains(server, "60.00ms") {
+		t.Errorf("server processing line should show duration, got %q", server)
+	}
+}
+
+func TestFormatTiming_ConnectionReused(t *testing.T) {
+	f := NewFormatter(false)
+
+	resp := &models.HttpResponse{
+		Timing: models.ResponseTiming{
+			ServerProcessing: 5 * time.Millisecond,
+			ContentTransfer:  time.Millisecond,
+			Total:            6 * time.Millisecond,
+			ConnectionReused: true,
+		},
+	}
+
+	formatted := f.formatTiming(resp)
+	if !strings.Contains(formatted, "(connection reused)") {
+		t.Errorf("should note connection reuse, got:\n%s", formatted)
+	}
+	if strings.Contains(formatted, "DNS Lookup") {
+		t.Errorf("reused connection should not show DNS phase, got:\n%s", formatted)
+	}
+}
+
+// findLine returns the first line containing substr
+func findLine(lines []string, substr string) string {
+	for _, line := range lines {
+		if strings.Contains(line, substr) {
+			return line
+		}
+	}
+	return ""
+}
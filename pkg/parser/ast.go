@@ -0,0 +1,455 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Position is a location in the source of a file
+type Position struct {
+	Offset int // Byte offset (0-based)
+	Line   int // Line (0-based)
+	Column int // Byte offset within the line (0-based)
+}
+
+// Span is the part of the source a node covers, from Start up to but not
+// including End. It does not include the line break ending the node.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// NodeKind identifies what a line or group of lines of a file is
+type NodeKind int
+
+const (
+	NodeBlank        NodeKind = iota // Empty or whitespace-only line outside of a body
+	NodeComment                      // # or // comment
+	NodeDelimiter                    // ### line starting a request block
+	NodeFileVariable                 // @name = value
+	NodeImport                       // @import path
+	NodeMetadata                     // # @key value
+	NodePreScript                    // < {% script %} or < script.js
+	NodeRequestLine                  // Method, URL and version, with query continuation lines
+	NodeHeader                       // Name: value
+	NodeBody                         // Body lines
+	NodePostScript                   // > {% script %} or > script.js
+	NodeIgnored                      // Line the parser ignores, after a post-response script
+)
+
+var nodeKindNames = map[NodeKind]string{
+	NodeBlank:        "blank",
+	NodeComment:      "comment",
+	NodeDelimiter:    "delimiter",
+	NodeFileVariable: "file variable",
+	NodeImport:       "import",
+	NodeMetadata:     "metadata",
+	NodePreScript:    "pre-request script",
+	NodeRequestLine:  "request line",
+	NodeHeader:       "header",
+	NodeBody:         "body",
+	NodePostScript:   "post-response script",
+	NodeIgnored:      "ignored",
+}
+
+func (k NodeKind) String() string {
+	if name, ok := nodeKindNames[k]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Node is one or more consecutive lines of a file. Raw holds the lines
+// exactly as written, joined by "\n", so that printing the nodes of a file
+// in order reproduces it.
+type Node struct {
+	Kind NodeKind
+	Span Span
+	Raw  string
+
+	// Name is the name of a header or file variable, or the key of
+	// metadata (lowercase)
+	Name string
+	// Value is the value of a header, file variable or metadata, the
+	// request line with its continuations joined, the code of an inline
+	// script or the text of a body
+	Value string
+	// Path is the file of an import or of a script file reference
+	Path string
+}
+
+// Block is the part of a file from one ### delimiter up to the next.
+// Every block but the first starts with its delimiter.
+type Block struct {
+	Index int // Index of the block (0-based), as in ParseWarning.BlockIndex
+	Span  Span
+	Nodes []*Node
+
+	delimited bool // Another block follows, so the last line ends with a line break
+}
+
+// File is the syntax tree of an .http file
+type File struct {
+	Blocks []*Block
+}
+
+// First returns the first node of a kind, or nil
+func (b *Block) First(kind NodeKind) *Node {
+	for _, n := range b.Nodes {
+		if n.Kind == kind {
+			return n
+		}
+	}
+	return nil
+}
+
+// All returns the nodes of a kind
+func (b *Block) All(kind NodeKind) []*Node {
+	var nodes []*Node
+	for _, n := range b.Nodes {
+		if n.Kind == kind {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// Metadata returns the last # @key node of a block, or nil
+func (b *Block) Metadata(key string) *Node {
+	var found *Node
+	for _, n := range b.Nodes {
+		if n.Kind == NodeMetadata && n.Name == key {
+			found = n
+		}
+	}
+	return found
+}
+
+// IsEmpty reports whether a block holds nothing but its delimiter and
+// blank lines
+func (b *Block) IsEmpty() bool {
+	for _, n := range b.Nodes {
+		if n.Kind != NodeDelimiter && n.Kind != NodeBlank {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse parses the content of an .http file into a syntax tree. Every line
+// belongs to exactly one node, so Print(Parse(content)) == content.
+func Parse(content string) *File {
+	lines := splitLines(content)
+	file := &File{}
+
+	// Content starting with a delimiter has an empty first block
+	if blockDelimiterRegex.MatchString(lines[0].text) {
+		file.Blocks = append(file.Blocks, &Block{})
+	}
+
+	start := 0
+	for i := 1; i <= len(lines); i++ {
+		if i < len(lines) && !blockDelimiterRegex.MatchString(lines[i].text) {
+			continue
+		}
+		block := parseBlock(len(file.Blocks), lines[start:i])
+		block.delimited = i < len(lines)
+		file.Blocks = append(file.Blocks, block)
+		start = i
+	}
+	return file
+}
+
+// Print prints a syntax tree back into text
+func Print(file *File) string {
+	var raw []string
+	for _, block := range file.Blocks {
+		for _, n := range block.Nodes {
+			raw = append(raw, n.Raw)
+		}
+	}
+	return strings.Join(raw, "\n")
+}
+
+// sourceLine is a line of a file without its line break
+type sourceLine struct {
+	text   string
+	offset int
+	number int
+}
+
+// splitLines splits content at "\n". A "\r" before it stays part of the
+// line, as ParseRequest has always treated it.
+func splitLines(content string) []sourceLine {
+	texts := strings.Split(content, "\n")
+	lines := make([]sourceLine, len(texts))
+	offset := 0
+	for i, text := range texts {
+		lines[i] = sourceLine{text: text, offset: offset, number: i}
+		offset += len(text) + 1
+	}
+	return lines
+}
+
+// fileVariableNodeRegex matches "@name = value" like the file variables of
+// the variables package
+var fileVariableNodeRegex = regexp.MustCompile(`^\s*@([^\s=]+)\s*=\s*(.*?)\s*$`)
+
+// blockBuilder groups the lines of a block into nodes
+type blockBuilder struct {
+	lines []sourceLine
+	block *Block
+	code  []string // Code lines of the inline script being read
+}
+
+// parseBlock builds the nodes of a block. Every block but the first starts
+// with its delimiter.
+func parseBlock(index int, lines []sourceLine) *Block {
+	b := &blockBuilder{lines: lines, block: &Block{Index: index}}
+	if len(lines) == 0 {
+		return b.block
+	}
+
+	first := 0
+	if index > 0 {
+		b.add(NodeDelimiter, 0)
+		first = 1
+	}
+	b.build(first)
+
+	nodes := b.block.Nodes
+	b.block.Span = Span{Start: nodes[0].Span.Start, End: nodes[len(nodes)-1].Span.End}
+	return b.block
+}
+
+// build classifies the lines of a block from first on. Lines are read the
+// way ParseRequest has always read them: scripts, metadata, comments, file
+// variables and imports before the request line, then headers up to a
+// blank line, then the body up to a post-response script.
+func (b *blockBuilder) build(first int) {
+	state := ParseStateURL
+	foundRequestLine := false
+	inPreScript := false
+	inPostScript := false
+
+	for i := first; i < len(b.lines); i++ {
+		line := b.lines[i].text
+		trimmedLine := strings.TrimSpace(line)
+
+		// Pre-request script file reference: < ./script.js
+		if !foundRequestLine && strings.HasPrefix(trimmedLine, "<") && !strings.HasPrefix(trimmedLine, "< {%") {
+			if scriptPath := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "<")); strings.HasSuffix(scriptPath, ".js") {
+				b.add(NodePreScript, i).Path = scriptPath
+				continue
+			}
+		}
+
+		// Pre-request script start: < {%
+		if !foundRequestLine && strings.HasPrefix(trimmedLine, "< {%") {
+			inPreScript = b.startScript(NodePreScript, i, strings.TrimPrefix(trimmedLine, "< {%"))
+			continue
+		}
+
+		if inPreScript {
+			inPreScript = b.scriptLine(NodePreScript, i)
+			continue
+		}
+
+		// Post-response script file reference: > ./script.js
+		if foundRequestLine && strings.HasPrefix(trimmedLine, ">") && !strings.HasPrefix(trimmedLine, "> {%") {
+			if scriptPath := strings.TrimSpace(strings.TrimPrefix(trimmedLine, ">")); strings.HasSuffix(scriptPath, ".js") {
+				b.add(NodePostScript, i).Path = scriptPath
+				state = ParseStatePostScript
+				continue
+			}
+		}
+
+		// Post-response script start: > {%
+		if foundRequestLine && strings.HasPrefix(trimmedLine, "> {%") {
+			inPostScript = b.startScript(NodePostScript, i, strings.TrimPrefix(trimmedLine, "> {%"))
+			state = ParseStatePostScript
+			continue
+		}
+
+		if inPostScript {
+			inPostScript = b.scriptLine(NodePostScript, i)
+			continue
+		}
+
+		// Nothing but scripts follows a post-response script
+		if state == ParseStatePostScript {
+			switch {
+			case trimmedLine == "":
+				b.add(NodeBlank, i)
+			case isComment(trimmedLine):
+				b.add(NodeComment, i)
+			default:
+				b.add(NodeIgnored, i)
+			}
+			continue
+		}
+
+		if !foundRequestLine && trimmedLine == "" {
+			b.add(NodeBlank, i)
+			continue
+		}
+
+		// Metadata comments (# @name, // @name, etc.) apply anywhere
+		if meta, ok := parseMetadata(trimmedLine); ok {
+			for key, value := range meta {
+				if key == "import" {
+					b.add(NodeImport, i).Path = value
+					continue
+				}
+				n := b.add(NodeMetadata, i)
+				n.Name, n.Value = key, value
+			}
+			continue
+		}
+
+		if !foundRequestLine && isComment(trimmedLine) {
+			b.add(NodeComment, i)
+			continue
+		}
+
+		if !foundRequestLine && isFileVariable(trimmedLine) {
+			n := b.add(NodeFileVariable, i)
+			if matches := fileVariableNodeRegex.FindStringSubmatch(trimmedLine); matches != nil {
+				n.Name, n.Value = matches[1], matches[2]
+			}
+			continue
+		}
+
+		if !foundRequestLine && isImport(trimmedLine) {
+			b.add(NodeImport, i).Path = importRegex.FindStringSubmatch(trimmedLine)[1]
+			continue
+		}
+
+		switch state {
+		case ParseStateURL:
+			var n *Node
+			if !foundRequestLine {
+				n = b.add(NodeRequestLine, i)
+				n.Value = trimmedLine
+				foundRequestLine = true
+			} else {
+				// Only query string continuations stay in this state
+				n = b.extend(i)
+				n.Value += trimmedLine
+			}
+
+			nextLine := ""
+			if i+1 < len(b.lines) {
+				nextLine = strings.TrimSpace(b.lines[i+1].text)
+			}
+			switch {
+			case isQueryStringContinuation(nextLine):
+			case nextLine == "":
+				// A blank line means the body follows
+				if i+1 < len(b.lines) {
+					i++
+					b.add(NodeBlank, i)
+				}
+				state = ParseStateBody
+			default:
+				state = ParseStateHeader
+			}
+
+		case ParseStateHeader:
+			switch {
+			case trimmedLine == "":
+				b.add(NodeBlank, i)
+				state = ParseStateBody
+			case isComment(trimmedLine):
+				b.add(NodeComment, i)
+			default:
+				n := b.add(NodeHeader, i)
+				if name, value, ok := strings.Cut(trimmedLine, ":"); ok {
+					n.Name, n.Value = strings.TrimSpace(name), strings.TrimSpace(value)
+				} else {
+					n.Name = trimmedLine
+				}
+			}
+
+		case ParseStateBody:
+			var n *Node
+			if last := b.last(); last != nil && last.Kind == NodeBody {
+				n = b.extend(i)
+			} else {
+				n = b.add(NodeBody, i)
+			}
+			n.Value = n.Raw
+		}
+	}
+}
+
+// startScript adds the node of a script starting at line i and reports
+// whether the script continues on the next lines
+func (b *blockBuilder) startScript(kind NodeKind, i int, rest string) bool {
+	n := b.add(kind, i)
+	b.code = nil
+	if strings.Contains(rest, "%}") {
+		n.Value = strings.TrimSpace(strings.TrimSuffix(rest, "%}"))
+		return false
+	}
+	return true
+}
+
+// scriptLine adds line i to the inline script being read and reports
+// whether the script continues after it
+func (b *blockBuilder) scriptLine(kind NodeKind, i int) bool {
+	var n *Node
+	if last := b.last(); last.Kind == kind && last.Path == "" {
+		n = b.extend(i)
+	} else {
+		// A script file reference came in between
+		n = b.add(kind, i)
+		b.code = nil
+	}
+
+	line := b.lines[i].text
+	trimmedLine := strings.TrimSpace(line)
+	open := true
+	if strings.Contains(trimmedLine, "%}") {
+		if beforeEnd := strings.Split(trimmedLine, "%}")[0]; strings.TrimSpace(beforeEnd) != "" {
+			b.code = append(b.code, beforeEnd)
+		}
+		open = false
+	} else {
+		b.code = append(b.code, line)
+	}
+	n.Value = strings.Join(b.code, "\n")
+	return open
+}
+
+// add adds a node for line i
+func (b *blockBuilder) add(kind NodeKind, i int) *Node {
+	line := b.lines[i]
+	n := &Node{
+		Kind: kind,
+		Raw:  line.text,
+		Span: Span{
+			Start: Position{Offset: line.offset, Line: line.number},
+			End:   Position{Offset: line.offset + len(line.text), Line: line.number, Column: len(line.text)},
+		},
+	}
+	b.block.Nodes = append(b.block.Nodes, n)
+	return n
+}
+
+// extend extends the last node to line i
+func (b *blockBuilder) extend(i int) *Node {
+	line := b.lines[i]
+	n := b.last()
+	n.Raw += "\n" + line.text
+	n.Span.End = Position{Offset: line.offset + len(line.text), Line: line.number, Column: len(line.text)}
+	return n
+}
+
+// last returns the last node added, or nil
+func (b *blockBuilder) last() *Node {
+	if len(b.block.Nodes) == 0 {
+		return nil
+	}
+	return b.block.Nodes[len(b.block.Nodes)-1]
+}
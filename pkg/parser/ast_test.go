@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const astSample = `@baseUrl = https://api.example.com
+# @import ./auth.http
+
+# @name login
+< {%
+  request.variables.set("ts", Date.now())
+%}
+POST {{baseUrl}}/login
+  ?verbose=true
+Content-Type: application/json
+# a comment
+
+{
+  "user": "me"
+}
+
+> {% client.global.set("token", response.body.token) %}
+
+### users
+GET {{baseUrl}}/users
+`
+
+func TestParseRoundTrip(t *testing.T) {
+	tests := []string{
+		astSample,
+		"",
+		"\n",
+		"GET https://api.example.com",
+		"###\nGET /1\n###\n###",
+		"GET /1\r\nAccept: a\r\n\r\nbody\r\n",
+		"  # indented\n\t@var = 1\nGET /1\n\n\n",
+		"< {%\nunterminated",
+	}
+
+	for _, content := range tests {
+		if got := Print(Parse(content)); got != content {
+			t.Errorf("Print(Parse(%q)) = %q", content, got)
+		}
+	}
+}
+
+func TestParseNodes(t *testing.T) {
+	file := Parse(astSample)
+	if len(file.Blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2", len(file.Blocks))
+	}
+
+	type node struct {
+		kind      NodeKind
+		startLine int
+		endLine   int
+		name      string
+		value     string
+	}
+	want := []node{
+		{NodeFileVariable, 0, 0, "baseUrl", "https://api.example.com"},
+		{NodeImport, 1, 1, "", ""},
+		{NodeBlank, 2, 2, "", ""},
+		{NodeMetadata, 3, 3, "name", "login"},
+		{NodePreScript, 4, 6, "", `  request.variables.set("ts", Date.now())`},
+		{NodeRequestLine, 7, 8, "", "POST {{baseUrl}}/login?verbose=true"},
+		{NodeHeader, 9, 9, "Content-Type", "application/json"},
+		{NodeComment, 10, 10, "", ""},
+		{NodeBlank, 11, 11, "", ""},
+		{NodeBody, 12, 15, "", "{\n  \"user\": \"me\"\n}\n"},
+		{NodePostScript, 16, 16, "", `client.global.set("token", response.body.token)`},
+		{NodeBlank, 17, 17, "", ""},
+	}
+
+	nodes := file.Blocks[0].Nodes
+	if len(nodes) != len(want) {
+		t.Fatalf("got %d nodes, want %d", len(nodes), len(want))
+	}
+	for i, w := range want {
+		n := nodes[i]
+		if n.Kind != w.kind || n.Span.Start.Line != w.startLine || n.Span.End.Line != w.endLine || n.Name != w.name || n.Value != w.value {
+			t.Errorf("node %d = %s lines %d-%d %q %q, want %s lines %d-%d %q %q", i,
+				n.Kind, n.Span.Start.Line, n.Span.End.Line, n.Name, n.Value,
+				w.kind, w.startLine, w.endLine, w.name, w.value)
+		}
+		if got := astSample[n.Span.Start.Offset:n.Span.End.Offset]; got != n.Raw {
+			t.Errorf("node %d span covers %q, want %q", i, got, n.Raw)
+		}
+	}
+	if imp := nodes[1]; imp.Path != "./auth.http" {
+		t.Errorf("import path = %q", imp.Path)
+	}
+
+	users := file.Blocks[1]
+	if users.Index != 1 || users.Nodes[0].Kind != NodeDelimiter || users.Span.Start.Line != 18 {
+		t.Errorf("second block = index %d starting at line %d with %s", users.Index, users.Span.Start.Line, users.Nodes[0].Kind)
+	}
+	if line := users.First(NodeRequestLine); line == nil || line.Span.Start.Line != 19 {
+		t.Errorf("second request line = %+v", line)
+	}
+}
+
+func TestParseLeadingDelimiter(t *testing.T) {
+	file := Parse("###\nGET /1")
+	if len(file.Blocks) != 2 || len(file.Blocks[0].Nodes) != 0 || !file.Blocks[0].IsEmpty() {
+		t.Fatalf("content starting with ### should have an empty first block, got %+v", file.Blocks)
+	}
+	if file.Blocks[1].First(NodeRequestLine) == nil {
+		t.Error("second block has no request line")
+	}
+}
+
+func TestParseBlockWarningLines(t *testing.T) {
+	content := `GET https://api.example.com/1
+
+###
+
+# @timeout soon
+FETCH https://api.example.com/2
+Accept application/json
+`
+	p := NewHttpRequestParser(content, nil, "")
+	block := Parse(content).Blocks[1]
+	req, warnings, err := p.ParseBlock(block)
+	if err != nil {
+		t.Fatalf("ParseBlock() error = %v", err)
+	}
+
+	wantLines := []int{4, 5, 6}
+	if len(warnings) != len(wantLines) {
+		t.Fatalf("got %d warnings, want %d: %v", len(warnings), len(wantLines), warnings)
+	}
+	for i, w := range warnings {
+		if w.Line != wantLines[i] || w.BlockIndex != 1 || w.Message != req.Warnings[i] {
+			t.Errorf("warning %d = line %d block %d %q, want line %d", i, w.Line, w.BlockIndex, w.Message, wantLines[i])
+		}
+	}
+	if w := warnings[2]; w.Span.Start.Column != 0 || w.Span.End.Column != len("Accept application/json") {
+		t.Errorf("header warning span = %+v", w.Span)
+	}
+}
+
+func TestParseAllWithWarningsLines(t *testing.T) {
+	content := `# @name a
+GET https://api.example.com/1
+
+###
+# only a comment
+
+###
+
+# @name a
+GET https://api.example.com/2`
+
+	result := NewHttpRequestParser(content, nil, "").ParseAllWithWarnings()
+
+	lines := map[string]int{}
+	for _, w := range result.Warnings {
+		switch {
+		case strings.Contains(w.Message, "skipped invalid request block"):
+			lines["skipped"] = w.Line
+		case strings.Contains(w.Message, "duplicate @name"):
+			lines["duplicate"] = w.Line
+		}
+	}
+	if lines["skipped"] != 4 {
+		t.Errorf("skipped block warning at line %d, want 4", lines["skipped"])
+	}
+	if lines["duplicate"] != 0 {
+		t.Errorf("duplicate name warning at line %d, want 0", lines["duplicate"])
+	}
+}
+
+func TestParseRequestHeadersAfterContinuation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "query continuation",
+			input: "GET https://api.example.com/users\n  ?page=1\n  &limit=10\nAccept: application/json\n\nbody",
+		},
+		{
+			name:  "comment after request line",
+			input: "GET https://api.example.com/users?page=1&limit=10\n# JSON please\nAccept: application/json\n\nbody",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewHttpRequestParser(tt.input, map[string]string{}, "").ParseRequest(tt.input)
+			if err != nil {
+				t.Fatalf("ParseRequest() error = %v", err)
+			}
+			if req.URL != "https://api.example.com/users?page=1&limit=10" {
+				t.Errorf("URL = %q", req.URL)
+			}
+			if req.Headers["Accept"] != "application/json" {
+				t.Errorf("Headers = %v, want Accept", req.Headers)
+			}
+			if req.RawBody != "body" {
+				t.Errorf("RawBody = %q, want body", req.RawBody)
+			}
+		})
+	}
+}
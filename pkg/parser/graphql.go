@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -38,3 +39,47 @@ func createGraphQLBody(body string) string {
 
 	return result
 }
+
+// isGraphQLSubscription reports whether a payload built by createGraphQLBody
+// holds a subscription operation. Only the leading query is decoded, since
+// the variables may still hold unresolved {{variables}}.
+func isGraphQLSubscription(payload string) bool {
+	decoder := json.NewDecoder(strings.NewReader(payload))
+	var query string
+	for _, want := range []string{"{", "query", ""} {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		switch t := token.(type) {
+		case json.Delim:
+			if string(t) != want {
+				return false
+			}
+		case string:
+			if want == "" {
+				query = t
+			} else if t != want {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	// Skip leading comments and blank lines
+	for line := range strings.SplitSeq(query, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		return strings.HasPrefix(trimmed, "subscription") &&
+			(len(trimmed) == len("subscription") || !isNameChar(trimmed[len("subscription")]))
+	}
+	return false
+}
+
+// isNameChar reports whether c may appear in a GraphQL name
+func isNameChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -1,6 +1,6 @@
 // Package parser provides functionality for parsing .http and .rest files
-// into structured HTTP request models with support for variables, multipart
-// forms, and embedded scripts.
+// into a lossless syntax tree and into structured HTTP request models with
+// support for variables, multipart forms, and embedded scripts.
 package parser
 
 import (
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/ideaspaper/restclient/internal/constants"
 	"github.com/ideaspaper/restclient/internal/httputil"
@@ -30,7 +31,8 @@ const (
 // ParseWarning represents a warning generated during parsing
 type ParseWarning struct {
 	BlockIndex int    // Index of the block (0-based)
-	Line       int    // Line number within the block (0-based)
+	Line       int    // Line of the file (0-based)
+	Span       Span   // Source the warning is about
 	Message    string // Warning message
 }
 
@@ -45,6 +47,7 @@ type HttpRequestParser struct {
 	content        string
 	defaultHeaders map[string]string
 	baseDir        string
+	sourceFile     string
 	warnings       []ParseWarning
 }
 
@@ -63,11 +66,17 @@ func NewHttpRequestParser(content string, defaultHeaders map[string]string, base
 	}
 }
 
-// addWarning adds a warning to the parser's warning list
-func (p *HttpRequestParser) addWarning(blockIndex, line int, message string) {
+// SetSourceFile sets the path recorded as the source file of parsed requests
+func (p *HttpRequestParser) SetSourceFile(path string) {
+	p.sourceFile = path
+}
+
+// addWarning adds a warning about a node to the parser's warning list
+func (p *HttpRequestParser) addWarning(blockIndex int, span Span, message string) {
 	p.warnings = append(p.warnings, ParseWarning{
 		BlockIndex: blockIndex,
-		Line:       line,
+		Line:       span.Start.Line,
+		Span:       span,
 		Message:    message,
 	})
 }
@@ -91,21 +100,22 @@ type DuplicateName struct {
 
 // ParseAllWithWarnings parses all requests and returns warnings for invalid blocks
 func (p *HttpRequestParser) ParseAllWithWarnings() *ParseResult {
-	blocks := splitRequestBlocks(p.content)
+	file := Parse(p.content)
 	var requests []*models.HttpRequest
 	p.warnings = []ParseWarning{} // Reset warnings
 
 	// Track names to detect duplicates
 	nameToRequests := make(map[string][]DuplicateName)
+	var requestBlocks []*Block // Block of each request
 
-	for i, block := range blocks {
-		if strings.TrimSpace(block) == "" {
+	for _, block := range file.Blocks {
+		if block.IsEmpty() {
 			continue
 		}
-		req, err := p.ParseRequest(block)
+		req, _, err := p.ParseBlock(block)
 		if err != nil {
 			// Collect warning instead of silently skipping
-			p.addWarning(i, 0, fmt.Sprintf("skipped invalid request block: %v", err))
+			p.addWarning(block.Index, firstContent(block).Span, fmt.Sprintf("skipped invalid request block: %v", err))
 			continue
 		}
 
@@ -124,16 +134,35 @@ func (p *HttpRequestParser) ParseAllWithWarnings() *ParseResult {
 		}
 
 		requests = append(requests, req)
+		requestBlocks = append(requestBlocks, block)
+	}
+
+	// # @openapi applies to the whole file
+	for _, block := range file.Blocks {
+		if n := block.Metadata("openapi"); n != nil {
+			spec := p.resolvePath(n.Value)
+			for _, req := range requests {
+				if req.Metadata.OpenAPI == "" {
+					req.Metadata.OpenAPI = spec
+				}
+			}
+			break
+		}
 	}
 
-	// Add warnings for duplicate names
+	// Add warnings for duplicate names, at the first @name
 	for name, dupes := range nameToRequests {
 		if len(dupes) > 1 {
 			var details []string
 			for _, d := range dupes {
 				details = append(details, fmt.Sprintf("request %d: %s %s", d.Index+1, d.Method, d.URL))
 			}
-			p.addWarning(dupes[0].Index, 0, fmt.Sprintf(
+			block := requestBlocks[dupes[0].Index]
+			node := block.Metadata("name")
+			if node == nil {
+				node = block.First(NodeRequestLine)
+			}
+			p.addWarning(block.Index, node.Span, fmt.Sprintf(
 				"duplicate @name '%s' found in %d requests (%s). First match will be used when selecting by name",
 				name, len(dupes), strings.Join(details, "; ")))
 		}
@@ -145,6 +174,17 @@ func (p *HttpRequestParser) ParseAllWithWarnings() *ParseResult {
 	}
 }
 
+// firstContent returns the first node of a non-empty block that is not
+// its delimiter or a blank line
+func firstContent(block *Block) *Node {
+	for _, n := range block.Nodes {
+		if n.Kind != NodeDelimiter && n.Kind != NodeBlank {
+			return n
+		}
+	}
+	return nil
+}
+
 // FindDuplicateNames returns a map of duplicate names to their occurrences
 func FindDuplicateNames(requests []*models.HttpRequest) map[string][]DuplicateName {
 	nameToRequests := make(map[string][]DuplicateName)
@@ -175,205 +215,61 @@ func FindDuplicateNames(requests []*models.HttpRequest) map[string][]DuplicateNa
 	return duplicates
 }
 
+// blockDelimiterRegex matches ### delimiters (3 or more # characters,
+// optionally followed by text)
+var blockDelimiterRegex = regexp.MustCompile(`(?m)^#{3,}.*$`)
+
 // splitRequestBlocks splits content by ### delimiter
 func splitRequestBlocks(content string) []string {
-	// Split by ### delimiter (3 or more # characters, optionally followed by text)
-	re := regexp.MustCompile(`(?m)^#{3,}.*$`)
-	parts := re.Split(content, -1)
-	return parts
+	return blockDelimiterRegex.Split(content, -1)
 }
 
 // ParseRequest parses a single HTTP request from text
 func (p *HttpRequestParser) ParseRequest(rawText string) (*models.HttpRequest, error) {
-	lines := strings.Split(rawText, "\n")
+	req, _, err := p.ParseBlock(parseBlock(0, splitLines(rawText)))
+	return req, err
+}
 
-	var requestLines []string
-	var headerLines []string
+// ParseBlock builds the request of a block of a syntax tree. The warnings
+// about the request are returned located at the lines they concern, and
+// are also set as the warnings of the request.
+func (p *HttpRequestParser) ParseBlock(block *Block) (*models.HttpRequest, []ParseWarning, error) {
+	var requestLine *Node
+	var headerNodes []*Node
+	var bodyNodes []*Node
 	var bodyLines []string
 	var preScriptLines []string
 	var postScriptLines []string
 	var metadata models.RequestMetadata
+	var metadataWarnings []ParseWarning
 
-	state := ParseStateURL
-	foundRequestLine := false
-	inPreScript := false
-	inPostScript := false
-
-	for i := 0; i < len(lines); i++ {
-		line := lines[i]
-		trimmedLine := strings.TrimSpace(line)
-
-		// Check for pre-request script file reference: < ./script.js (not < {% which is inline)
-		if !foundRequestLine && strings.HasPrefix(trimmedLine, "<") && !strings.HasPrefix(trimmedLine, "< {%") {
-			scriptPath := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "<"))
-			// Check if it looks like a script file path (ends with .js)
-			if strings.HasSuffix(scriptPath, ".js") {
-				content, err := p.readFileContent(scriptPath, "")
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to read pre-request script file '%s': %v\n", scriptPath, err)
-				} else {
-					preScriptLines = append(preScriptLines, content)
-				}
-				continue
-			}
-		}
-
-		// Check for pre-request script start: < {%
-		if !foundRequestLine && strings.HasPrefix(trimmedLine, "< {%") {
-			inPreScript = true
-			// Check if script content is on the same line
-			rest := strings.TrimPrefix(trimmedLine, "< {%")
-			if strings.Contains(rest, "%}") {
-				// Single line script
-				scriptContent := strings.TrimSuffix(rest, "%}")
-				preScriptLines = append(preScriptLines, strings.TrimSpace(scriptContent))
-				inPreScript = false
-			}
-			continue
-		}
-
-		// Check for pre-script end
-		if inPreScript {
-			if strings.Contains(trimmedLine, "%}") {
-				// End of pre-script
-				beforeEnd := strings.Split(trimmedLine, "%}")[0]
-				if strings.TrimSpace(beforeEnd) != "" {
-					preScriptLines = append(preScriptLines, beforeEnd)
-				}
-				inPreScript = false
-			} else {
-				preScriptLines = append(preScriptLines, line)
-			}
-			continue
-		}
-
-		// Check for post-response script file reference: > ./script.js (not > {% which is inline)
-		if foundRequestLine && strings.HasPrefix(trimmedLine, ">") && !strings.HasPrefix(trimmedLine, "> {%") {
-			scriptPath := strings.TrimSpace(strings.TrimPrefix(trimmedLine, ">"))
-			// Check if it looks like a script file path (ends with .js)
-			if strings.HasSuffix(scriptPath, ".js") {
-				content, err := p.readFileContent(scriptPath, "")
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to read post-response script file '%s': %v\n", scriptPath, err)
-				} else {
-					postScriptLines = append(postScriptLines, content)
-				}
-				state = ParseStatePostScript
-				continue
-			}
-		}
-
-		// Check for post-response script start: > {%
-		if foundRequestLine && strings.HasPrefix(trimmedLine, "> {%") {
-			inPostScript = true
-			state = ParseStatePostScript
-			// Check if script content is on the same line
-			rest := strings.TrimPrefix(trimmedLine, "> {%")
-			if strings.Contains(rest, "%}") {
-				// Single line script
-				scriptContent := strings.TrimSuffix(rest, "%}")
-				postScriptLines = append(postScriptLines, strings.TrimSpace(scriptContent))
-				inPostScript = false
-			}
-			continue
-		}
-
-		// Check for post-script end
-		if inPostScript {
-			if strings.Contains(trimmedLine, "%}") {
-				// End of post-script
-				beforeEnd := strings.Split(trimmedLine, "%}")[0]
-				if strings.TrimSpace(beforeEnd) != "" {
-					postScriptLines = append(postScriptLines, beforeEnd)
-				}
-				inPostScript = false
-			} else {
-				postScriptLines = append(postScriptLines, line)
-			}
-			continue
-		}
-
-		// Skip if we're in post-script state but not inside a script block
-		if state == ParseStatePostScript && !inPostScript {
-			continue
-		}
-
-		// Skip empty lines at the beginning
-		if !foundRequestLine && trimmedLine == "" {
-			continue
-		}
-
-		// Check for metadata comments (# @name, // @name, etc.)
-		if meta, ok := parseMetadata(trimmedLine); ok {
-			applyMetadata(&metadata, meta)
-			continue
-		}
-
-		// Skip regular comments at the beginning
-		if !foundRequestLine && isComment(trimmedLine) {
-			continue
-		}
-
-		// Skip file variable declarations (@varName = value)
-		if !foundRequestLine && isFileVariable(trimmedLine) {
-			continue
+	warn := func(warnings []ParseWarning, n *Node, messages []string) []ParseWarning {
+		for _, message := range messages {
+			warnings = append(warnings, ParseWarning{BlockIndex: block.Index, Line: n.Span.Start.Line, Span: n.Span, Message: message})
 		}
+		return warnings
+	}
 
-		nextLine := ""
-		if i+1 < len(lines) {
-			nextLine = strings.TrimSpace(lines[i+1])
-		}
-
-		switch state {
-		case ParseStateURL:
-			if !foundRequestLine && trimmedLine != "" && !isComment(trimmedLine) {
-				requestLines = append(requestLines, trimmedLine)
-				foundRequestLine = true
-
-				// Check if next line is a query string continuation
-				if isQueryStringContinuation(nextLine) {
-					continue
-				}
-
-				// Check if there's more content
-				if nextLine == "" {
-					// Empty line means body follows (skip the blank line)
-					i++
-					state = ParseStateBody
-				} else if !isComment(nextLine) {
-					state = ParseStateHeader
-				}
-			} else if isQueryStringContinuation(trimmedLine) {
-				// Query string continuation
-				requestLines = append(requestLines, trimmedLine)
-			}
-
-		case ParseStateHeader:
-			if trimmedLine == "" {
-				state = ParseStateBody
-			} else if !isComment(trimmedLine) {
-				headerLines = append(headerLines, trimmedLine)
-			}
-
-		case ParseStateBody:
-			// Check if this line starts a post-script
-			if strings.HasPrefix(trimmedLine, "> {%") {
-				inPostScript = true
-				state = ParseStatePostScript
-				rest := strings.TrimPrefix(trimmedLine, "> {%")
-				if strings.Contains(rest, "%}") {
-					scriptContent := strings.TrimSuffix(rest, "%}")
-					postScriptLines = append(postScriptLines, strings.TrimSpace(scriptContent))
-					inPostScript = false
-				}
-				continue
-			}
-			bodyLines = append(bodyLines, line) // Preserve original line with whitespace
+	for _, n := range block.Nodes {
+		switch n.Kind {
+		case NodePreScript:
+			preScriptLines = append(preScriptLines, p.scriptCode(n, "pre-request")...)
+		case NodePostScript:
+			postScriptLines = append(postScriptLines, p.scriptCode(n, "post-response")...)
+		case NodeMetadata:
+			metadataWarnings = warn(metadataWarnings, n, applyMetadata(&metadata, map[string]string{n.Name: n.Value}))
+		case NodeRequestLine:
+			requestLine = n
+		case NodeHeader:
+			headerNodes = append(headerNodes, n)
+		case NodeBody:
+			bodyNodes = append(bodyNodes, n)
+			bodyLines = append(bodyLines, strings.Split(n.Value, "\n")...)
 		}
 	}
 
-	if !foundRequestLine {
-		return nil, errors.NewParseError("", 0, "no request line found")
+	if requestLine == nil {
+		return nil, nil, errors.NewParseError("", 0, "no request line found")
 	}
 
 	// Set scripts in metadata
@@ -385,18 +281,21 @@ func (p *HttpRequestParser) ParseRequest(rawText string) (*models.HttpRequest, e
 	}
 
 	// Parse request line
-	reqLineResult := parseRequestLine(strings.Join(requestLines, ""))
+	reqLineResult := parseRequestLine(requestLine.Value)
 	method := reqLineResult.Method
 	url := reqLineResult.URL
 
-	// Collect request line warnings (will be added to parser warnings later if needed)
-	var requestWarnings []string
-	requestWarnings = append(requestWarnings, reqLineResult.Warnings...)
+	warnings := metadataWarnings
+	warnings = warn(warnings, requestLine, reqLineResult.Warnings)
 
-	// Parse headers
-	headersResult := parseHeadersWithWarnings(headerLines, p.defaultHeaders, url)
-	headers := headersResult.Headers
-	requestWarnings = append(requestWarnings, headersResult.Warnings...)
+	// Parse headers, locating the warnings of each header at its line
+	var headerLines []string
+	for _, n := range headerNodes {
+		line := strings.TrimSpace(n.Raw)
+		headerLines = append(headerLines, line)
+		warnings = warn(warnings, n, parseHeadersWithWarnings([]string{line}, nil, url).Warnings)
+	}
+	headers := parseHeaders(headerLines, p.defaultHeaders, url)
 
 	// Check for GraphQL request
 	isGraphQL := false
@@ -408,8 +307,10 @@ func (p *HttpRequestParser) ParseRequest(rawText string) (*models.HttpRequest, e
 		}
 	}
 
-	// Auto-detect GraphQL by URL path or content-type
-	if !isGraphQL {
+	// Auto-detect GraphQL by URL path or content-type; WebSocket and gRPC
+	// bodies are messages, not a query
+	isWebSocket := strings.EqualFold(method, models.MethodWebSocket) || strings.HasPrefix(url, "ws://") || strings.HasPrefix(url, "wss://")
+	if !isGraphQL && !isWebSocket && method != models.MethodGRPC {
 		if strings.HasSuffix(url, "/graphql") || strings.Contains(url, "/graphql?") {
 			contentType, _ := httputil.GetHeader(headers, constants.HeaderContentType)
 			if contentType == "" || strings.Contains(contentType, constants.MIMEApplicationJSON) {
@@ -418,11 +319,25 @@ func (p *HttpRequestParser) ParseRequest(rawText string) (*models.HttpRequest, e
 		}
 	}
 
+	// A body running up to the next block keeps the line break before its
+	// delimiter, as the body of the last block keeps the one at the end of
+	// the file
+	if len(bodyNodes) > 0 && bodyNodes[len(bodyNodes)-1] == block.Nodes[len(block.Nodes)-1] && block.delimited {
+		bodyLines = append(bodyLines, "")
+	}
+
 	// Parse body
 	bodyResult := p.parseBodyWithWarnings(bodyLines, headers, isGraphQL)
 	body := bodyResult.Body
 	rawBody := bodyResult.RawBody
-	requestWarnings = append(requestWarnings, bodyResult.Warnings...)
+	if len(bodyNodes) > 0 {
+		warnings = warn(warnings, bodyNodes[0], bodyResult.Warnings)
+	}
+
+	// Subscriptions run over a WebSocket
+	if isGraphQL && isGraphQLSubscription(rawBody) {
+		metadata.GraphQLSubscription = true
+	}
 
 	// Handle Host header for relative URLs
 	if hostHeader, ok := httputil.GetHeader(headers, constants.HeaderHost); ok && strings.HasPrefix(url, "/") {
@@ -433,9 +348,16 @@ func (p *HttpRequestParser) ParseRequest(rawText string) (*models.HttpRequest, e
 		url = fmt.Sprintf("%s://%s%s", scheme, hostHeader, url)
 	}
 
+	// .proto and OpenAPI paths are relative to the .http file
+	metadata.Proto = p.resolvePath(metadata.Proto)
+	metadata.OpenAPI = p.resolvePath(metadata.OpenAPI)
+
 	req := models.NewHttpRequest(method, url, headers, body, rawBody, metadata.Name)
 	req.Metadata = metadata
-	req.Warnings = requestWarnings
+	for _, w := range warnings {
+		req.Warnings = append(req.Warnings, w.Message)
+	}
+	req.SourceFile = p.sourceFile
 
 	// Parse multipart parts if applicable
 	contentType, _ := httputil.GetHeader(headers, constants.HeaderContentType)
@@ -443,7 +365,32 @@ func (p *HttpRequestParser) ParseRequest(rawText string) (*models.HttpRequest, e
 		req.MultipartParts = p.parseMultipartParts(rawBody, contentType)
 	}
 
-	return req, nil
+	return req, warnings, nil
+}
+
+// scriptCode returns the code of a script node, reading script files
+// relative to the .http file
+func (p *HttpRequestParser) scriptCode(n *Node, kind string) []string {
+	if n.Path == "" {
+		if n.Value == "" {
+			return nil
+		}
+		return []string{n.Value}
+	}
+	content, err := p.readFileContent(n.Path, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read %s script file '%s': %v\n", kind, n.Path, err)
+		return nil
+	}
+	return []string{content}
+}
+
+// resolvePath makes a path relative to the .http file absolute
+func (p *HttpRequestParser) resolvePath(path string) string {
+	if path != "" && !filepath.IsAbs(path) && p.baseDir != "" {
+		return filepath.Join(p.baseDir, path)
+	}
+	return path
 }
 
 // parseMetadata checks if a line contains metadata and extracts it
@@ -464,8 +411,10 @@ func parseMetadata(line string) (map[string]string, bool) {
 	return map[string]string{key: value}, true
 }
 
-// applyMetadata applies parsed metadata to RequestMetadata
-func applyMetadata(metadata *models.RequestMetadata, meta map[string]string) {
+// applyMetadata applies parsed metadata to RequestMetadata, returning
+// warnings for values that could not be parsed
+func applyMetadata(metadata *models.RequestMetadata, meta map[string]string) []string {
+	var warnings []string
 	for k, v := range meta {
 		switch k {
 		case "name":
@@ -493,8 +442,35 @@ func applyMetadata(metadata *models.RequestMetadata, meta map[string]string) {
 				pv.IsPassword = true
 			}
 			metadata.Prompts = append(metadata.Prompts, pv)
-		}
+		case "retry":
+			warnings = append(warnings, parseRetryCount(&metadata.Retry, v)...)
+		case "retry-on":
+			warnings = append(warnings, parseRetryOn(&metadata.Retry, v)...)
+		case "retry-backoff":
+			warnings = append(warnings, parseRetryBackoff(&metadata.Retry, v)...)
+		case "timeout":
+			warnings = append(warnings, parseTimeout(&metadata.Timeout, k, v)...)
+		case "connect-timeout":
+			warnings = append(warnings, parseTimeout(&metadata.ConnectTimeout, k, v)...)
+		case "cache-ttl":
+			warnings = append(warnings, parseTimeout(&metadata.CacheTTL, k, v)...)
+		case "proto":
+			metadata.Proto = v
+		case "openapi":
+			metadata.OpenAPI = v
+		}
+	}
+	return warnings
+}
+
+// parseTimeout parses a duration such as "# @timeout 5s" or "# @cache-ttl 15m"
+func parseTimeout(timeout *time.Duration, key, value string) []string {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return []string{fmt.Sprintf("@%s expects a positive duration such as 5s or 500ms, got '%s'", key, value)}
 	}
+	*timeout = d
+	return nil
 }
 
 // isComment checks if a line is a comment
@@ -525,7 +501,7 @@ var validHTTPMethods = map[string]bool{
 	"HEAD": true, "OPTIONS": true, "CONNECT": true, "TRACE": true,
 	"LOCK": true, "UNLOCK": true, "PROPFIND": true, "PROPPATCH": true,
 	"COPY": true, "MOVE": true, "MKCOL": true, "MKCALENDAR": true,
-	"ACL": true, "SEARCH": true,
+	"ACL": true, "SEARCH": true, models.MethodWebSocket: true, models.MethodGRPC: true,
 }
 
 // parseRequestLineResult contains the parsed request line and any warnings
@@ -541,7 +517,7 @@ func parseRequestLine(line string) parseRequestLineResult {
 	line = strings.TrimSpace(line)
 
 	// Match HTTP method
-	methodRegex := regexp.MustCompile(`^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS|CONNECT|TRACE|LOCK|UNLOCK|PROPFIND|PROPPATCH|COPY|MOVE|MKCOL|MKCALENDAR|ACL|SEARCH)\s+`)
+	methodRegex := regexp.MustCompile(`^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS|CONNECT|TRACE|LOCK|UNLOCK|PROPFIND|PROPPATCH|COPY|MOVE|MKCOL|MKCALENDAR|ACL|SEARCH|WEBSOCKET|GRPC)\s+`)
 	if matches := methodRegex.FindStringSubmatch(strings.ToUpper(line)); matches != nil {
 		result.Method = matches[1]
 		line = line[len(matches[0]):]
@@ -732,6 +708,7 @@ func ParseFile(filePath string, defaultHeaders map[string]string) ([]*models.Htt
 
 	baseDir := filepath.Dir(filePath)
 	parser := NewHttpRequestParser(string(content), defaultHeaders, baseDir)
+	parser.SetSourceFile(filePath)
 	return parser.ParseAll()
 }
 
@@ -744,6 +721,7 @@ func ParseFileWithWarnings(filePath string, defaultHeaders map[string]string) (*
 
 	baseDir := filepath.Dir(filePath)
 	parser := NewHttpRequestParser(string(content), defaultHeaders, baseDir)
+	parser.SetSourceFile(filePath)
 	return parser.ParseAllWithWarnings(), nil
 }
 
@@ -64,6 +64,35 @@ func FuzzSplitRequestBlocks(f *testing.F) {
 	})
 }
 
+// FuzzParseRoundTrip tests that printing a syntax tree reproduces its input
+func FuzzParseRoundTrip(f *testing.F) {
+	seeds := []string{
+		"GET /1\n###\nGET /2",
+		"###\n###\n",
+		"# @name a\n< {%\nx\n%}\nPOST /1\n?a=1\nAccept: b\n\n{}\n> {% y %}\nrest",
+		"GET /1\r\nAccept: a\r\n\r\nbody",
+		"",
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		file := Parse(input)
+		if got := Print(file); got != input {
+			t.Errorf("Print(Parse(%q)) = %q", input, got)
+		}
+		for _, block := range file.Blocks {
+			for _, n := range block.Nodes {
+				if input[n.Span.Start.Offset:n.Span.End.Offset] != n.Raw {
+					t.Errorf("span of %s node does not cover %q", n.Kind, n.Raw)
+				}
+			}
+		}
+	})
+}
+
 // FuzzParseRequestLine tests request line parsing
 func FuzzParseRequestLine(f *testing.F) {
 	seeds := []string{
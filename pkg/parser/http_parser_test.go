@@ -2,8 +2,12 @@ package parser
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
 )
 
 func TestParseRequestLine(t *testing.T) {
@@ -362,6 +366,16 @@ GET https://api.example.com/users`,
 			wantMethod: "GET",
 			wantURL:    "https://api.example.com/users?page=1&limit=10",
 		},
+		{
+			name: "headers after query string continuation",
+			input: `GET https://api.example.com/users
+    ?page=1
+    &limit=10
+Accept: application/json`,
+			wantMethod:  "GET",
+			wantURL:     "https://api.example.com/users?page=1&limit=10",
+			wantHeaders: map[string]string{"Accept": "application/json"},
+		},
 		{
 			name: "with comments",
 			input: `# This is a request to get users
@@ -488,6 +502,37 @@ POST https://api.example.com/users`,
 	}
 }
 
+func TestParseAll_BodyLineBreaks(t *testing.T) {
+	content := `POST https://api.example.com/users
+Content-Type: application/json
+
+{}
+
+###
+
+POST https://api.example.com/users
+Content-Type: application/json
+
+{}
+`
+	parser := NewHttpRequestParser(content, nil, "")
+	requests, err := parser.ParseAll()
+	if err != nil {
+		t.Fatalf("ParseAll() error = %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("ParseAll() count = %d, want 2", len(requests))
+	}
+
+	// The line break before a delimiter stays part of the body
+	if requests[0].RawBody != "{}\n\n" {
+		t.Errorf("RawBody before delimiter = %q, want %q", requests[0].RawBody, "{}\n\n")
+	}
+	if requests[1].RawBody != "{}\n" {
+		t.Errorf("RawBody at end of file = %q, want %q", requests[1].RawBody, "{}\n")
+	}
+}
+
 func TestSplitRequestBlocks(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -670,6 +715,33 @@ subscription OnMessage {
 	if !strings.Contains(req.RawBody, `"operationName":"OnMessage"`) {
 		t.Errorf("GraphQL body should contain operationName, got: %v", req.RawBody)
 	}
+	if !req.Metadata.GraphQLSubscription {
+		t.Error("Expected GraphQLSubscription to be set")
+	}
+}
+
+func TestIsGraphQLSubscription(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{"named subscription", "subscription OnMessage {\n  messageAdded { id }\n}", true},
+		{"anonymous subscription", "subscription {\n  messageAdded { id }\n}", true},
+		{"after comments", "# Live updates\nsubscription {\n  messageAdded { id }\n}", true},
+		{"unresolved variables", "subscription ($id: ID!) {\n  messageAdded(id: $id) { id }\n}\n\n{\"id\": {{id}}}", true},
+		{"query", "query GetUser {\n  user { name }\n}", false},
+		{"query named subscription", "query subscriptions {\n  subscriptions { id }\n}", false},
+		{"field named subscriptions", "{\n  subscriptions { id }\n}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isGraphQLSubscription(createGraphQLBody(tt.body)); got != tt.want {
+				t.Errorf("isGraphQLSubscription() = %v, want %v", got, tt.want)
+			}
+		})
+	}
 }
 
 func TestGraphQLAutoDetectByURL(t *testing.T) {
@@ -1272,6 +1344,116 @@ GET https://api.example.com/users`
 	}
 }
 
+func TestRetryMetadata(t *testing.T) {
+	input := `# @retry 3
+# @retry-on 502, 503,504,timeout
+# @retry-backoff exponential 200ms
+GET https://api.example.com/users`
+
+	parser := NewHttpRequestParser(input, nil, "")
+	req, err := parser.ParseRequest(input)
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+
+	retry := req.Metadata.Retry
+	if retry.Retries != 3 {
+		t.Errorf("Retries = %d, want 3", retry.Retries)
+	}
+	if len(retry.StatusCodes) != 3 || retry.StatusCodes[0] != 502 || retry.StatusCodes[2] != 504 {
+		t.Errorf("StatusCodes = %v, want [502 503 504]", retry.StatusCodes)
+	}
+	if !retry.OnTimeout || retry.OnError {
+		t.Errorf("OnTimeout = %v, OnError = %v, want true, false", retry.OnTimeout, retry.OnError)
+	}
+	if retry.Backoff != "exponential" || retry.Delay != 200*time.Millisecond {
+		t.Errorf("Backoff = %q %v, want exponential 200ms", retry.Backoff, retry.Delay)
+	}
+	if len(req.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", req.Warnings)
+	}
+}
+
+func TestRetryMetadataWarnings(t *testing.T) {
+	input := `# @retry many
+# @retry-on 503,flaky,999
+# @retry-backoff fibonacci
+GET https://api.example.com/users`
+
+	parser := NewHttpRequestParser(input, nil, "")
+	req, err := parser.ParseRequest(input)
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+
+	if req.Metadata.Retry.Retries != 0 {
+		t.Errorf("invalid @retry should be ignored, got %d", req.Metadata.Retry.Retries)
+	}
+	if len(req.Metadata.Retry.StatusCodes) != 1 {
+		t.Errorf("valid @retry-on codes should be kept, got %v", req.Metadata.Retry.StatusCodes)
+	}
+	if len(req.Warnings) != 4 {
+		t.Errorf("expected 4 warnings, got %d: %v", len(req.Warnings), req.Warnings)
+	}
+}
+
+func TestTimeoutMetadata(t *testing.T) {
+	input := `# @timeout 5s
+# @connect-timeout 500ms
+GET https://api.example.com/users`
+
+	parser := NewHttpRequestParser(input, nil, "")
+	req, err := parser.ParseRequest(input)
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+
+	if req.Metadata.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want 5s", req.Metadata.Timeout)
+	}
+	if req.Metadata.ConnectTimeout != 500*time.Millisecond {
+		t.Errorf("ConnectTimeout = %v, want 500ms", req.Metadata.ConnectTimeout)
+	}
+	if len(req.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", req.Warnings)
+	}
+}
+
+func TestCacheTTLMetadata(t *testing.T) {
+	input := `# @name login
+# @cache-ttl 15m
+POST https://api.example.com/login`
+
+	parser := NewHttpRequestParser(input, nil, "")
+	req, err := parser.ParseRequest(input)
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+
+	if req.Metadata.CacheTTL != 15*time.Minute {
+		t.Errorf("CacheTTL = %v, want 15m", req.Metadata.CacheTTL)
+	}
+}
+
+func TestTimeoutMetadataWarnings(t *testing.T) {
+	input := `# @timeout 5
+# @connect-timeout -1s
+GET https://api.example.com/users`
+
+	parser := NewHttpRequestParser(input, nil, "")
+	req, err := parser.ParseRequest(input)
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+
+	if req.Metadata.Timeout != 0 || req.Metadata.ConnectTimeout != 0 {
+		t.Errorf("invalid timeouts should be ignored, got %v and %v", req.Metadata.Timeout, req.Metadata.ConnectTimeout)
+	}
+	if len(req.Warnings) != 2 {
+		t.Errorf("expected 2 warnings, got %d: %v", len(req.Warnings), req.Warnings)
+	}
+}
+
 func TestParseWarningContent(t *testing.T) {
 	input := `GET https://api.example.com/users
 
@@ -1485,6 +1667,22 @@ GET https://api.example.com/other`
 	}
 }
 
+func TestDuplicateNameWarningBlockIndex(t *testing.T) {
+	// The first block holds no request, so the first request is in block 1
+	content := "@host = x\n###\n# @name a\nGET /1\n###\n# @name a\nGET /2"
+
+	result := NewHttpRequestParser(content, nil, "").ParseAllWithWarnings()
+	for _, w := range result.Warnings {
+		if strings.Contains(w.Message, "duplicate @name 'a'") {
+			if w.BlockIndex != 1 {
+				t.Errorf("duplicate warning BlockIndex = %d, want 1", w.BlockIndex)
+			}
+			return
+		}
+	}
+	t.Error("Expected warning about duplicate @name 'a', but none found")
+}
+
 func TestDuplicateNameDetails(t *testing.T) {
 	content := `# @name test
 GET https://api.example.com/first
@@ -2272,3 +2470,148 @@ client.log("second response");
 		t.Errorf("Second request PostScript should contain 'second response': %q", second.Metadata.PostScript)
 	}
 }
+
+func TestWebSocketRequest(t *testing.T) {
+	input := `WEBSOCKET wss://api.example.com/graphql
+Authorization: Bearer token
+
+{"type": "subscribe"}
+===
+{"type": "ping"}`
+
+	parser := NewHttpRequestParser(input, nil, "")
+	result := parser.ParseAllWithWarnings()
+	if len(result.Requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(result.Requests))
+	}
+	req := result.Requests[0]
+
+	if req.Method != "WEBSOCKET" || !req.IsWebSocket() {
+		t.Errorf("Method = %q, want WEBSOCKET", req.Method)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", result.Warnings)
+	}
+	// The body is a list of messages, not a GraphQL query
+	if req.RawBody != "{\"type\": \"subscribe\"}\n===\n{\"type\": \"ping\"}" {
+		t.Errorf("RawBody = %q", req.RawBody)
+	}
+	if steps := req.WebSocketSteps(); len(steps) != 2 {
+		t.Errorf("expected 2 messages, got %+v", steps)
+	}
+}
+
+func TestGRPCRequest(t *testing.T) {
+	input := `# @proto ./protos/orders.proto
+GRPC localhost:50051/orders.v1.Orders/GetOrder
+Authorization: Bearer token
+
+{"id": "42"}`
+
+	parser := NewHttpRequestParser(input, nil, "/project/requests")
+	req, err := parser.ParseRequest(input)
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+
+	if req.Method != "GRPC" || req.URL != "localhost:50051/orders.v1.Orders/GetOrder" {
+		t.Errorf("request line = %s %s", req.Method, req.URL)
+	}
+	if req.Metadata.Proto != filepath.Join("/project/requests", "protos/orders.proto") {
+		t.Errorf("Proto = %q, want it relative to the .http file", req.Metadata.Proto)
+	}
+	if req.RawBody != `{"id": "42"}` {
+		t.Errorf("RawBody = %q", req.RawBody)
+	}
+}
+
+func TestOpenAPIMetadata(t *testing.T) {
+	input := `@baseUrl = https://api.example.com
+
+GET {{baseUrl}}/users
+
+###
+# @openapi ./spec.yaml
+GET {{baseUrl}}/users/1
+
+###
+# @openapi /specs/other.yaml
+GET {{baseUrl}}/teams`
+
+	requests, err := NewHttpRequestParser(input, nil, "/project").ParseAll()
+	if err != nil || len(requests) != 3 {
+		t.Fatalf("ParseAll() = %v, %v", requests, err)
+	}
+	want := []string{filepath.Join("/project", "spec.yaml"), filepath.Join("/project", "spec.yaml"), "/specs/other.yaml"}
+	for i, req := range requests {
+		if req.Metadata.OpenAPI != want[i] {
+			t.Errorf("request %d OpenAPI = %q, want %q", i+1, req.Metadata.OpenAPI, want[i])
+		}
+	}
+}
+
+func TestResolveImports(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	writeFile("shared/base.http", "@baseUrl = https://api.example.com\n")
+	writeFile("shared/auth.http", "# @import ./base.http\n\n# @name login\nPOST {{baseUrl}}/login\n")
+	writeFile("common.http", "@import ./shared/base.http\n// @import \"./shared/auth.http\"\n")
+	main := writeFile("main.http", "# @import ./common.http\n\nGET {{baseUrl}}/users\n")
+
+	content, _ := os.ReadFile(main)
+	files, err := ResolveImports(main, string(content))
+	if err != nil {
+		t.Fatalf("ResolveImports() error = %v", err)
+	}
+
+	// Each file once, after its own imports
+	var got []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := []string{"shared/base.http", "shared/auth.http", "common.http"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ResolveImports() = %v, want %v", got, want)
+	}
+
+	// The import lines are not mistaken for a request line
+	parser := NewHttpRequestParser(string(content), nil, dir)
+	requests, _ := parser.ParseAll()
+	if len(requests) != 1 || requests[0].URL != "{{baseUrl}}/users" {
+		t.Errorf("ParseAll() = %v, want the GET request only", requests)
+	}
+}
+
+func TestResolveImports_Errors(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.http")
+	b := filepath.Join(dir, "b.http")
+	os.WriteFile(a, []byte("# @import ./b.http\n"), 0644)
+	os.WriteFile(b, []byte("@baseUrl = x\n\n# @import ./a.http\n"), 0644)
+
+	_, err := ResolveImports(a, "# @import ./b.http\n")
+	var parseErr *errors.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("ResolveImports() error = %v, want a ParseError", err)
+	}
+	if parseErr.File != b || parseErr.Line != 3 || !strings.Contains(parseErr.Message, "a.http -> b.http -> a.http") {
+		t.Errorf("ParseError = %v, want the cycle at %s:3", parseErr, b)
+	}
+
+	_, err = ResolveImports(a, "\n# @import ./missing.http\n")
+	if !errors.As(err, &parseErr) || parseErr.Line != 2 || !strings.Contains(parseErr.Message, "missing.http") {
+		t.Errorf("ResolveImports() error = %v, want a ParseError at line 2", err)
+	}
+}
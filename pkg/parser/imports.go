@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// importRegex matches "# @import path", "// @import path" and "@import path"
+var importRegex = regexp.MustCompile(`^\s*(?:(?:#|//)\s*)?@import\s+([^=\s].*?)\s*$`)
+
+// ImportedFile is a file pulled in with @import
+type ImportedFile struct {
+	Path    string // Absolute path of the file
+	Content string
+}
+
+// isImport checks if a line is an @import directive
+func isImport(line string) bool {
+	return importRegex.MatchString(line)
+}
+
+// ResolveImports returns the files imported by the file at filePath,
+// directly or transitively. Each file appears once, after the files it
+// imports, so that later files override the variables of earlier ones.
+// Import paths are relative to the importing file. An import cycle is
+// reported as a ParseError at the @import line that closes it.
+func ResolveImports(filePath, content string) ([]ImportedFile, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, errors.NewParseErrorWithCause(filePath, 0, "failed to resolve path", err)
+	}
+
+	r := &importResolver{loaded: make(map[string]bool)}
+	if err := r.resolve(absPath, content); err != nil {
+		return nil, err
+	}
+	return r.files, nil
+}
+
+// importResolver walks @import directives depth first
+type importResolver struct {
+	stack  []string // Files being resolved, outermost first
+	loaded map[string]bool
+	files  []ImportedFile
+}
+
+// resolve loads the imports of one file
+func (r *importResolver) resolve(path, content string) error {
+	r.stack = append(r.stack, path)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	for i, line := range strings.Split(content, "\n") {
+		matches := importRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		target := strings.Trim(matches[1], `"'`)
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		target = filepath.Clean(target)
+
+		if cycle := r.cycle(target); cycle != "" {
+			return errors.NewParseError(path, i+1, "import cycle: "+cycle)
+		}
+		if r.loaded[target] {
+			continue
+		}
+
+		imported, err := os.ReadFile(target)
+		if err != nil {
+			return errors.NewParseErrorWithCause(path, i+1, "failed to import "+matches[1], err)
+		}
+		if err := r.resolve(target, string(imported)); err != nil {
+			return err
+		}
+		r.loaded[target] = true
+		r.files = append(r.files, ImportedFile{Path: target, Content: string(imported)})
+	}
+	return nil
+}
+
+// cycle describes the import chain back to target if target is already
+// being resolved, e.g. "a.http -> b.http -> a.http"
+func (r *importResolver) cycle(target string) string {
+	for i, path := range r.stack {
+		if path != target {
+			continue
+		}
+		var chain []string
+		for _, p := range r.stack[i:] {
+			chain = append(chain, filepath.Base(p))
+		}
+		return strings.Join(append(chain, filepath.Base(target)), " -> ")
+	}
+	return ""
+}
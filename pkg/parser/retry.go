@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// parseRetryCount parses "# @retry 3"
+func parseRetryCount(policy *models.RetryPolicy, value string) []string {
+	retries, err := strconv.Atoi(value)
+	if err != nil || retries < 0 {
+		return []string{fmt.Sprintf("@retry expects a non-negative number of retries, got '%s'", value)}
+	}
+	policy.Retries = retries
+	return nil
+}
+
+// parseRetryOn parses "# @retry-on 502,503,504,timeout". Besides status codes
+// it accepts "timeout" and "error" (connection errors).
+func parseRetryOn(policy *models.RetryPolicy, value string) []string {
+	var warnings []string
+	for _, token := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		switch strings.ToLower(token) {
+		case "timeout":
+			policy.OnTimeout = true
+		case "error":
+			policy.OnError = true
+		default:
+			code, err := strconv.Atoi(token)
+			if err != nil || code < 100 || code > 599 {
+				warnings = append(warnings, fmt.Sprintf("@retry-on: '%s' is not a status code, 'timeout' or 'error'", token))
+				continue
+			}
+			policy.StatusCodes = append(policy.StatusCodes, code)
+		}
+	}
+	return warnings
+}
+
+// parseRetryBackoff parses "# @retry-backoff exponential 200ms". The strategy
+// and the delay are both optional, e.g. "constant" or "1s".
+func parseRetryBackoff(policy *models.RetryPolicy, value string) []string {
+	var warnings []string
+	for _, field := range strings.Fields(value) {
+		switch strings.ToLower(field) {
+		case models.BackoffConstant, models.BackoffLinear, models.BackoffExponential:
+			policy.Backoff = strings.ToLower(field)
+		default:
+			delay, err := time.ParseDuration(field)
+			if err != nil || delay < 0 {
+				warnings = append(warnings, fmt.Sprintf("@retry-backoff: '%s' is not a strategy (constant, linear, exponential) or a delay such as 200ms", field))
+				continue
+			}
+			policy.Delay = delay
+		}
+	}
+	return warnings
+}
@@ -782,6 +782,12 @@ func convertScriptToPostman(script string) string {
 	// Convert client.log() to console.log()
 	result = strings.ReplaceAll(result, "client.log(", "console.log(")
 
+	// Convert client.execution flow control; stop() has no Postman equivalent
+	// other than clearing the next request
+	result = strings.ReplaceAll(result, "client.execution.setNextRequest(", "pm.execution.setNextRequest(")
+	result = strings.ReplaceAll(result, "client.execution.skipRequest(", "pm.execution.skipRequest(")
+	result = strings.ReplaceAll(result, "client.execution.stop()", "pm.execution.setNextRequest(null)")
+
 	// Convert client.global.set() to pm.globals.set()
 	result = strings.ReplaceAll(result, "client.global.set(", "pm.globals.set(")
 
@@ -735,6 +735,16 @@ func TestConvertScriptToPostman(t *testing.T) {
 			input:    `client.assert(response.status === 200);`,
 			expected: `pm.expect(pm.response.code === 200).to.be.true;`,
 		},
+		{
+			name:     "Convert client.execution.setNextRequest",
+			input:    `client.execution.setNextRequest("poll");`,
+			expected: `pm.execution.setNextRequest("poll");`,
+		},
+		{
+			name:     "Convert client.execution.stop",
+			input:    `client.execution.stop();`,
+			expected: `pm.execution.setNextRequest(null);`,
+		},
 		{
 			name:     "Convert client.log",
 			input:    `client.log("Hello world");`,
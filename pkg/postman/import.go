@@ -103,7 +103,7 @@ func ImportCollection(collection *Collection, opts ImportOptions) (*ImportResult
 		filePath := opts.OutputFile
 		if filePath == "" {
 			// Default: use collection name in current directory
-			filePath = filepath.Join(opts.OutputDir, sanitizeFileName(collection.Info.Name)+".http")
+			filePath = filepath.Join(opts.OutputDir, SanitizeFileName(collection.Info.Name)+".http")
 		}
 
 		// Create parent directory if needed
@@ -125,7 +125,7 @@ func ImportCollection(collection *Collection, opts ImportOptions) (*ImportResult
 		result.VariablesCount = len(collection.Variable)
 	} else {
 		// Create output directory with collection name as root folder
-		collectionDir := filepath.Join(opts.OutputDir, sanitizeFileName(collection.Info.Name))
+		collectionDir := filepath.Join(opts.OutputDir, SanitizeFileName(collection.Info.Name))
 		if err := os.MkdirAll(collectionDir, 0755); err != nil {
 			return nil, errors.Wrap(err, "failed to create output directory")
 		}
@@ -148,7 +148,7 @@ func processItems(collection *Collection, items []Item, currentDir string, rootD
 
 			if len(item.Item) > 0 {
 				// Create subdirectory for folder
-				folderDir := filepath.Join(currentDir, sanitizeFileName(item.Name))
+				folderDir := filepath.Join(currentDir, SanitizeFileName(item.Name))
 				if err := os.MkdirAll(folderDir, 0755); err != nil {
 					result.Errors = append(result.Errors, fmt.Sprintf("failed to create folder %s: %v", folderDir, err))
 					continue
@@ -191,7 +191,7 @@ func processItems(collection *Collection, items []Item, currentDir string, rootD
 			// Use parent folder name
 			fileName = filepath.Base(currentDir) + ".http"
 		} else if collection.Info.Name != "" {
-			fileName = sanitizeFileName(collection.Info.Name) + ".http"
+			fileName = SanitizeFileName(collection.Info.Name) + ".http"
 		}
 
 		filePath := filepath.Join(currentDir, fileName)
@@ -578,8 +578,8 @@ func writeBody(content *strings.Builder, body *Body) {
 	}
 }
 
-// sanitizeFileName makes a string safe for use as a filename
-func sanitizeFileName(name string) string {
+// SanitizeFileName makes a string safe for use as a filename
+func SanitizeFileName(name string) string {
 	// Replace invalid characters
 	invalid := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|"}
 	result := name
@@ -644,6 +644,12 @@ func convertScriptFromPostman(script string) string {
 	// Convert console.log() to client.log()
 	result = strings.ReplaceAll(result, "console.log(", "client.log(")
 
+	// Convert run flow control to client.execution; the legacy postman.* form
+	// is still common in older collections
+	result = strings.ReplaceAll(result, "postman.setNextRequest(", "client.execution.setNextRequest(")
+	result = strings.ReplaceAll(result, "pm.execution.setNextRequest(", "client.execution.setNextRequest(")
+	result = strings.ReplaceAll(result, "pm.execution.skipRequest(", "client.execution.skipRequest(")
+
 	// Convert pm.globals.set() to client.global.set()
 	result = strings.ReplaceAll(result, "pm.globals.set(", "client.global.set(")
 
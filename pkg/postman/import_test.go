@@ -758,8 +758,8 @@ func TestSanitizeFileName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			if got := sanitizeFileName(tt.input); got != tt.expected {
-				t.Errorf("sanitizeFileName(%q) = %q, want %q", tt.input, got, tt.expected)
+			if got := SanitizeFileName(tt.input); got != tt.expected {
+				t.Errorf("SanitizeFileName(%q) = %q, want %q", tt.input, got, tt.expected)
 			}
 		})
 	}
@@ -862,6 +862,21 @@ func TestConvertScriptFromPostman(t *testing.T) {
 			input:    `var str = Array(10).fill(0).map(() => Math.random().toString(36).charAt(2)).join('');`,
 			expected: `var str = $randomString(10);`,
 		},
+		{
+			name:     "Convert legacy postman.setNextRequest",
+			input:    `postman.setNextRequest("Poll job");`,
+			expected: `client.execution.setNextRequest("Poll job");`,
+		},
+		{
+			name:     "Convert pm.execution.setNextRequest null",
+			input:    `pm.execution.setNextRequest(null);`,
+			expected: `client.execution.setNextRequest(null);`,
+		},
+		{
+			name:     "Convert pm.execution.skipRequest",
+			input:    `pm.execution.skipRequest();`,
+			expected: `client.execution.skipRequest();`,
+		},
 		{
 			name:     "Convert btoa",
 			input:    `var encoded = btoa(data);`,
@@ -922,6 +937,8 @@ func TestScriptRoundTrip(t *testing.T) {
 		`client.global.set("key", response.body.id);`,
 		`var hash = $sha256(data);`,
 		`var id = $uuid();`,
+		`client.execution.setNextRequest("poll");`,
+		`client.execution.skipRequest();`,
 	}
 
 	for _, original := range originalScripts {
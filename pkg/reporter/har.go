@@ -0,0 +1,27 @@
+package reporter
+
+import (
+	"io"
+
+	"github.com/ideaspaper/restclient/pkg/har"
+)
+
+// HARReporter writes the requests and responses of a run as a HAR 1.2
+// archive, with the timing phases of each response
+type HARReporter struct{}
+
+// Report implements Reporter
+func (r *HARReporter) Report(w io.Writer, run *Run) error {
+	entries := make([]har.Entry, 0, len(run.Suites))
+	for _, suite := range run.Suites {
+		if suite.Request == nil {
+			continue
+		}
+		entry := har.NewEntry(suite.Request, suite.Response, suite.Timestamp)
+		if suite.Response == nil {
+			entry.Response.Error = suite.Error
+		}
+		entries = append(entries, entry)
+	}
+	return har.Write(w, har.New(entries))
+}
@@ -0,0 +1,100 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// JSONReporter writes results as a JSON document
+type JSONReporter struct{}
+
+type jsonReport struct {
+	Name       string      `json:"name,omitempty"`
+	Timestamp  time.Time   `json:"timestamp"`
+	DurationMs int64       `json:"durationMs"`
+	Summary    jsonSummary `json:"summary"`
+	Suites     []jsonSuite `json:"suites"`
+}
+
+type jsonSummary struct {
+	Requests       int  `json:"requests"`
+	RequestsFailed int  `json:"requestsFailed"`
+	Tests          int  `json:"tests"`
+	TestsFailed    int  `json:"testsFailed"`
+	Passed         bool `json:"passed"`
+}
+
+type jsonSuite struct {
+	Name       string     `json:"name"`
+	File       string     `json:"file,omitempty"`
+	Method     string     `json:"method"`
+	URL        string     `json:"url"`
+	StatusCode int        `json:"statusCode,omitempty"`
+	Timestamp  time.Time  `json:"timestamp"`
+	DurationMs int64      `json:"durationMs"`
+	Passed     bool       `json:"passed"`
+	Error      string     `json:"error,omitempty"`
+	Logs       []string   `json:"logs,omitempty"`
+	Tests      []jsonCase `json:"tests"`
+}
+
+type jsonCase struct {
+	Name       string   `json:"name"`
+	Passed     bool     `json:"passed"`
+	Failure    string   `json:"failure,omitempty"`
+	DurationMs int64    `json:"durationMs"`
+	Logs       []string `json:"logs,omitempty"`
+}
+
+// Report implements Reporter
+func (r *JSONReporter) Report(w io.Writer, run *Run) error {
+	tests, failures, _ := run.Totals()
+	report := jsonReport{
+		Name:       run.Name,
+		Timestamp:  run.Timestamp,
+		DurationMs: run.Duration.Milliseconds(),
+		Summary: jsonSummary{
+			Requests:    len(run.Suites),
+			Tests:       tests,
+			TestsFailed: failures,
+		},
+		Suites: make([]jsonSuite, 0, len(run.Suites)),
+	}
+
+	for _, suite := range run.Suites {
+		js := jsonSuite{
+			Name:       suite.Name,
+			File:       suite.File,
+			Method:     suite.Method,
+			URL:        suite.URL,
+			StatusCode: suite.StatusCode,
+			Timestamp:  suite.Timestamp,
+			DurationMs: suite.Duration.Milliseconds(),
+			Passed:     suite.Passed(),
+			Error:      suite.Error,
+			Logs:       suite.Logs,
+			Tests:      make([]jsonCase, 0, len(suite.Cases)),
+		}
+		for _, c := range suite.Cases {
+			js.Tests = append(js.Tests, jsonCase{
+				Name:       c.Name,
+				Passed:     c.Passed,
+				Failure:    c.Failure,
+				DurationMs: c.Duration.Milliseconds(),
+				Logs:       c.Logs,
+			})
+		}
+		if !js.Passed {
+			report.Summary.RequestsFailed++
+		}
+		report.Suites = append(report.Suites, js)
+	}
+	report.Summary.Passed = report.Summary.RequestsFailed == 0
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return errors.Wrap(encoder.Encode(report), "failed to write JSON report")
+}
@@ -0,0 +1,138 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// JUnitReporter writes results as JUnit XML
+type JUnitReporter struct{}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr,omitempty"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+	SystemOut  string          `xml:"system-out,omitempty"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// Report implements Reporter
+func (r *JUnitReporter) Report(w io.Writer, run *Run) error {
+	tests, failures, errs := run.Totals()
+	doc := junitTestSuites{
+		Name:     run.Name,
+		Tests:    tests + errs,
+		Failures: failures,
+		Errors:   errs,
+		Time:     junitSeconds(run.Duration),
+	}
+
+	for _, suite := range run.Suites {
+		doc.Suites = append(doc.Suites, r.convertSuite(suite))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Wrap(err, "failed to write JUnit report")
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return errors.Wrap(err, "failed to write JUnit report")
+	}
+	_, err := io.WriteString(w, "\n")
+	return errors.Wrap(err, "failed to write JUnit report")
+}
+
+// convertSuite maps a request to a <testsuite>. A request-level error is
+// reported as an extra test case with an <error> element, since most JUnit
+// consumers ignore errors attached to the suite itself.
+func (r *JUnitReporter) convertSuite(suite Suite) junitTestSuite {
+	js := junitTestSuite{
+		Name:      suite.Name,
+		Tests:     len(suite.Cases),
+		Failures:  suite.Failures(),
+		Time:      junitSeconds(suite.Duration),
+		SystemOut: strings.Join(suite.Logs, "\n"),
+	}
+	if !suite.Timestamp.IsZero() {
+		js.Timestamp = suite.Timestamp.UTC().Format("2006-01-02T15:04:05")
+	}
+
+	js.Properties = append(js.Properties, junitProperty{Name: "request", Value: suite.Method + " " + suite.URL})
+	if suite.File != "" {
+		js.Properties = append(js.Properties, junitProperty{Name: "file", Value: suite.File})
+	}
+	if suite.StatusCode != 0 {
+		js.Properties = append(js.Properties, junitProperty{Name: "status", Value: fmt.Sprintf("%d", suite.StatusCode)})
+	}
+
+	for _, c := range suite.Cases {
+		tc := junitTestCase{
+			Name:      c.Name,
+			Classname: suite.Name,
+			Time:      junitSeconds(c.Duration),
+			SystemOut: strings.Join(c.Logs, "\n"),
+		}
+		if !c.Passed {
+			tc.Failure = &junitMessage{Message: c.Failure, Type: "AssertionError", Body: c.Failure}
+		}
+		js.Cases = append(js.Cases, tc)
+	}
+
+	if suite.Error != "" {
+		js.Tests++
+		js.Errors = 1
+		js.Cases = append(js.Cases, junitTestCase{
+			Name:      suite.Method + " " + suite.URL,
+			Classname: suite.Name,
+			Time:      junitSeconds(suite.Duration),
+			Error:     &junitMessage{Message: suite.Error, Body: suite.Error},
+		})
+	}
+
+	return js
+}
+
+// junitSeconds formats a duration as fractional seconds
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
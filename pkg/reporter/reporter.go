@@ -0,0 +1,115 @@
+// Package reporter writes the results of a test run in machine-readable
+// formats (JUnit XML, TAP, JSON and HAR) for CI systems and dashboards.
+package reporter
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+// Supported report formats
+const (
+	FormatJUnit = "junit"
+	FormatTAP   = "tap"
+	FormatJSON  = "json"
+	FormatHAR   = "har"
+)
+
+// Reporter writes a report for a completed run
+type Reporter interface {
+	Report(w io.Writer, run *Run) error
+}
+
+// Run is the result of executing one or more .http files
+type Run struct {
+	Name      string
+	Timestamp time.Time
+	Duration  time.Duration
+	Suites    []Suite
+}
+
+// Suite is the result of a single request. Each client.test in its
+// post-response script becomes a Case.
+type Suite struct {
+	Name       string
+	File       string
+	Method     string
+	URL        string
+	StatusCode int // 0 if no response was received
+	Timestamp  time.Time
+	Duration   time.Duration
+	Cases      []Case
+	Logs       []string
+	Error      string // Request-level error (send failure, script error); empty on success
+
+	Request  *models.HttpRequest  // The request as sent
+	Response *models.HttpResponse // nil if no response was received
+}
+
+// Case is the result of a single client.test
+type Case struct {
+	Name     string
+	Passed   bool
+	Failure  string
+	Duration time.Duration
+	Logs     []string
+}
+
+// Failures returns the number of failed cases in the suite
+func (s *Suite) Failures() int {
+	failures := 0
+	for _, c := range s.Cases {
+		if !c.Passed {
+			failures++
+		}
+	}
+	return failures
+}
+
+// Passed reports whether the request succeeded and all of its cases passed
+func (s *Suite) Passed() bool {
+	return s.Error == "" && s.Failures() == 0
+}
+
+// Totals returns the number of cases, failed cases and errored suites in the run
+func (r *Run) Totals() (tests, failures, errs int) {
+	for i := range r.Suites {
+		tests += len(r.Suites[i].Cases)
+		failures += r.Suites[i].Failures()
+		if r.Suites[i].Error != "" {
+			errs++
+		}
+	}
+	return tests, failures, errs
+}
+
+var reporters = map[string]func() Reporter{
+	FormatJUnit: func() Reporter { return &JUnitReporter{} },
+	FormatTAP:   func() Reporter { return &TAPReporter{} },
+	FormatJSON:  func() Reporter { return &JSONReporter{} },
+	FormatHAR:   func() Reporter { return &HARReporter{} },
+}
+
+// New returns the reporter for the given format name
+func New(format string) (Reporter, error) {
+	factory, ok := reporters[strings.ToLower(format)]
+	if !ok {
+		return nil, errors.NewValidationErrorWithValue("reporter", format, "must be one of: "+strings.Join(Formats(), ", "))
+	}
+	return factory(), nil
+}
+
+// Formats returns the names of all supported formats
+func Formats() []string {
+	formats := make([]string, 0, len(reporters))
+	for name := range reporters {
+		formats = append(formats, name)
+	}
+	slices.Sort(formats)
+	return formats
+}
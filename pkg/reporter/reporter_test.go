@@ -0,0 +1,220 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/har"
+	"github.com/ideaspaper/restclient/pkg/models"
+)
+
+func sampleRun() *Run {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &Run{
+		Name:      "api.http",
+		Timestamp: ts,
+		Duration:  1500 * time.Millisecond,
+		Suites: []Suite{
+			{
+				Name:       "login",
+				File:       "api.http",
+				Method:     "POST",
+				URL:        "https://api.example.com/login",
+				StatusCode: 200,
+				Timestamp:  ts,
+				Duration:   120 * time.Millisecond,
+				Logs:       []string{"token received"},
+				Request: &models.HttpRequest{
+					Method:  "POST",
+					URL:     "https://api.example.com/login",
+					Headers: map[string]string{"Content-Type": "application/json"},
+					RawBody: `{"user": "a"}`,
+				},
+				Response: &models.HttpResponse{
+					StatusCode:      200,
+					StatusMessage:   "200 OK",
+					HttpVersion:     "HTTP/2.0",
+					Headers:         map[string][]string{"Content-Type": {"application/json"}},
+					Body:            `{"token": "t"}`,
+					BodySizeInBytes: 14,
+					Timing: models.ResponseTiming{
+						DNSLookup:        10 * time.Millisecond,
+						TCPConnection:    20 * time.Millisecond,
+						TLSHandshake:     30 * time.Millisecond,
+						ServerProcessing: 50 * time.Millisecond,
+						ContentTransfer:  5 * time.Millisecond,
+						Total:            115 * time.Millisecond,
+					},
+				},
+				Cases: []Case{
+					{Name: "status is 200", Passed: true, Duration: time.Millisecond},
+					{Name: "has token", Passed: false, Failure: "expected token", Logs: []string{"body: {}"}},
+				},
+			},
+			{
+				Name:     "getUsers",
+				File:     "api.http",
+				Method:   "GET",
+				URL:      "https://api.example.com/users",
+				Duration: 30 * time.Millisecond,
+				Error:    "connection refused",
+				Request:  &models.HttpRequest{Method: "GET", URL: "https://api.example.com/users?page=2"},
+			},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, format := range []string{"junit", "tap", "json", "har", "JUnit"} {
+		if _, err := New(format); err != nil {
+			t.Errorf("New(%q) error = %v", format, err)
+		}
+	}
+
+	_, err := New("html")
+	if !errors.Is(err, errors.ErrInvalidInput) {
+		t.Errorf("New(html) error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestRunTotals(t *testing.T) {
+	tests, failures, errs := sampleRun().Totals()
+	if tests != 2 || failures != 1 || errs != 1 {
+		t.Errorf("Totals() = %d, %d, %d, want 2, 1, 1", tests, failures, errs)
+	}
+}
+
+func TestJUnitReporter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&JUnitReporter{}).Report(&buf, sampleRun()); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Errorf("report should start with XML header, got %q", buf.String()[:20])
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, buf.String())
+	}
+
+	if doc.Tests != 3 || doc.Failures != 1 || doc.Errors != 1 {
+		t.Errorf("testsuites totals = %d/%d/%d, want 3/1/1", doc.Tests, doc.Failures, doc.Errors)
+	}
+	if len(doc.Suites) != 2 {
+		t.Fatalf("got %d suites, want 2", len(doc.Suites))
+	}
+
+	login := doc.Suites[0]
+	if login.Name != "login" || login.Time != "0.120" || login.Timestamp != "2024-01-02T03:04:05" {
+		t.Errorf("login suite = %+v", login)
+	}
+	if login.SystemOut != "token received" {
+		t.Errorf("suite system-out = %q", login.SystemOut)
+	}
+	if len(login.Cases) != 2 || login.Cases[1].Failure == nil || login.Cases[1].Failure.Message != "expected token" {
+		t.Errorf("login cases = %+v", login.Cases)
+	}
+	if login.Cases[1].SystemOut != "body: {}" {
+		t.Errorf("case system-out = %q", login.Cases[1].SystemOut)
+	}
+
+	users := doc.Suites[1]
+	if users.Errors != 1 || len(users.Cases) != 1 || users.Cases[0].Error == nil {
+		t.Errorf("errored suite should contain an error test case, got %+v", users)
+	}
+}
+
+func TestTAPReporter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&TAPReporter{}).Report(&buf, sampleRun()); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{
+		"TAP version 13\n1..3\n",
+		"# login (POST https://api.example.com/login)\n# token received\n",
+		"ok 1 - login: status is 200\n",
+		"not ok 2 - login: has token\n  ---\n  message: \"expected token\"\n",
+		"  logs:\n    - \"body: {}\"\n  ...\n",
+		"not ok 3 - getUsers: request failed\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q\nGot:\n%s", want, output)
+		}
+	}
+}
+
+func TestTAPEscape(t *testing.T) {
+	if got := tapEscape(`a # b \ c`); got != `a \# b \\ c` {
+		t.Errorf("tapEscape() = %q", got)
+	}
+}
+
+func TestJSONReporter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&JSONReporter{}).Report(&buf, sampleRun()); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+
+	want := jsonSummary{Requests: 2, RequestsFailed: 2, Tests: 2, TestsFailed: 1, Passed: false}
+	if report.Summary != want {
+		t.Errorf("Summary = %+v, want %+v", report.Summary, want)
+	}
+	if report.DurationMs != 1500 {
+		t.Errorf("DurationMs = %d, want 1500", report.DurationMs)
+	}
+	if len(report.Suites) != 2 || report.Suites[1].Error != "connection refused" {
+		t.Errorf("Suites = %+v", report.Suites)
+	}
+	if report.Suites[0].Tests[1].Failure != "expected token" {
+		t.Errorf("failed test = %+v", report.Suites[0].Tests[1])
+	}
+}
+
+func TestHARReporter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&HARReporter{}).Report(&buf, sampleRun()); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	archive, err := har.Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("report is not a valid HAR: %v", err)
+	}
+	if archive.Log.Version != "1.2" || len(archive.Log.Entries) != 2 {
+		t.Fatalf("log = %+v", archive.Log)
+	}
+
+	login := archive.Log.Entries[0]
+	if login.Request.Method != "POST" || login.Request.HTTPVersion != "HTTP/2.0" || login.Request.PostData == nil || login.Request.PostData.Text != `{"user": "a"}` {
+		t.Errorf("login request = %+v", login.Request)
+	}
+	if login.Response.Status != 200 || login.Response.StatusText != "OK" || login.Response.Content.Text != `{"token": "t"}` {
+		t.Errorf("login response = %+v", login.Response)
+	}
+	wantTimings := har.Timings{Blocked: -1, DNS: 10, Connect: 50, SSL: 30, Wait: 50, Receive: 5}
+	if login.Timings != wantTimings || login.Time != 115 {
+		t.Errorf("login timings = %+v (time %v), want %+v", login.Timings, login.Time, wantTimings)
+	}
+
+	users := archive.Log.Entries[1]
+	if users.Response.Status != 0 || users.Response.Error != "connection refused" {
+		t.Errorf("failed request response = %+v", users.Response)
+	}
+	if len(users.Request.QueryString) != 1 || users.Request.QueryString[0] != (har.NameValue{Name: "page", Value: "2"}) {
+		t.Errorf("queryString = %+v", users.Request.QueryString)
+	}
+}
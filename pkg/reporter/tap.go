@@ -0,0 +1,75 @@
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+// TAPReporter writes results in the Test Anything Protocol (version 13)
+type TAPReporter struct{}
+
+// Report implements Reporter
+func (r *TAPReporter) Report(w io.Writer, run *Run) error {
+	bw := bufio.NewWriter(w)
+
+	tests, _, errs := run.Totals()
+	fmt.Fprintln(bw, "TAP version 13")
+	fmt.Fprintf(bw, "1..%d\n", tests+errs)
+
+	n := 0
+	for _, suite := range run.Suites {
+		fmt.Fprintf(bw, "# %s (%s %s)\n", suite.Name, suite.Method, suite.URL)
+		for _, log := range suite.Logs {
+			fmt.Fprintf(bw, "# %s\n", log)
+		}
+
+		for _, c := range suite.Cases {
+			n++
+			if c.Passed {
+				fmt.Fprintf(bw, "ok %d - %s: %s\n", n, tapEscape(suite.Name), tapEscape(c.Name))
+			} else {
+				fmt.Fprintf(bw, "not ok %d - %s: %s\n", n, tapEscape(suite.Name), tapEscape(c.Name))
+			}
+			writeTAPDiagnostics(bw, c.Failure, c.Duration.Milliseconds(), c.Logs)
+		}
+
+		if suite.Error != "" {
+			n++
+			fmt.Fprintf(bw, "not ok %d - %s: request failed\n", n, tapEscape(suite.Name))
+			writeTAPDiagnostics(bw, suite.Error, suite.Duration.Milliseconds(), nil)
+		}
+	}
+
+	return errors.Wrap(bw.Flush(), "failed to write TAP report")
+}
+
+// writeTAPDiagnostics writes a YAML diagnostics block for a test point
+func writeTAPDiagnostics(w io.Writer, message string, durationMs int64, logs []string) {
+	if message == "" && len(logs) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "  ---")
+	if message != "" {
+		fmt.Fprintf(w, "  message: %q\n", message)
+	}
+	fmt.Fprintf(w, "  duration_ms: %d\n", durationMs)
+	if len(logs) > 0 {
+		fmt.Fprintln(w, "  logs:")
+		for _, log := range logs {
+			fmt.Fprintf(w, "    - %q\n", log)
+		}
+	}
+	fmt.Fprintln(w, "  ...")
+}
+
+// tapEscape escapes characters with special meaning in a TAP description
+func tapEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "#", "\\#")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/ideaspaper/restclient/internal/httputil"
 	"github.com/ideaspaper/restclient/pkg/errors"
+	"github.com/ideaspaper/restclient/pkg/models"
 )
 
 // Engine executes JavaScript scripts for HTTP request/response handling
@@ -29,9 +30,11 @@ type Engine struct {
 
 // TestResult represents the result of a single test
 type TestResult struct {
-	Name   string
-	Passed bool
-	Error  string
+	Name     string
+	Passed   bool
+	Error    string
+	Duration time.Duration
+	Logs     []string // Logs written while the test function ran
 }
 
 // ScriptResult contains the results of script execution
@@ -40,6 +43,20 @@ type ScriptResult struct {
 	Logs       []string
 	Error      error
 	GlobalVars map[string]any
+	Execution  Execution
+}
+
+// Execution holds the flow-control decisions a script made through
+// client.execution. They only take effect when running a collection.
+type Execution struct {
+	NextRequest string // Name of the request to run next (setNextRequest)
+	Stop        bool   // End the run after this request (stop or setNextRequest(null))
+	Skip        bool   // skipRequest was called
+}
+
+// IsSet reports whether the script made any flow-control decision
+func (e Execution) IsSet() bool {
+	return e.NextRequest != "" || e.Stop || e.Skip
 }
 
 // NewEngine creates a new scripting engine
@@ -177,19 +194,21 @@ func (e *Engine) createClientObject(ctx *ScriptContext, result *ScriptResult) ma
 			}
 
 			// Execute the test function
+			logStart := len(result.Logs)
+			start := time.Now()
 			_, err := testFunc(goja.Undefined())
+			test := TestResult{
+				Name:     testName,
+				Passed:   err == nil,
+				Duration: time.Since(start),
+			}
 			if err != nil {
-				result.Tests = append(result.Tests, TestResult{
-					Name:   testName,
-					Passed: false,
-					Error:  err.Error(),
-				})
-			} else {
-				result.Tests = append(result.Tests, TestResult{
-					Name:   testName,
-					Passed: true,
-				})
+				test.Error = err.Error()
+			}
+			if len(result.Logs) > logStart {
+				test.Logs = append([]string(nil), result.Logs[logStart:]...)
 			}
+			result.Tests = append(result.Tests, test)
 			return goja.Undefined()
 		},
 
@@ -221,6 +240,35 @@ func (e *Engine) createClientObject(ctx *ScriptContext, result *ScriptResult) ma
 			return goja.Undefined()
 		},
 
+		// client.execution
+		"execution": map[string]any{
+			// client.execution.setNextRequest(name); null or no name stops the run
+			"setNextRequest": func(call goja.FunctionCall) goja.Value {
+				arg := call.Argument(0)
+				if goja.IsNull(arg) || goja.IsUndefined(arg) || arg.String() == "" {
+					result.Execution.NextRequest = ""
+					result.Execution.Stop = true
+					return goja.Undefined()
+				}
+				result.Execution.NextRequest = arg.String()
+				result.Execution.Stop = false
+				return goja.Undefined()
+			},
+
+			// client.execution.skipRequest()
+			"skipRequest": func(call goja.FunctionCall) goja.Value {
+				result.Execution.Skip = true
+				return goja.Undefined()
+			},
+
+			// client.execution.stop()
+			"stop": func(call goja.FunctionCall) goja.Value {
+				result.Execution.NextRequest = ""
+				result.Execution.Stop = true
+				return goja.Undefined()
+			},
+		},
+
 		// client.global
 		"global": map[string]any{
 			// client.global.set(name, value)
@@ -290,6 +338,9 @@ func (e *Engine) createResponseObject(ctx *ScriptContext) map[string]any {
 		"status":     resp.StatusCode,
 		"statusText": resp.StatusMessage,
 		"body":       bodyObj,
+		"timings":    timingsObject(resp.Timing),
+		"events":     eventsObject(resp.Events),
+		"messages":   messagesObject(resp.Messages),
 		"contentType": map[string]any{
 			"mimeType": getMimeType(contentType),
 			"charset":  getCharset(contentType),
@@ -324,6 +375,58 @@ func (e *Engine) createResponseObject(ctx *ScriptContext) map[string]any {
 	}
 }
 
+// eventsObject exposes Server-Sent Events to scripts. JSON data is parsed,
+// like response.body.
+func eventsObject(events []models.ServerSentEvent) []map[string]any {
+	result := make([]map[string]any, 0, len(events))
+	for _, event := range events {
+		var data any
+		if err := json.Unmarshal([]byte(event.Data), &data); err != nil {
+			data = event.Data
+		}
+		result = append(result, map[string]any{
+			"id":    event.ID,
+			"event": event.Event,
+			"data":  data,
+			"retry": event.Retry,
+		})
+	}
+	return result
+}
+
+// messagesObject exposes received WebSocket messages to scripts. JSON data
+// is parsed, like response.body.
+func messagesObject(messages []models.WebSocketMessage) []map[string]any {
+	result := make([]map[string]any, 0, len(messages))
+	for _, message := range messages {
+		var data any
+		if message.Binary || json.Unmarshal([]byte(message.Data), &data) != nil {
+			data = message.Data
+		}
+		result = append(result, map[string]any{
+			"data":   data,
+			"binary": message.Binary,
+		})
+	}
+	return result
+}
+
+// timingsObject exposes response timings to scripts, in milliseconds
+func timingsObject(t models.ResponseTiming) map[string]any {
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	return map[string]any{
+		"dnsLookup":        ms(t.DNSLookup),
+		"tcpConnection":    ms(t.TCPConnection),
+		"tlsHandshake":     ms(t.TLSHandshake),
+		"serverProcessing": ms(t.ServerProcessing),
+		"contentTransfer":  ms(t.ContentTransfer),
+		"total":            ms(t.Total),
+		"connectionReused": t.ConnectionReused,
+	}
+}
+
 // createRequestObject creates the request JavaScript object
 func (e *Engine) createRequestObject(ctx *ScriptContext) map[string]any {
 	req := ctx.Request
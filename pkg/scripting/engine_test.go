@@ -4,6 +4,7 @@ import (
 	"context"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/ideaspaper/restclient/pkg/models"
 )
@@ -125,6 +126,78 @@ func TestClientTestFailure(t *testing.T) {
 	}
 }
 
+func TestClientTestCapturesLogs(t *testing.T) {
+	engine := NewEngine()
+	ctx := NewScriptContext()
+	ctx.SetRequest(&models.HttpRequest{
+		Method: "GET",
+		URL:    "https://example.com",
+	})
+
+	script := `
+		client.log("before");
+		client.test("logs inside", function() {
+			client.log("inside 1");
+			console.log("inside 2");
+		});
+		client.test("no logs", function() {});
+	`
+	result, err := engine.Execute(script, ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Tests) != 2 {
+		t.Fatalf("Expected 2 tests, got %d", len(result.Tests))
+	}
+
+	logs := result.Tests[0].Logs
+	if len(logs) != 2 || logs[0] != "inside 1" || logs[1] != "inside 2" {
+		t.Errorf("Expected test logs [inside 1, inside 2], got %v", logs)
+	}
+	if len(result.Tests[1].Logs) != 0 {
+		t.Errorf("Expected no logs for second test, got %v", result.Tests[1].Logs)
+	}
+	if len(result.Logs) != 3 {
+		t.Errorf("Expected 3 script logs, got %d", len(result.Logs))
+	}
+}
+
+func TestClientExecution(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   Execution
+	}{
+		{"no decision", `client.log("hi");`, Execution{}},
+		{"set next request", `client.execution.setNextRequest("poll");`, Execution{NextRequest: "poll"}},
+		{"set next request null", `client.execution.setNextRequest(null);`, Execution{Stop: true}},
+		{"stop", `client.execution.stop();`, Execution{Stop: true}},
+		{"last call wins", `client.execution.stop(); client.execution.setNextRequest("poll");`, Execution{NextRequest: "poll"}},
+		{"skip request", `client.execution.skipRequest();`, Execution{Skip: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewScriptContext()
+			ctx.SetRequest(&models.HttpRequest{Method: "GET", URL: "https://example.com"})
+
+			result, err := NewEngine().Execute(tt.script, ctx)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Error != nil {
+				t.Fatalf("Unexpected script error: %v", result.Error)
+			}
+			if result.Execution != tt.want {
+				t.Errorf("Execution = %+v, want %+v", result.Execution, tt.want)
+			}
+			if result.Execution.IsSet() != (tt.want != Execution{}) {
+				t.Errorf("IsSet() = %v", result.Execution.IsSet())
+			}
+		})
+	}
+}
+
 func TestGlobalVariables(t *testing.T) {
 	engine := NewEngine()
 	ctx := NewScriptContext()
@@ -691,3 +764,111 @@ func TestEngineExecuteWithContextCancellation(t *testing.T) {
 		t.Fatalf("expected one log, got %d", len(result.Logs))
 	}
 }
+
+func TestResponseTimings(t *testing.T) {
+	engine := NewEngine()
+	ctx := NewScriptContext()
+	ctx.SetRequest(&models.HttpRequest{
+		Method: "GET",
+		URL:    "https://example.com",
+	})
+	ctx.SetResponse(&models.HttpResponse{
+		StatusCode: 200,
+		Body:       `{}`,
+		Headers:    map[string][]string{},
+		Timing: models.ResponseTiming{
+			DNSLookup:        2 * time.Millisecond,
+			ServerProcessing: 1500 * time.Microsecond,
+			Total:            10 * time.Millisecond,
+			ConnectionReused: true,
+		},
+	})
+
+	script := `
+		client.test("timings", function() {
+			client.assert(response.timings.total === 10, "total");
+			client.assert(response.timings.dnsLookup === 2, "dns");
+			client.assert(response.timings.serverProcessing === 1.5, "server");
+			client.assert(response.timings.tlsHandshake === 0, "tls");
+			client.assert(response.timings.connectionReused === true, "reused");
+		});
+	`
+	result, err := engine.Execute(script, ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Tests) != 1 || !result.Tests[0].Passed {
+		t.Errorf("Expected timings test to pass, got %+v", result.Tests)
+	}
+}
+
+func TestResponseEvents(t *testing.T) {
+	engine := NewEngine()
+	ctx := NewScriptContext()
+	ctx.SetRequest(&models.HttpRequest{
+		Method: "GET",
+		URL:    "https://example.com/events",
+	})
+	ctx.SetResponse(&models.HttpResponse{
+		StatusCode: 200,
+		Headers:    map[string][]string{"Content-Type": {"text/event-stream"}},
+		Events: []models.ServerSentEvent{
+			{ID: "1", Event: "progress", Data: `{"percent": 50}`},
+			{ID: "2", Event: "message", Data: "[DONE]", Retry: 1000},
+		},
+	})
+
+	script := `
+		client.test("events", function() {
+			client.assert(response.events.length === 2, "count");
+			var percent = 0;
+			response.events.forEach(function(e) {
+				if (e.event === "progress") {
+					percent = e.data.percent;
+				}
+			});
+			client.assert(percent === 50, "JSON data is parsed");
+			client.assert(response.events[1].data === "[DONE]", "other data is a string");
+			client.assert(response.events[1].id === "2" && response.events[1].retry === 1000, "id and retry");
+		});
+	`
+	result, err := engine.Execute(script, ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Tests) != 1 || !result.Tests[0].Passed {
+		t.Errorf("Expected events test to pass, got %+v", result.Tests)
+	}
+}
+
+func TestResponseMessages(t *testing.T) {
+	engine := NewEngine()
+	ctx := NewScriptContext()
+	ctx.SetRequest(&models.HttpRequest{
+		Method: "WEBSOCKET",
+		URL:    "wss://example.com/live",
+	})
+	ctx.SetResponse(&models.HttpResponse{
+		StatusCode: 101,
+		Messages: []models.WebSocketMessage{
+			{Data: `{"type": "ack"}`},
+			{Data: "pong"},
+		},
+	})
+
+	script := `
+		client.test("messages", function() {
+			client.assert(response.messages.length === 2, "count");
+			client.assert(response.messages[0].data.type === "ack", "JSON data is parsed");
+			client.assert(response.messages[1].data === "pong", "other data is a string");
+			client.assert(response.messages[1].binary === false, "binary flag");
+		});
+	`
+	result, err := engine.Execute(script, ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Tests) != 1 || !result.Tests[0].Passed {
+		t.Errorf("Expected messages test to pass, got %+v", result.Tests)
+	}
+}
@@ -68,6 +68,33 @@ func LoadOrCreateEnvironmentStore(fs filesystem.FileSystem, sessionDir string) (
 	return store, nil
 }
 
+// LoadEnvironmentStore loads an existing environment store without writing
+// to disk, returning the defaults when the store is missing or unreadable.
+func LoadEnvironmentStore(fs filesystem.FileSystem, sessionDir string) (*EnvironmentStore, error) {
+	if fs == nil {
+		fs = filesystem.Default
+	}
+	if sessionDir == "" {
+		return nil, errors.NewValidationError("sessionDir", "session directory is required")
+	}
+
+	data, err := fs.ReadFile(filepath.Join(sessionDir, environmentStoreFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return defaultEnvironmentStore(), nil
+		}
+		return nil, errors.Wrap(err, "failed to read session environments")
+	}
+
+	store := &EnvironmentStore{}
+	if err := json.Unmarshal(data, store); err != nil {
+		return defaultEnvironmentStore(), nil
+	}
+	store.ensureIntegrity()
+	store.Version = environmentStoreVersion
+	return store, nil
+}
+
 // SaveEnvironmentStore persists the environment store to disk.
 func SaveEnvironmentStore(fs filesystem.FileSystem, sessionDir string, store *EnvironmentStore) error {
 	if fs == nil {
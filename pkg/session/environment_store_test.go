@@ -2,6 +2,7 @@ package session
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ideaspaper/restclient/internal/filesystem"
@@ -34,6 +35,36 @@ func TestLoadOrCreateEnvironmentStore_CreatesDefaults(t *testing.T) {
 	}
 }
 
+func TestLoadEnvironmentStore_DoesNotCreate(t *testing.T) {
+	fs := filesystem.NewMockFileSystem()
+	sessionDir := "/sessions/missing"
+
+	store, err := LoadEnvironmentStore(fs, sessionDir)
+	if err != nil {
+		t.Fatalf("LoadEnvironmentStore() error = %v", err)
+	}
+	if !store.HasEnvironment("development") {
+		t.Error("a missing store should load the defaults")
+	}
+
+	path := filepath.Join(sessionDir, environmentStoreFileName)
+	if fs.HasFile(path) {
+		t.Errorf("expected %s not to be created", path)
+	}
+
+	fs.WithFile(path, []byte(`{"version": 0, "environmentVariables": {"prod": {"url": "https://api"}}}`))
+	store, err = LoadEnvironmentStore(fs, sessionDir)
+	if err != nil {
+		t.Fatalf("LoadEnvironmentStore() error = %v", err)
+	}
+	if got := store.GetEnvironment("prod")["url"]; got != "https://api" {
+		t.Errorf("prod url = %q", got)
+	}
+	if data, _ := fs.ReadFile(path); !strings.Contains(string(data), `"version": 0`) {
+		t.Error("loading should not rewrite the store")
+	}
+}
+
 func TestEnvironmentStore_LoadExisting(t *testing.T) {
 	fs := filesystem.NewMockFileSystem()
 	sessionDir := "/sessions/existing"
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/ideaspaper/restclient/pkg/errors"
+)
+
+const (
+	resultsFileName = "results.json"
+
+	// MaxResultBodySize is the largest response body stored with a request
+	// result. Larger results are only kept for the current process.
+	MaxResultBodySize = 1 << 20
+)
+
+// RequestResult represents the serializable response of a named request
+type RequestResult struct {
+	StatusCode int                 `json:"statusCode"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Body       string              `json:"body"`
+	SavedAt    time.Time           `json:"savedAt"`
+	ExpiresAt  time.Time           `json:"expiresAt,omitempty"`
+}
+
+// Expired reports whether the result is past its expiry time
+func (r RequestResult) Expired() bool {
+	return !r.ExpiresAt.IsZero() && !time.Now().Before(r.ExpiresAt)
+}
+
+// LoadResults loads request results from disk
+func (s *SessionManager) LoadResults() error {
+	path := filepath.Join(s.sessionPath, resultsFileName)
+	data, err := s.fs.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read results file")
+	}
+
+	if err := json.Unmarshal(data, &s.results); err != nil {
+		return errors.Wrap(err, "failed to parse results file")
+	}
+	return nil
+}
+
+// SaveResults saves request results to disk
+func (s *SessionManager) SaveResults() error {
+	if err := s.fs.MkdirAll(s.sessionPath, 0755); err != nil {
+		return errors.Wrap(err, "failed to create session directory")
+	}
+
+	// Clean expired results before saving
+	for key, result := range s.results {
+		if result.Expired() {
+			delete(s.results, key)
+		}
+	}
+
+	data, err := json.MarshalIndent(s.results, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal results")
+	}
+
+	path := filepath.Join(s.sessionPath, resultsFileName)
+	return s.fs.WriteFile(path, data, 0644)
+}
+
+// SetResult stores the result of a named request. A result whose body
+// exceeds MaxResultBodySize replaces any stored one without being stored.
+func (s *SessionManager) SetResult(key string, result RequestResult) {
+	if len(result.Body) > MaxResultBodySize {
+		delete(s.results, key)
+		return
+	}
+	if result.SavedAt.IsZero() {
+		result.SavedAt = time.Now()
+	}
+	s.results[key] = result
+}
+
+// GetAllResults returns all stored request results that have not expired
+func (s *SessionManager) GetAllResults() map[string]RequestResult {
+	results := make(map[string]RequestResult, len(s.results))
+	for key, result := range s.results {
+		if !result.Expired() {
+			results[key] = result
+		}
+	}
+	return results
+}
+
+// ClearResults clears all request results
+func (s *SessionManager) ClearResults() {
+	s.results = make(map[string]RequestResult)
+}
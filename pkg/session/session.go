@@ -1,6 +1,6 @@
-// Package session provides session management for persisting cookies and
-// variables across HTTP requests, supporting both directory-scoped and
-// named session modes.
+// Package session provides session management for persisting cookies,
+// variables and named request results across HTTP requests, supporting
+// both directory-scoped and named session modes.
 package session
 
 import (
@@ -45,8 +45,9 @@ type SessionManager struct {
 	fs          filesystem.FileSystem
 	baseDir     string
 	sessionPath string
-	cookies     map[string][]Cookie // host -> cookies
-	variables   map[string]any      // variable name -> value
+	cookies     map[string][]Cookie      // host -> cookies
+	variables   map[string]any           // variable name -> value
+	results     map[string]RequestResult // result key -> result
 }
 
 // NewSessionManager creates a new session manager
@@ -92,6 +93,7 @@ func NewSessionManagerWithFS(fs filesystem.FileSystem, baseDir, httpFilePath, se
 		sessionPath: sessionPath,
 		cookies:     make(map[string][]Cookie),
 		variables:   make(map[string]any),
+		results:     make(map[string]RequestResult),
 	}
 
 	return s, nil
@@ -103,18 +105,21 @@ func hashPath(path string) string {
 	return hex.EncodeToString(h[:8]) // Use first 8 bytes (16 hex chars)
 }
 
-// Load loads both cookies and variables from disk
+// Load loads cookies, variables and request results from disk
 func (s *SessionManager) Load() error {
-	if err := s.LoadCookies(); err != nil && !os.IsNotExist(err) {
+	if err := s.LoadCookies(); err != nil && !errors.Is(err, os.ErrNotExist) {
 		return errors.Wrap(err, "failed to load cookies")
 	}
-	if err := s.LoadVariables(); err != nil && !os.IsNotExist(err) {
+	if err := s.LoadVariables(); err != nil && !errors.Is(err, os.ErrNotExist) {
 		return errors.Wrap(err, "failed to load variables")
 	}
+	if err := s.LoadResults(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "failed to load results")
+	}
 	return nil
 }
 
-// Save saves both cookies and variables to disk
+// Save saves cookies, variables and request results to disk
 func (s *SessionManager) Save() error {
 	if err := s.SaveCookies(); err != nil {
 		return errors.Wrap(err, "failed to save cookies")
@@ -122,6 +127,9 @@ func (s *SessionManager) Save() error {
 	if err := s.SaveVariables(); err != nil {
 		return errors.Wrap(err, "failed to save variables")
 	}
+	if err := s.SaveResults(); err != nil {
+		return errors.Wrap(err, "failed to save results")
+	}
 	return nil
 }
 
@@ -345,10 +353,11 @@ func (s *SessionManager) ClearVariables() {
 	s.variables = make(map[string]any)
 }
 
-// ClearAll clears both cookies and variables
+// ClearAll clears cookies, variables and request results
 func (s *SessionManager) ClearAll() {
 	s.ClearCookies()
 	s.ClearVariables()
+	s.ClearResults()
 }
 
 // Delete removes the session directory from disk
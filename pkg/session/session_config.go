@@ -22,6 +22,7 @@ type SessionConfig struct {
 	Environment SessionEnvironmentBlock `json:"environment"`
 	HTTP        SessionHTTPBlock        `json:"http"`
 	TLS         SessionTLSBlock         `json:"tls"`
+	Validation  SessionValidationBlock  `json:"validation"`
 }
 
 // SessionEnvironmentBlock contains environment-specific settings.
@@ -46,6 +47,13 @@ type SessionTLSBlock struct {
 	Certificates         map[string]SessionCertificateBlock `json:"certificates"`
 }
 
+// SessionValidationBlock configures checks of responses.
+type SessionValidationBlock struct {
+	// OpenAPI is the spec responses are validated against, relative to the
+	// .http file; # @openapi in a file takes precedence
+	OpenAPI string `json:"openapi"`
+}
+
 // SessionCertificateBlock stores certificate references for a host.
 type SessionCertificateBlock struct {
 	Cert       string `json:"cert,omitempty"`
@@ -100,6 +108,33 @@ func LoadOrCreateSessionConfig(fs filesystem.FileSystem, sessionDir string) (*Se
 	return cfg, nil
 }
 
+// LoadSessionConfig loads an existing session config without writing to
+// disk, returning the defaults when the config is missing or unreadable.
+func LoadSessionConfig(fs filesystem.FileSystem, sessionDir string) (*SessionConfig, error) {
+	if fs == nil {
+		fs = filesystem.Default
+	}
+	if sessionDir == "" {
+		return nil, errors.NewValidationError("sessionDir", "session directory is required")
+	}
+
+	data, err := fs.ReadFile(filepath.Join(sessionDir, sessionConfigFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return defaultSessionConfig(), nil
+		}
+		return nil, errors.Wrap(err, "failed to read session config")
+	}
+
+	cfg := &SessionConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return defaultSessionConfig(), nil
+	}
+	cfg.ensureIntegrity()
+	cfg.Version = sessionConfigVersion
+	return cfg, nil
+}
+
 // SaveSessionConfig persists the provided session config to disk.
 func SaveSessionConfig(fs filesystem.FileSystem, sessionDir string, cfg *SessionConfig) error {
 	if fs == nil {
@@ -4,8 +4,11 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/ideaspaper/restclient/internal/filesystem"
 )
 
 func TestNewSessionManager(t *testing.T) {
@@ -247,6 +250,52 @@ func TestSessionManager_Persistence(t *testing.T) {
 	}
 }
 
+func TestSessionManager_Results(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sm1, err := NewSessionManager(tempDir, "", "test-results")
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+
+	sm1.SetResult("login", RequestResult{
+		StatusCode: 200,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}},
+		Body:       `{"token": "abc"}`,
+	})
+	sm1.SetResult("expired", RequestResult{StatusCode: 200, ExpiresAt: time.Now().Add(-time.Minute)})
+	sm1.SetResult("large", RequestResult{StatusCode: 200, Body: strings.Repeat("x", MaxResultBodySize+1)})
+
+	if err := sm1.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	sm2, err := NewSessionManager(tempDir, "", "test-results")
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+	if err := sm2.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	results := sm2.GetAllResults()
+	if len(results) != 1 {
+		t.Fatalf("After Load(), GetAllResults() returned %d results, want 1", len(results))
+	}
+	login := results["login"]
+	if login.StatusCode != 200 || login.Body != `{"token": "abc"}` || login.Headers["Content-Type"][0] != "application/json" {
+		t.Errorf("After Load(), login result = %+v", login)
+	}
+	if login.SavedAt.IsZero() {
+		t.Error("SetResult() should record when the result was saved")
+	}
+
+	sm2.ClearResults()
+	if len(sm2.GetAllResults()) != 0 {
+		t.Error("ClearResults() should remove all results")
+	}
+}
+
 func TestSessionManager_Delete(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -454,3 +503,30 @@ func TestCookieWithPort(t *testing.T) {
 		t.Errorf("GetCookiesForURL() returned %d cookies, want 1", len(cookies))
 	}
 }
+
+func TestLoadSessionConfig_DoesNotCreate(t *testing.T) {
+	fs := filesystem.NewMockFileSystem()
+	sessionDir := "/sessions/missing"
+
+	cfg, err := LoadSessionConfig(fs, sessionDir)
+	if err != nil {
+		t.Fatalf("LoadSessionConfig() error = %v", err)
+	}
+	if cfg.DefaultHeaders()["User-Agent"] != "restclient-cli" {
+		t.Errorf("a missing config should load the defaults, got %v", cfg.DefaultHeaders())
+	}
+
+	path := filepath.Join(sessionDir, sessionConfigFileName)
+	if fs.HasFile(path) {
+		t.Errorf("expected %s not to be created", path)
+	}
+
+	fs.WithFile(path, []byte(`{"version": 1, "environment": {"current": "prod"}}`))
+	cfg, err = LoadSessionConfig(fs, sessionDir)
+	if err != nil {
+		t.Fatalf("LoadSessionConfig() error = %v", err)
+	}
+	if got := cfg.CurrentEnvironment(); got != "prod" {
+		t.Errorf("CurrentEnvironment() = %q, want prod", got)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -58,6 +59,21 @@ type RequestResult struct {
 	StatusCode int
 	Headers    map[string][]string
 	Body       string
+	ExpiresAt  time.Time // When dependent requests should send it again (zero = never)
+}
+
+// RequestReference identifies the request a request variable refers to
+type RequestReference struct {
+	File string // Path of the file holding the request, empty for any file
+	Name string
+}
+
+// String returns the reference as written in a request variable
+func (r RequestReference) String() string {
+	if r.File == "" {
+		return r.Name
+	}
+	return r.File + "#" + r.Name
 }
 
 // NewVariableProcessor creates a new variable processor
@@ -86,11 +102,78 @@ func (v *VariableProcessor) SetFileVariables(vars map[string]string) {
 	maps.Copy(v.fileVariables, vars)
 }
 
+// UnsetFileVariables removes file variables that still hold the given
+// values, keeping those changed since, such as by client.global.set()
+func (v *VariableProcessor) UnsetFileVariables(vars map[string]string) {
+	for name, value := range vars {
+		if v.fileVariables[name] == value {
+			delete(v.fileVariables, name)
+		}
+	}
+}
+
 // SetRequestResult stores a request result for request variables
 func (v *VariableProcessor) SetRequestResult(name string, result RequestResult) {
 	v.requestResults[name] = result
 }
 
+// HasRequestResult reports whether a result that has not expired is stored
+// for the referenced request
+func (v *VariableProcessor) HasRequestResult(ref RequestReference) bool {
+	result, ok := v.requestResults[v.ResultKey(ref)]
+	return ok && (result.ExpiresAt.IsZero() || time.Now().Before(result.ExpiresAt))
+}
+
+// ResultKey returns the key under which the referenced request's result is
+// stored, resolving a file path against the current directory
+func (v *VariableProcessor) ResultKey(ref RequestReference) string {
+	if ref.File == "" {
+		return ref.Name
+	}
+	file := ref.File
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(v.currentDir, file)
+	}
+	return RequestKey(file, ref.Name)
+}
+
+// RequestReferences returns the requests that text refers to through request
+// variables, directly or through file and environment variables
+func (v *VariableProcessor) RequestReferences(text string) []RequestReference {
+	var refs []RequestReference
+	v.collectRequestReferences(text, make(map[string]bool), &refs)
+	return refs
+}
+
+// collectRequestReferences adds the request references in text to refs,
+// following each variable once
+func (v *VariableProcessor) collectRequestReferences(text string, visited map[string]bool, refs *[]RequestReference) {
+	for _, match := range variableRegex.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimPrefix(strings.TrimSpace(match[1]), "%")
+		if name == "" || strings.HasPrefix(name, "$") || visited[name] {
+			continue
+		}
+		visited[name] = true
+
+		if requestPartIndex(name) >= 0 {
+			ref, _ := parseRequestReference(name)
+			if !slices.Contains(*refs, ref) {
+				*refs = append(*refs, ref)
+			}
+			continue
+		}
+
+		if val, ok := v.fileVariables[name]; ok {
+			v.collectRequestReferences(val, visited, refs)
+		}
+		for _, env := range []string{"$shared", v.environment} {
+			if val, ok := v.envVariables[env][name]; ok {
+				v.collectRequestReferences(val, visited, refs)
+			}
+		}
+	}
+}
+
 // SetPromptHandler sets the handler for prompt variables
 func (v *VariableProcessor) SetPromptHandler(handler func(name, description string, isPassword bool) (string, error)) {
 	v.promptHandler = handler
@@ -101,14 +184,21 @@ func (v *VariableProcessor) SetCurrentDir(dir string) {
 	v.currentDir = dir
 }
 
+// ClearCache discards resolved values so that the next request re-resolves
+// dynamic variables ($guid, $timestamp, request results, script globals)
+func (v *VariableProcessor) ClearCache() {
+	clear(v.resolvedCache)
+}
+
+// variableRegex matches a {{variable}} reference
+var variableRegex = regexp.MustCompile(`\{\{(.+?)\}\}`)
+
 // Process processes all variables in the given text
 func (v *VariableProcessor) Process(text string) (string, error) {
-	varRegex := regexp.MustCompile(`\{\{(.+?)\}\}`)
-
 	lastIndex := 0
 	var builder strings.Builder
 
-	matches := varRegex.FindAllStringSubmatchIndex(text, -1)
+	matches := variableRegex.FindAllStringSubmatchIndex(text, -1)
 	for _, match := range matches {
 		builder.WriteString(text[lastIndex:match[0]])
 
@@ -431,19 +521,19 @@ func (v *VariableProcessor) resolveEnvironmentVariable(name string) (string, err
 func (v *VariableProcessor) resolveRequestVariable(name string) (string, error) {
 	// Parse the variable reference
 	// Format: requestName.(response|request).(body|headers).(path|headerName)
-	parts := strings.SplitN(name, ".", 4)
-	if len(parts) < 4 {
+	ref, rest := parseRequestReference(name)
+	parts := strings.SplitN(rest, ".", 3)
+	if len(parts) < 3 {
 		return "", errors.NewValidationErrorWithValue("request variable", name, "invalid format (expected: requestName.response.body.path)")
 	}
 
-	requestName := parts[0]
-	reqOrResp := parts[1]
-	bodyOrHeaders := parts[2]
-	path := parts[3]
+	reqOrResp := parts[0]
+	bodyOrHeaders := parts[1]
+	path := parts[2]
 
-	result, ok := v.requestResults[requestName]
+	result, ok := v.requestResults[v.ResultKey(ref)]
 	if !ok {
-		return "", errors.NewValidationErrorWithValue("request", requestName, "not found in cache")
+		return "", errors.NewValidationErrorWithValue("request", ref.String(), "not found in cache")
 	}
 
 	if reqOrResp == "response" {
@@ -462,6 +552,36 @@ func (v *VariableProcessor) resolveRequestVariable(name string) (string, error)
 	return "", errors.NewValidationErrorWithValue("request variable", name, "unsupported format")
 }
 
+// parseRequestReference splits a request variable into the referenced
+// request and the rest of the variable. A request in another file is
+// prefixed with its path: auth.http#login.response.body.$.token
+func parseRequestReference(name string) (RequestReference, string) {
+	var ref RequestReference
+	if hash := strings.Index(name, "#"); hash > 0 && hash < requestPartIndex(name) {
+		ref.File, name = name[:hash], name[hash+1:]
+	}
+	ref.Name, name, _ = strings.Cut(name, ".")
+	return ref, name
+}
+
+// requestPartIndex returns the index of ".response." or ".request." in a
+// request variable
+func requestPartIndex(name string) int {
+	if i := strings.Index(name, ".response."); i >= 0 {
+		return i
+	}
+	return strings.Index(name, ".request.")
+}
+
+// RequestKey returns the key under which the result of a request from a
+// given file is stored, for file-qualified request variables
+func RequestKey(file, name string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	return filepath.Clean(file) + "#" + name
+}
+
 // addDuration adds a duration to a time based on unit
 func addDuration(t time.Time, offset int, unit string) time.Time {
 	switch unit {
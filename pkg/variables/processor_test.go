@@ -1,6 +1,7 @@
 package variables
 
 import (
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
@@ -314,6 +315,62 @@ func TestResolveRequestVariables(t *testing.T) {
 	}
 }
 
+func TestResolveRequestVariables_OtherFile(t *testing.T) {
+	dir := t.TempDir()
+	vp := NewVariableProcessor()
+	vp.SetCurrentDir(dir)
+	vp.SetRequestResult("login", RequestResult{StatusCode: 200, Body: `{"token": "local"}`})
+	vp.SetRequestResult(RequestKey(filepath.Join(dir, "auth", "auth.http"), "login"), RequestResult{StatusCode: 200, Body: `{"token": "shared"}`})
+
+	got, err := vp.Process("{{auth/auth.http#login.response.body.$.token}} {{login.response.body.$.token}}")
+	if err != nil || got != "shared local" {
+		t.Errorf("Process() = %q, %v, want %q", got, err, "shared local")
+	}
+
+	if _, err := vp.Process("{{other.http#login.response.body.$.token}}"); err == nil || !strings.Contains(err.Error(), "other.http#login") {
+		t.Errorf("Process() error = %v, want the qualified request name", err)
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	vp := NewVariableProcessor()
+	vp.SetRequestResult("login", RequestResult{StatusCode: 200, Body: `{"token": "first"}`})
+
+	got, err := vp.Process("{{login.response.body.$.token}}")
+	if err != nil || got != "first" {
+		t.Fatalf("Process() = %q, %v, want %q", got, err, "first")
+	}
+
+	vp.SetRequestResult("login", RequestResult{StatusCode: 200, Body: `{"token": "second"}`})
+
+	got, _ = vp.Process("{{login.response.body.$.token}}")
+	if got != "first" {
+		t.Errorf("Process() before ClearCache = %q, want cached %q", got, "first")
+	}
+
+	vp.ClearCache()
+
+	got, err = vp.Process("{{login.response.body.$.token}}")
+	if err != nil || got != "second" {
+		t.Errorf("Process() after ClearCache = %q, %v, want %q", got, err, "second")
+	}
+}
+
+func TestUnsetFileVariables(t *testing.T) {
+	vp := NewVariableProcessor()
+	vp.SetFileVariables(map[string]string{"host": "a.example.com", "token": "file"})
+	vp.SetFileVariables(map[string]string{"token": "script"})
+
+	vp.UnsetFileVariables(map[string]string{"host": "a.example.com", "token": "file"})
+
+	if _, err := vp.Process("{{host}}"); err == nil {
+		t.Error("host should be unset")
+	}
+	if got, err := vp.Process("{{token}}"); err != nil || got != "script" {
+		t.Errorf("Process() = %q, %v, want the changed value %q", got, err, "script")
+	}
+}
+
 func TestURLEncodedVariable(t *testing.T) {
 	vp := NewVariableProcessor()
 	vp.SetFileVariables(map[string]string{